@@ -13,15 +13,30 @@ const (
 	// MessageCreated メッセージ作成イベント
 	MessageCreated model.BotEvent = "MESSAGE_CREATED"
 
+	// ThreadReplyCreated スレッド返信メッセージ作成イベント
+	ThreadReplyCreated model.BotEvent = "THREAD_REPLY_CREATED"
+
 	// DirectMessageCreated ダイレクトメッセージ作成イベント
 	DirectMessageCreated model.BotEvent = "DIRECT_MESSAGE_CREATED"
 
+	// MessageMoved メッセージ移動イベント
+	MessageMoved model.BotEvent = "MESSAGE_MOVED"
+
+	// MessageRestored メッセージ復元イベント
+	MessageRestored model.BotEvent = "MESSAGE_RESTORED"
+
 	// ChannelCreated チャンネル作成イベント
 	ChannelCreated model.BotEvent = "CHANNEL_CREATED"
 
 	// ChannelTopicChanged チャンネルトピック変更イベント
 	ChannelTopicChanged model.BotEvent = "CHANNEL_TOPIC_CHANGED"
 
+	// ChannelMemberJoined ユーザーのチャンネル参加イベント
+	ChannelMemberJoined model.BotEvent = "CHANNEL_MEMBER_JOINED"
+
+	// ChannelMemberLeft ユーザーのチャンネル退出イベント
+	ChannelMemberLeft model.BotEvent = "CHANNEL_MEMBER_LEFT"
+
 	// UserCreated ユーザー作成イベント
 	UserCreated model.BotEvent = "USER_CREATED"
 )
@@ -31,9 +46,14 @@ var eventSet = map[model.BotEvent]bool{
 	Joined:               true,
 	Left:                 true,
 	MessageCreated:       true,
+	ThreadReplyCreated:   true,
 	DirectMessageCreated: true,
+	MessageMoved:         true,
+	MessageRestored:      true,
 	ChannelCreated:       true,
 	ChannelTopicChanged:  true,
+	ChannelMemberJoined:  true,
+	ChannelMemberLeft:    true,
 	UserCreated:          true,
 }
 
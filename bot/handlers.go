@@ -1,6 +1,7 @@
 package bot
 
 import (
+	"strings"
 	"sync"
 
 	"github.com/gofrs/uuid"
@@ -19,15 +20,19 @@ var eventHandlerSet = map[string]eventHandler{
 	event.BotLeft:             botJoinedAndLeftHandler,
 	event.BotPingRequest:      botPingRequestHandler,
 	event.MessageCreated:      messageCreatedHandler,
+	event.ThreadReplyCreated:  threadReplyCreatedHandler,
+	event.MessageMoved:        messageMovedHandler,
+	event.MessageRestored:     messageRestoredHandler,
 	event.UserCreated:         userCreatedHandler,
 	event.ChannelCreated:      channelCreatedHandler,
 	event.ChannelTopicUpdated: channelTopicUpdatedHandler,
+	event.ChannelMemberJoined: channelMemberJoinedAndLeftHandler,
+	event.ChannelMemberLeft:   channelMemberJoinedAndLeftHandler,
 }
 
 func messageCreatedHandler(p *Processor, _ string, fields hub.Fields) {
 	m := fields["message"].(*model.Message)
-	embedded := fields["embedded"].([]*message.EmbeddedInfo)
-	plain := fields["plain"].(string)
+	doc := fields["document"].(*message.Document)
 
 	ch, err := p.repo.GetChannel(m.ChannelID)
 	if err != nil {
@@ -67,12 +72,12 @@ func messageCreatedHandler(p *Processor, _ string, fields hub.Fields) {
 			return
 		}
 
-		payload := directMessageCreatedPayload{
-			basePayload: makeBasePayload(),
-			Message:     makeMessagePayload(m, user, embedded, plain),
-		}
-
-		multicast(p, DirectMessageCreated, &payload, []*model.Bot{bot})
+		multicastMessage(p, DirectMessageCreated, doc, []*model.Bot{bot}, func(citations []*repository.MessageCitation) interface{} {
+			return &directMessageCreatedPayload{
+				basePayload: makeBasePayload(),
+				Message:     makeMessagePayload(m, user, doc, citations),
+			}
+		})
 	} else {
 		bots, err := p.repo.GetBotsByChannel(m.ChannelID)
 		if err != nil {
@@ -84,13 +89,163 @@ func messageCreatedHandler(p *Processor, _ string, fields hub.Fields) {
 			return
 		}
 
-		payload := messageCreatedPayload{
+		multicastMessage(p, MessageCreated, doc, bots, func(citations []*repository.MessageCitation) interface{} {
+			return &messageCreatedPayload{
+				basePayload: makeBasePayload(),
+				Message:     makeMessagePayload(m, user, doc, citations),
+			}
+		})
+	}
+}
+
+func threadReplyCreatedHandler(p *Processor, _ string, fields hub.Fields) {
+	m := fields["message"].(*model.Message)
+	doc := fields["document"].(*message.Document)
+
+	ch, err := p.repo.GetChannel(m.ChannelID)
+	if err != nil {
+		p.logger.Error("failed to GetChannel", zap.Error(err), zap.Stringer("id", m.ChannelID))
+		return
+	}
+
+	var bots []*model.Bot
+	if ch.IsDMChannel() {
+		ids, err := p.repo.GetPrivateChannelMemberIDs(ch.ID)
+		if err != nil {
+			p.logger.Error("failed to GetPrivateChannelMemberIDs", zap.Error(err), zap.Stringer("id", ch.ID))
+			return
+		}
+		for _, id := range ids {
+			if id == m.UserID {
+				continue
+			}
+			bot, err := p.repo.GetBotByBotUserID(id)
+			if err != nil {
+				if err != repository.ErrNotFound {
+					p.logger.Error("failed to GetBotByBotUserID", zap.Error(err), zap.Stringer("id", id))
+				}
+				continue
+			}
+			bots = append(bots, bot)
+		}
+	} else {
+		bots, err = p.repo.GetBotsByChannel(m.ChannelID)
+		if err != nil {
+			p.logger.Error("failed to GetBotsByChannel", zap.Error(err), zap.Stringer("id", m.ChannelID))
+			return
+		}
+	}
+	bots = filterBots(p, bots, stateFilter(model.BotActive), eventFilter(ThreadReplyCreated), botUserIDNotEqualsFilter(m.UserID))
+	if len(bots) == 0 {
+		return
+	}
+
+	user, err := p.repo.GetUser(m.UserID)
+	if err != nil {
+		p.logger.Error("failed to GetUser", zap.Error(err), zap.Stringer("id", m.UserID))
+		return
+	}
+
+	multicastMessage(p, ThreadReplyCreated, doc, bots, func(citations []*repository.MessageCitation) interface{} {
+		return &threadReplyCreatedPayload{
 			basePayload: makeBasePayload(),
-			Message:     makeMessagePayload(m, user, embedded, plain),
+			Message:     makeMessagePayload(m, user, doc, citations),
+			ParentID:    m.ParentID,
 		}
+	})
+}
+
+func messageRestoredHandler(p *Processor, _ string, fields hub.Fields) {
+	m := fields["message"].(*model.Message)
+	doc := fields["document"].(*message.Document)
 
-		multicast(p, MessageCreated, &payload, bots)
+	ch, err := p.repo.GetChannel(m.ChannelID)
+	if err != nil {
+		p.logger.Error("failed to GetChannel", zap.Error(err), zap.Stringer("id", m.ChannelID))
+		return
 	}
+
+	var bots []*model.Bot
+	if ch.IsDMChannel() {
+		ids, err := p.repo.GetPrivateChannelMemberIDs(ch.ID)
+		if err != nil {
+			p.logger.Error("failed to GetPrivateChannelMemberIDs", zap.Error(err), zap.Stringer("id", ch.ID))
+			return
+		}
+		for _, id := range ids {
+			if id == m.UserID {
+				continue
+			}
+			bot, err := p.repo.GetBotByBotUserID(id)
+			if err != nil {
+				if err != repository.ErrNotFound {
+					p.logger.Error("failed to GetBotByBotUserID", zap.Error(err), zap.Stringer("id", id))
+				}
+				continue
+			}
+			bots = append(bots, bot)
+		}
+	} else {
+		bots, err = p.repo.GetBotsByChannel(m.ChannelID)
+		if err != nil {
+			p.logger.Error("failed to GetBotsByChannel", zap.Error(err), zap.Stringer("id", m.ChannelID))
+			return
+		}
+	}
+	bots = filterBots(p, bots, stateFilter(model.BotActive), eventFilter(MessageRestored), botUserIDNotEqualsFilter(m.UserID))
+	if len(bots) == 0 {
+		return
+	}
+
+	user, err := p.repo.GetUser(m.UserID)
+	if err != nil {
+		p.logger.Error("failed to GetUser", zap.Error(err), zap.Stringer("id", m.UserID))
+		return
+	}
+
+	multicastMessage(p, MessageRestored, doc, bots, func(citations []*repository.MessageCitation) interface{} {
+		return &messageRestoredPayload{
+			basePayload: makeBasePayload(),
+			Message:     makeMessagePayload(m, user, doc, citations),
+			ParentID:    m.ParentID,
+		}
+	})
+}
+
+func messageMovedHandler(p *Processor, _ string, fields hub.Fields) {
+	ids := fields["message_ids"].([]uuid.UUID)
+	fromID := fields["from_channel_id"].(uuid.UUID)
+	toID := fields["to_channel_id"].(uuid.UUID)
+
+	// 移動元・移動先の両方のチャンネルに参加しているBOTには一度だけ送信する
+	var bots []*model.Bot
+	added := make(map[uuid.UUID]bool)
+	for _, chID := range []uuid.UUID{fromID, toID} {
+		bs, err := p.repo.GetBotsByChannel(chID)
+		if err != nil {
+			p.logger.Error("failed to GetBotsByChannel", zap.Error(err), zap.Stringer("id", chID))
+			return
+		}
+		for _, b := range bs {
+			if !added[b.ID] {
+				added[b.ID] = true
+				bots = append(bots, b)
+			}
+		}
+	}
+	bots = filterBots(p, bots, stateFilter(model.BotActive), eventFilter(MessageMoved))
+	if len(bots) == 0 {
+		return
+	}
+
+	payload := messageMovedPayload{
+		basePayload:   makeBasePayload(),
+		MessageIDs:    ids,
+		FromChannelID: fromID,
+		ToChannelID:   toID,
+	}
+
+	multicast(p, MessageMoved, &payload, bots)
 }
 
 func botJoinedAndLeftHandler(p *Processor, ev string, fields hub.Fields) {
@@ -253,6 +408,61 @@ func channelTopicUpdatedHandler(p *Processor, _ string, fields hub.Fields) {
 	multicast(p, ChannelTopicChanged, &payload, bots)
 }
 
+func channelMemberJoinedAndLeftHandler(p *Processor, ev string, fields hub.Fields) {
+	chID := fields["channel_id"].(uuid.UUID)
+	userID := fields["user_id"].(uuid.UUID)
+
+	var botEvent model.BotEvent
+	switch ev {
+	case event.ChannelMemberJoined:
+		botEvent = ChannelMemberJoined
+	case event.ChannelMemberLeft:
+		botEvent = ChannelMemberLeft
+	}
+
+	bots, err := p.repo.GetBotsByChannel(chID)
+	if err != nil {
+		p.logger.Error("failed to GetBotsByChannel", zap.Error(err), zap.Stringer("id", chID))
+		return
+	}
+	bots = filterBots(p, bots, stateFilter(model.BotActive), eventFilter(botEvent))
+	if len(bots) == 0 {
+		return
+	}
+
+	ch, err := p.repo.GetChannel(chID)
+	if err != nil {
+		p.logger.Error("failed to GetChannel", zap.Error(err), zap.Stringer("id", chID))
+		return
+	}
+
+	path, err := p.repo.GetChannelPath(chID)
+	if err != nil {
+		p.logger.Error("failed to GetChannelPath", zap.Error(err), zap.Stringer("id", chID))
+		return
+	}
+
+	chCreator, err := p.repo.GetUser(ch.CreatorID)
+	if err != nil {
+		p.logger.Error("failed to GetUser", zap.Error(err), zap.Stringer("id", ch.CreatorID))
+		return
+	}
+
+	user, err := p.repo.GetUser(userID)
+	if err != nil {
+		p.logger.Error("failed to GetUser", zap.Error(err), zap.Stringer("id", userID))
+		return
+	}
+
+	payload := channelMemberPayload{
+		basePayload: makeBasePayload(),
+		Channel:     makeChannelPayload(ch, path, chCreator),
+		User:        makeUserPayload(user),
+	}
+
+	multicast(p, botEvent, &payload, bots)
+}
+
 func botPingRequestHandler(p *Processor, _ string, fields hub.Fields) {
 	botID := fields["bot_id"].(uuid.UUID)
 	bot, err := p.repo.GetBotByID(botID)
@@ -285,6 +495,48 @@ func botPingRequestHandler(p *Processor, _ string, fields hub.Fields) {
 	}
 }
 
+// multicastMessage メッセージを含むイベントを送信します
+//
+// メッセージ中の引用はBOTユーザーがアクセス可能なものだけを解決し、解決結果が同じBOTごとにまとめて送信します。
+func multicastMessage(p *Processor, ev model.BotEvent, doc *message.Document, targets []*model.Bot, makePayload func(citations []*repository.MessageCitation) interface{}) {
+	var ids []uuid.UUID
+	for _, v := range doc.CitedMessageIDs() {
+		if id, err := uuid.FromString(v); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		multicast(p, ev, makePayload(nil), targets)
+		return
+	}
+
+	type group struct {
+		citations []*repository.MessageCitation
+		bots      []*model.Bot
+	}
+	groups := make(map[string]*group)
+	for _, bot := range targets {
+		citations, err := p.repo.GetMessageCitations(bot.BotUserID, ids)
+		if err != nil {
+			p.logger.Error("failed to GetMessageCitations", zap.Error(err), zap.Stringer("id", bot.BotUserID))
+			continue
+		}
+		var key strings.Builder
+		for _, c := range citations {
+			key.WriteString(c.MessageID.String())
+		}
+		g, ok := groups[key.String()]
+		if !ok {
+			g = &group{citations: citations}
+			groups[key.String()] = g
+		}
+		g.bots = append(g.bots, bot)
+	}
+	for _, g := range groups {
+		multicast(p, ev, makePayload(g.citations), g.bots)
+	}
+}
+
 func multicast(p *Processor, ev model.BotEvent, payload interface{}, targets []*model.Bot) {
 	buf, release, err := p.makePayloadJSON(&payload)
 	if err != nil {
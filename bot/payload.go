@@ -5,6 +5,7 @@ import (
 
 	"github.com/gofrs/uuid"
 	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
 	"github.com/traPtitech/traQ/utils/message"
 )
 
@@ -25,23 +26,49 @@ type messagePayload struct {
 	Text      string                  `json:"text"`
 	PlainText string                  `json:"plainText"`
 	Embedded  []*message.EmbeddedInfo `json:"embedded"`
+	Citations []citationPayload       `json:"citations"`
 	CreatedAt time.Time               `json:"createdAt"`
 	UpdatedAt time.Time               `json:"updatedAt"`
 }
 
-func makeMessagePayload(message *model.Message, user *model.User, embedded []*message.EmbeddedInfo, plain string) messagePayload {
+func makeMessagePayload(message *model.Message, user *model.User, doc *message.Document, citations []*repository.MessageCitation) messagePayload {
 	return messagePayload{
 		ID:        message.ID,
 		User:      makeUserPayload(user),
 		ChannelID: message.ChannelID,
 		Text:      message.Text,
-		PlainText: plain,
-		Embedded:  embedded,
+		PlainText: doc.SingleLineText(),
+		Embedded:  doc.Embedded(),
+		Citations: makeCitationPayloads(citations),
 		CreatedAt: message.CreatedAt,
 		UpdatedAt: message.UpdatedAt,
 	}
 }
 
+type citationPayload struct {
+	MessageID   uuid.UUID `json:"messageId"`
+	UserID      uuid.UUID `json:"userId"`
+	ChannelID   uuid.UUID `json:"channelId"`
+	ChannelPath string    `json:"channelPath"`
+	Excerpt     string    `json:"excerpt"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+func makeCitationPayloads(citations []*repository.MessageCitation) []citationPayload {
+	res := make([]citationPayload, len(citations))
+	for i, c := range citations {
+		res[i] = citationPayload{
+			MessageID:   c.MessageID,
+			UserID:      c.UserID,
+			ChannelID:   c.ChannelID,
+			ChannelPath: c.ChannelPath,
+			Excerpt:     c.Excerpt,
+			CreatedAt:   c.CreatedAt,
+		}
+	}
+	return res
+}
+
 type channelPayload struct {
 	ID        uuid.UUID   `json:"id"`
 	Name      string      `json:"name"`
@@ -87,11 +114,30 @@ type messageCreatedPayload struct {
 	Message messagePayload `json:"message"`
 }
 
+type threadReplyCreatedPayload struct {
+	basePayload
+	Message  messagePayload `json:"message"`
+	ParentID uuid.UUID      `json:"parentId"`
+}
+
 type directMessageCreatedPayload struct {
 	basePayload
 	Message messagePayload `json:"message"`
 }
 
+type messageRestoredPayload struct {
+	basePayload
+	Message  messagePayload `json:"message"`
+	ParentID uuid.UUID      `json:"parentId"`
+}
+
+type messageMovedPayload struct {
+	basePayload
+	MessageIDs    []uuid.UUID `json:"messageIds"`
+	FromChannelID uuid.UUID   `json:"fromChannelId"`
+	ToChannelID   uuid.UUID   `json:"toChannelId"`
+}
+
 type pingPayload struct {
 	basePayload
 }
@@ -113,6 +159,12 @@ type channelTopicChangedPayload struct {
 	Updater userPayload    `json:"updater"`
 }
 
+type channelMemberPayload struct {
+	basePayload
+	Channel channelPayload `json:"channel"`
+	User    userPayload    `json:"user"`
+}
+
 type userCreatedPayload struct {
 	basePayload
 	User userPayload `json:"user"`
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"time"
+
+	"github.com/traPtitech/traQ/exporter"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	channelExportDefaultInterval = 10 * time.Second
+	channelExportFetchLimit      = 10
+	// channelExportRunningTimeout 処理中のままこの時間が経過したチャンネルエクスポートは失敗とみなす
+	channelExportRunningTimeout = time.Hour
+)
+
+// ChannelExportWorker チャンネルエクスポート処理者構造体
+//
+// チャンネルエクスポートはDBで管理されるため、リクエストとは独立して処理され、プロセスの再起動後も処理されます。
+// 処理前にDB上で状態をアトミックに遷移させるため、複数のプロセスで動作させても二重に処理されることはありません。
+type ChannelExportWorker struct {
+	repo     repository.Repository
+	logger   *zap.Logger
+	interval time.Duration
+	done     chan struct{}
+}
+
+// NewChannelExportWorker ChannelExportWorkerを生成し、起動します
+func NewChannelExportWorker(repo repository.Repository, logger *zap.Logger, interval time.Duration) *ChannelExportWorker {
+	if interval <= 0 {
+		interval = channelExportDefaultInterval
+	}
+	w := &ChannelExportWorker{
+		repo:     repo,
+		logger:   logger,
+		interval: interval,
+		done:     make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Stop ChannelExportWorkerを停止します
+func (w *ChannelExportWorker) Stop() {
+	close(w.done)
+}
+
+func (w *ChannelExportWorker) run() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.process()
+	for {
+		select {
+		case <-ticker.C:
+			w.process()
+		case <-w.done:
+			return
+		}
+	}
+}
+
+func (w *ChannelExportWorker) process() {
+	// 処理中にプロセスが落ちたものを失敗にする
+	if n, err := w.repo.FailStaleChannelExports(time.Now().Add(-channelExportRunningTimeout)); err != nil {
+		w.logger.Error("failed to FailStaleChannelExports", zap.Error(err))
+	} else if n > 0 {
+		w.logger.Warn("channel exports interrupted while running were marked as failed", zap.Int("count", n))
+	}
+
+	exports, err := w.repo.GetPendingChannelExports(channelExportFetchLimit)
+	if err != nil {
+		w.logger.Error("failed to GetPendingChannelExports", zap.Error(err))
+		return
+	}
+	for _, e := range exports {
+		w.export(e)
+	}
+}
+
+func (w *ChannelExportWorker) export(e *model.ChannelExport) {
+	logger := w.logger.With(zap.Stringer("channelExportId", e.ID), zap.Stringer("channelId", e.ChannelID))
+
+	// 他のプロセスが既に処理している場合は何もしない
+	ok, err := w.repo.ClaimChannelExport(e.ID)
+	if err != nil {
+		logger.Error("failed to ClaimChannelExport", zap.Error(err))
+		return
+	}
+	if !ok {
+		return
+	}
+
+	file, err := exporter.SaveArchive(w.repo, e)
+	if err != nil {
+		logger.Error("failed to export channel", zap.Error(err))
+		reason := "failed to export the channel"
+		if err == repository.ErrNotFound {
+			reason = "the channel is not found"
+		}
+		if err := w.repo.MarkChannelExportFailed(e.ID, reason); err != nil {
+			logger.Error("failed to MarkChannelExportFailed", zap.Error(err))
+		}
+		return
+	}
+
+	if err := w.repo.MarkChannelExportCompleted(e.ID, file.ID); err != nil {
+		logger.Error("failed to MarkChannelExportCompleted", zap.Error(err), zap.Stringer("fileId", file.ID))
+	}
+}
@@ -72,7 +72,17 @@ const (
 	//  	message: *model.Message
 	//  	embedded: []*message.EmbeddedInfo
 	//      plain: string
+	//      document: *message.Document
 	MessageCreated = "message.created"
+	// ThreadReplyCreated スレッドに返信メッセージが作成された
+	// 	Fields:
+	// 		message_id: uuid.UUID
+	//  	message: *model.Message
+	// 		parent_id: uuid.UUID
+	//  	embedded: []*message.EmbeddedInfo
+	//      plain: string
+	//      document: *message.Document
+	ThreadReplyCreated = "message.thread_reply.created"
 	// MessageUpdated メッセージが更新された
 	// 	Fields:
 	// 		message_id: uuid.UUID
@@ -84,6 +94,20 @@ const (
 	// 		message_id: uuid.UUID
 	//  	message: *model.Message
 	MessageDeleted = "message.deleted"
+	// MessageRestored 削除されたメッセージが復元された
+	// 	Fields:
+	// 		message_id: uuid.UUID
+	//  	message: *model.Message
+	//  	embedded: []*message.EmbeddedInfo
+	//      plain: string
+	//      document: *message.Document
+	MessageRestored = "message.restored"
+	// MessageMoved メッセージが別のチャンネルに移動された
+	// 	Fields:
+	// 		message_ids: []uuid.UUID
+	// 		from_channel_id: uuid.UUID
+	// 		to_channel_id: uuid.UUID
+	MessageMoved = "message.moved"
 	// MessageStamped メッセージにスタンプが押された
 	// 	Fields:
 	// 		message_id: uuid.UUID
@@ -108,6 +132,11 @@ const (
 	// 		message_id: uuid.UUID
 	// 		pin_id: uuid.UUID
 	MessageUnpinned = "message.unpinned"
+	// MessagePreviewsReady メッセージ中のURLのプレビューが取得できた
+	// 	Fields:
+	// 		message_id: uuid.UUID
+	//  	message: *model.Message
+	MessagePreviewsReady = "message.previews_ready"
 
 	// ChannelCreated チャンネルが作成された
 	// 	Fields:
@@ -130,6 +159,16 @@ const (
 	// 		channel_id: uuid.UUID
 	// 		private: bool
 	ChannelDeleted = "channel.deleted"
+	// ChannelMemberJoined ユーザーがパブリックチャンネルに参加した
+	// 	Fields:
+	// 		channel_id: uuid.UUID
+	// 		user_id: uuid.UUID
+	ChannelMemberJoined = "channel.member.joined"
+	// ChannelMemberLeft ユーザーがパブリックチャンネルから退出した
+	// 	Fields:
+	// 		channel_id: uuid.UUID
+	// 		user_id: uuid.UUID
+	ChannelMemberLeft = "channel.member.left"
 	// ChannelRead チャンネルのメッセージが既読された
 	// 	Fields:
 	// 		user_id: uuid.UUID
@@ -170,6 +209,32 @@ const (
 	// 		stamp_id: uuid.UUID
 	StampDeleted = "stamp.deleted"
 
+	// PollUpdated 投票の票が更新された
+	// 	Fields:
+	// 		poll_id: uuid.UUID
+	// 		channel_id: uuid.UUID
+	// 		message_id: uuid.UUID
+	PollUpdated = "poll.updated"
+
+	// ModerationCaseUpdated モデレーションケースが作成・更新された
+	// 	Fields:
+	// 		case_id: uuid.UUID
+	// 		message_id: uuid.UUID
+	ModerationCaseUpdated = "moderation.case_updated"
+	// ModerationWarned ユーザーがモデレーターから警告された
+	// 	Fields:
+	// 		case_id: uuid.UUID
+	// 		user_id: uuid.UUID
+	// 		message: string
+	ModerationWarned = "moderation.warned"
+
+	// ReminderFired リマインダーの日時になった
+	// 	Fields:
+	// 		reminder_id: uuid.UUID
+	// 		user_id: uuid.UUID
+	// 		message_id: uuid.UUID
+	ReminderFired = "reminder.fired"
+
 	// ClipCreated クリップが作成された
 	// 	Fields:
 	// 		user_id: uuid.UUID
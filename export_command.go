@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/gofrs/uuid"
+	"github.com/traPtitech/traQ/exporter"
+	"github.com/traPtitech/traQ/repository"
+)
+
+// runExportCommand チャンネルをzipアーカイブにエクスポートするサブコマンド
+//
+// 使い方: traQ export -channel <チャンネルID> [-descendants] [-o <出力先>]
+func runExportCommand(repo repository.Repository, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	channel := flags.String("channel", "", "export target channel id")
+	descendants := flags.Bool("descendants", false, "export descendant channels too")
+	output := flags.String("o", "", "output file path (default: <channel id>.zip, \"-\" for stdout)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	channelID, err := uuid.FromString(*channel)
+	if err != nil || channelID == uuid.Nil {
+		return errors.New("invalid channel id")
+	}
+	ch, err := repo.GetChannel(channelID)
+	if err != nil {
+		return err
+	}
+	if ch.IsDMChannel() {
+		return errors.New("dm channels cannot be exported")
+	}
+
+	if *output == "-" {
+		return exporter.Export(repo, os.Stdout, channelID, *descendants)
+	}
+
+	path := *output
+	if len(path) == 0 {
+		path = fmt.Sprintf("%s.zip", channelID)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := exportToFile(repo, f, channelID, *descendants); err != nil {
+		_ = os.Remove(path)
+		return err
+	}
+	return nil
+}
+
+func exportToFile(repo repository.Repository, f io.WriteCloser, channelID uuid.UUID, descendants bool) error {
+	if err := exporter.Export(repo, f, channelID, descendants); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,413 @@
+package exporter
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/utils/message"
+)
+
+// メッセージを一度に取得する件数
+const messagesPageSize = 200
+
+// Exporter チャンネルエクスポーター
+//
+// チャンネルのメッセージ・スタンプ・ピン・編集履歴・添付ファイルを、
+// JSONと静的HTMLを含むzipアーカイブとして書き出します。
+// メッセージはページ単位で取得して逐次書き出すため、チャンネル全体をメモリに載せることはありません。
+type Exporter struct {
+	repo repository.Repository
+	zw   *zip.Writer
+
+	channels []*channelPayload
+	users    map[uuid.UUID]*userPayload
+	stamps   map[uuid.UUID]*model.Stamp
+	files    map[uuid.UUID]*filePayload
+	fileIDs  []uuid.UUID
+}
+
+// Export 指定したチャンネルをzipアーカイブとしてwに書き出します
+//
+// withDescendantsがtrueの場合、子孫チャンネルも含めて書き出します。
+// チャンネルが存在しない場合はrepository.ErrNotFoundを返します。
+func Export(repo repository.Repository, w io.Writer, channelID uuid.UUID, withDescendants bool) error {
+	e := &Exporter{
+		repo:   repo,
+		zw:     zip.NewWriter(w),
+		users:  map[uuid.UUID]*userPayload{},
+		stamps: map[uuid.UUID]*model.Stamp{},
+		files:  map[uuid.UUID]*filePayload{},
+	}
+
+	ids := []uuid.UUID{channelID}
+	if withDescendants {
+		descendants, err := e.getDescendantChannelIDs(channelID)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, descendants...)
+	}
+
+	for _, id := range ids {
+		if err := e.exportChannel(id); err != nil {
+			return err
+		}
+	}
+	if err := e.writeJSON("channels.json", e.channels); err != nil {
+		return err
+	}
+	if err := e.writeUsers(); err != nil {
+		return err
+	}
+	if err := e.writeStamps(); err != nil {
+		return err
+	}
+	if err := e.writeFiles(); err != nil {
+		return err
+	}
+	if err := e.writeIndex(); err != nil {
+		return err
+	}
+	return e.zw.Close()
+}
+
+func (e *Exporter) getDescendantChannelIDs(channelID uuid.UUID) ([]uuid.UUID, error) {
+	var result []uuid.UUID
+	children, err := e.repo.GetChildrenChannelIDs(channelID)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range children {
+		result = append(result, v)
+		sub, err := e.getDescendantChannelIDs(v)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, sub...)
+	}
+	return result, nil
+}
+
+func (e *Exporter) exportChannel(channelID uuid.UUID) error {
+	ch, err := e.repo.GetChannel(channelID)
+	if err != nil {
+		return err
+	}
+	path, err := e.repo.GetChannelPath(channelID)
+	if err != nil {
+		return err
+	}
+	cp := makeChannelPayload(ch, path)
+	e.channels = append(e.channels, cp)
+
+	if err := e.writeMessagesJSON(channelID); err != nil {
+		return err
+	}
+	return e.writeMessagesHTML(cp)
+}
+
+// eachMessages チャンネルの全メッセージを投稿日時の昇順でページ単位で走査します
+func (e *Exporter) eachMessages(channelID uuid.UUID, f func(m *model.Message) error) error {
+	var after uuid.UUID
+	for {
+		messages, more, err := e.repo.GetMessages(repository.MessagesQuery{
+			Channel: channelID,
+			After:   after,
+			Limit:   messagesPageSize,
+			Asc:     true,
+		})
+		if err != nil {
+			return err
+		}
+		for _, m := range messages {
+			if err := f(m); err != nil {
+				return err
+			}
+		}
+		if !more || len(messages) == 0 {
+			return nil
+		}
+		after = messages[len(messages)-1].ID
+	}
+}
+
+func (e *Exporter) writeMessagesJSON(channelID uuid.UUID) error {
+	w, err := e.zw.Create(fmt.Sprintf("channels/%s/messages.json", channelID))
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	first := true
+	err = e.eachMessages(channelID, func(m *model.Message) error {
+		var history []*model.ArchivedMessage
+		if m.IsEdited() {
+			h, err := e.repo.GetArchivedMessagesByID(m.ID)
+			if err != nil {
+				return err
+			}
+			history = h
+		}
+		if err := e.collectReferences(m); err != nil {
+			return err
+		}
+
+		if !first {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		first = false
+		return enc.Encode(makeMessagePayload(m, history))
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "]")
+	return err
+}
+
+// collectReferences メッセージが参照しているユーザー・スタンプ・ファイルを記録します
+func (e *Exporter) collectReferences(m *model.Message) error {
+	if err := e.addUser(m.UserID); err != nil {
+		return err
+	}
+	for _, s := range m.Stamps {
+		if err := e.addUser(s.UserID); err != nil {
+			return err
+		}
+		if err := e.addStamp(s.StampID); err != nil {
+			return err
+		}
+	}
+	for _, id := range embeddedFileIDs(m.Text) {
+		if err := e.addFile(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *Exporter) addUser(id uuid.UUID) error {
+	if _, ok := e.users[id]; ok {
+		return nil
+	}
+	user, err := e.repo.GetUser(id)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			e.users[id] = nil
+			return nil
+		}
+		return err
+	}
+	e.users[id] = makeUserPayload(user)
+	return nil
+}
+
+func (e *Exporter) addStamp(id uuid.UUID) error {
+	if _, ok := e.stamps[id]; ok {
+		return nil
+	}
+	s, err := e.repo.GetStamp(id)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			e.stamps[id] = nil
+			return nil
+		}
+		return err
+	}
+	e.stamps[id] = s
+	return nil
+}
+
+func (e *Exporter) addFile(id uuid.UUID) error {
+	if _, ok := e.files[id]; ok {
+		return nil
+	}
+	meta, err := e.repo.GetFileMeta(id)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			e.files[id] = nil
+			return nil
+		}
+		return err
+	}
+	e.files[id] = &filePayload{
+		ID:        meta.ID,
+		Name:      meta.Name,
+		Mime:      meta.Mime,
+		Size:      meta.Size,
+		MD5:       meta.Hash,
+		Path:      fmt.Sprintf("files/%s/%s", meta.ID, sanitizeFileName(meta.Name)),
+		CreatedAt: meta.CreatedAt,
+	}
+	e.fileIDs = append(e.fileIDs, id)
+	return nil
+}
+
+func (e *Exporter) writeMessagesHTML(ch *channelPayload) error {
+	w, err := e.zw.Create(fmt.Sprintf("channels/%s/index.html", ch.ID))
+	if err != nil {
+		return err
+	}
+	if err := templates.ExecuteTemplate(w, "channelHeader", ch); err != nil {
+		return err
+	}
+	err = e.eachMessages(ch.ID, func(m *model.Message) error {
+		return templates.ExecuteTemplate(w, "message", e.makeMessageView(m))
+	})
+	if err != nil {
+		return err
+	}
+	return templates.ExecuteTemplate(w, "channelFooter", nil)
+}
+
+func (e *Exporter) makeMessageView(m *model.Message) *messageView {
+	v := &messageView{
+		ID:        m.ID.String(),
+		UserName:  m.UserID.String(),
+		Content:   renderContent(m.Text),
+		CreatedAt: m.CreatedAt,
+		Reply:     m.IsThreadReply(),
+		Edited:    m.IsEdited(),
+		Pinned:    m.Pin != nil,
+	}
+	if u := e.users[m.UserID]; u != nil {
+		v.UserName = u.DisplayName
+		if len(v.UserName) == 0 {
+			v.UserName = u.Name
+		}
+	}
+	for _, id := range embeddedFileIDs(m.Text) {
+		if f := e.files[id]; f != nil {
+			v.Files = append(v.Files, f)
+		}
+	}
+	stamps := map[uuid.UUID]*stampView{}
+	for _, s := range m.Stamps {
+		sv, ok := stamps[s.StampID]
+		if !ok {
+			sv = &stampView{Name: s.StampID.String()}
+			if st := e.stamps[s.StampID]; st != nil {
+				sv.Name = st.Name
+			}
+			stamps[s.StampID] = sv
+			v.Stamps = append(v.Stamps, sv)
+		}
+		sv.Count += s.Count
+	}
+	return v
+}
+
+func (e *Exporter) writeUsers() error {
+	users := make([]*userPayload, 0, len(e.users))
+	for _, u := range e.users {
+		if u != nil {
+			users = append(users, u)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Name < users[j].Name })
+	return e.writeJSON("users.json", users)
+}
+
+func (e *Exporter) writeStamps() error {
+	stamps := make([]*model.Stamp, 0, len(e.stamps))
+	for _, s := range e.stamps {
+		if s != nil {
+			stamps = append(stamps, s)
+		}
+	}
+	sort.Slice(stamps, func(i, j int) bool { return stamps[i].Name < stamps[j].Name })
+	return e.writeJSON("stamps.json", stamps)
+}
+
+func (e *Exporter) writeFiles() error {
+	files := make([]*filePayload, 0, len(e.fileIDs))
+	for _, id := range e.fileIDs {
+		meta, r, err := e.repo.OpenFile(id)
+		if err != nil {
+			if err == repository.ErrNotFound {
+				continue
+			}
+			return err
+		}
+		f := e.files[id]
+		w, err := e.zw.CreateHeader(&zip.FileHeader{
+			Name:     f.Path,
+			Method:   zip.Store,
+			Modified: meta.CreatedAt,
+		})
+		if err != nil {
+			r.Close()
+			return err
+		}
+		_, err = io.Copy(w, r)
+		r.Close()
+		if err != nil {
+			return err
+		}
+		files = append(files, f)
+	}
+	return e.writeJSON("files.json", files)
+}
+
+func (e *Exporter) writeIndex() error {
+	w, err := e.zw.Create("index.html")
+	if err != nil {
+		return err
+	}
+	return templates.ExecuteTemplate(w, "index", &indexView{
+		ExportedAt: time.Now(),
+		Channels:   e.channels,
+	})
+}
+
+func (e *Exporter) writeJSON(name string, v interface{}) error {
+	w, err := e.zw.Create(name)
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(w).Encode(v)
+}
+
+// embeddedFileIDs メッセージ本文に埋め込まれているファイルのIDを返します
+func embeddedFileIDs(text string) (ids []uuid.UUID) {
+	embedded, _ := message.Parse(text)
+	for _, v := range embedded {
+		if v.Type != "file" {
+			continue
+		}
+		id, err := uuid.FromString(v.ID)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// renderContent メッセージ本文をHTMLに変換します
+func renderContent(text string) template.HTML {
+	return template.HTML(message.ParseAST(text).HTML())
+}
+
+func sanitizeFileName(name string) string {
+	name = strings.NewReplacer("/", "_", "\\", "_").Replace(name)
+	if len(name) == 0 || name == "." || name == ".." {
+		return "file"
+	}
+	return name
+}
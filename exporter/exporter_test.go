@@ -0,0 +1,36 @@
+package exporter
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmbeddedFileIDs(t *testing.T) {
+	t.Parallel()
+
+	id := uuid.Must(uuid.NewV4())
+	text := fmt.Sprintf(`test !{"type":"file","raw":"","id":"%s"} !{"type":"user","raw":"@test","id":"%s"} !{"type":"file","raw":"","id":"invalid"}`, id, uuid.Must(uuid.NewV4()))
+	assert.Equal(t, []uuid.UUID{id}, embeddedFileIDs(text))
+	assert.Empty(t, embeddedFileIDs("test"))
+}
+
+func TestRenderContent(t *testing.T) {
+	t.Parallel()
+
+	id := uuid.Must(uuid.NewV4())
+	text := fmt.Sprintf("hello <b>\n!{\"type\":\"user\",\"raw\":\"@test\",\"id\":\"%s\"}\n!{\"type\":\"file\",\"raw\":\"\",\"id\":\"%s\"}", id, id)
+	assert.EqualValues(t, fmt.Sprintf(`<p>hello &lt;b&gt;<br><span class="mention" data-type="user" data-id="%s">@test</span><br><span class="file" data-type="file" data-id="%s">[添付ファイル]</span></p>`, id, id), renderContent(text))
+}
+
+func TestSanitizeFileName(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "test.txt", sanitizeFileName("test.txt"))
+	assert.Equal(t, ".._.._etc_passwd", sanitizeFileName("../../etc/passwd"))
+	assert.Equal(t, "a_b", sanitizeFileName(`a\b`))
+	assert.Equal(t, "file", sanitizeFileName(""))
+	assert.Equal(t, "file", sanitizeFileName(".."))
+}
@@ -0,0 +1,119 @@
+package exporter
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/traPtitech/traQ/model"
+)
+
+type channelPayload struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Path      string    `json:"path"`
+	ParentID  uuid.UUID `json:"parentId"`
+	Topic     string    `json:"topic"`
+	IsPublic  bool      `json:"public"`
+	CreatorID uuid.UUID `json:"creatorId"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func makeChannelPayload(ch *model.Channel, path string) *channelPayload {
+	return &channelPayload{
+		ID:        ch.ID,
+		Name:      ch.Name,
+		Path:      "#" + path,
+		ParentID:  ch.ParentID,
+		Topic:     ch.Topic,
+		IsPublic:  ch.IsPublic,
+		CreatorID: ch.CreatorID,
+		CreatedAt: ch.CreatedAt,
+		UpdatedAt: ch.UpdatedAt,
+	}
+}
+
+type messagePayload struct {
+	ID        uuid.UUID            `json:"id"`
+	UserID    uuid.UUID            `json:"userId"`
+	ParentID  uuid.UUID            `json:"parentId"`
+	Content   string               `json:"content"`
+	EditCount int                  `json:"editCount"`
+	CreatedAt time.Time            `json:"createdAt"`
+	UpdatedAt time.Time            `json:"updatedAt"`
+	Stamps    []model.MessageStamp `json:"stamps"`
+	Pin       *pinPayload          `json:"pin"`
+	History   []*revisionPayload   `json:"history"`
+}
+
+type pinPayload struct {
+	UserID    uuid.UUID `json:"userId"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type revisionPayload struct {
+	RevisionID uuid.UUID `json:"revisionId"`
+	UserID     uuid.UUID `json:"userId"`
+	Content    string    `json:"content"`
+	DateTime   time.Time `json:"dateTime"`
+}
+
+func makeMessagePayload(m *model.Message, history []*model.ArchivedMessage) *messagePayload {
+	res := &messagePayload{
+		ID:        m.ID,
+		UserID:    m.UserID,
+		ParentID:  m.ParentID,
+		Content:   m.Text,
+		EditCount: m.EditCount,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+		Stamps:    m.Stamps,
+		History:   make([]*revisionPayload, len(history)),
+	}
+	if res.Stamps == nil {
+		res.Stamps = make([]model.MessageStamp, 0)
+	}
+	if m.Pin != nil {
+		res.Pin = &pinPayload{
+			UserID:    m.Pin.UserID,
+			CreatedAt: m.Pin.CreatedAt,
+		}
+	}
+	for i, v := range history {
+		res.History[i] = &revisionPayload{
+			RevisionID: v.ID,
+			UserID:     v.UserID,
+			Content:    v.Text,
+			DateTime:   v.DateTime,
+		}
+	}
+	return res
+}
+
+type userPayload struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	DisplayName string    `json:"displayName"`
+	IconID      uuid.UUID `json:"iconId"`
+	Bot         bool      `json:"bot"`
+}
+
+func makeUserPayload(user *model.User) *userPayload {
+	return &userPayload{
+		ID:          user.ID,
+		Name:        user.Name,
+		DisplayName: user.DisplayName,
+		IconID:      user.Icon,
+		Bot:         user.Bot,
+	}
+}
+
+type filePayload struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Mime      string    `json:"mime"`
+	Size      int64     `json:"size"`
+	MD5       string    `json:"md5"`
+	Path      string    `json:"path"`
+	CreatedAt time.Time `json:"createdAt"`
+}
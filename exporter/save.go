@@ -0,0 +1,46 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+)
+
+// SaveArchive チャンネルエクスポートのアーカイブを作成し、ファイルとして保存します
+//
+// アーカイブは一時ファイルに書き出してから保存するため、書き出しに失敗した場合に不完全なファイルが残ることはありません。
+// 保存したファイルにはエクスポートを要求したユーザーのみがアクセスできます。
+// 成功した場合、保存したファイルのメタデータとnilを返します。
+func SaveArchive(repo repository.Repository, e *model.ChannelExport) (*model.File, error) {
+	ch, err := repo.GetChannel(e.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+
+	tmp, err := ioutil.TempFile("", "traq-export-*.zip")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if err := Export(repo, tmp, e.ChannelID, e.WithDescendants); err != nil {
+		return nil, err
+	}
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	name := fmt.Sprintf("%s-%s.zip", ch.Name, e.CreatedAt.Format("20060102150405"))
+	return repo.SaveFileWithACL(name, tmp, size, "application/zip", model.FileTypeChannelExport, e.UserID, repository.ACL{e.UserID: true})
+}
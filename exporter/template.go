@@ -0,0 +1,113 @@
+package exporter
+
+import (
+	"html/template"
+	"time"
+)
+
+var templates = template.Must(template.New("").Funcs(template.FuncMap{
+	"datetime": func(t time.Time) string {
+		return t.Format("2006-01-02 15:04:05")
+	},
+}).Parse(`
+{{- define "style" -}}
+<style>
+body { font-family: sans-serif; margin: 0 auto; max-width: 960px; padding: 16px; color: #333; }
+.message { border-bottom: 1px solid #eee; padding: 8px 0; }
+.message.reply { margin-left: 32px; }
+.meta { color: #888; font-size: 0.85em; }
+.name { font-weight: bold; color: #333; }
+.content { word-break: break-word; margin: 4px 0; }
+.content p { margin: 0; }
+.content pre { background: #f6f6f6; padding: 8px; overflow-x: auto; }
+.content blockquote { border-left: 4px solid #ddd; margin: 4px 0; padding-left: 8px; color: #666; }
+.mention, .channel-link { color: #005bac; }
+.stamps, .files { font-size: 0.85em; }
+.stamp { display: inline-block; background: #f3f3f3; border-radius: 4px; margin-right: 4px; padding: 0 4px; }
+</style>
+{{- end -}}
+
+{{- define "index" -}}
+<!DOCTYPE html>
+<html lang="ja">
+<head>
+<meta charset="utf-8">
+<title>traQ export</title>
+{{template "style"}}
+</head>
+<body>
+<h1>traQ export</h1>
+<p class="meta">exported at {{datetime .ExportedAt}}</p>
+<ul>
+{{- range .Channels}}
+<li><a href="channels/{{.ID}}/index.html">{{.Path}}</a></li>
+{{- end}}
+</ul>
+</body>
+</html>
+{{end -}}
+
+{{- define "channelHeader" -}}
+<!DOCTYPE html>
+<html lang="ja">
+<head>
+<meta charset="utf-8">
+<title>{{.Path}}</title>
+{{template "style"}}
+</head>
+<body>
+<p><a href="../../index.html">&laquo; index</a></p>
+<h1>{{.Path}}</h1>
+{{- if .Topic}}
+<p class="content">{{.Topic}}</p>
+{{- end}}
+{{end -}}
+
+{{- define "message" -}}
+<div class="message{{if .Reply}} reply{{end}}" id="{{.ID}}">
+<div class="meta"><span class="name">{{.UserName}}</span> {{datetime .CreatedAt}}{{if .Edited}} (edited){{end}}{{if .Pinned}} 📌{{end}}</div>
+<div class="content">{{.Content}}</div>
+{{- if .Files}}
+<div class="files">
+{{- range .Files}}
+<a href="../../{{.Path}}">{{.Name}}</a>
+{{- end}}
+</div>
+{{- end}}
+{{- if .Stamps}}
+<div class="stamps">
+{{- range .Stamps}}
+<span class="stamp">:{{.Name}}: {{.Count}}</span>
+{{- end}}
+</div>
+{{- end}}
+</div>
+{{end -}}
+
+{{- define "channelFooter" -}}
+</body>
+</html>
+{{end -}}
+`))
+
+type indexView struct {
+	ExportedAt time.Time
+	Channels   []*channelPayload
+}
+
+type messageView struct {
+	ID        string
+	UserName  string
+	Content   template.HTML
+	CreatedAt time.Time
+	Reply     bool
+	Edited    bool
+	Pinned    bool
+	Files     []*filePayload
+	Stamps    []*stampView
+}
+
+type stampView struct {
+	Name  string
+	Count int
+}
@@ -59,19 +59,27 @@ func NewFCMManager(repo repository.Repository, hub *hub.Hub, logger *zap.Logger,
 	}
 
 	go func() {
-		sub := hub.Subscribe(100, event.MessageCreated)
+		// MessageRestoredは新規投稿ではないため通知しない
+		sub := hub.Subscribe(100, event.MessageCreated, event.ThreadReplyCreated)
 		for ev := range sub.Receiver {
 			m := ev.Fields["message"].(*model.Message)
-			p := ev.Fields["plain"].(string)
-			e := ev.Fields["embedded"].([]*message.EmbeddedInfo)
-			go manager.processMessageCreated(m, p, e)
+			d := ev.Fields["document"].(*message.Document)
+			go manager.processMessageCreated(m, d)
+		}
+	}()
+	go func() {
+		sub := hub.Subscribe(100, event.ReminderFired)
+		for ev := range sub.Receiver {
+			go manager.processReminderFired(ev.Fields["reminder_id"].(uuid.UUID), ev.Fields["user_id"].(uuid.UUID), ev.Fields["message_id"].(uuid.UUID))
 		}
 	}()
 	return manager, nil
 }
 
-func (m *FCMManager) processMessageCreated(message *model.Message, plain string, embedded []*message.EmbeddedInfo) {
+func (m *FCMManager) processMessageCreated(message *model.Message, doc *message.Document) {
 	logger := m.logger.With(zap.Stringer("messageId", message.ID))
+	plain := doc.SingleLineText()
+	embedded := doc.Embedded()
 
 	// チャンネル情報を取得
 	ch, err := m.repo.GetChannel(message.ChannelID)
@@ -86,6 +94,10 @@ func (m *FCMManager) processMessageCreated(message *model.Message, plain string,
 		logger.Error("failed to GetUser", zap.Error(err), zap.Stringer("userId", message.UserID)) // 失敗
 		return
 	}
+	// リマインダーのDMはprocessReminderFiredで通知する
+	if mUser.Bot && mUser.Name == model.ReminderBotUserName {
+		return
+	}
 
 	// データ初期化
 	data := map[string]string{
@@ -140,6 +152,30 @@ func (m *FCMManager) processMessageCreated(message *model.Message, plain string,
 	// 対象者計算
 	targets := map[uuid.UUID]bool{}
 	switch {
+	case message.IsThreadReply(): // スレッドへの返信
+		users, err := m.repo.GetThreadParticipantIDs(message.ParentID)
+		if err != nil {
+			logger.Error("failed to GetThreadParticipantIDs", zap.Error(err), zap.Stringer("parentId", message.ParentID)) // 失敗
+			return
+		}
+		addIDsToSet(targets, users)
+
+		if ch.IsPublic {
+			// ユーザーグループ・メンションユーザー取得
+			if err := m.addMentionedUserIDs(targets, embedded); err != nil {
+				logger.Error("failed to GetUserGroupMemberIDs", zap.Error(err)) // 失敗
+				return
+			}
+
+			// ミュート除外
+			muted, err := m.repo.GetMuteUserIDs(message.ChannelID)
+			if err != nil {
+				logger.Error("failed to GetMuteUserIDs", zap.Error(err), zap.Stringer("channelId", message.ChannelID)) // 失敗
+				return
+			}
+			deleteIDsFromSet(targets, muted)
+		}
+
 	case ch.IsForced: // 強制通知チャンネル
 		users, err := m.repo.GetUsers()
 		if err != nil {
@@ -170,20 +206,9 @@ func (m *FCMManager) processMessageCreated(message *model.Message, plain string,
 		addIDsToSet(targets, users)
 
 		// ユーザーグループ・メンションユーザー取得
-		for _, v := range embedded {
-			switch v.Type {
-			case "user":
-				if uid, err := uuid.FromString(v.ID); err == nil {
-					addIDsToSet(targets, []uuid.UUID{uid})
-				}
-			case "group":
-				gs, err := m.repo.GetUserGroupMemberIDs(uuid.FromStringOrNil(v.ID))
-				if err != nil {
-					logger.Error("failed to GetUserGroupMemberIDs", zap.Error(err), zap.String("groupId", v.ID)) // 失敗
-					return
-				}
-				addIDsToSet(targets, gs)
-			}
+		if err := m.addMentionedUserIDs(targets, embedded); err != nil {
+			logger.Error("failed to GetUserGroupMemberIDs", zap.Error(err)) // 失敗
+			return
 		}
 
 		// ミュート除外
@@ -198,73 +223,120 @@ func (m *FCMManager) processMessageCreated(message *model.Message, plain string,
 
 	// 送信
 	for u := range targets {
-		go func(u uuid.UUID) {
-			devs, err := m.repo.GetDeviceTokensByUserID(u)
-			if err != nil {
-				logger.Error("failed to GetDeviceTokensByUserID", zap.Error(err), zap.Stringer("userId", u)) // 失敗
-				return
-			}
+		go m.sendToUser(logger, u, data)
+	}
+}
 
-			payload := &messaging.Message{
-				Data: data,
-				Android: &messaging.AndroidConfig{
-					Priority: "high",
-					TTL:      &messageTTL,
-				},
-				APNS: &messaging.APNSConfig{
-					Headers: map[string]string{
-						"apns-expiration": strconv.FormatInt(time.Now().Add(messageTTL).Unix(), 10),
-					},
-					Payload: &messaging.APNSPayload{
-						Aps: &messaging.Aps{
-							Alert: &messaging.ApsAlert{
-								Title: data["title"],
-								Body:  data["body"],
-							},
-							Sound:    "default",
-							ThreadID: data["tag"],
-						},
-					},
-				},
-				Webpush: &messaging.WebpushConfig{
-					Headers: map[string]string{
-						"TTL": strconv.Itoa(messageTTLSeconds),
+func (m *FCMManager) processReminderFired(reminderID, userID, messageID uuid.UUID) {
+	logger := m.logger.With(zap.Stringer("reminderId", reminderID), zap.Stringer("messageId", messageID))
+
+	msg, err := m.repo.GetMessageByID(messageID)
+	if err != nil {
+		logger.Error("failed to GetMessageByID", zap.Error(err)) // 失敗
+		return
+	}
+
+	body := message.ParseAST(msg.Text).SingleLineText()
+	if s := utf8string.NewString(body); s.RuneCount() > 100 {
+		body = s.Slice(0, 97) + "..."
+	}
+	data := map[string]string{
+		"title":     "リマインダー",
+		"body":      body,
+		"icon":      fmt.Sprintf("%s/api/1.0/public/icon/%s", m.origin, strings.ReplaceAll(model.ReminderBotUserName, "#", "%23")),
+		"vibration": "[1000, 1000, 1000]",
+		"tag":       fmt.Sprintf("r:%s", reminderID),
+		"badge":     fmt.Sprintf("%s/static/badge.png", m.origin),
+		"path":      "/messages/" + messageID.String(),
+	}
+	m.sendToUser(logger, userID, data)
+}
+
+// sendToUser 指定したユーザーの全てのデバイスに通知を送信します
+func (m *FCMManager) sendToUser(logger *zap.Logger, u uuid.UUID, data map[string]string) {
+	devs, err := m.repo.GetDeviceTokensByUserID(u)
+	if err != nil {
+		logger.Error("failed to GetDeviceTokensByUserID", zap.Error(err), zap.Stringer("userId", u)) // 失敗
+		return
+	}
+
+	payload := &messaging.Message{
+		Data: data,
+		Android: &messaging.AndroidConfig{
+			Priority: "high",
+			TTL:      &messageTTL,
+		},
+		APNS: &messaging.APNSConfig{
+			Headers: map[string]string{
+				"apns-expiration": strconv.FormatInt(time.Now().Add(messageTTL).Unix(), 10),
+			},
+			Payload: &messaging.APNSPayload{
+				Aps: &messaging.Aps{
+					Alert: &messaging.ApsAlert{
+						Title: data["title"],
+						Body:  data["body"],
 					},
+					Sound:    "default",
+					ThreadID: data["tag"],
 				},
-			}
-			for _, token := range devs {
-				payload.Token = token
-				err := backoff.Retry(func() error {
-					if _, err := m.messaging.Send(context.Background(), payload); err != nil {
-						fcmSendCounter.WithLabelValues("error").Inc()
-						switch {
-						case messaging.IsRegistrationTokenNotRegistered(err):
-							if err := m.repo.UnregisterDevice(token); err != nil {
-								return backoff.Permanent(err)
-							}
-						case messaging.IsInvalidArgument(err):
-							return backoff.Permanent(err)
-						case messaging.IsServerUnavailable(err):
-							fallthrough
-						case messaging.IsInternal(err):
-							fallthrough
-						case messaging.IsMessageRateExceeded(err):
-							fallthrough
-						case messaging.IsUnknown(err):
-							return err
-						default:
-							return err
-						}
+			},
+		},
+		Webpush: &messaging.WebpushConfig{
+			Headers: map[string]string{
+				"TTL": strconv.Itoa(messageTTLSeconds),
+			},
+		},
+	}
+	for _, token := range devs {
+		payload.Token = token
+		err := backoff.Retry(func() error {
+			if _, err := m.messaging.Send(context.Background(), payload); err != nil {
+				fcmSendCounter.WithLabelValues("error").Inc()
+				switch {
+				case messaging.IsRegistrationTokenNotRegistered(err):
+					if err := m.repo.UnregisterDevice(token); err != nil {
+						return backoff.Permanent(err)
 					}
-					fcmSendCounter.WithLabelValues("ok").Inc()
-					return nil
-				}, backoff.NewExponentialBackOff())
-				if err != nil {
-					logger.Error("an error occurred in sending fcm", zap.Error(err), zap.String("deviceToken", token))
+				case messaging.IsInvalidArgument(err):
+					return backoff.Permanent(err)
+				case messaging.IsServerUnavailable(err):
+					fallthrough
+				case messaging.IsInternal(err):
+					fallthrough
+				case messaging.IsMessageRateExceeded(err):
+					fallthrough
+				case messaging.IsUnknown(err):
+					return err
+				default:
+					return err
 				}
 			}
-		}(u)
+			fcmSendCounter.WithLabelValues("ok").Inc()
+			return nil
+		}, backoff.NewExponentialBackOff())
+		if err != nil {
+			logger.Error("an error occurred in sending fcm", zap.Error(err), zap.String("deviceToken", token))
+		}
+	}
+}
+
+// addMentionedUserIDs メンションされたユーザーとグループのメンバーを対象者に追加します
+func (m *FCMManager) addMentionedUserIDs(targets map[uuid.UUID]bool, embedded []*message.EmbeddedInfo) error {
+	for _, v := range embedded {
+		switch v.Type {
+		case "user":
+			if uid, err := uuid.FromString(v.ID); err == nil {
+				addIDsToSet(targets, []uuid.UUID{uid})
+			}
+		case "group":
+			gs, err := m.repo.GetUserGroupMemberIDs(uuid.FromStringOrNil(v.ID))
+			if err != nil {
+				return err
+			}
+			addIDsToSet(targets, gs)
+		}
 	}
+	return nil
 }
 
 func addIDsToSet(set map[uuid.UUID]bool, ids []uuid.UUID) {
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"github.com/traPtitech/traQ/importer"
+	"github.com/traPtitech/traQ/repository"
+)
+
+// runImportSlackCommand Slackのワークスペースエクスポートをインポートするサブコマンド
+//
+// 使い方: traQ import-slack -file <エクスポートzip> -fallback-user <ユーザー名> [-parent <チャンネルID>] [-state <進捗ファイル>] [-dry-run]
+// 結果のレポートはJSONで標準出力に書き出します。
+// -stateを指定した場合は進捗をファイルに保存し、同じファイルを指定して再実行すると中断した箇所から再開します。
+func runImportSlackCommand(repo repository.Repository, args []string) error {
+	flags := flag.NewFlagSet("import-slack", flag.ContinueOnError)
+	file := flags.String("file", "", "slack export zip file path")
+	fallbackUser := flags.String("fallback-user", "", "traQ user name who posts messages of unmapped slack users")
+	parent := flags.String("parent", "", "parent channel id of imported channels (default: root)")
+	channelNames := flags.String("channel-map", "", "channel name overrides (slack=traq,...)")
+	userNames := flags.String("user-map", "", "user name overrides (slack=traq,...)")
+	token := flags.String("slack-token", "", "slack token to download files not included in the export")
+	state := flags.String("state", "", "progress file to resume an interrupted import")
+	dryRun := flags.Bool("dry-run", false, "report mappings and conflicts without writing anything")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	opts := importer.SlackImportOptions{
+		SlackToken: *token,
+		DryRun:     *dryRun,
+	}
+	user, err := repo.GetUserByName(*fallbackUser)
+	if err != nil {
+		return fmt.Errorf("invalid fallback user: %v", err)
+	}
+	opts.FallbackUserID = user.ID
+	if len(*parent) > 0 {
+		if opts.ParentChannelID, err = uuid.FromString(*parent); err != nil {
+			return errors.New("invalid parent channel id")
+		}
+	}
+	if opts.ChannelNames, err = parseNameMap(*channelNames); err != nil {
+		return err
+	}
+	if opts.UserNames, err = parseNameMap(*userNames); err != nil {
+		return err
+	}
+	if len(*state) > 0 {
+		if opts.Progress, err = loadSlackImportProgress(*state); err != nil {
+			return err
+		}
+		if !opts.DryRun {
+			opts.OnBatch = func(p *importer.SlackImportProgress) error {
+				return saveSlackImportProgress(*state, p)
+			}
+		}
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	report, err := importer.ImportSlack(repo, f, stat.Size(), opts)
+	if opts.OnBatch != nil {
+		// エラーで中断した場合も、そこまでの進捗を保存する
+		if serr := saveSlackImportProgress(*state, opts.Progress); serr != nil && err == nil {
+			err = serr
+		}
+	}
+	if report != nil {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(report)
+	}
+	return err
+}
+
+// loadSlackImportProgress Slackインポートの進捗をファイルから読み込みます ファイルが存在しない場合は空の進捗を返します
+func loadSlackImportProgress(path string) (*importer.SlackImportProgress, error) {
+	p := &importer.SlackImportProgress{}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return p, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(b, p); err != nil {
+		return nil, fmt.Errorf("invalid state file: %v", err)
+	}
+	return p, nil
+}
+
+// saveSlackImportProgress Slackインポートの進捗をファイルに書き込みます
+func saveSlackImportProgress(path string, p *importer.SlackImportProgress) error {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	// 書き込み途中で中断しても壊れないよう、一時ファイルに書き込んでから置き換える
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// parseNameMap "a=b,c=d"形式の文字列をmapに変換します
+func parseNameMap(s string) (map[string]string, error) {
+	res := map[string]string{}
+	if len(s) == 0 {
+		return res, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || len(kv[0]) == 0 || len(kv[1]) == 0 {
+			return nil, fmt.Errorf("invalid name mapping: %s", pair)
+		}
+		res[kv[0]] = kv[1]
+	}
+	return res, nil
+}
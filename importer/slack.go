@@ -0,0 +1,653 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/utils/validator"
+)
+
+const (
+	// ConflictInvalidChannelName traQで使用できないチャンネル名
+	ConflictInvalidChannelName = "invalid_channel_name"
+	// ConflictChannelNameCollision 既存チャンネル、または他のインポート対象チャンネルとの名前の衝突
+	ConflictChannelNameCollision = "channel_name_collision"
+)
+
+// ErrSlackImportConflict マッピングに競合があるためインポートを中止したことを表すエラー
+var ErrSlackImportConflict = errors.New("slack import has mapping conflicts")
+
+// SlackImportOptions Slackインポートのオプション
+type SlackImportOptions struct {
+	// ParentChannelID インポートしたチャンネルを作成する親チャンネルのID uuid.Nilの場合はルートに作成します
+	ParentChannelID uuid.UUID
+	// FallbackUserID 対応するtraQユーザーが存在しないSlackユーザーの投稿を代わりに投稿するユーザーのID
+	FallbackUserID uuid.UUID
+	// ChannelNames Slackのチャンネル名からtraQのチャンネル名への対応の上書き
+	ChannelNames map[string]string
+	// UserNames Slackのユーザー名からtraQのユーザー名への対応の上書き
+	UserNames map[string]string
+	// SlackToken エクスポートに含まれていないファイルをSlackからダウンロードする際に使用するトークン
+	SlackToken string
+	// DryRun trueの場合、書き込みを行わずに対応関係と競合のみを報告します
+	DryRun bool
+	// Progress 中断したインポートの進捗 指定した場合、インポート済みのチャンネルとメッセージを飛ばして再開します
+	//
+	// インポート中に更新されます。nilの場合は最初からインポートします。
+	Progress *SlackImportProgress
+	// OnBatch メッセージのバッチ(チャンネルの日付ファイル1つ)のインポートが完了するごとに呼び出されます
+	//
+	// 進捗の保存に使用します。エラーを返した場合はインポートを中止します。
+	OnBatch func(progress *SlackImportProgress) error
+}
+
+// SlackImportProgress 中断したSlackインポートを再開するための進捗
+type SlackImportProgress struct {
+	// Channels SlackのチャンネルID -> 作成したtraQのチャンネルID
+	Channels map[string]uuid.UUID `json:"channels"`
+	// LastTs SlackのチャンネルID -> インポート済みの最後のメッセージのts
+	LastTs map[string]string `json:"lastTs"`
+	// Parents SlackのチャンネルID -> スレッドの親メッセージのts -> traQのメッセージID
+	Parents map[string]map[string]uuid.UUID `json:"parents"`
+}
+
+func (p *SlackImportProgress) init() {
+	if p.Channels == nil {
+		p.Channels = map[string]uuid.UUID{}
+	}
+	if p.LastTs == nil {
+		p.LastTs = map[string]string{}
+	}
+	if p.Parents == nil {
+		p.Parents = map[string]map[string]uuid.UUID{}
+	}
+}
+
+// SlackImportReport Slackインポートの結果
+type SlackImportReport struct {
+	DryRun          bool                   `json:"dryRun"`
+	Channels        []*SlackChannelMapping `json:"channels"`
+	Users           []*SlackUserMapping    `json:"users"`
+	Conflicts       []*SlackImportConflict `json:"conflicts"`
+	Warnings        []string               `json:"warnings"`
+	Messages        int                    `json:"messages"`
+	Stamps          int                    `json:"stamps"`
+	Files           int                    `json:"files"`
+	SkippedMessages int                    `json:"skippedMessages"`
+}
+
+// SlackChannelMapping Slackのチャンネルとの対応
+type SlackChannelMapping struct {
+	SlackID       string    `json:"slackId"`
+	SlackName     string    `json:"slackName"`
+	TraQName      string    `json:"traqName"`
+	TraQChannelID uuid.UUID `json:"traqChannelId"`
+}
+
+// SlackUserMapping Slackのユーザーとの対応 対応するユーザーが存在しない場合、TraQUserIDはuuid.Nilです
+type SlackUserMapping struct {
+	SlackID      string    `json:"slackId"`
+	SlackName    string    `json:"slackName"`
+	DisplayName  string    `json:"displayName"`
+	TraQUserName string    `json:"traqUserName"`
+	TraQUserID   uuid.UUID `json:"traqUserId"`
+}
+
+// SlackImportConflict インポートを妨げるマッピングの競合
+type SlackImportConflict struct {
+	Kind   string `json:"kind"`
+	Target string `json:"target"`
+	Reason string `json:"reason"`
+}
+
+type slackImporter struct {
+	repo     repository.Repository
+	opts     SlackImportOptions
+	files    map[string]*zip.File
+	client   *http.Client
+	report   *SlackImportReport
+	progress *SlackImportProgress
+
+	users             map[string]*SlackUserMapping
+	channelsBySlackID map[string]*SlackChannelMapping
+	stamps            map[string]uuid.UUID
+	warned            map[string]bool
+}
+
+// ImportSlack Slackのワークスペースエクスポート(zip)をtraQにインポートします
+//
+// チャンネルはParentChannelID直下の公開チャンネルとして作成し、ユーザーは名前が一致するtraQユーザーに対応付けます。
+// リアクションは名前が一致するスタンプに、添付ファイルはtraQのファイルに変換します。投稿日時は元の日時を保持します。
+// チャンネル名の衝突などの競合がある場合は、何も書き込まずにレポートとErrSlackImportConflictを返します。
+// DryRunが指定された場合は、書き込みを行わずにレポートを返します。
+// コンテンツフィルタなどで投稿できないメッセージは警告を出して飛ばします。
+// 途中でエラーが発生した場合は、opts.Progressを指定して再度呼び出すことで続きからインポートできます。
+func ImportSlack(repo repository.Repository, r io.ReaderAt, size int64, opts SlackImportOptions) (*SlackImportReport, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	i := &slackImporter{
+		repo:              repo,
+		opts:              opts,
+		files:             map[string]*zip.File{},
+		client:            &http.Client{Timeout: time.Minute},
+		report:            &SlackImportReport{DryRun: opts.DryRun, Conflicts: []*SlackImportConflict{}, Warnings: []string{}},
+		users:             map[string]*SlackUserMapping{},
+		channelsBySlackID: map[string]*SlackChannelMapping{},
+		stamps:            map[string]uuid.UUID{},
+		warned:            map[string]bool{},
+		progress:          opts.Progress,
+	}
+	if i.progress == nil {
+		i.progress = &SlackImportProgress{}
+	}
+	i.progress.init()
+	for _, f := range zr.File {
+		i.files[f.Name] = f
+	}
+
+	if _, err := repo.GetUser(opts.FallbackUserID); err != nil {
+		return nil, fmt.Errorf("invalid fallback user: %v", err)
+	}
+	if opts.ParentChannelID != uuid.Nil {
+		parent, err := repo.GetChannel(opts.ParentChannelID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid parent channel: %v", err)
+		}
+		if !parent.IsPublic || parent.IsDMChannel() {
+			return nil, errors.New("invalid parent channel: parent channel must be public")
+		}
+	}
+
+	var users []*slackUser
+	if err := i.readJSON("users.json", &users); err != nil {
+		return nil, err
+	}
+	var channels []*slackChannel
+	if err := i.readJSON("channels.json", &channels); err != nil {
+		return nil, err
+	}
+
+	if err := i.mapUsers(users); err != nil {
+		return nil, err
+	}
+	if err := i.mapStamps(); err != nil {
+		return nil, err
+	}
+	if err := i.mapChannels(channels); err != nil {
+		return nil, err
+	}
+	if len(i.report.Conflicts) > 0 && !opts.DryRun {
+		return i.report, ErrSlackImportConflict
+	}
+
+	if !opts.DryRun {
+		for _, ch := range channels {
+			if err := i.createChannel(ch); err != nil {
+				return i.report, err
+			}
+		}
+	}
+	for _, ch := range channels {
+		if err := i.importMessages(i.channelsBySlackID[ch.ID]); err != nil {
+			return i.report, err
+		}
+	}
+	return i.report, nil
+}
+
+func (i *slackImporter) readJSON(name string, v interface{}) error {
+	f, ok := i.files[name]
+	if !ok {
+		return fmt.Errorf("%s is not found in the export", name)
+	}
+	r, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	if err := json.NewDecoder(r).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode %s: %v", name, err)
+	}
+	return nil
+}
+
+func (i *slackImporter) warn(format string, a ...interface{}) {
+	msg := fmt.Sprintf(format, a...)
+	if i.warned[msg] {
+		return
+	}
+	i.warned[msg] = true
+	i.report.Warnings = append(i.report.Warnings, msg)
+}
+
+func (i *slackImporter) conflict(kind, target, reason string) {
+	i.report.Conflicts = append(i.report.Conflicts, &SlackImportConflict{Kind: kind, Target: target, Reason: reason})
+}
+
+// mapUsers Slackのユーザーを名前が一致するtraQユーザーに対応付けます
+func (i *slackImporter) mapUsers(users []*slackUser) error {
+	for _, u := range users {
+		m := &SlackUserMapping{
+			SlackID:      u.ID,
+			SlackName:    u.Name,
+			DisplayName:  firstNonEmpty(u.Profile.DisplayName, u.Profile.RealName, u.RealName, u.Name),
+			TraQUserName: u.Name,
+		}
+		if name, ok := i.opts.UserNames[u.Name]; ok {
+			m.TraQUserName = name
+		}
+
+		user, err := i.repo.GetUserByName(m.TraQUserName)
+		switch err {
+		case nil:
+			m.TraQUserID = user.ID
+		case repository.ErrNotFound:
+			i.warn("slack user @%s has no corresponding traQ user; their messages will be posted by the fallback user", u.Name)
+		default:
+			return err
+		}
+
+		i.users[u.ID] = m
+		i.report.Users = append(i.report.Users, m)
+	}
+	return nil
+}
+
+func (i *slackImporter) mapStamps() error {
+	stamps, err := i.repo.GetAllStamps()
+	if err != nil {
+		return err
+	}
+	for _, s := range stamps {
+		i.stamps[s.Name] = s.ID
+	}
+	return nil
+}
+
+// mapChannels Slackのチャンネルに対応するtraQのチャンネル名を決定し、競合を検出します
+func (i *slackImporter) mapChannels(channels []*slackChannel) error {
+	sort.SliceStable(channels, func(a, b int) bool { return channels[a].Created < channels[b].Created })
+
+	// MySQLの照合順序では大文字小文字を区別しないため、小文字で比較する
+	used := map[string]string{}
+	children, err := i.repo.GetChildrenChannelIDs(i.opts.ParentChannelID)
+	if err != nil {
+		return err
+	}
+	resumed := map[uuid.UUID]bool{}
+	for _, id := range i.progress.Channels {
+		resumed[id] = true
+	}
+	for _, id := range children {
+		if resumed[id] {
+			continue
+		}
+		ch, err := i.repo.GetChannel(id)
+		if err != nil {
+			return err
+		}
+		used[strings.ToLower(ch.Name)] = ""
+	}
+
+	for _, ch := range channels {
+		m := &SlackChannelMapping{
+			SlackID:   ch.ID,
+			SlackName: ch.Name,
+			TraQName:  ch.Name,
+		}
+		if name, ok := i.opts.ChannelNames[ch.Name]; ok {
+			m.TraQName = name
+		}
+
+		if id, ok := i.progress.Channels[ch.ID]; ok {
+			// 前回のインポートで作成済みのチャンネルを使う
+			c, err := i.repo.GetChannel(id)
+			if err != nil {
+				return fmt.Errorf("failed to get channel of slack channel #%s created by the previous import: %v", ch.Name, err)
+			}
+			m.TraQName = c.Name
+			m.TraQChannelID = c.ID
+			used[strings.ToLower(c.Name)] = ch.Name
+		} else if !validator.ChannelRegex.MatchString(m.TraQName) {
+			i.conflict(ConflictInvalidChannelName, ch.Name, fmt.Sprintf("%q is not a valid traQ channel name", m.TraQName))
+		} else if by, ok := used[strings.ToLower(m.TraQName)]; ok {
+			if len(by) == 0 {
+				i.conflict(ConflictChannelNameCollision, ch.Name, fmt.Sprintf("channel %q already exists", m.TraQName))
+			} else {
+				i.conflict(ConflictChannelNameCollision, ch.Name, fmt.Sprintf("%q is also mapped from slack channel #%s", m.TraQName, by))
+			}
+		} else {
+			used[strings.ToLower(m.TraQName)] = ch.Name
+		}
+
+		i.channelsBySlackID[ch.ID] = m
+		i.report.Channels = append(i.report.Channels, m)
+	}
+	return nil
+}
+
+func (i *slackImporter) createChannel(ch *slackChannel) error {
+	m := i.channelsBySlackID[ch.ID]
+	if m.TraQChannelID != uuid.Nil {
+		return nil
+	}
+	creatorID, _ := i.userID(ch.Creator)
+
+	c, err := i.repo.CreatePublicChannel(m.TraQName, i.opts.ParentChannelID, creatorID)
+	if err != nil {
+		return fmt.Errorf("failed to create channel #%s: %v", m.TraQName, err)
+	}
+	m.TraQChannelID = c.ID
+	i.progress.Channels[ch.ID] = c.ID
+
+	if topic := firstNonEmpty(ch.Topic.Value, ch.Purpose.Value); len(topic) > 0 {
+		if err := i.repo.UpdateChannelTopic(c.ID, i.convertText(topic), creatorID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// userID Slackのユーザーに対応するtraQユーザーのIDを返します
+//
+// 対応するユーザーが存在しない場合はFallbackUserIDとfalseを返します。
+func (i *slackImporter) userID(slackID string) (uuid.UUID, bool) {
+	if u, ok := i.users[slackID]; ok && u.TraQUserID != uuid.Nil {
+		return u.TraQUserID, true
+	}
+	return i.opts.FallbackUserID, false
+}
+
+// importMessages チャンネルのメッセージを日付ファイルごとのバッチでインポートします
+//
+// 進捗に記録された最後のメッセージまでは飛ばします。
+func (i *slackImporter) importMessages(ch *SlackChannelMapping) error {
+	var names []string
+	for name := range i.files {
+		if strings.HasPrefix(name, ch.SlackName+"/") && strings.HasSuffix(name, ".json") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	// スレッドの親メッセージのts -> traQのメッセージID
+	parents, ok := i.progress.Parents[ch.SlackID]
+	if !ok {
+		parents = map[string]uuid.UUID{}
+		if !i.opts.DryRun {
+			i.progress.Parents[ch.SlackID] = parents
+		}
+	}
+	var last time.Time
+	if ts, ok := i.progress.LastTs[ch.SlackID]; ok {
+		last, _ = parseSlackTimestamp(ts)
+	}
+
+	for _, name := range names {
+		var messages []*slackMessage
+		if err := i.readJSON(name, &messages); err != nil {
+			return err
+		}
+		sort.SliceStable(messages, func(a, b int) bool { return messages[a].Ts < messages[b].Ts })
+
+		imported := false
+		for _, m := range messages {
+			if t, err := parseSlackTimestamp(m.Ts); err == nil && !t.After(last) {
+				continue
+			}
+			if err := i.importMessage(ch, m, parents); err != nil {
+				return err
+			}
+			if !i.opts.DryRun {
+				i.progress.LastTs[ch.SlackID] = m.Ts
+				imported = true
+			}
+		}
+
+		if imported && i.opts.OnBatch != nil {
+			if err := i.opts.OnBatch(i.progress); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (i *slackImporter) importMessage(ch *SlackChannelMapping, m *slackMessage, parents map[string]uuid.UUID) error {
+	if !m.isImportable() {
+		i.report.SkippedMessages++
+		return nil
+	}
+	createdAt, err := parseSlackTimestamp(m.Ts)
+	if err != nil {
+		i.warn("message with invalid timestamp %q in #%s is skipped", m.Ts, ch.SlackName)
+		i.report.SkippedMessages++
+		return nil
+	}
+
+	userID, mapped := i.userID(m.User)
+	text := i.convertText(m.Text)
+	if !mapped {
+		name := m.Username
+		if u, ok := i.users[m.User]; ok {
+			name = u.DisplayName
+		}
+		if len(name) > 0 {
+			text = fmt.Sprintf("**%s**: %s", name, text)
+		}
+	}
+
+	attached := false
+	for _, f := range m.Files {
+		fileID, ok, err := i.importFile(f, userID)
+		if err != nil {
+			return err
+		}
+		if ok {
+			attached = true
+			if fileID != uuid.Nil {
+				text += "\n" + makeEmbed("file", "", fileID)
+			}
+		}
+	}
+	if len(strings.TrimSpace(text)) == 0 && !attached {
+		i.report.SkippedMessages++
+		return nil
+	}
+
+	var stampCount int
+	for _, r := range m.Reactions {
+		if _, ok := i.stamps[r.Name]; !ok {
+			i.warn("reaction :%s: has no corresponding traQ stamp", r.Name)
+			continue
+		}
+		for _, u := range r.Users {
+			if _, ok := i.userID(u); ok {
+				stampCount++
+			}
+		}
+	}
+
+	if i.opts.DryRun {
+		i.report.Messages++
+		i.report.Stamps += stampCount
+		return nil
+	}
+
+	var created *model.Message
+	if parentID, ok := parents[m.ThreadTs]; ok && m.isThreadReply() {
+		created, err = i.repo.CreateThreadReply(userID, parentID, text)
+	} else {
+		created, err = i.repo.CreateMessage(userID, ch.TraQChannelID, text)
+	}
+	if err != nil {
+		switch e := err.(type) {
+		case *repository.ContentFilterError:
+			i.warn("message %s in #%s is rejected by content filter rule %s and skipped", m.Ts, ch.SlackName, e.RuleID)
+			i.report.SkippedMessages++
+			return nil
+		case *repository.ArgumentError:
+			i.warn("message %s in #%s is invalid and skipped: %v", m.Ts, ch.SlackName, err)
+			i.report.SkippedMessages++
+			return nil
+		}
+		return fmt.Errorf("failed to create message %s in #%s: %v", m.Ts, ch.SlackName, err)
+	}
+	if err := i.completeMessage(created, m, createdAt); err != nil {
+		// 再開時に重複しないよう、途中までインポートしたメッセージは削除する
+		_ = i.repo.DeleteMessage(created.ID)
+		return fmt.Errorf("failed to import message %s in #%s: %v", m.Ts, ch.SlackName, err)
+	}
+	if m.isThreadParent() {
+		parents[m.Ts] = created.ID
+	}
+	i.report.Messages++
+	return nil
+}
+
+// completeMessage 作成したメッセージにスタンプと元の投稿日時を設定します
+func (i *slackImporter) completeMessage(created *model.Message, m *slackMessage, createdAt time.Time) error {
+	stamps := 0
+	for _, r := range m.Reactions {
+		stampID, ok := i.stamps[r.Name]
+		if !ok {
+			continue
+		}
+		for _, u := range r.Users {
+			uid, ok := i.userID(u)
+			if !ok {
+				continue
+			}
+			if _, err := i.repo.AddStampToMessage(created.ID, stampID, uid); err != nil {
+				return err
+			}
+			stamps++
+		}
+	}
+
+	if err := i.repo.SetMessageCreatedAt(created.ID, createdAt); err != nil {
+		return err
+	}
+	i.report.Stamps += stamps
+	return nil
+}
+
+// importFile Slackの添付ファイルをtraQのファイルとして保存します
+//
+// ファイルはエクスポート内の__uploads/<ファイルID>/<ファイル名>から読み込みます。
+// 含まれていない場合、SlackTokenが指定されていてSlackのファイルURLであればSlackからダウンロードします。
+// ファイルを取得できない場合はfalseを返します。DryRunの場合は取得可能かどうかのみを判定し、uuid.Nilを返します。
+func (i *slackImporter) importFile(f slackFile, creatorID uuid.UUID) (uuid.UUID, bool, error) {
+	zf, inZip := i.files[fmt.Sprintf("__uploads/%s/%s", f.ID, f.Name)]
+	if !inZip && len(f.URLPrivateDownload) > 0 && !isSlackFileURL(f.URLPrivateDownload) {
+		// 細工されたエクスポートにトークンを送信させないため、Slack以外のURLからはダウンロードしない
+		i.warn("file %s (%s) is not hosted on slack and cannot be downloaded", f.ID, f.Name)
+		return uuid.Nil, false, nil
+	}
+	downloadable := len(i.opts.SlackToken) > 0 && isSlackFileURL(f.URLPrivateDownload)
+	if !inZip && !downloadable {
+		i.warn("file %s (%s) is not included in the export and cannot be downloaded", f.ID, f.Name)
+		return uuid.Nil, false, nil
+	}
+	if i.opts.DryRun {
+		i.report.Files++
+		return uuid.Nil, true, nil
+	}
+
+	var (
+		src  io.ReadCloser
+		size int64
+		err  error
+	)
+	if inZip {
+		src, err = zf.Open()
+		size = int64(zf.UncompressedSize64)
+	} else {
+		src, size, err = i.download(f.URLPrivateDownload)
+	}
+	if err != nil {
+		i.warn("failed to fetch file %s (%s): %v", f.ID, f.Name, err)
+		return uuid.Nil, false, nil
+	}
+	defer src.Close()
+
+	mime := f.Mimetype
+	if len(mime) == 0 {
+		mime = "application/octet-stream"
+	}
+	file, err := i.repo.SaveFile(f.Name, src, size, mime, model.FileTypeUserFile, creatorID)
+	if err != nil {
+		return uuid.Nil, false, err
+	}
+	i.report.Files++
+	return file.ID, true, nil
+}
+
+// download Slackのファイルをダウンロードします
+//
+// トークンを送信するため、uはisSlackFileURLを満たす必要があります。
+// リダイレクト先が別のドメインの場合、net/httpはAuthorizationヘッダーを送信しません。
+func (i *slackImporter) download(u string) (io.ReadCloser, int64, error) {
+	if !isSlackFileURL(u) {
+		return nil, 0, errors.New("not a slack file url")
+	}
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Authorization", "Bearer "+i.opts.SlackToken)
+	res, err := i.client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		return nil, 0, fmt.Errorf("unexpected status code: %d", res.StatusCode)
+	}
+	if res.ContentLength >= 0 {
+		return res.Body, res.ContentLength, nil
+	}
+
+	// サイズが不明な場合は一度読み込む
+	defer res.Body.Close()
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, res.Body); err != nil {
+		return nil, 0, err
+	}
+	return ioutil.NopCloser(&buf), int64(buf.Len()), nil
+}
+
+// isSlackFileURL Slackのトークンを送信してよいURL(https://slack.com, https://*.slack.com)かどうか
+func isSlackFileURL(s string) bool {
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme != "https" {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	return host == "slack.com" || strings.HasSuffix(host, ".slack.com")
+}
+
+func firstNonEmpty(s ...string) string {
+	for _, v := range s {
+		if len(v) > 0 {
+			return v
+		}
+	}
+	return ""
+}
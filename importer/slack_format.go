@@ -0,0 +1,90 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"github.com/traPtitech/traQ/utils/message"
+)
+
+var (
+	slackControlRegex = regexp.MustCompile(`<([^<>\n]+)>`)
+	slackUnescaper    = strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&")
+)
+
+// convertText Slackのmrkdwn形式のテキストをtraQのメッセージ本文に変換します
+//
+// ユーザーメンションとチャンネルリンクは、対応するtraQのユーザー・チャンネルが存在する場合は埋め込みに変換します。
+func (i *slackImporter) convertText(text string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range slackControlRegex.FindAllStringSubmatchIndex(text, -1) {
+		b.WriteString(slackUnescaper.Replace(text[last:loc[0]]))
+		b.WriteString(i.convertControl(text[loc[2]:loc[3]]))
+		last = loc[1]
+	}
+	b.WriteString(slackUnescaper.Replace(text[last:]))
+	return b.String()
+}
+
+// convertControl <...>で囲まれたSlackの制御シーケンスを変換します
+func (i *slackImporter) convertControl(s string) string {
+	target, label := s, ""
+	if idx := strings.IndexByte(s, '|'); idx >= 0 {
+		target, label = s[:idx], slackUnescaper.Replace(s[idx+1:])
+	}
+
+	switch {
+	case strings.HasPrefix(target, "@"):
+		if u, ok := i.users[target[1:]]; ok {
+			if u.TraQUserID != uuid.Nil {
+				return makeEmbed("user", "@"+u.TraQUserName, u.TraQUserID)
+			}
+			return "@" + u.SlackName
+		}
+		if len(label) > 0 {
+			return "@" + strings.TrimPrefix(label, "@")
+		}
+		return s
+	case strings.HasPrefix(target, "#"):
+		if ch, ok := i.channelsBySlackID[target[1:]]; ok {
+			if ch.TraQChannelID != uuid.Nil {
+				return makeEmbed("channel", "#"+ch.TraQName, ch.TraQChannelID)
+			}
+			return "#" + ch.TraQName
+		}
+		if len(label) > 0 {
+			return "#" + label
+		}
+		return s
+	case strings.HasPrefix(target, "!"):
+		if len(label) > 0 {
+			return label
+		}
+		switch name := target[1:]; name {
+		case "here", "channel", "everyone":
+			return "@" + name
+		default:
+			return s
+		}
+	default:
+		target = slackUnescaper.Replace(target)
+		if len(label) == 0 || label == target {
+			return target
+		}
+		return fmt.Sprintf("[%s](%s)", label, target)
+	}
+}
+
+// makeEmbed traQのメッセージ埋め込み文字列を生成します
+func makeEmbed(typ, raw string, id uuid.UUID) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(&message.EmbeddedInfo{Raw: raw, Type: typ, ID: id.String()})
+	return "!" + strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,442 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+)
+
+// fakeRepository インポーターが使用するメソッドのみを実装したリポジトリ
+type fakeRepository struct {
+	repository.Repository
+
+	users    map[string]*model.User
+	channels map[uuid.UUID]*model.Channel
+	stamps   []*model.Stamp
+	messages map[uuid.UUID]*model.Message
+	order    []uuid.UUID
+	files    map[uuid.UUID][]byte
+	topics   map[uuid.UUID]string
+
+	// rejected このテキストを含むメッセージをコンテンツフィルタで拒否します
+	rejected string
+	// failOn このテキストを含むメッセージの作成でエラーを返します
+	failOn string
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{
+		users:    map[string]*model.User{},
+		channels: map[uuid.UUID]*model.Channel{},
+		messages: map[uuid.UUID]*model.Message{},
+		files:    map[uuid.UUID][]byte{},
+		topics:   map[uuid.UUID]string{},
+	}
+}
+
+func (r *fakeRepository) addUser(name string) *model.User {
+	u := &model.User{ID: uuid.Must(uuid.NewV4()), Name: name}
+	r.users[name] = u
+	return u
+}
+
+func (r *fakeRepository) GetUser(id uuid.UUID) (*model.User, error) {
+	for _, u := range r.users {
+		if u.ID == id {
+			return u, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *fakeRepository) GetUserByName(name string) (*model.User, error) {
+	if u, ok := r.users[name]; ok {
+		return u, nil
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *fakeRepository) GetChannel(id uuid.UUID) (*model.Channel, error) {
+	if ch, ok := r.channels[id]; ok {
+		return ch, nil
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *fakeRepository) GetChildrenChannelIDs(id uuid.UUID) ([]uuid.UUID, error) {
+	var res []uuid.UUID
+	for _, ch := range r.channels {
+		if ch.ParentID == id {
+			res = append(res, ch.ID)
+		}
+	}
+	return res, nil
+}
+
+func (r *fakeRepository) CreatePublicChannel(name string, parent, creatorID uuid.UUID) (*model.Channel, error) {
+	ch := &model.Channel{ID: uuid.Must(uuid.NewV4()), Name: name, ParentID: parent, CreatorID: creatorID, IsPublic: true}
+	r.channels[ch.ID] = ch
+	return ch, nil
+}
+
+func (r *fakeRepository) UpdateChannelTopic(channelID uuid.UUID, topic string, updaterID uuid.UUID) error {
+	r.topics[channelID] = topic
+	return nil
+}
+
+func (r *fakeRepository) GetAllStamps() ([]*model.Stamp, error) {
+	return r.stamps, nil
+}
+
+func (r *fakeRepository) CreateMessage(userID, channelID uuid.UUID, text string) (*model.Message, error) {
+	if len(r.rejected) > 0 && strings.Contains(text, r.rejected) {
+		return nil, &repository.ContentFilterError{RuleID: uuid.Must(uuid.NewV4())}
+	}
+	if len(r.failOn) > 0 && strings.Contains(text, r.failOn) {
+		return nil, errors.New("failed")
+	}
+	m := &model.Message{ID: uuid.Must(uuid.NewV4()), UserID: userID, ChannelID: channelID, Text: text, CreatedAt: time.Now()}
+	r.messages[m.ID] = m
+	r.order = append(r.order, m.ID)
+	return m, nil
+}
+
+func (r *fakeRepository) CreateThreadReply(userID, parentID uuid.UUID, text string) (*model.Message, error) {
+	parent := r.messages[parentID]
+	m, err := r.CreateMessage(userID, parent.ChannelID, text)
+	if err != nil {
+		return nil, err
+	}
+	m.ParentID = parentID
+	return m, nil
+}
+
+func (r *fakeRepository) DeleteMessage(messageID uuid.UUID) error {
+	delete(r.messages, messageID)
+	return nil
+}
+
+func (r *fakeRepository) AddStampToMessage(messageID, stampID, userID uuid.UUID) (*model.MessageStamp, error) {
+	m := r.messages[messageID]
+	ms := model.MessageStamp{MessageID: messageID, StampID: stampID, UserID: userID, Count: 1}
+	m.Stamps = append(m.Stamps, ms)
+	return &ms, nil
+}
+
+func (r *fakeRepository) SetMessageCreatedAt(messageID uuid.UUID, createdAt time.Time) error {
+	r.messages[messageID].CreatedAt = createdAt
+	return nil
+}
+
+func (r *fakeRepository) SaveFile(name string, src io.Reader, size int64, mime string, fType string, creatorID uuid.UUID) (*model.File, error) {
+	b, err := ioutil.ReadAll(src)
+	if err != nil {
+		return nil, err
+	}
+	f := &model.File{ID: uuid.Must(uuid.NewV4()), Name: name, Size: size, Mime: mime, CreatorID: creatorID}
+	r.files[f.ID] = b
+	return f, nil
+}
+
+func makeSlackExport(t *testing.T, entries map[string]interface{}) *bytes.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, v := range entries {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		if b, ok := v.([]byte); ok {
+			_, err = w.Write(b)
+		} else {
+			err = json.NewEncoder(w).Encode(v)
+		}
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return bytes.NewReader(buf.Bytes())
+}
+
+func defaultSlackExport(t *testing.T) *bytes.Reader {
+	t.Helper()
+	return makeSlackExport(t, map[string]interface{}{
+		"users.json": []map[string]interface{}{
+			{"id": "U1", "name": "alice", "profile": map[string]string{"display_name": "Alice"}},
+			{"id": "U2", "name": "bob", "profile": map[string]string{"display_name": "Bob"}},
+		},
+		"channels.json": []map[string]interface{}{
+			{"id": "C1", "name": "general", "created": 1, "creator": "U1", "topic": map[string]string{"value": "hello"}},
+			{"id": "C2", "name": "random", "created": 2, "creator": "U1"},
+		},
+		"general/2019-01-01.json": []map[string]interface{}{
+			{"type": "message", "user": "U1", "text": "hi <@U2> &amp; <#C2|random>", "ts": "1546300800.000100", "thread_ts": "1546300800.000100",
+				"reactions": []map[string]interface{}{{"name": "tada", "users": []string{"U1", "U2"}, "count": 2}, {"name": "unknown", "users": []string{"U1"}, "count": 1}}},
+			{"type": "message", "user": "U1", "text": "reply", "ts": "1546300900.000000", "thread_ts": "1546300800.000100"},
+			{"type": "message", "subtype": "channel_join", "user": "U2", "text": "<@U2> has joined the channel", "ts": "1546300700.000000"},
+		},
+		"general/2019-01-02.json": []map[string]interface{}{
+			{"type": "message", "user": "U2", "text": "file", "ts": "1546387200.000000",
+				"files": []map[string]interface{}{{"id": "F1", "name": "a.txt", "mimetype": "text/plain"}, {"id": "F2", "name": "b.txt"}}},
+		},
+		"__uploads/F1/a.txt": []byte("test"),
+	})
+}
+
+func TestParseSlackTimestamp(t *testing.T) {
+	t.Parallel()
+
+	ts, err := parseSlackTimestamp("1503435956.000247")
+	if assert.NoError(t, err) {
+		assert.Equal(t, time.Unix(1503435956, 247000), ts)
+	}
+	ts, err = parseSlackTimestamp("1503435956")
+	if assert.NoError(t, err) {
+		assert.Equal(t, time.Unix(1503435956, 0), ts)
+	}
+	_, err = parseSlackTimestamp("invalid")
+	assert.Error(t, err)
+}
+
+func TestIsSlackFileURL(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, isSlackFileURL("https://files.slack.com/files-pri/T1-F1/download/a.txt"))
+	assert.True(t, isSlackFileURL("https://slack.com/a"))
+	assert.False(t, isSlackFileURL("http://files.slack.com/a"))
+	assert.False(t, isSlackFileURL("https://example.com/a"))
+	assert.False(t, isSlackFileURL("https://files.slack.com.example.com/a"))
+	assert.False(t, isSlackFileURL("https://evilslack.com/a"))
+	assert.False(t, isSlackFileURL("invalid"))
+}
+
+func TestSlackImporter_convertText(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.Must(uuid.NewV4())
+	channelID := uuid.Must(uuid.NewV4())
+	i := &slackImporter{
+		users: map[string]*SlackUserMapping{
+			"U1": {SlackID: "U1", SlackName: "alice", TraQUserName: "alice", TraQUserID: userID},
+			"U2": {SlackID: "U2", SlackName: "bob"},
+		},
+		channelsBySlackID: map[string]*SlackChannelMapping{
+			"C1": {SlackID: "C1", SlackName: "general", TraQName: "general", TraQChannelID: channelID},
+		},
+	}
+
+	cases := []struct {
+		in  string
+		out string
+	}{
+		{"plain &lt;text&gt; &amp;", "plain <text> &"},
+		{"<@U1>", `!{"raw":"@alice","type":"user","id":"` + userID.String() + `"}`},
+		{"<@U2>", "@bob"},
+		{"<@U3|carol>", "@carol"},
+		{"<#C1|general>", `!{"raw":"#general","type":"channel","id":"` + channelID.String() + `"}`},
+		{"<#C9|other>", "#other"},
+		{"<!here>", "@here"},
+		{"<!subteam^S1|@team>", "@team"},
+		{"<https://example.com>", "https://example.com"},
+		{"<https://example.com?a=1&amp;b=2|example>", "[example](https://example.com?a=1&b=2)"},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.out, i.convertText(c.in), c.in)
+	}
+}
+
+func TestImportSlack(t *testing.T) {
+	t.Parallel()
+
+	t.Run("DryRun", func(t *testing.T) {
+		t.Parallel()
+		repo := newFakeRepository()
+		fallback := repo.addUser("traq")
+		repo.addUser("alice")
+		existing, _ := repo.CreatePublicChannel("General", uuid.Nil, fallback.ID)
+		repo.stamps = []*model.Stamp{{ID: uuid.Must(uuid.NewV4()), Name: "tada"}}
+		export := defaultSlackExport(t)
+
+		report, err := ImportSlack(repo, export, export.Size(), SlackImportOptions{
+			FallbackUserID: fallback.ID,
+			ChannelNames:   map[string]string{"random": "this_name_is_too_long_for_traq"},
+			DryRun:         true,
+		})
+		require.NoError(t, err)
+
+		assert.True(t, report.DryRun)
+		if assert.Len(t, report.Conflicts, 2) {
+			assert.Equal(t, ConflictChannelNameCollision, report.Conflicts[0].Kind)
+			assert.Equal(t, "general", report.Conflicts[0].Target)
+			assert.Equal(t, ConflictInvalidChannelName, report.Conflicts[1].Kind)
+			assert.Equal(t, "random", report.Conflicts[1].Target)
+		}
+		assert.Equal(t, 3, report.Messages)
+		assert.Equal(t, 1, report.SkippedMessages)
+		assert.Equal(t, 1, report.Stamps)
+		assert.Equal(t, 1, report.Files)
+		assert.Len(t, report.Warnings, 3) // bob, :unknown:, F2
+
+		assert.Len(t, repo.channels, 1)
+		assert.Contains(t, repo.channels, existing.ID)
+		assert.Empty(t, repo.messages)
+	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		t.Parallel()
+		repo := newFakeRepository()
+		fallback := repo.addUser("traq")
+		export := defaultSlackExport(t)
+
+		report, err := ImportSlack(repo, export, export.Size(), SlackImportOptions{
+			FallbackUserID: fallback.ID,
+			ChannelNames:   map[string]string{"random": "general"},
+		})
+		assert.Equal(t, ErrSlackImportConflict, err)
+		if assert.Len(t, report.Conflicts, 1) {
+			assert.Equal(t, ConflictChannelNameCollision, report.Conflicts[0].Kind)
+			assert.Equal(t, "random", report.Conflicts[0].Target)
+		}
+		assert.Empty(t, repo.channels)
+		assert.Empty(t, repo.messages)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		t.Parallel()
+		repo := newFakeRepository()
+		fallback := repo.addUser("traq")
+		alice := repo.addUser("alice")
+		parent, _ := repo.CreatePublicChannel("slack", uuid.Nil, fallback.ID)
+		tada := &model.Stamp{ID: uuid.Must(uuid.NewV4()), Name: "tada"}
+		repo.stamps = []*model.Stamp{tada}
+		export := defaultSlackExport(t)
+
+		report, err := ImportSlack(repo, export, export.Size(), SlackImportOptions{
+			ParentChannelID: parent.ID,
+			FallbackUserID:  fallback.ID,
+		})
+		require.NoError(t, err)
+		assert.Empty(t, report.Conflicts)
+		assert.Equal(t, 3, report.Messages)
+		assert.Equal(t, 1, report.Stamps)
+		assert.Equal(t, 1, report.Files)
+
+		general := report.Channels[0]
+		random := report.Channels[1]
+		assert.Equal(t, "general", general.TraQName)
+		if ch, ok := repo.channels[general.TraQChannelID]; assert.True(t, ok) {
+			assert.Equal(t, parent.ID, ch.ParentID)
+			assert.Equal(t, alice.ID, ch.CreatorID)
+		}
+		assert.Equal(t, "hello", repo.topics[general.TraQChannelID])
+
+		require.Len(t, repo.order, 3)
+		first := repo.messages[repo.order[0]]
+		assert.Equal(t, alice.ID, first.UserID)
+		assert.Equal(t, general.TraQChannelID, first.ChannelID)
+		assert.Equal(t, `hi @bob & !{"raw":"#random","type":"channel","id":"`+random.TraQChannelID.String()+`"}`, first.Text)
+		assert.Equal(t, time.Unix(1546300800, 100000), first.CreatedAt)
+		if assert.Len(t, first.Stamps, 1) {
+			assert.Equal(t, tada.ID, first.Stamps[0].StampID)
+			assert.Equal(t, alice.ID, first.Stamps[0].UserID)
+		}
+
+		reply := repo.messages[repo.order[1]]
+		assert.Equal(t, first.ID, reply.ParentID)
+		assert.Equal(t, time.Unix(1546300900, 0), reply.CreatedAt)
+
+		withFile := repo.messages[repo.order[2]]
+		assert.Equal(t, fallback.ID, withFile.UserID)
+		assert.True(t, strings.HasPrefix(withFile.Text, "**Bob**: file\n"))
+		require.Len(t, repo.files, 1)
+		for id, b := range repo.files {
+			assert.Contains(t, withFile.Text, id.String())
+			assert.Equal(t, "test", string(b))
+		}
+	})
+	t.Run("SkipRejected", func(t *testing.T) {
+		t.Parallel()
+		repo := newFakeRepository()
+		fallback := repo.addUser("traq")
+		repo.addUser("alice")
+		repo.rejected = "reply"
+		export := defaultSlackExport(t)
+
+		report, err := ImportSlack(repo, export, export.Size(), SlackImportOptions{
+			FallbackUserID: fallback.ID,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, report.Messages)
+		assert.Equal(t, 2, report.SkippedMessages)
+		assert.Len(t, repo.messages, 2)
+	})
+
+	t.Run("Resume", func(t *testing.T) {
+		t.Parallel()
+		repo := newFakeRepository()
+		fallback := repo.addUser("traq")
+		repo.addUser("alice")
+		repo.failOn = "file"
+		progress := &SlackImportProgress{}
+		batches := 0
+		opts := SlackImportOptions{
+			FallbackUserID: fallback.ID,
+			Progress:       progress,
+			OnBatch: func(p *SlackImportProgress) error {
+				batches++
+				return nil
+			},
+		}
+
+		export := defaultSlackExport(t)
+		_, err := ImportSlack(repo, export, export.Size(), opts)
+		require.Error(t, err)
+		assert.Equal(t, 1, batches)
+		assert.Len(t, repo.messages, 2)
+		assert.Len(t, progress.Channels, 2)
+		assert.Equal(t, "1546300900.000000", progress.LastTs["C1"])
+
+		repo.failOn = ""
+		export = defaultSlackExport(t)
+		report, err := ImportSlack(repo, export, export.Size(), opts)
+		require.NoError(t, err)
+		assert.Empty(t, report.Conflicts)
+		assert.Equal(t, 1, report.Messages)
+		assert.Equal(t, 2, batches)
+		assert.Len(t, repo.channels, 2)
+		assert.Len(t, repo.messages, 3)
+		assert.Equal(t, "1546387200.000000", progress.LastTs["C1"])
+	})
+
+	t.Run("NonSlackFileURL", func(t *testing.T) {
+		t.Parallel()
+		repo := newFakeRepository()
+		fallback := repo.addUser("traq")
+		export := makeSlackExport(t, map[string]interface{}{
+			"users.json":    []map[string]interface{}{},
+			"channels.json": []map[string]interface{}{{"id": "C1", "name": "general", "created": 1}},
+			"general/2019-01-01.json": []map[string]interface{}{
+				{"type": "message", "text": "file", "ts": "1546300800.000000",
+					"files": []map[string]interface{}{{"id": "F1", "name": "a.txt", "url_private_download": "https://example.com/a.txt"}}},
+			},
+		})
+
+		report, err := ImportSlack(repo, export, export.Size(), SlackImportOptions{
+			FallbackUserID: fallback.ID,
+			SlackToken:     "xoxp-token",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 0, report.Files)
+		assert.Contains(t, report.Warnings, "file F1 (a.txt) is not hosted on slack and cannot be downloaded")
+	})
+}
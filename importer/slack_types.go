@@ -0,0 +1,111 @@
+package importer
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Slackエクスポートのusers.jsonの要素
+type slackUser struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	RealName string `json:"real_name"`
+	Deleted  bool   `json:"deleted"`
+	IsBot    bool   `json:"is_bot"`
+	Profile  struct {
+		DisplayName string `json:"display_name"`
+		RealName    string `json:"real_name"`
+	} `json:"profile"`
+}
+
+// Slackエクスポートのchannels.jsonの要素
+type slackChannel struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Created    int64  `json:"created"`
+	Creator    string `json:"creator"`
+	IsArchived bool   `json:"is_archived"`
+	Topic      struct {
+		Value string `json:"value"`
+	} `json:"topic"`
+	Purpose struct {
+		Value string `json:"value"`
+	} `json:"purpose"`
+}
+
+// Slackエクスポートの<チャンネル名>/<日付>.jsonの要素
+type slackMessage struct {
+	Type      string          `json:"type"`
+	Subtype   string          `json:"subtype"`
+	User      string          `json:"user"`
+	Username  string          `json:"username"`
+	Text      string          `json:"text"`
+	Ts        string          `json:"ts"`
+	ThreadTs  string          `json:"thread_ts"`
+	Reactions []slackReaction `json:"reactions"`
+	Files     []slackFile     `json:"files"`
+}
+
+type slackReaction struct {
+	Name  string   `json:"name"`
+	Users []string `json:"users"`
+	Count int      `json:"count"`
+}
+
+type slackFile struct {
+	ID                 string `json:"id"`
+	Name               string `json:"name"`
+	Title              string `json:"title"`
+	Mimetype           string `json:"mimetype"`
+	Size               int64  `json:"size"`
+	URLPrivateDownload string `json:"url_private_download"`
+}
+
+// isThreadReply スレッドへの返信メッセージかどうか
+func (m *slackMessage) isThreadReply() bool {
+	return len(m.ThreadTs) > 0 && m.ThreadTs != m.Ts
+}
+
+// インポート対象のメッセージのサブタイプ
+var importableSubtypes = map[string]bool{
+	"":                 true,
+	"bot_message":      true,
+	"file_share":       true,
+	"me_message":       true,
+	"thread_broadcast": true,
+}
+
+// isThreadParent スレッドの親メッセージかどうか
+func (m *slackMessage) isThreadParent() bool {
+	return len(m.ThreadTs) > 0 && m.ThreadTs == m.Ts
+}
+
+// isImportable インポート対象のメッセージかどうか
+func (m *slackMessage) isImportable() bool {
+	return m.Type == "message" && importableSubtypes[m.Subtype]
+}
+
+// parseSlackTimestamp Slackのタイムスタンプ("1503435956.000247")を時刻に変換します
+func parseSlackTimestamp(ts string) (time.Time, error) {
+	sec, frac := ts, ""
+	if i := strings.IndexByte(ts, '.'); i >= 0 {
+		sec, frac = ts[:i], ts[i+1:]
+	}
+	s, err := strconv.ParseInt(sec, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var usec int64
+	if len(frac) > 0 {
+		if len(frac) > 6 {
+			frac = frac[:6]
+		}
+		frac += strings.Repeat("0", 6-len(frac))
+		usec, err = strconv.ParseInt(frac, 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+	}
+	return time.Unix(s, usec*int64(time.Microsecond)), nil
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/traPtitech/traQ/repository"
 	"github.com/traPtitech/traQ/router"
 	"github.com/traPtitech/traQ/sessions"
+	"github.com/traPtitech/traQ/unfurler"
 	"github.com/traPtitech/traQ/utils"
 	"github.com/traPtitech/traQ/utils/storage"
 	"go.uber.org/zap"
@@ -110,6 +111,22 @@ func main() {
 		}
 	}
 
+	// Subcommands
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "export":
+			if err := runExportCommand(repo, os.Args[2:]); err != nil {
+				logger.Fatal("failed to export channel", zap.Error(err))
+			}
+			return
+		case "import-slack":
+			if err := runImportSlackCommand(repo, os.Args[2:]); err != nil {
+				logger.Fatal("failed to import slack export", zap.Error(err))
+			}
+			return
+		}
+	}
+
 	if viper.GetBool("generateThumbnailOnStartUp") {
 		var files []uuid.UUID
 		if err := engine.Model(&model.File{}).Where("has_thumbnail = false").Pluck("id", &files).Error; err != nil {
@@ -148,6 +165,30 @@ func main() {
 	// Bot Processor
 	bot.NewProcessor(repo, hub, logger.Named("bot_processor"))
 
+	// Mention Recorder
+	NewMentionRecorder(repo, hub, logger.Named("mention_recorder"))
+
+	// Link Preview Unfurler
+	if viper.GetBool("unfurl.enabled") {
+		unfurler.New(repo, hub, logger.Named("unfurler"), unfurler.Config{
+			Timeout:     time.Duration(viper.GetInt("unfurl.timeout")) * time.Second,
+			MaxBodySize: viper.GetInt64("unfurl.maxBodySize"),
+			CacheTTL:    time.Duration(viper.GetInt("unfurl.cacheTTL")) * time.Second,
+		})
+	}
+
+	// Scheduled Message Sender
+	scheduledMessageSender := NewScheduledMessageSender(repo, logger.Named("scheduled_message_sender"), time.Duration(viper.GetInt("scheduledMessage.interval"))*time.Second)
+
+	// Reminder Sender
+	reminderSender := NewReminderSender(repo, logger.Named("reminder_sender"), time.Duration(viper.GetInt("reminder.interval"))*time.Second, viper.GetString("origin"))
+
+	// Message Retention Sweeper
+	messageRetentionSweeper := NewMessageRetentionSweeper(repo, logger.Named("message_retention_sweeper"), time.Duration(viper.GetInt("messageRetention.interval"))*time.Second)
+
+	// Channel Export Worker
+	channelExportWorker := NewChannelExportWorker(repo, logger.Named("channel_export_worker"), time.Duration(viper.GetInt("channelExport.interval"))*time.Second)
+
 	// JWT for QRCode
 	pubRaw, err := ioutil.ReadFile(viper.GetString("jwt.keys.public"))
 	if err != nil {
@@ -167,6 +208,20 @@ func main() {
 		AccessTokenExp:   viper.GetInt("oauth2.accessTokenExp"),
 		IsRefreshEnabled: viper.GetBool("oauth2.isRefreshEnabled"),
 		SkyWaySecretKey:  viper.GetString("skyway.secretKey"),
+		RateLimit: router.RateLimitConfig{
+			User: router.RateLimit{
+				PerMinute: viper.GetInt("rateLimit.user.perMinute"),
+				Burst:     viper.GetInt("rateLimit.user.burst"),
+			},
+			Bot: router.RateLimit{
+				PerMinute: viper.GetInt("rateLimit.bot.perMinute"),
+				Burst:     viper.GetInt("rateLimit.bot.burst"),
+			},
+			Webhook: router.RateLimit{
+				PerMinute: viper.GetInt("rateLimit.webhook.perMinute"),
+				Burst:     viper.GetInt("rateLimit.webhook.burst"),
+			},
+		},
 	})
 	e := echo.New()
 	if viper.GetBool("accessLog.enabled") {
@@ -194,6 +249,10 @@ func main() {
 	if err := e.Shutdown(ctx); err != nil {
 		logger.Warn("abnormal shutdown", zap.Error(err))
 	}
+	scheduledMessageSender.Stop()
+	reminderSender.Stop()
+	messageRetentionSweeper.Stop()
+	channelExportWorker.Stop()
 	sessions.PurgeCache()
 }
 
@@ -230,6 +289,25 @@ func setDefaultConfigs() {
 	viper.SetDefault("jwt.keys.private", "./keys/ec.pem")
 
 	viper.SetDefault("skyway.secretKey", "")
+
+	viper.SetDefault("scheduledMessage.interval", 10)
+	viper.SetDefault("reminder.interval", 10)
+
+	viper.SetDefault("messageRetention.interval", 60*60)
+
+	viper.SetDefault("channelExport.interval", 10)
+
+	viper.SetDefault("unfurl.enabled", true)
+	viper.SetDefault("unfurl.timeout", 5)
+	viper.SetDefault("unfurl.maxBodySize", 1<<20)
+	viper.SetDefault("unfurl.cacheTTL", 60*60*24)
+
+	viper.SetDefault("rateLimit.user.perMinute", 30)
+	viper.SetDefault("rateLimit.user.burst", 10)
+	viper.SetDefault("rateLimit.bot.perMinute", 60)
+	viper.SetDefault("rateLimit.bot.burst", 20)
+	viper.SetDefault("rateLimit.webhook.perMinute", 60)
+	viper.SetDefault("rateLimit.webhook.burst", 20)
 }
 
 func getDatabase() (*gorm.DB, error) {
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/leandro-lugaresi/hub"
+	"github.com/traPtitech/traQ/event"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/utils/message"
+	"go.uber.org/zap"
+)
+
+// MentionRecorder 投稿されたメッセージのメンションをメンション一覧に記録するサービス
+type MentionRecorder struct {
+	repo   repository.Repository
+	logger *zap.Logger
+}
+
+// NewMentionRecorder MentionRecorderを生成し、起動します
+func NewMentionRecorder(repo repository.Repository, hub *hub.Hub, logger *zap.Logger) *MentionRecorder {
+	r := &MentionRecorder{
+		repo:   repo,
+		logger: logger,
+	}
+	go func() {
+		sub := hub.Subscribe(100, event.MessageCreated, event.ThreadReplyCreated)
+		for ev := range sub.Receiver {
+			m := ev.Fields["message"].(*model.Message)
+			embedded := ev.Fields["embedded"].([]*message.EmbeddedInfo)
+			go r.record(m, embedded)
+		}
+	}()
+	return r
+}
+
+// record メッセージでメンションされたユーザーをメンション一覧に記録します
+//
+// 直接のメンションはグループのメンションより優先されます。メッセージの投稿者は含まれません。
+// プライベートチャンネルの場合はチャンネルのメンバーのみが対象です。
+func (r *MentionRecorder) record(m *model.Message, embedded []*message.EmbeddedInfo) {
+	mentions := map[uuid.UUID]uuid.UUID{}
+	for _, v := range embedded {
+		if v.Type != "group" {
+			continue
+		}
+		gid := uuid.FromStringOrNil(v.ID)
+		gs, err := r.repo.GetUserGroupMemberIDs(gid)
+		if err != nil {
+			r.logger.Error("failed to GetUserGroupMemberIDs", zap.Error(err), zap.Stringer("id", gid))
+			return
+		}
+		for _, uid := range gs {
+			if _, ok := mentions[uid]; !ok {
+				mentions[uid] = gid
+			}
+		}
+	}
+	for _, v := range embedded {
+		if v.Type != "user" {
+			continue
+		}
+		if uid, err := uuid.FromString(v.ID); err == nil {
+			mentions[uid] = uuid.Nil
+		}
+	}
+	delete(mentions, m.UserID)
+	if len(mentions) == 0 {
+		return
+	}
+
+	ch, err := r.repo.GetChannel(m.ChannelID)
+	if err != nil {
+		r.logger.Error("failed to GetChannel", zap.Error(err), zap.Stringer("id", m.ChannelID))
+		return
+	}
+	if !ch.IsPublic {
+		ids, err := r.repo.GetPrivateChannelMemberIDs(ch.ID)
+		if err != nil {
+			r.logger.Error("failed to GetPrivateChannelMemberIDs", zap.Error(err), zap.Stringer("id", ch.ID))
+			return
+		}
+		members := map[uuid.UUID]bool{}
+		for _, id := range ids {
+			members[id] = true
+		}
+		for uid := range mentions {
+			if !members[uid] {
+				delete(mentions, uid)
+			}
+		}
+		if len(mentions) == 0 {
+			return
+		}
+	}
+
+	if err := r.repo.CreateMentions(m, mentions); err != nil {
+		r.logger.Error("failed to CreateMentions", zap.Error(err), zap.Stringer("messageId", m.ID))
+	}
+}
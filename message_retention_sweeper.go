@@ -0,0 +1,112 @@
+package main
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	messageRetentionDefaultInterval = time.Hour
+	messageRetentionBatchSize       = 500
+)
+
+// MessageRetentionSweeper チャンネルのメッセージ保持ポリシーに従って期限切れのメッセージを削除する構造体
+//
+// メッセージの削除はRepository.DeleteExpiredMessageを通して行うため、通常の削除と同様にピンの削除やイベントの発行が行われます。
+type MessageRetentionSweeper struct {
+	repo     repository.Repository
+	logger   *zap.Logger
+	interval time.Duration
+	done     chan struct{}
+}
+
+// NewMessageRetentionSweeper MessageRetentionSweeperを生成し、起動します
+func NewMessageRetentionSweeper(repo repository.Repository, logger *zap.Logger, interval time.Duration) *MessageRetentionSweeper {
+	if interval <= 0 {
+		interval = messageRetentionDefaultInterval
+	}
+	s := &MessageRetentionSweeper{
+		repo:     repo,
+		logger:   logger,
+		interval: interval,
+		done:     make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// Stop MessageRetentionSweeperを停止します
+func (s *MessageRetentionSweeper) Stop() {
+	close(s.done)
+}
+
+func (s *MessageRetentionSweeper) run() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.sweep()
+	for {
+		select {
+		case <-ticker.C:
+			s.sweep()
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *MessageRetentionSweeper) sweep() {
+	targets, err := s.repo.GetRetentionTargetChannels()
+	if err != nil {
+		s.logger.Error("failed to GetRetentionTargetChannels", zap.Error(err))
+		return
+	}
+
+	now := time.Now()
+	for channelID, policy := range targets {
+		s.sweepChannel(channelID, policy, now)
+	}
+}
+
+func (s *MessageRetentionSweeper) sweepChannel(channelID uuid.UUID, policy *model.ChannelRetentionPolicy, now time.Time) {
+	logger := s.logger.With(zap.Stringer("channelId", channelID))
+	before := now.AddDate(0, 0, -policy.Days)
+
+	deleted := 0
+	for {
+		select {
+		case <-s.done:
+			return
+		default:
+		}
+
+		ids, err := s.repo.GetExpiredMessageIDs(channelID, before, policy.ExemptPinned, policy.ExemptClipped, messageRetentionBatchSize)
+		if err != nil {
+			logger.Error("failed to GetExpiredMessageIDs", zap.Error(err))
+			return
+		}
+		if len(ids) == 0 {
+			break
+		}
+
+		for _, id := range ids {
+			// 他のプロセスが既に削除している場合はErrNotFoundになる
+			if err := s.repo.DeleteExpiredMessage(id); err != nil && err != repository.ErrNotFound {
+				logger.Error("failed to DeleteExpiredMessage", zap.Error(err), zap.Stringer("messageId", id))
+				return
+			}
+			deleted++
+		}
+		if len(ids) < messageRetentionBatchSize {
+			break
+		}
+	}
+
+	if deleted > 0 {
+		logger.Info("expired messages were deleted", zap.Int("count", deleted))
+	}
+}
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"github.com/gofrs/uuid"
+	"time"
+)
+
+// ChannelExportState チャンネルエクスポートの状態
+type ChannelExportState int
+
+const (
+	// ChannelExportPending チャンネルエクスポートの状態: 処理待ち
+	ChannelExportPending ChannelExportState = 0
+	// ChannelExportRunning チャンネルエクスポートの状態: 処理中
+	ChannelExportRunning ChannelExportState = 1
+	// ChannelExportCompleted チャンネルエクスポートの状態: 完了
+	ChannelExportCompleted ChannelExportState = 2
+	// ChannelExportFailed チャンネルエクスポートの状態: 失敗
+	ChannelExportFailed ChannelExportState = 3
+)
+
+// String 状態を表す文字列を返します
+func (s ChannelExportState) String() string {
+	switch s {
+	case ChannelExportPending:
+		return "pending"
+	case ChannelExportRunning:
+		return "running"
+	case ChannelExportCompleted:
+		return "completed"
+	case ChannelExportFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText encoding.TextMarshaler 実装
+func (s ChannelExportState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// ChannelExport チャンネルエクスポート構造体
+type ChannelExport struct {
+	ID              uuid.UUID          `gorm:"type:char(36);not null;primary_key" json:"id"`
+	ChannelID       uuid.UUID          `gorm:"type:char(36);not null"             json:"channelId"`
+	UserID          uuid.UUID          `gorm:"type:char(36);not null"             json:"userId"`
+	WithDescendants bool               `gorm:"type:boolean;not null"              json:"descendants"`
+	State           ChannelExportState `gorm:"type:tinyint;not null;index"        json:"state"`
+	FileID          uuid.UUID          `gorm:"type:char(36);not null"             json:"-"`
+	Error           string             `gorm:"type:text;not null"                 json:"error"`
+	CreatedAt       time.Time          `gorm:"precision:6"                        json:"createdAt"`
+	UpdatedAt       time.Time          `gorm:"precision:6"                        json:"updatedAt"`
+}
+
+// TableName ChannelExport構造体のテーブル名
+func (*ChannelExport) TableName() string {
+	return "channel_exports"
+}
@@ -0,0 +1,20 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestChannelExport_TableName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "channel_exports", (&ChannelExport{}).TableName())
+}
+
+func TestChannelExportState_String(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "pending", ChannelExportPending.String())
+	assert.Equal(t, "running", ChannelExportRunning.String())
+	assert.Equal(t, "completed", ChannelExportCompleted.String())
+	assert.Equal(t, "failed", ChannelExportFailed.String())
+	assert.Equal(t, "unknown", ChannelExportState(100).String())
+}
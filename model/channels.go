@@ -16,18 +16,20 @@ var dmChannelRootUUID = uuid.Must(uuid.FromString(DirectMessageChannelRootID))
 
 // Channel チャンネルの構造体
 type Channel struct {
-	ID        uuid.UUID  `gorm:"type:char(36);not null;primary_key"`
-	Name      string     `gorm:"type:varchar(20);not null;unique_index:name_parent" validate:"channel,required"`
-	ParentID  uuid.UUID  `gorm:"type:char(36);not null;unique_index:name_parent"`
-	Topic     string     `gorm:"type:text;not null"`
-	IsForced  bool       `gorm:"type:boolean;not null;default:false"`
-	IsPublic  bool       `gorm:"type:boolean;not null;default:false"`
-	IsVisible bool       `gorm:"type:boolean;not null;default:false"`
-	CreatorID uuid.UUID  `gorm:"type:char(36);not null"`
-	UpdaterID uuid.UUID  `gorm:"type:char(36);not null"`
-	CreatedAt time.Time  `gorm:"precision:6"`
-	UpdatedAt time.Time  `gorm:"precision:6"`
-	DeletedAt *time.Time `gorm:"precision:6"`
+	ID               uuid.UUID  `gorm:"type:char(36);not null;primary_key"`
+	Name             string     `gorm:"type:varchar(20);not null;unique_index:name_parent" validate:"channel,required"`
+	ParentID         uuid.UUID  `gorm:"type:char(36);not null;unique_index:name_parent"`
+	Topic            string     `gorm:"type:text;not null"`
+	IsForced         bool       `gorm:"type:boolean;not null;default:false"`
+	IsPublic         bool       `gorm:"type:boolean;not null;default:false"`
+	IsVisible        bool       `gorm:"type:boolean;not null;default:false"`
+	SlowModeInterval int        `gorm:"type:int;not null;default:0"`
+	IsArchived       bool       `gorm:"type:boolean;not null;default:false"`
+	CreatorID        uuid.UUID  `gorm:"type:char(36);not null"`
+	UpdaterID        uuid.UUID  `gorm:"type:char(36);not null"`
+	CreatedAt        time.Time  `gorm:"precision:6"`
+	UpdatedAt        time.Time  `gorm:"precision:6"`
+	DeletedAt        *time.Time `gorm:"precision:6"`
 }
 
 // TableName テーブル名を指定するメソッド
@@ -61,3 +63,41 @@ type UserSubscribeChannel struct {
 func (*UserSubscribeChannel) TableName() string {
 	return "users_subscribe_channels"
 }
+
+// ChannelMember パブリックチャンネルの参加メンバー構造体
+//
+// プライベートチャンネルのメンバーはUsersPrivateChannelで管理します。
+type ChannelMember struct {
+	ChannelID uuid.UUID `gorm:"type:char(36);not null;primary_key"`
+	UserID    uuid.UUID `gorm:"type:char(36);not null;primary_key;index"`
+	CreatedAt time.Time `gorm:"precision:6"`
+}
+
+// TableName ChannelMember構造体のテーブル名
+func (*ChannelMember) TableName() string {
+	return "channel_members"
+}
+
+// ChannelRetentionPolicy チャンネルのメッセージ保持ポリシー構造体
+//
+// ポリシーが設定されていないチャンネルは祖先チャンネルのポリシーを継承します。
+type ChannelRetentionPolicy struct {
+	ChannelID     uuid.UUID `gorm:"type:char(36);not null;primary_key"  json:"channelId"`
+	Days          int       `gorm:"type:int;not null"                   json:"days"`
+	ExemptPinned  bool      `gorm:"type:boolean;not null;default:false" json:"exemptPinned"`
+	ExemptClipped bool      `gorm:"type:boolean;not null;default:false" json:"exemptClipped"`
+	CreatedAt     time.Time `gorm:"precision:6"                         json:"createdAt"`
+	UpdatedAt     time.Time `gorm:"precision:6"                         json:"updatedAt"`
+}
+
+// TableName ChannelRetentionPolicy構造体のテーブル名
+func (*ChannelRetentionPolicy) TableName() string {
+	return "channel_retention_policies"
+}
+
+// IsEnabled メッセージの削除が有効なポリシーかどうかを返します
+//
+// 保持日数が0のポリシーは祖先チャンネルのポリシーを打ち消し、メッセージを無期限に保持します。
+func (p *ChannelRetentionPolicy) IsEnabled() bool {
+	return p.Days > 0
+}
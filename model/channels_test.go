@@ -27,3 +27,19 @@ func TestUserSubscribeChannel_TableName(t *testing.T) {
 
 	assert.Equal(t, "users_subscribe_channels", (&UserSubscribeChannel{}).TableName())
 }
+
+func TestChannelMember_TableName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "channel_members", (&ChannelMember{}).TableName())
+}
+
+func TestChannelRetentionPolicy_TableName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "channel_retention_policies", (&ChannelRetentionPolicy{}).TableName())
+}
+
+func TestChannelRetentionPolicy_IsEnabled(t *testing.T) {
+	t.Parallel()
+	assert.False(t, (&ChannelRetentionPolicy{Days: 0}).IsEnabled())
+	assert.True(t, (&ChannelRetentionPolicy{Days: 30}).IsEnabled())
+}
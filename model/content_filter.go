@@ -0,0 +1,51 @@
+package model
+
+import (
+	"github.com/gofrs/uuid"
+	"time"
+)
+
+// ContentFilterAction コンテンツフィルタのルールに一致した場合の動作
+type ContentFilterAction string
+
+const (
+	// ContentFilterActionReject メッセージの投稿・編集を拒否する
+	ContentFilterActionReject ContentFilterAction = "reject"
+	// ContentFilterActionFlag メッセージを通報としてモデレーターに報告する
+	ContentFilterActionFlag ContentFilterAction = "flag"
+	// ContentFilterActionMask 一致した部分を伏せ字にする
+	ContentFilterActionMask ContentFilterAction = "mask"
+)
+
+// Valid 有効な動作かどうかを返します
+func (a ContentFilterAction) Valid() bool {
+	switch a {
+	case ContentFilterActionReject, ContentFilterActionFlag, ContentFilterActionMask:
+		return true
+	default:
+		return false
+	}
+}
+
+// ContentFilterRule コンテンツフィルタのルール構造体
+type ContentFilterRule struct {
+	ID          uuid.UUID           `gorm:"type:char(36);not null;primary_key" json:"id"`
+	Pattern     string              `gorm:"type:text;not null"                 json:"pattern"`
+	IsRegex     bool                `gorm:"type:boolean;not null;default:false" json:"isRegex"`
+	ChannelID   uuid.UUID           `gorm:"type:char(36);not null"             json:"channelId"`
+	Action      ContentFilterAction `gorm:"type:varchar(10);not null"          json:"action"`
+	Description string              `gorm:"type:text;not null"                 json:"description"`
+	CreatorID   uuid.UUID           `gorm:"type:char(36);not null"             json:"creatorId"`
+	CreatedAt   time.Time           `gorm:"precision:6"                        json:"createdAt"`
+	UpdatedAt   time.Time           `gorm:"precision:6"                        json:"updatedAt"`
+}
+
+// TableName ContentFilterRule構造体のテーブル名
+func (*ContentFilterRule) TableName() string {
+	return "content_filter_rules"
+}
+
+// IsGlobal 全てのチャンネルに適用されるルールかどうかを返します
+func (r *ContentFilterRule) IsGlobal() bool {
+	return r.ChannelID == uuid.Nil
+}
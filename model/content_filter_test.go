@@ -0,0 +1,29 @@
+package model
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestContentFilterRule_TableName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "content_filter_rules", (&ContentFilterRule{}).TableName())
+}
+
+func TestContentFilterRule_IsGlobal(t *testing.T) {
+	t.Parallel()
+	assert.True(t, (&ContentFilterRule{}).IsGlobal())
+	assert.False(t, (&ContentFilterRule{ChannelID: uuid.Must(uuid.NewV4())}).IsGlobal())
+}
+
+func TestContentFilterAction_Valid(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	assert.True(ContentFilterActionReject.Valid())
+	assert.True(ContentFilterActionFlag.Valid())
+	assert.True(ContentFilterActionMask.Valid())
+	assert.False(ContentFilterAction("").Valid())
+	assert.False(ContentFilterAction("delete").Valid())
+}
@@ -16,6 +16,8 @@ const (
 	FileTypeStamp = "stamp"
 	// FileTypeThumbnail サムネイルファイルタイプ
 	FileTypeThumbnail = "thumbnail"
+	// FileTypeChannelExport チャンネルエクスポートファイルタイプ
+	FileTypeChannelExport = "export"
 )
 
 // File DBに格納するファイルの構造体
@@ -25,6 +27,7 @@ type File struct {
 	Mime            string     `gorm:"type:text;not null"                   json:"mime"                  validate:"required"`
 	Size            int64      `gorm:"type:bigint;not null"                 json:"size"                  validate:"min=0,required"`
 	CreatorID       uuid.UUID  `gorm:"type:char(36);not null"               json:"-"`
+	ChannelID       uuid.UUID  `gorm:"type:char(36);not null;default:'00000000-0000-0000-0000-000000000000';index" json:"-"`
 	Hash            string     `gorm:"type:char(32);not null"               json:"md5"                   validate:"max=32"`
 	Type            string     `gorm:"type:varchar(30);not null;default:''" json:"-"`
 	HasThumbnail    bool       `gorm:"type:boolean;not null;default:false"  json:"hasThumb"`
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// LinkPreview URLプレビュー(OGP/Twitter Card/oEmbed)のキャッシュ構造体
+type LinkPreview struct {
+	URLHash     string    `gorm:"type:char(64);not null;primary_key" json:"-"`
+	URL         string    `gorm:"type:text;not null"                 json:"url"`
+	Type        string    `gorm:"type:varchar(30);not null"          json:"type"`
+	Title       string    `gorm:"type:text;not null"                 json:"title"`
+	Description string    `gorm:"type:text;not null"                 json:"description"`
+	ImageURL    string    `gorm:"type:text;not null"                 json:"image"`
+	SiteName    string    `gorm:"type:text;not null"                 json:"siteName"`
+	Failed      bool      `gorm:"type:boolean;not null;default:false" json:"-"`
+	FetchedAt   time.Time `gorm:"precision:6;index"                  json:"fetchedAt"`
+}
+
+// TableName LinkPreview構造体のテーブル名
+func (*LinkPreview) TableName() string {
+	return "link_previews"
+}
+
+// LinkPreviewURLHash URLからLinkPreviewの主キーを生成します
+func LinkPreviewURLHash(url string) string {
+	h := sha256.Sum256([]byte(url))
+	return hex.EncodeToString(h[:])
+}
@@ -0,0 +1,18 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLinkPreview_TableName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "link_previews", (&LinkPreview{}).TableName())
+}
+
+func TestLinkPreviewURLHash(t *testing.T) {
+	t.Parallel()
+	assert.Len(t, LinkPreviewURLHash("https://example.com"), 64)
+	assert.Equal(t, LinkPreviewURLHash("https://example.com"), LinkPreviewURLHash("https://example.com"))
+	assert.NotEqual(t, LinkPreviewURLHash("https://example.com"), LinkPreviewURLHash("https://example.com/"))
+}
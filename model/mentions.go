@@ -0,0 +1,27 @@
+package model
+
+import (
+	"github.com/gofrs/uuid"
+	"time"
+)
+
+// Mention メッセージでユーザーがメンションされた記録の構造体
+type Mention struct {
+	UserID    uuid.UUID `gorm:"type:char(36);not null;primary_key;index:user_id_created_at_idx"`
+	MessageID uuid.UUID `gorm:"type:char(36);not null;primary_key"`
+	ChannelID uuid.UUID `gorm:"type:char(36);not null"`
+	// GroupID グループのメンションによってメンションされた場合のグループID
+	GroupID uuid.UUID `gorm:"type:char(36);not null;default:'00000000-0000-0000-0000-000000000000'"`
+	// CreatedAt メッセージの投稿日時
+	CreatedAt time.Time `gorm:"precision:6;index:user_id_created_at_idx"`
+}
+
+// TableName Mention構造体のテーブル名
+func (*Mention) TableName() string {
+	return "mentions"
+}
+
+// IsGroupMention グループのメンションによるものかどうかを返します
+func (m *Mention) IsGroupMention() bool {
+	return m.GroupID != uuid.Nil
+}
@@ -0,0 +1,18 @@
+package model
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMention_TableName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "mentions", (&Mention{}).TableName())
+}
+
+func TestMention_IsGroupMention(t *testing.T) {
+	t.Parallel()
+	assert.False(t, (&Mention{}).IsGroupMention())
+	assert.True(t, (&Mention{GroupID: uuid.Must(uuid.NewV4())}).IsGroupMention())
+}
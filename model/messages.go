@@ -10,13 +10,16 @@ type Message struct {
 	ID        uuid.UUID  `gorm:"type:char(36);not null;primary_key"`
 	UserID    uuid.UUID  `gorm:"type:char(36);not null;"`
 	ChannelID uuid.UUID  `gorm:"type:char(36);not null;index"`
+	ParentID  uuid.UUID  `gorm:"type:char(36);not null;default:'00000000-0000-0000-0000-000000000000';index"`
 	Text      string     `gorm:"type:text;not null"`
+	EditCount int        `gorm:"type:int;not null;default:0"`
 	CreatedAt time.Time  `gorm:"precision:6;index"`
 	UpdatedAt time.Time  `gorm:"precision:6"`
 	DeletedAt *time.Time `gorm:"precision:6;index"`
 
 	Stamps []MessageStamp `gorm:"association_autoupdate:false;association_autocreate:false;preload:false;foreignkey:MessageID"`
 	Pin    *Pin           `gorm:"association_autoupdate:false;association_autocreate:false;preload:false;foreignkey:MessageID"`
+	Thread *MessageThread `gorm:"association_autoupdate:false;association_autocreate:false;preload:false;foreignkey:ParentID;association_foreignkey:ID"`
 }
 
 // TableName DBの名前を指定するメソッド
@@ -24,6 +27,30 @@ func (m *Message) TableName() string {
 	return "messages"
 }
 
+// IsThreadReply スレッドへの返信メッセージかどうかを返します
+func (m *Message) IsThreadReply() bool {
+	return m.ParentID != uuid.Nil
+}
+
+// IsEdited メッセージが編集されたことがあるかどうかを返します
+func (m *Message) IsEdited() bool {
+	return m.EditCount > 0
+}
+
+// MessageThread メッセージのスレッド情報
+type MessageThread struct {
+	ParentID          uuid.UUID `gorm:"type:char(36);not null;primary_key"`
+	ReplyCount        int       `gorm:"type:int;not null;default:0"`
+	LatestReplyID     uuid.UUID `gorm:"type:char(36);not null"`
+	LatestReplyUserID uuid.UUID `gorm:"type:char(36);not null"`
+	LatestReplyAt     time.Time `gorm:"precision:6"`
+}
+
+// TableName テーブル名
+func (t *MessageThread) TableName() string {
+	return "message_threads"
+}
+
 // ChannelLatestMessage チャンネル別最新メッセージ
 type ChannelLatestMessage struct {
 	ChannelID uuid.UUID `gorm:"type:char(36);not null;primary_key"`
@@ -36,17 +63,28 @@ func (m *ChannelLatestMessage) TableName() string {
 	return "channel_latest_messages"
 }
 
-// Unread 未読レコード
-type Unread struct {
-	UserID     uuid.UUID `gorm:"type:char(36);not null;primary_key"`
-	MessageID  uuid.UUID `gorm:"type:char(36);not null;primary_key"`
-	Noticeable bool      `gorm:"type:boolean;not null;default:false"`
-	CreatedAt  time.Time `gorm:"precision:6"`
+// ChannelReadPointer ユーザーのチャンネル既読位置構造体
+type ChannelReadPointer struct {
+	UserID    uuid.UUID `gorm:"type:char(36);not null;primary_key"`
+	ChannelID uuid.UUID `gorm:"type:char(36);not null;primary_key"`
+	// LastReadAt この日時以前のメッセージは既読
+	LastReadAt *time.Time `gorm:"precision:6"`
+	// LastReadMessageID 最後に読んだメッセージのID
+	LastReadMessageID uuid.UUID `gorm:"type:char(36);not null;default:'00000000-0000-0000-0000-000000000000'"`
+	// MentionCount 既読位置以降の自分がメンションされたメッセージの数
+	MentionCount int `gorm:"type:int;not null;default:0"`
+	// NotifiedCount 既読位置以降の自分に通知されたメッセージ(メンション・スレッドの返信)の数
+	NotifiedCount int `gorm:"type:int;not null;default:0"`
+	// FirstNotifiedAt 既読位置以降の最初に通知されたメッセージの日時
+	FirstNotifiedAt *time.Time `gorm:"precision:6"`
+	// LastNotifiedAt 既読位置以降の最後に通知されたメッセージの日時
+	LastNotifiedAt *time.Time `gorm:"precision:6"`
+	UpdatedAt      time.Time  `gorm:"precision:6"`
 }
 
 // TableName テーブル名
-func (unread *Unread) TableName() string {
-	return "unreads"
+func (p *ChannelReadPointer) TableName() string {
+	return "channel_read_pointers"
 }
 
 // ArchivedMessage 編集前のアーカイブ化されたメッセージの構造体
@@ -3,6 +3,7 @@ package model
 import (
 	"testing"
 
+	"github.com/gofrs/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -11,9 +12,9 @@ func TestMessage_TableName(t *testing.T) {
 	assert.Equal(t, "messages", (&Message{}).TableName())
 }
 
-func TestUnread_TableName(t *testing.T) {
+func TestChannelReadPointer_TableName(t *testing.T) {
 	t.Parallel()
-	assert.Equal(t, "unreads", (&Unread{}).TableName())
+	assert.Equal(t, "channel_read_pointers", (&ChannelReadPointer{}).TableName())
 }
 
 func TestChannelLatestMessage_TableName(t *testing.T) {
@@ -25,3 +26,20 @@ func TestArchivedMessage_TableName(t *testing.T) {
 	t.Parallel()
 	assert.Equal(t, "archived_messages", (&ArchivedMessage{}).TableName())
 }
+
+func TestMessageThread_TableName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "message_threads", (&MessageThread{}).TableName())
+}
+
+func TestMessage_IsThreadReply(t *testing.T) {
+	t.Parallel()
+	assert.False(t, (&Message{}).IsThreadReply())
+	assert.True(t, (&Message{ParentID: uuid.Must(uuid.NewV4())}).IsThreadReply())
+}
+
+func TestMessage_IsEdited(t *testing.T) {
+	t.Parallel()
+	assert.False(t, (&Message{}).IsEdited())
+	assert.True(t, (&Message{EditCount: 1}).IsEdited())
+}
@@ -0,0 +1,16 @@
+package model
+
+import (
+	"time"
+)
+
+// Migration 実行済みのデータ移行のレコード
+type Migration struct {
+	ID        string    `gorm:"type:varchar(100);not null;primary_key"`
+	AppliedAt time.Time `gorm:"precision:6"`
+}
+
+// TableName Migration構造体のテーブル名
+func (*Migration) TableName() string {
+	return "migrations"
+}
@@ -0,0 +1,11 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMigration_TableName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "migrations", (&Migration{}).TableName())
+}
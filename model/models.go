@@ -5,6 +5,20 @@ var (
 	// モデルを追加したら各自ここに追加しなければいけない
 	// **順番注意**
 	Tables = []interface{}{
+		&Migration{},
+		&Reminder{},
+		&Mention{},
+		&ModerationLog{},
+		&ModerationCase{},
+		&ContentFilterRule{},
+		&PollVote{},
+		&PollOption{},
+		&Poll{},
+		&LinkPreview{},
+		&ChannelRetentionPolicy{},
+		&ChannelExport{},
+		&ScheduledMessage{},
+		&MessageThread{},
 		&ChannelLatestMessage{},
 		&BotEventLog{},
 		&BotJoinChannel{},
@@ -20,12 +34,13 @@ var (
 		&Clip{},
 		&ClipFolder{},
 		&UsersTag{},
-		&Unread{},
+		&ChannelReadPointer{},
 		&Star{},
 		&Device{},
 		&Pin{},
 		&FileACLEntry{},
 		&File{},
+		&ChannelMember{},
 		&UsersPrivateChannel{},
 		&UserSubscribeChannel{},
 		&Tag{},
@@ -42,12 +57,15 @@ var (
 		// Table, Key, Reference, OnDelete, OnUpdate
 		{"users_private_channels", "user_id", "users(id)", "CASCADE", "CASCADE"},
 		{"users_private_channels", "channel_id", "channels(id)", "CASCADE", "CASCADE"},
+		{"channel_members", "user_id", "users(id)", "CASCADE", "CASCADE"},
+		{"channel_members", "channel_id", "channels(id)", "CASCADE", "CASCADE"},
 		{"messages", "user_id", "users(id)", "CASCADE", "CASCADE"},
 		{"messages", "channel_id", "channels(id)", "CASCADE", "CASCADE"},
+		{"message_threads", "parent_id", "messages(id)", "CASCADE", "CASCADE"},
 		{"users_tags", "user_id", "users(id)", "CASCADE", "CASCADE"},
 		{"users_tags", "tag_id", "tags(id)", "CASCADE", "CASCADE"},
-		{"unreads", "user_id", "users(id)", "CASCADE", "CASCADE"},
-		{"unreads", "message_id", "messages(id)", "CASCADE", "CASCADE"},
+		{"channel_read_pointers", "user_id", "users(id)", "CASCADE", "CASCADE"},
+		{"channel_read_pointers", "channel_id", "channels(id)", "CASCADE", "CASCADE"},
 		{"devices", "user_id", "users(id)", "CASCADE", "CASCADE"},
 		{"stars", "user_id", "users(id)", "CASCADE", "CASCADE"},
 		{"stars", "channel_id", "channels(id)", "CASCADE", "CASCADE"},
@@ -66,5 +84,20 @@ var (
 		{"messages_stamps", "user_id", "users(id)", "CASCADE", "CASCADE"},
 		{"stamps", "file_id", "files(id)", "NO ACTION", "CASCADE"},
 		{"webhook_bots", "bot_user_id", "users(id)", "CASCADE", "CASCADE"},
+		{"scheduled_messages", "user_id", "users(id)", "CASCADE", "CASCADE"},
+		{"scheduled_messages", "channel_id", "channels(id)", "CASCADE", "CASCADE"},
+		{"channel_retention_policies", "channel_id", "channels(id)", "CASCADE", "CASCADE"},
+		{"moderation_cases", "message_id", "messages(id)", "CASCADE", "CASCADE"},
+		{"mentions", "user_id", "users(id)", "CASCADE", "CASCADE"},
+		{"mentions", "message_id", "messages(id)", "CASCADE", "CASCADE"},
+		{"reminders", "user_id", "users(id)", "CASCADE", "CASCADE"},
+		{"reminders", "message_id", "messages(id)", "CASCADE", "CASCADE"},
+		{"moderation_logs", "case_id", "moderation_cases(id)", "CASCADE", "CASCADE"},
+		{"polls", "channel_id", "channels(id)", "CASCADE", "CASCADE"},
+		{"polls", "creator_id", "users(id)", "CASCADE", "CASCADE"},
+		{"poll_options", "poll_id", "polls(id)", "CASCADE", "CASCADE"},
+		{"poll_votes", "poll_id", "polls(id)", "CASCADE", "CASCADE"},
+		{"poll_votes", "option_id", "poll_options(id)", "CASCADE", "CASCADE"},
+		{"poll_votes", "user_id", "users(id)", "CASCADE", "CASCADE"},
 	}
 )
@@ -0,0 +1,105 @@
+package model
+
+import (
+	"github.com/gofrs/uuid"
+	"time"
+)
+
+// ModerationCaseState モデレーションケースの状態
+type ModerationCaseState string
+
+const (
+	// ModerationCaseStateOpen 未対応
+	ModerationCaseStateOpen ModerationCaseState = "open"
+	// ModerationCaseStateInReview 対応中
+	ModerationCaseStateInReview ModerationCaseState = "in_review"
+	// ModerationCaseStateResolved 対処済み
+	ModerationCaseStateResolved ModerationCaseState = "resolved"
+	// ModerationCaseStateDismissed 却下
+	ModerationCaseStateDismissed ModerationCaseState = "dismissed"
+)
+
+// Valid 有効な状態かどうかを返します
+func (s ModerationCaseState) Valid() bool {
+	switch s {
+	case ModerationCaseStateOpen, ModerationCaseStateInReview, ModerationCaseStateResolved, ModerationCaseStateDismissed:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsClosed 対応が完了した状態かどうかを返します
+func (s ModerationCaseState) IsClosed() bool {
+	return s == ModerationCaseStateResolved || s == ModerationCaseStateDismissed
+}
+
+// ModerationActionType モデレーターが実行できる対処の種類
+type ModerationActionType string
+
+const (
+	// ModerationActionDeleteMessage 通報されたメッセージを削除する
+	ModerationActionDeleteMessage ModerationActionType = "delete_message"
+	// ModerationActionSuspendUser メッセージの投稿者のアカウントを一時停止する
+	ModerationActionSuspendUser ModerationActionType = "suspend_user"
+	// ModerationActionWarn メッセージの投稿者に警告を送る
+	ModerationActionWarn ModerationActionType = "warn"
+)
+
+// Valid 有効な対処かどうかを返します
+func (a ModerationActionType) Valid() bool {
+	switch a {
+	case ModerationActionDeleteMessage, ModerationActionSuspendUser, ModerationActionWarn:
+		return true
+	default:
+		return false
+	}
+}
+
+// ModerationLogType モデレーションケースの監査ログの種類
+type ModerationLogType string
+
+const (
+	// ModerationLogReported メッセージが通報された
+	ModerationLogReported ModerationLogType = "reported"
+	// ModerationLogStateChanged ケースの状態が変更された
+	ModerationLogStateChanged ModerationLogType = "state_changed"
+	// ModerationLogAssigned ケースの担当者が変更された
+	ModerationLogAssigned ModerationLogType = "assigned"
+	// ModerationLogNote モデレーターがメモを残した
+	ModerationLogNote ModerationLogType = "note"
+	// ModerationLogAction モデレーターが対処を実行した
+	ModerationLogAction ModerationLogType = "action"
+)
+
+// ModerationCase モデレーションケース構造体
+//
+// 同じメッセージへの通報は1つのケースにまとめられます。
+type ModerationCase struct {
+	ID         uuid.UUID           `gorm:"type:char(36);not null;primary_key" json:"id"`
+	MessageID  uuid.UUID           `gorm:"type:char(36);not null;unique"      json:"messageId"`
+	State      ModerationCaseState `gorm:"type:varchar(20);not null;index"    json:"state"`
+	AssigneeID uuid.UUID           `gorm:"type:char(36);not null"             json:"assigneeId"`
+	CreatedAt  time.Time           `gorm:"precision:6"                        json:"createdAt"`
+	UpdatedAt  time.Time           `gorm:"precision:6;index"                  json:"updatedAt"`
+}
+
+// TableName ModerationCase構造体のテーブル名
+func (*ModerationCase) TableName() string {
+	return "moderation_cases"
+}
+
+// ModerationLog モデレーションケースの監査ログ構造体
+type ModerationLog struct {
+	ID        uuid.UUID         `gorm:"type:char(36);not null;primary_key" json:"id"`
+	CaseID    uuid.UUID         `gorm:"type:char(36);not null;index"       json:"caseId"`
+	ActorID   uuid.UUID         `gorm:"type:char(36);not null"             json:"actorId"`
+	Type      ModerationLogType `gorm:"type:varchar(20);not null"          json:"type"`
+	Detail    string            `gorm:"type:text;not null"                 json:"detail"`
+	CreatedAt time.Time         `gorm:"precision:6"                        json:"createdAt"`
+}
+
+// TableName ModerationLog構造体のテーブル名
+func (*ModerationLog) TableName() string {
+	return "moderation_logs"
+}
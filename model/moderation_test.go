@@ -0,0 +1,49 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestModerationCase_TableName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "moderation_cases", (&ModerationCase{}).TableName())
+}
+
+func TestModerationLog_TableName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "moderation_logs", (&ModerationLog{}).TableName())
+}
+
+func TestModerationCaseState_Valid(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	assert.True(ModerationCaseStateOpen.Valid())
+	assert.True(ModerationCaseStateInReview.Valid())
+	assert.True(ModerationCaseStateResolved.Valid())
+	assert.True(ModerationCaseStateDismissed.Valid())
+	assert.False(ModerationCaseState("").Valid())
+	assert.False(ModerationCaseState("closed").Valid())
+}
+
+func TestModerationCaseState_IsClosed(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	assert.False(ModerationCaseStateOpen.IsClosed())
+	assert.False(ModerationCaseStateInReview.IsClosed())
+	assert.True(ModerationCaseStateResolved.IsClosed())
+	assert.True(ModerationCaseStateDismissed.IsClosed())
+}
+
+func TestModerationActionType_Valid(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	assert.True(ModerationActionDeleteMessage.Valid())
+	assert.True(ModerationActionSuspendUser.Valid())
+	assert.True(ModerationActionWarn.Valid())
+	assert.False(ModerationActionType("").Valid())
+	assert.False(ModerationActionType("ban").Valid())
+}
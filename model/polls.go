@@ -0,0 +1,58 @@
+package model
+
+import (
+	"github.com/gofrs/uuid"
+	"time"
+)
+
+// Poll 投票構造体
+type Poll struct {
+	ID             uuid.UUID  `gorm:"type:char(36);not null;primary_key"`
+	ChannelID      uuid.UUID  `gorm:"type:char(36);not null;index"`
+	MessageID      uuid.UUID  `gorm:"type:char(36);not null"`
+	CreatorID      uuid.UUID  `gorm:"type:char(36);not null"`
+	Question       string     `gorm:"type:text;not null"`
+	MultipleChoice bool       `gorm:"type:boolean;not null;default:false"`
+	Anonymous      bool       `gorm:"type:boolean;not null;default:false"`
+	Deadline       *time.Time `gorm:"precision:6"`
+	CreatedAt      time.Time  `gorm:"precision:6"`
+	UpdatedAt      time.Time  `gorm:"precision:6"`
+
+	Options []*PollOption `gorm:"association_autoupdate:false;association_autocreate:false;preload:false;foreignkey:PollID"`
+}
+
+// TableName Poll構造体のテーブル名
+func (*Poll) TableName() string {
+	return "polls"
+}
+
+// IsClosed 指定した日時に投票が締め切られているかどうかを返します
+func (p *Poll) IsClosed(now time.Time) bool {
+	return p.Deadline != nil && !now.Before(*p.Deadline)
+}
+
+// PollOption 投票の選択肢構造体
+type PollOption struct {
+	ID       uuid.UUID `gorm:"type:char(36);not null;primary_key"`
+	PollID   uuid.UUID `gorm:"type:char(36);not null;index"`
+	Label    string    `gorm:"type:varchar(100);not null"`
+	Position int       `gorm:"type:int;not null"`
+}
+
+// TableName PollOption構造体のテーブル名
+func (*PollOption) TableName() string {
+	return "poll_options"
+}
+
+// PollVote 投票の票構造体
+type PollVote struct {
+	PollID    uuid.UUID `gorm:"type:char(36);not null;primary_key"`
+	UserID    uuid.UUID `gorm:"type:char(36);not null;primary_key"`
+	OptionID  uuid.UUID `gorm:"type:char(36);not null;primary_key"`
+	CreatedAt time.Time `gorm:"precision:6"`
+}
+
+// TableName PollVote構造体のテーブル名
+func (*PollVote) TableName() string {
+	return "poll_votes"
+}
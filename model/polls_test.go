@@ -0,0 +1,33 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestPoll_TableName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "polls", (&Poll{}).TableName())
+}
+
+func TestPoll_IsClosed(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	now := time.Now()
+	assert.False((&Poll{}).IsClosed(now))
+	deadline := now.Add(time.Minute)
+	assert.False((&Poll{Deadline: &deadline}).IsClosed(now))
+	assert.True((&Poll{Deadline: &deadline}).IsClosed(deadline))
+}
+
+func TestPollOption_TableName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "poll_options", (&PollOption{}).TableName())
+}
+
+func TestPollVote_TableName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "poll_votes", (&PollVote{}).TableName())
+}
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// ReminderBotUserName リマインダーを通知するシステムBotユーザーのユーザー名
+const ReminderBotUserName = "traQ#reminder"
+
+// ReminderState リマインダーの状態
+type ReminderState int
+
+const (
+	// ReminderPending リマインダーの状態: 通知待ち
+	ReminderPending ReminderState = 0
+	// ReminderSending リマインダーの状態: 通知処理中
+	ReminderSending ReminderState = 1
+	// ReminderSent リマインダーの状態: 通知済み
+	ReminderSent ReminderState = 2
+	// ReminderFailed リマインダーの状態: 通知失敗
+	ReminderFailed ReminderState = 3
+)
+
+// String 状態を表す文字列を返します
+func (s ReminderState) String() string {
+	switch s {
+	case ReminderPending:
+		return "pending"
+	case ReminderSending:
+		return "sending"
+	case ReminderSent:
+		return "sent"
+	case ReminderFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText encoding.TextMarshaler 実装
+func (s ReminderState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Reminder メッセージのリマインダー構造体
+type Reminder struct {
+	ID        uuid.UUID     `gorm:"type:char(36);not null;primary_key"          json:"id"`
+	UserID    uuid.UUID     `gorm:"type:char(36);not null;index"                json:"userId"`
+	MessageID uuid.UUID     `gorm:"type:char(36);not null"                      json:"messageId"`
+	RemindAt  time.Time     `gorm:"precision:6;index:remind_at_state"           json:"remindAt"`
+	State     ReminderState `gorm:"type:tinyint;not null;index:remind_at_state" json:"state"`
+	Error     string        `gorm:"type:text;not null"                          json:"error"`
+	CreatedAt time.Time     `gorm:"precision:6"                                 json:"createdAt"`
+	UpdatedAt time.Time     `gorm:"precision:6"                                 json:"updatedAt"`
+}
+
+// TableName Reminder構造体のテーブル名
+func (*Reminder) TableName() string {
+	return "reminders"
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReminder_TableName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "reminders", (&Reminder{}).TableName())
+}
+
+func TestReminderState_String(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "pending", ReminderPending.String())
+	assert.Equal(t, "sending", ReminderSending.String())
+	assert.Equal(t, "sent", ReminderSent.String())
+	assert.Equal(t, "failed", ReminderFailed.String())
+	assert.Equal(t, "unknown", ReminderState(100).String())
+}
+
+func TestReminderState_MarshalText(t *testing.T) {
+	t.Parallel()
+	b, err := ReminderSent.MarshalText()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "sent", string(b))
+	}
+}
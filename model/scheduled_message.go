@@ -0,0 +1,60 @@
+package model
+
+import (
+	"github.com/gofrs/uuid"
+	"time"
+)
+
+// ScheduledMessageState 予約投稿メッセージの状態
+type ScheduledMessageState int
+
+const (
+	// ScheduledMessagePending 予約投稿メッセージの状態: 投稿待ち
+	ScheduledMessagePending ScheduledMessageState = 0
+	// ScheduledMessageSending 予約投稿メッセージの状態: 投稿処理中
+	ScheduledMessageSending ScheduledMessageState = 1
+	// ScheduledMessageSent 予約投稿メッセージの状態: 投稿済み
+	ScheduledMessageSent ScheduledMessageState = 2
+	// ScheduledMessageFailed 予約投稿メッセージの状態: 投稿失敗
+	ScheduledMessageFailed ScheduledMessageState = 3
+)
+
+// String 状態を表す文字列を返します
+func (s ScheduledMessageState) String() string {
+	switch s {
+	case ScheduledMessagePending:
+		return "pending"
+	case ScheduledMessageSending:
+		return "sending"
+	case ScheduledMessageSent:
+		return "sent"
+	case ScheduledMessageFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText encoding.TextMarshaler 実装
+func (s ScheduledMessageState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// ScheduledMessage 予約投稿メッセージ構造体
+type ScheduledMessage struct {
+	ID          uuid.UUID             `gorm:"type:char(36);not null;primary_key"          json:"id"`
+	UserID      uuid.UUID             `gorm:"type:char(36);not null;index"                json:"userId"`
+	ChannelID   uuid.UUID             `gorm:"type:char(36);not null"                      json:"channelId"`
+	Text        string                `gorm:"type:text;not null"                          json:"text"`
+	ScheduledAt time.Time             `gorm:"precision:6;index:scheduled_state"           json:"scheduledAt"`
+	State       ScheduledMessageState `gorm:"type:tinyint;not null;index:scheduled_state" json:"state"`
+	MessageID   uuid.UUID             `gorm:"type:char(36);not null"                      json:"messageId"`
+	Error       string                `gorm:"type:text;not null"                          json:"error"`
+	CreatedAt   time.Time             `gorm:"precision:6"                                 json:"createdAt"`
+	UpdatedAt   time.Time             `gorm:"precision:6"                                 json:"updatedAt"`
+}
+
+// TableName ScheduledMessage構造体のテーブル名
+func (*ScheduledMessage) TableName() string {
+	return "scheduled_messages"
+}
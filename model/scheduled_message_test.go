@@ -0,0 +1,28 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestScheduledMessage_TableName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "scheduled_messages", (&ScheduledMessage{}).TableName())
+}
+
+func TestScheduledMessageState_String(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "pending", ScheduledMessagePending.String())
+	assert.Equal(t, "sending", ScheduledMessageSending.String())
+	assert.Equal(t, "sent", ScheduledMessageSent.String())
+	assert.Equal(t, "failed", ScheduledMessageFailed.String())
+	assert.Equal(t, "unknown", ScheduledMessageState(100).String())
+}
+
+func TestScheduledMessageState_MarshalText(t *testing.T) {
+	t.Parallel()
+	b, err := ScheduledMessageSent.MarshalText()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "sent", string(b))
+	}
+}
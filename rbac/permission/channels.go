@@ -13,4 +13,12 @@ var (
 	DeleteChannel = gorbac.NewStdPermission("delete_channel")
 	// ChangeParentChannel 親チャンネル変更権限
 	ChangeParentChannel = gorbac.NewStdPermission("change_parent_channel")
+	// ExportChannel チャンネルエクスポート権限
+	ExportChannel = gorbac.NewStdPermission("export_channel")
+	// ArchiveChannel チャンネルアーカイブ・アーカイブ解除権限
+	ArchiveChannel = gorbac.NewStdPermission("archive_channel")
+	// JoinChannel チャンネル参加権限
+	JoinChannel = gorbac.NewStdPermission("join_channel")
+	// LeaveChannel チャンネル退出権限
+	LeaveChannel = gorbac.NewStdPermission("leave_channel")
 )
@@ -0,0 +1,8 @@
+package permission
+
+import "github.com/mikespook/gorbac"
+
+var (
+	// ManageContentFilter コンテンツフィルタ管理権限
+	ManageContentFilter = gorbac.NewStdPermission("manage_content_filter")
+)
@@ -9,21 +9,43 @@ var list = map[string]gorbac.Permission{
 	EditChannel.ID():         EditChannel,
 	DeleteChannel.ID():       DeleteChannel,
 	ChangeParentChannel.ID(): ChangeParentChannel,
+	ExportChannel.ID():       ExportChannel,
+	ArchiveChannel.ID():      ArchiveChannel,
+	JoinChannel.ID():         JoinChannel,
+	LeaveChannel.ID():        LeaveChannel,
 
 	GetTopic.ID():  GetTopic,
 	EditTopic.ID(): EditTopic,
 
-	GetMessage.ID():        GetMessage,
-	PostMessage.ID():       PostMessage,
-	EditMessage.ID():       EditMessage,
-	DeleteMessage.ID():     DeleteMessage,
-	ReportMessage.ID():     ReportMessage,
-	GetMessageReports.ID(): GetMessageReports,
+	GetMessage.ID():             GetMessage,
+	PostMessage.ID():            PostMessage,
+	PostEphemeralMessage.ID():   PostEphemeralMessage,
+	EditMessage.ID():            EditMessage,
+	DeleteMessage.ID():          DeleteMessage,
+	ReportMessage.ID():          ReportMessage,
+	GetMessageReports.ID():      GetMessageReports,
+	RestoreMessage.ID():         RestoreMessage,
+	MoveMessage.ID():            MoveMessage,
+	GetScheduledMessage.ID():    GetScheduledMessage,
+	CreateScheduledMessage.ID(): CreateScheduledMessage,
+	EditScheduledMessage.ID():   EditScheduledMessage,
+	DeleteScheduledMessage.ID(): DeleteScheduledMessage,
+	GetReminder.ID():            GetReminder,
+	CreateReminder.ID():         CreateReminder,
+	EditReminder.ID():           EditReminder,
+	DeleteReminder.ID():         DeleteReminder,
 
 	GetPin.ID():    GetPin,
 	CreatePin.ID(): CreatePin,
 	DeletePin.ID(): DeletePin,
 
+	GetPoll.ID():    GetPoll,
+	CreatePoll.ID(): CreatePoll,
+	VotePoll.ID():   VotePoll,
+
+	ManageContentFilter.ID(): ManageContentFilter,
+	ManageModeration.ID():    ManageModeration,
+
 	GetNotificationStatus.ID():     GetNotificationStatus,
 	ChangeNotificationStatus.ID():  ChangeNotificationStatus,
 	ConnectNotificationStream.ID(): ConnectNotificationStream,
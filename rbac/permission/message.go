@@ -7,6 +7,8 @@ var (
 	GetMessage = gorbac.NewStdPermission("get_message")
 	// PostMessage メッセージ投稿権限
 	PostMessage = gorbac.NewStdPermission("post_message")
+	// PostEphemeralMessage 一時メッセージ投稿権限
+	PostEphemeralMessage = gorbac.NewStdPermission("post_ephemeral_message")
 	// EditMessage メッセージ編集権限
 	EditMessage = gorbac.NewStdPermission("edit_message")
 	// DeleteMessage メッセージ削除権限
@@ -15,4 +17,24 @@ var (
 	ReportMessage = gorbac.NewStdPermission("report_message")
 	// GetMessageReports メッセージ通報取得権限
 	GetMessageReports = gorbac.NewStdPermission("get_message_reports")
+	// RestoreMessage 削除されたメッセージの取得・復元権限
+	RestoreMessage = gorbac.NewStdPermission("restore_message")
+	// MoveMessage メッセージのチャンネル間移動権限
+	MoveMessage = gorbac.NewStdPermission("move_message")
+	// GetScheduledMessage 予約投稿メッセージ取得権限
+	GetScheduledMessage = gorbac.NewStdPermission("get_scheduled_message")
+	// CreateScheduledMessage 予約投稿メッセージ作成権限
+	CreateScheduledMessage = gorbac.NewStdPermission("create_scheduled_message")
+	// EditScheduledMessage 予約投稿メッセージ編集権限
+	EditScheduledMessage = gorbac.NewStdPermission("edit_scheduled_message")
+	// DeleteScheduledMessage 予約投稿メッセージ削除権限
+	DeleteScheduledMessage = gorbac.NewStdPermission("delete_scheduled_message")
+	// GetReminder リマインダー取得権限
+	GetReminder = gorbac.NewStdPermission("get_reminder")
+	// CreateReminder リマインダー作成権限
+	CreateReminder = gorbac.NewStdPermission("create_reminder")
+	// EditReminder リマインダー編集権限
+	EditReminder = gorbac.NewStdPermission("edit_reminder")
+	// DeleteReminder リマインダー削除権限
+	DeleteReminder = gorbac.NewStdPermission("delete_reminder")
 )
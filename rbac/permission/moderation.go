@@ -0,0 +1,8 @@
+package permission
+
+import "github.com/mikespook/gorbac"
+
+var (
+	// ManageModeration 通報のモデレーション権限
+	ManageModeration = gorbac.NewStdPermission("manage_moderation")
+)
@@ -0,0 +1,12 @@
+package permission
+
+import "github.com/mikespook/gorbac"
+
+var (
+	// GetPoll 投票取得権限
+	GetPoll = gorbac.NewStdPermission("get_poll")
+	// CreatePoll 投票作成権限
+	CreatePoll = gorbac.NewStdPermission("create_poll")
+	// VotePoll 投票権限
+	VotePoll = gorbac.NewStdPermission("vote_poll")
+)
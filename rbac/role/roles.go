@@ -38,9 +38,13 @@ func SetRole(rbac *rbac.RBAC) {
 			permission.GetTopic,
 
 			permission.GetMessage,
+			permission.GetScheduledMessage,
+			permission.GetReminder,
 
 			permission.GetPin,
 
+			permission.GetPoll,
+
 			permission.GetNotificationStatus,
 			permission.ConnectNotificationStream,
 
@@ -82,10 +86,19 @@ func SetRole(rbac *rbac.RBAC) {
 			permission.EditMessage,
 			permission.DeleteMessage,
 			permission.ReportMessage,
+			permission.CreateScheduledMessage,
+			permission.EditScheduledMessage,
+			permission.DeleteScheduledMessage,
+			permission.CreateReminder,
+			permission.EditReminder,
+			permission.DeleteReminder,
 
 			permission.CreatePin,
 			permission.DeletePin,
 
+			permission.CreatePoll,
+			permission.VotePoll,
+
 			permission.ChangeNotificationStatus,
 			permission.RegisterDevice,
 
@@ -106,6 +119,9 @@ func SetRole(rbac *rbac.RBAC) {
 			permission.MuteChannel,
 			permission.UnmuteChannel,
 
+			permission.JoinChannel,
+			permission.LeaveChannel,
+
 			permission.AddTag,
 			permission.RemoveTag,
 			permission.ChangeTagLockState,
@@ -158,8 +174,15 @@ func SetRole(rbac *rbac.RBAC) {
 			permission.EditChannel,
 			permission.DeleteChannel,
 			permission.ChangeParentChannel,
+			permission.ExportChannel,
+			permission.ArchiveChannel,
 
+			permission.PostEphemeralMessage,
 			permission.GetMessageReports,
+			permission.RestoreMessage,
+			permission.MoveMessage,
+			permission.ManageContentFilter,
+			permission.ManageModeration,
 
 			permission.RegisterUser,
 			permission.EditOtherUsers,
@@ -184,6 +207,7 @@ func SetRole(rbac *rbac.RBAC) {
 
 			permission.GetMessage,
 			permission.PostMessage,
+			permission.PostEphemeralMessage,
 			permission.EditMessage,
 			permission.DeleteMessage,
 
@@ -191,6 +215,9 @@ func SetRole(rbac *rbac.RBAC) {
 			permission.CreatePin,
 			permission.DeletePin,
 
+			permission.GetPoll,
+			permission.CreatePoll,
+
 			permission.GetNotificationStatus,
 			permission.ChangeNotificationStatus,
 
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	reminderDefaultInterval = 10 * time.Second
+	reminderFetchLimit      = 100
+	// reminderSendingTimeout 通知処理中のままこの時間が経過したリマインダーは通知失敗とみなす
+	reminderSendingTimeout = 5 * time.Minute
+)
+
+// ReminderSender リマインダー送信者構造体
+//
+// リマインダーはDBで管理されるため、プロセスの再起動後も通知されます。
+// 通知はシステムBotユーザーからのDMとして送信され、SSEとFCMにはevent.ReminderFiredを通して通知されます。
+type ReminderSender struct {
+	repo     repository.Repository
+	logger   *zap.Logger
+	interval time.Duration
+	origin   string
+	done     chan struct{}
+}
+
+// NewReminderSender ReminderSenderを生成し、起動します
+func NewReminderSender(repo repository.Repository, logger *zap.Logger, interval time.Duration, origin string) *ReminderSender {
+	if interval <= 0 {
+		interval = reminderDefaultInterval
+	}
+	s := &ReminderSender{
+		repo:     repo,
+		logger:   logger,
+		interval: interval,
+		origin:   origin,
+		done:     make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// Stop ReminderSenderを停止します
+func (s *ReminderSender) Stop() {
+	close(s.done)
+}
+
+func (s *ReminderSender) run() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.process()
+	for {
+		select {
+		case <-ticker.C:
+			s.process()
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *ReminderSender) process() {
+	// 通知処理中にプロセスが落ちたものを通知失敗にする
+	if n, err := s.repo.FailStaleReminders(time.Now().Add(-reminderSendingTimeout)); err != nil {
+		s.logger.Error("failed to FailStaleReminders", zap.Error(err))
+	} else if n > 0 {
+		s.logger.Warn("reminders interrupted while sending were marked as failed", zap.Int("count", n))
+	}
+
+	reminders, err := s.repo.GetDueReminders(time.Now(), reminderFetchLimit)
+	if err != nil {
+		s.logger.Error("failed to GetDueReminders", zap.Error(err))
+		return
+	}
+	for _, r := range reminders {
+		s.send(r)
+	}
+}
+
+func (s *ReminderSender) send(r *model.Reminder) {
+	logger := s.logger.With(zap.Stringer("reminderId", r.ID))
+
+	// 他のプロセスが既に処理している場合は何もしない
+	ok, err := s.repo.ClaimReminder(r.ID)
+	if err != nil {
+		logger.Error("failed to ClaimReminder", zap.Error(err))
+		return
+	}
+	if !ok {
+		return
+	}
+
+	// 通知時点でメッセージにアクセスできるかを再確認
+	m, err := s.repo.GetMessageByID(r.MessageID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			s.markFailed(logger, r, "the message is not found")
+			return
+		}
+		logger.Error("failed to GetMessageByID", zap.Error(err))
+		s.markFailed(logger, r, "internal error")
+		return
+	}
+	accessible, err := s.repo.IsChannelAccessibleToUser(r.UserID, m.ChannelID)
+	if err != nil {
+		logger.Error("failed to IsChannelAccessibleToUser", zap.Error(err))
+		s.markFailed(logger, r, "internal error")
+		return
+	}
+	if !accessible {
+		s.markFailed(logger, r, "the message is not accessible")
+		return
+	}
+
+	bot, err := s.repo.GetReminderBotUser()
+	if err != nil {
+		logger.Error("failed to GetReminderBotUser", zap.Error(err))
+		s.markFailed(logger, r, "internal error")
+		return
+	}
+	dm, err := s.repo.GetDirectMessageChannel(bot.ID, r.UserID)
+	if err != nil {
+		logger.Error("failed to GetDirectMessageChannel", zap.Error(err))
+		s.markFailed(logger, r, "internal error")
+		return
+	}
+	if _, err := s.repo.CreateMessage(bot.ID, dm.ID, fmt.Sprintf("リマインダー: %s/messages/%s", s.origin, m.ID)); err != nil {
+		logger.Error("failed to CreateMessage", zap.Error(err))
+		s.markFailed(logger, r, "failed to post the message")
+		return
+	}
+
+	if err := s.repo.MarkReminderSent(r.ID); err != nil {
+		logger.Error("failed to MarkReminderSent", zap.Error(err))
+	}
+}
+
+func (s *ReminderSender) markFailed(logger *zap.Logger, r *model.Reminder, reason string) {
+	if err := s.repo.MarkReminderFailed(r.ID, reason); err != nil {
+		logger.Error("failed to MarkReminderFailed", zap.Error(err))
+	}
+}
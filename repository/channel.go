@@ -38,7 +38,7 @@ type ChannelRepository interface {
 	// 引数に問題がある場合、ArgumentErrorを返します。
 	// 既にNameが使われている場合、ErrAlreadyExistsを返します。
 	// 引数にuuid.Nilを指定した場合、ErrNilIDを返します。
-	// 作成不可能な親チャンネルを指定した場合、ErrForbiddenを返します。
+	// 作成不可能な親チャンネル(DM、アーカイブされたチャンネル)を指定した場合、ErrForbiddenを返します。
 	// 存在しない親チャンネルを指定した場合、ErrNotFoundを返します。
 	// DBによるエラーを返すことがあります。
 	CreateChildChannel(name string, parentID, creatorID uuid.UUID) (*model.Channel, error)
@@ -49,6 +49,24 @@ type ChannelRepository interface {
 	// 存在しないチャンネルを指定した場合、ErrNotFoundを返します。
 	// DBによるエラーを返すことがあります。
 	UpdateChannelAttributes(channelID uuid.UUID, visibility, forced *bool) error
+	// UpdateChannelSlowModeInterval 指定したチャンネルのスローモードの投稿間隔(秒)を変更します
+	//
+	// intervalに0を指定するとスローモードを無効にします。
+	// 成功した場合、nilを返します。
+	// intervalが負の場合、ArgumentErrorを返します。
+	// 引数にuuid.Nilを指定した場合、ErrNilIDを返します。
+	// 存在しないチャンネルを指定した場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	UpdateChannelSlowModeInterval(channelID uuid.UUID, interval int) error
+	// UpdateChannelArchived 指定したチャンネルとその子孫チャンネルのアーカイブ状態を変更します
+	//
+	// アーカイブされたチャンネルは読み取り専用になります。
+	// 成功した場合、nilを返します。
+	// 引数にuuid.Nilを指定した場合、ErrNilIDを返します。
+	// DMチャンネル、またはアーカイブされた親を持つチャンネルのアーカイブを解除しようとした場合、ErrForbiddenを返します。
+	// 存在しないチャンネルを指定した場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	UpdateChannelArchived(channelID uuid.UUID, archived bool, updaterID uuid.UUID) error
 	// UpdateChannelTopic 指定したチャンネルのトピックを更新します
 	//
 	// 成功した場合、nilを返します。
@@ -155,4 +173,32 @@ type ChannelRepository interface {
 	// 存在しないユーザーを指定した場合は空配列とnilを返します。
 	// DBによるエラーを返すことがあります。
 	GetSubscribedChannelIDs(userID uuid.UUID) ([]uuid.UUID, error)
+	// JoinChannel 指定したユーザーを指定したパブリックチャンネルに参加させます
+	//
+	// 参加したチャンネルは購読状態になります。
+	// 成功した、或いは既に参加していた場合、nilを返します。
+	// 引数にuuid.Nilを指定した場合、ErrNilIDを返します。
+	// 存在しないチャンネルを指定した場合、ErrNotFoundを返します。
+	// パブリックチャンネル以外、またはアーカイブされたチャンネルを指定した場合、ErrForbiddenを返します。
+	// DBによるエラーを返すことがあります。
+	JoinChannel(userID, channelID uuid.UUID) error
+	// LeaveChannel 指定したユーザーを指定したパブリックチャンネルから退出させます
+	//
+	// 退出したチャンネルの購読は解除されます。
+	// 成功した、或いは既に参加していない場合、nilを返します。
+	// 引数にuuid.Nilを指定した場合、ErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	LeaveChannel(userID, channelID uuid.UUID) error
+	// GetJoinedUserIDs 指定したパブリックチャンネルに参加しているユーザーのUUIDを全て取得する
+	//
+	// 成功した場合、UUIDの配列とnilを返します。
+	// 存在しないチャンネルを指定した場合は空配列とnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetJoinedUserIDs(channelID uuid.UUID) ([]uuid.UUID, error)
+	// GetJoinedChannelIDs 指定したユーザーが参加しているパブリックチャンネルのUUIDを全て取得する
+	//
+	// 成功した場合、UUIDの配列とnilを返します。
+	// 存在しないユーザーを指定した場合は空配列とnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetJoinedChannelIDs(userID uuid.UUID) ([]uuid.UUID, error)
 }
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/traPtitech/traQ/model"
+)
+
+// ChannelExportRepository チャンネルエクスポートリポジトリ
+type ChannelExportRepository interface {
+	// CreateChannelExport チャンネルエクスポートを処理待ちとして作成します
+	//
+	// 成功した場合、チャンネルエクスポートとnilを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	CreateChannelExport(channelID, userID uuid.UUID, withDescendants bool) (*model.ChannelExport, error)
+	// GetChannelExport 指定したチャンネルエクスポートを取得します
+	//
+	// 成功した場合、チャンネルエクスポートとnilを返します。
+	// 存在しないチャンネルエクスポートを指定した場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	GetChannelExport(id uuid.UUID) (*model.ChannelExport, error)
+	// GetPendingChannelExports 処理待ちのチャンネルエクスポートを作成日時の昇順で取得します
+	//
+	// 成功した場合、チャンネルエクスポートの配列とnilを返します。負のlimitは無視されます。
+	// DBによるエラーを返すことがあります。
+	GetPendingChannelExports(limit int) ([]*model.ChannelExport, error)
+	// ClaimChannelExport 指定した処理待ちのチャンネルエクスポートを処理中にします
+	//
+	// 状態の遷移はアトミックに行われ、複数のプロセスから同時に呼び出された場合でも
+	// trueを返すのは一つの呼び出しのみです。
+	// 成功した場合、trueとnilを返します。
+	// 既に他で処理が開始されていた場合や存在しない場合、falseとnilを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	ClaimChannelExport(id uuid.UUID) (bool, error)
+	// MarkChannelExportCompleted 指定した処理中のチャンネルエクスポートを完了にします
+	//
+	// fileIDにはエクスポートしたアーカイブのファイルIDを指定します。
+	// 成功した場合、nilを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	MarkChannelExportCompleted(id, fileID uuid.UUID) error
+	// MarkChannelExportFailed 指定した処理中のチャンネルエクスポートを失敗にします
+	//
+	// 成功した場合、nilを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	MarkChannelExportFailed(id uuid.UUID, reason string) error
+	// FailStaleChannelExports 指定した日時以前から処理中のままのチャンネルエクスポートを失敗にします
+	//
+	// 処理中にプロセスが終了した場合に使用します。
+	// 成功した場合、失敗にした件数とnilを返します。
+	// DBによるエラーを返すことがあります。
+	FailStaleChannelExports(before time.Time) (int, error)
+}
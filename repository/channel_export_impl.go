@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/traPtitech/traQ/model"
+)
+
+// CreateChannelExport implements ChannelExportRepository interface.
+func (repo *GormRepository) CreateChannelExport(channelID, userID uuid.UUID, withDescendants bool) (*model.ChannelExport, error) {
+	if channelID == uuid.Nil || userID == uuid.Nil {
+		return nil, ErrNilID
+	}
+	e := &model.ChannelExport{
+		ID:              uuid.Must(uuid.NewV4()),
+		ChannelID:       channelID,
+		UserID:          userID,
+		WithDescendants: withDescendants,
+		State:           model.ChannelExportPending,
+	}
+	if err := repo.db.Create(e).Error; err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// GetChannelExport implements ChannelExportRepository interface.
+func (repo *GormRepository) GetChannelExport(id uuid.UUID) (*model.ChannelExport, error) {
+	if id == uuid.Nil {
+		return nil, ErrNotFound
+	}
+	e := &model.ChannelExport{}
+	if err := repo.db.Where(&model.ChannelExport{ID: id}).Take(e).Error; err != nil {
+		return nil, convertError(err)
+	}
+	return e, nil
+}
+
+// GetPendingChannelExports implements ChannelExportRepository interface.
+func (repo *GormRepository) GetPendingChannelExports(limit int) (arr []*model.ChannelExport, err error) {
+	arr = make([]*model.ChannelExport, 0)
+	err = repo.db.
+		Where("state = ?", model.ChannelExportPending).
+		Order("created_at").
+		Scopes(limitAndOffset(limit, 0)).
+		Find(&arr).
+		Error
+	return arr, err
+}
+
+// ClaimChannelExport implements ChannelExportRepository interface.
+func (repo *GormRepository) ClaimChannelExport(id uuid.UUID) (bool, error) {
+	if id == uuid.Nil {
+		return false, ErrNilID
+	}
+	result := repo.db.
+		Model(&model.ChannelExport{}).
+		Where("id = ? AND state = ?", id, model.ChannelExportPending).
+		Updates(map[string]interface{}{"state": model.ChannelExportRunning})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// MarkChannelExportCompleted implements ChannelExportRepository interface.
+func (repo *GormRepository) MarkChannelExportCompleted(id, fileID uuid.UUID) error {
+	if id == uuid.Nil || fileID == uuid.Nil {
+		return ErrNilID
+	}
+	return repo.db.
+		Model(&model.ChannelExport{}).
+		Where("id = ? AND state = ?", id, model.ChannelExportRunning).
+		Updates(map[string]interface{}{"state": model.ChannelExportCompleted, "file_id": fileID}).
+		Error
+}
+
+// MarkChannelExportFailed implements ChannelExportRepository interface.
+func (repo *GormRepository) MarkChannelExportFailed(id uuid.UUID, reason string) error {
+	if id == uuid.Nil {
+		return ErrNilID
+	}
+	return repo.db.
+		Model(&model.ChannelExport{}).
+		Where("id = ? AND state = ?", id, model.ChannelExportRunning).
+		Updates(map[string]interface{}{"state": model.ChannelExportFailed, "error": reason}).
+		Error
+}
+
+// FailStaleChannelExports implements ChannelExportRepository interface.
+func (repo *GormRepository) FailStaleChannelExports(before time.Time) (int, error) {
+	result := repo.db.
+		Model(&model.ChannelExport{}).
+		Where("state = ? AND updated_at < ?", model.ChannelExportRunning, before).
+		Updates(map[string]interface{}{"state": model.ChannelExportFailed, "error": "interrupted while exporting"})
+	return int(result.RowsAffected), result.Error
+}
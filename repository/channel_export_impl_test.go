@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/traPtitech/traQ/model"
+)
+
+func TestRepositoryImpl_CreateChannelExport(t *testing.T) {
+	t.Parallel()
+	repo, assert, _, user, channel := setupWithUserAndChannel(t, common)
+
+	_, err := repo.CreateChannelExport(uuid.Nil, user.ID, false)
+	assert.Equal(ErrNilID, err)
+	_, err = repo.CreateChannelExport(channel.ID, uuid.Nil, false)
+	assert.Equal(ErrNilID, err)
+
+	e, err := repo.CreateChannelExport(channel.ID, user.ID, true)
+	if assert.NoError(err) {
+		assert.NotEqual(uuid.Nil, e.ID)
+		assert.Equal(channel.ID, e.ChannelID)
+		assert.Equal(user.ID, e.UserID)
+		assert.True(e.WithDescendants)
+		assert.Equal(model.ChannelExportPending, e.State)
+		assert.Equal(uuid.Nil, e.FileID)
+	}
+}
+
+func TestRepositoryImpl_GetChannelExport(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, common)
+
+	e, err := repo.CreateChannelExport(channel.ID, user.ID, false)
+	require.NoError(err)
+
+	_, err = repo.GetChannelExport(uuid.Nil)
+	assert.Equal(ErrNotFound, err)
+	_, err = repo.GetChannelExport(uuid.Must(uuid.NewV4()))
+	assert.Equal(ErrNotFound, err)
+
+	r, err := repo.GetChannelExport(e.ID)
+	if assert.NoError(err) {
+		assert.Equal(e.ID, r.ID)
+		assert.Equal(model.ChannelExportPending, r.State)
+	}
+}
+
+func TestRepositoryImpl_ClaimChannelExport(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, common)
+
+	e, err := repo.CreateChannelExport(channel.ID, user.ID, false)
+	require.NoError(err)
+
+	pending, err := repo.GetPendingChannelExports(-1)
+	require.NoError(err)
+	ids := make([]uuid.UUID, len(pending))
+	for i, p := range pending {
+		ids[i] = p.ID
+	}
+	assert.Contains(ids, e.ID)
+
+	_, err = repo.ClaimChannelExport(uuid.Nil)
+	assert.Equal(ErrNilID, err)
+
+	ok, err := repo.ClaimChannelExport(e.ID)
+	if assert.NoError(err) {
+		assert.True(ok)
+	}
+	// 二重には処理されない
+	ok, err = repo.ClaimChannelExport(e.ID)
+	if assert.NoError(err) {
+		assert.False(ok)
+	}
+
+	file := mustMakeFile(t, repo, user.ID)
+	assert.Equal(ErrNilID, repo.MarkChannelExportCompleted(e.ID, uuid.Nil))
+	if assert.NoError(repo.MarkChannelExportCompleted(e.ID, file.ID)) {
+		r, err := repo.GetChannelExport(e.ID)
+		require.NoError(err)
+		assert.Equal(model.ChannelExportCompleted, r.State)
+		assert.Equal(file.ID, r.FileID)
+	}
+}
+
+func TestRepositoryImpl_MarkChannelExportFailed(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, common)
+
+	e, err := repo.CreateChannelExport(channel.ID, user.ID, false)
+	require.NoError(err)
+	ok, err := repo.ClaimChannelExport(e.ID)
+	require.NoError(err)
+	require.True(ok)
+
+	assert.Equal(ErrNilID, repo.MarkChannelExportFailed(uuid.Nil, "reason"))
+	if assert.NoError(repo.MarkChannelExportFailed(e.ID, "reason")) {
+		r, err := repo.GetChannelExport(e.ID)
+		require.NoError(err)
+		assert.Equal(model.ChannelExportFailed, r.State)
+		assert.Equal("reason", r.Error)
+	}
+}
+
+func TestRepositoryImpl_FailStaleChannelExports(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, common)
+
+	e, err := repo.CreateChannelExport(channel.ID, user.ID, false)
+	require.NoError(err)
+	ok, err := repo.ClaimChannelExport(e.ID)
+	require.NoError(err)
+	require.True(ok)
+
+	n, err := repo.FailStaleChannelExports(time.Now().Add(time.Minute))
+	if assert.NoError(err) {
+		assert.True(n >= 1)
+	}
+	r, err := repo.GetChannelExport(e.ID)
+	require.NoError(err)
+	assert.Equal(model.ChannelExportFailed, r.State)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/traPtitech/traQ/utils"
 	"github.com/traPtitech/traQ/utils/validator"
 	"sync"
+	"time"
 )
 
 var (
@@ -180,6 +181,11 @@ func (repo *GormRepository) CreateChildChannel(name string, parentID, creatorID
 		return nil, ErrForbidden
 	}
 
+	// アーカイブされたチャンネルの子チャンネルは作れない
+	if pCh.IsArchived {
+		return nil, ErrForbidden
+	}
+
 	// チャンネル名検証
 	if !validator.ChannelRegex.MatchString(name) {
 		return nil, ArgError("name", "invalid name")
@@ -298,6 +304,136 @@ func (repo *GormRepository) UpdateChannelAttributes(channelID uuid.UUID, visibil
 	return nil
 }
 
+// UpdateChannelSlowModeInterval implements ChannelRepository interface.
+func (repo *GormRepository) UpdateChannelSlowModeInterval(channelID uuid.UUID, interval int) error {
+	if channelID == uuid.Nil {
+		return ErrNilID
+	}
+	if interval < 0 {
+		return ArgError("interval", "Interval must be non-negative")
+	}
+
+	var ch model.Channel
+	err := repo.transact(func(tx *gorm.DB) error {
+		if err := tx.First(&ch, &model.Channel{ID: channelID}).Error; err != nil {
+			return convertError(err)
+		}
+		return tx.Model(&ch).Update("slow_mode_interval", interval).Error
+	})
+	if err != nil {
+		return err
+	}
+	repo.hub.Publish(hub.Message{
+		Name: event.ChannelUpdated,
+		Fields: hub.Fields{
+			"channel_id": ch.ID,
+			"private":    !ch.IsPublic,
+		},
+	})
+	return nil
+}
+
+// UpdateChannelArchived implements ChannelRepository interface.
+func (repo *GormRepository) UpdateChannelArchived(channelID uuid.UUID, archived bool, updaterID uuid.UUID) error {
+	if channelID == uuid.Nil {
+		return ErrNilID
+	}
+
+	updated := make([]*model.Channel, 0)
+	err := repo.transact(func(tx *gorm.DB) error {
+		var ch model.Channel
+		if err := tx.First(&ch, &model.Channel{ID: channelID}).Error; err != nil {
+			return convertError(err)
+		}
+
+		// ダイレクトメッセージチャンネルはアーカイブ出来ない
+		if ch.IsDMChannel() {
+			return ErrForbidden
+		}
+
+		// 親がアーカイブされている場合はアーカイブを解除出来ない
+		if !archived && ch.ParentID != uuid.Nil {
+			var parent model.Channel
+			if err := tx.First(&parent, &model.Channel{ID: ch.ParentID}).Error; err != nil {
+				return convertError(err)
+			}
+			if parent.IsArchived {
+				return ErrForbidden
+			}
+		}
+
+		desc, err := repo.getDescendantChannelIDs(tx, channelID)
+		if err != nil {
+			return err
+		}
+		desc = append(desc, channelID)
+
+		for _, v := range desc {
+			ch := model.Channel{}
+			if err := tx.First(&ch, &model.Channel{ID: v}).Error; err != nil {
+				if gorm.IsRecordNotFoundError(err) {
+					continue
+				}
+				return err
+			}
+			if ch.IsArchived == archived {
+				continue
+			}
+			if err := tx.Model(&ch).Updates(map[string]interface{}{
+				"is_archived": archived,
+				"updater_id":  updaterID,
+			}).Error; err != nil {
+				return err
+			}
+			updated = append(updated, &ch)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, v := range updated {
+		repo.hub.Publish(hub.Message{
+			Name: event.ChannelUpdated,
+			Fields: hub.Fields{
+				"channel_id": v.ID,
+				"private":    !v.IsPublic,
+			},
+		})
+	}
+	return nil
+}
+
+// checkChannelNotArchived チャンネルがアーカイブされている場合、ErrChannelArchivedを返します
+func checkChannelNotArchived(tx *gorm.DB, channelID uuid.UUID) error {
+	archived, err := dbExists(tx, &model.Channel{ID: channelID, IsArchived: true})
+	if err != nil {
+		return err
+	}
+	if archived {
+		return ErrChannelArchived
+	}
+	return nil
+}
+
+// checkMessageChannelNotArchived メッセージのチャンネルがアーカイブされている場合、ErrChannelArchivedを返します
+func checkMessageChannelNotArchived(tx *gorm.DB, messageID uuid.UUID) error {
+	c := 0
+	err := tx.Table("messages").
+		Joins("INNER JOIN channels ON channels.id = messages.channel_id").
+		Where("messages.id = ? AND channels.is_archived = true", messageID).
+		Limit(1).
+		Count(&c).
+		Error
+	if err != nil {
+		return err
+	}
+	if c > 0 {
+		return ErrChannelArchived
+	}
+	return nil
+}
+
 // UpdateChannelTopic implements ChannelRepository interface.
 func (repo *GormRepository) UpdateChannelTopic(channelID uuid.UUID, topic string, updaterID uuid.UUID) error {
 	if channelID == uuid.Nil {
@@ -421,6 +557,11 @@ func (repo *GormRepository) ChangeChannelParent(channelID uuid.UUID, parent uuid
 			return ErrForbidden
 		}
 
+		// アーカイブされていないチャンネルをアーカイブされたチャンネルの子チャンネルには出来ない
+		if pCh.IsArchived && !ch.IsArchived {
+			return ErrForbidden
+		}
+
 		// 親と公開状況が一致しているか
 		if ch.IsPublic != pCh.IsPublic {
 			return ErrForbidden
@@ -747,8 +888,20 @@ func (repo *GormRepository) SubscribeChannel(userID, channelID uuid.UUID) error
 	if userID == uuid.Nil || channelID == uuid.Nil {
 		return ErrNilID
 	}
-	var s model.UserSubscribeChannel
-	return repo.db.FirstOrCreate(&s, &model.UserSubscribeChannel{UserID: userID, ChannelID: channelID}).Error
+	return repo.transact(func(tx *gorm.DB) error {
+		s := &model.UserSubscribeChannel{UserID: userID, ChannelID: channelID}
+		if exists, err := dbExists(tx, s); err != nil {
+			return err
+		} else if exists {
+			// 既読位置が無い既存の購読は、現在を既読位置とする
+			return ensureReadPointers(tx, channelID, time.Now(), []uuid.UUID{userID})
+		}
+		if err := tx.Create(s).Error; err != nil {
+			return err
+		}
+		// 購読前のメッセージは未読にしない
+		return updateReadPointers(tx, channelID, uuid.Nil, time.Now(), []uuid.UUID{userID})
+	})
 }
 
 // UnsubscribeChannel implements ChannelRepository interface.
@@ -789,6 +942,120 @@ func (repo *GormRepository) GetSubscribedChannelIDs(userID uuid.UUID) (channels
 	return channels, err
 }
 
+// JoinChannel implements ChannelRepository interface.
+func (repo *GormRepository) JoinChannel(userID, channelID uuid.UUID) error {
+	if userID == uuid.Nil || channelID == uuid.Nil {
+		return ErrNilID
+	}
+
+	var joined bool
+	err := repo.transact(func(tx *gorm.DB) error {
+		var ch model.Channel
+		if err := tx.First(&ch, &model.Channel{ID: channelID}).Error; err != nil {
+			return convertError(err)
+		}
+		if !ch.IsPublic || ch.IsArchived {
+			return ErrForbidden
+		}
+
+		m := &model.ChannelMember{ChannelID: channelID, UserID: userID}
+		if exists, err := dbExists(tx, m); err != nil {
+			return err
+		} else if exists {
+			return nil
+		}
+		if err := tx.Create(m).Error; err != nil {
+			return err
+		}
+		joined = true
+
+		// 参加したチャンネルは購読する
+		s := &model.UserSubscribeChannel{UserID: userID, ChannelID: channelID}
+		if exists, err := dbExists(tx, s); err != nil {
+			return err
+		} else if exists {
+			// 既読位置が無い既存の購読は、参加時を既読位置とする
+			return ensureReadPointers(tx, channelID, m.CreatedAt, []uuid.UUID{userID})
+		}
+		if err := tx.Create(s).Error; err != nil {
+			return err
+		}
+		// 参加前のメッセージは未読にしない
+		return updateReadPointers(tx, channelID, uuid.Nil, time.Now(), []uuid.UUID{userID})
+	})
+	if err != nil {
+		return err
+	}
+	if joined {
+		repo.hub.Publish(hub.Message{
+			Name: event.ChannelMemberJoined,
+			Fields: hub.Fields{
+				"channel_id": channelID,
+				"user_id":    userID,
+			},
+		})
+	}
+	return nil
+}
+
+// LeaveChannel implements ChannelRepository interface.
+func (repo *GormRepository) LeaveChannel(userID, channelID uuid.UUID) error {
+	if userID == uuid.Nil || channelID == uuid.Nil {
+		return ErrNilID
+	}
+
+	var left bool
+	err := repo.transact(func(tx *gorm.DB) error {
+		r := tx.Delete(&model.ChannelMember{ChannelID: channelID, UserID: userID})
+		if r.Error != nil {
+			return r.Error
+		}
+		left = r.RowsAffected > 0
+		return tx.Delete(&model.UserSubscribeChannel{UserID: userID, ChannelID: channelID}).Error
+	})
+	if err != nil {
+		return err
+	}
+	if left {
+		repo.hub.Publish(hub.Message{
+			Name: event.ChannelMemberLeft,
+			Fields: hub.Fields{
+				"channel_id": channelID,
+				"user_id":    userID,
+			},
+		})
+	}
+	return nil
+}
+
+// GetJoinedUserIDs implements ChannelRepository interface.
+func (repo *GormRepository) GetJoinedUserIDs(channelID uuid.UUID) (users []uuid.UUID, err error) {
+	users = make([]uuid.UUID, 0)
+	if channelID == uuid.Nil {
+		return users, nil
+	}
+	err = repo.db.
+		Model(&model.ChannelMember{}).
+		Where(&model.ChannelMember{ChannelID: channelID}).
+		Pluck("user_id", &users).
+		Error
+	return users, err
+}
+
+// GetJoinedChannelIDs implements ChannelRepository interface.
+func (repo *GormRepository) GetJoinedChannelIDs(userID uuid.UUID) (channels []uuid.UUID, err error) {
+	channels = make([]uuid.UUID, 0)
+	if userID == uuid.Nil {
+		return channels, nil
+	}
+	err = repo.db.
+		Model(&model.ChannelMember{}).
+		Where(&model.ChannelMember{UserID: userID}).
+		Pluck("channel_id", &channels).
+		Error
+	return channels, err
+}
+
 // isChannelPresent チャンネル名が同階層に既に存在するか
 func (repo *GormRepository) isChannelPresent(tx *gorm.DB, name string, parent uuid.UUID) (bool, error) {
 	c := 0
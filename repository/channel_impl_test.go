@@ -67,6 +67,77 @@ func TestRepositoryImpl_UpdateChannelAttributes(t *testing.T) {
 	}
 }
 
+func TestRepositoryImpl_UpdateChannelSlowModeInterval(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, channel := setupWithChannel(t, common)
+
+	assert.EqualError(repo.UpdateChannelSlowModeInterval(uuid.Nil, 10), ErrNilID.Error())
+	assert.Error(repo.UpdateChannelSlowModeInterval(channel.ID, -1))
+	assert.Equal(ErrNotFound, repo.UpdateChannelSlowModeInterval(uuid.Must(uuid.NewV4()), 10))
+
+	if assert.NoError(repo.UpdateChannelSlowModeInterval(channel.ID, 30)) {
+		ch, err := repo.GetChannel(channel.ID)
+		require.NoError(err)
+		assert.Equal(30, ch.SlowModeInterval)
+	}
+}
+
+func TestRepositoryImpl_UpdateChannelArchived(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, parent := setupWithUserAndChannel(t, common)
+	child := mustMakeChannelDetail(t, repo, user.ID, random, parent.ID)
+	m := mustMakeMessage(t, repo, user.ID, parent.ID)
+	pinID := mustMakePin(t, repo, m.ID, user.ID)
+	poll := mustMakePoll(t, repo, user.ID, parent.ID, false)
+	unpinned := mustMakeMessage(t, repo, user.ID, child.ID)
+	stamp := mustMakeStamp(t, repo, random, user.ID)
+	deleted := mustMakeMessage(t, repo, user.ID, parent.ID)
+	require.NoError(repo.DeleteMessage(deleted.ID))
+
+	assert.EqualError(repo.UpdateChannelArchived(uuid.Nil, true, user.ID), ErrNilID.Error())
+	assert.Equal(ErrNotFound, repo.UpdateChannelArchived(uuid.Must(uuid.NewV4()), true, user.ID))
+
+	if assert.NoError(repo.UpdateChannelArchived(parent.ID, true, user.ID)) {
+		ch, err := repo.GetChannel(parent.ID)
+		require.NoError(err)
+		assert.True(ch.IsArchived)
+		ch, err = repo.GetChannel(child.ID)
+		require.NoError(err)
+		assert.True(ch.IsArchived)
+	}
+
+	// アーカイブされたチャンネルは変更できない
+	_, err := repo.CreateMessage(user.ID, parent.ID, "test")
+	assert.Equal(ErrChannelArchived, err)
+	_, err = repo.CreateThreadReply(user.ID, m.ID, "test")
+	assert.Equal(ErrChannelArchived, err)
+	assert.Equal(ErrChannelArchived, repo.UpdateMessage(m.ID, "test"))
+	assert.Equal(ErrChannelArchived, repo.DeleteMessage(m.ID))
+	assert.Equal(ErrChannelArchived, repo.RestoreMessage(deleted.ID))
+	_, err = repo.AddStampToMessage(m.ID, stamp.ID, user.ID)
+	assert.Equal(ErrChannelArchived, err)
+	_, err = repo.CreatePin(unpinned.ID, user.ID)
+	assert.Equal(ErrChannelArchived, err)
+	assert.Equal(ErrChannelArchived, repo.DeletePin(pinID))
+	assert.Equal(ErrChannelArchived, repo.VotePoll(poll.ID, user.ID, []uuid.UUID{poll.Options[0].ID}))
+	assert.Equal(ErrChannelArchived, repo.RetractPollVote(poll.ID, user.ID))
+	_, err = repo.MoveMessages(m.ID, m.ID, mustMakeChannel(t, repo, random).ID)
+	assert.Equal(ErrChannelArchived, err)
+	// 保存期間によるメッセージの削除はアーカイブされていても行われる
+	assert.NoError(repo.DeleteExpiredMessage(m.ID))
+
+	// アーカイブされた親の子チャンネルは作れず、アーカイブも解除できない
+	_, err = repo.CreateChildChannel(utils.RandAlphabetAndNumberString(20), parent.ID, user.ID)
+	assert.Equal(ErrForbidden, err)
+	assert.Equal(ErrForbidden, repo.UpdateChannelArchived(child.ID, false, user.ID))
+
+	if assert.NoError(repo.UpdateChannelArchived(parent.ID, false, user.ID)) {
+		ch, err := repo.GetChannel(child.ID)
+		require.NoError(err)
+		assert.False(ch.IsArchived)
+	}
+}
+
 func TestRepositoryImpl_GetChannelByMessageID(t *testing.T) {
 	t.Parallel()
 	repo, _, _, user, channel := setupWithUserAndChannel(t, common)
@@ -422,6 +493,79 @@ func TestRepositoryImpl_GetSubscribedChannelIDs(t *testing.T) {
 	}
 }
 
+func TestRepositoryImpl_JoinChannel(t *testing.T) {
+	t.Parallel()
+	repo, assert, _, user, ch := setupWithUserAndChannel(t, common)
+
+	assert.EqualError(repo.JoinChannel(uuid.Nil, ch.ID), ErrNilID.Error())
+	assert.Equal(ErrNotFound, repo.JoinChannel(user.ID, uuid.Must(uuid.NewV4())))
+
+	private := mustMakePrivateChannel(t, repo, random, []uuid.UUID{user.ID})
+	assert.Equal(ErrForbidden, repo.JoinChannel(user.ID, private.ID))
+
+	if assert.NoError(repo.JoinChannel(user.ID, ch.ID)) {
+		assert.Equal(1, count(t, getDB(repo).Model(model.ChannelMember{}).Where(&model.ChannelMember{UserID: user.ID})))
+		// 参加すると購読状態になる
+		assert.Equal(1, count(t, getDB(repo).Model(model.UserSubscribeChannel{}).Where(&model.UserSubscribeChannel{UserID: user.ID})))
+	}
+	assert.NoError(repo.JoinChannel(user.ID, ch.ID))
+}
+
+func TestRepositoryImpl_LeaveChannel(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, ch := setupWithUserAndChannel(t, common)
+
+	assert.EqualError(repo.LeaveChannel(uuid.Nil, ch.ID), ErrNilID.Error())
+
+	require.NoError(repo.JoinChannel(user.ID, ch.ID))
+	if assert.NoError(repo.LeaveChannel(user.ID, ch.ID)) {
+		assert.Equal(0, count(t, getDB(repo).Model(model.ChannelMember{}).Where(&model.ChannelMember{UserID: user.ID})))
+		assert.Equal(0, count(t, getDB(repo).Model(model.UserSubscribeChannel{}).Where(&model.UserSubscribeChannel{UserID: user.ID})))
+	}
+	assert.NoError(repo.LeaveChannel(user.ID, ch.ID))
+}
+
+func TestRepositoryImpl_GetJoinedUserIDs(t *testing.T) {
+	t.Parallel()
+	repo, assert, require := setup(t, common)
+
+	user1 := mustMakeUser(t, repo, random)
+	user2 := mustMakeUser(t, repo, random)
+	ch := mustMakeChannel(t, repo, random)
+	require.NoError(repo.JoinChannel(user1.ID, ch.ID))
+	require.NoError(repo.JoinChannel(user2.ID, ch.ID))
+
+	arr, err := repo.GetJoinedUserIDs(ch.ID)
+	if assert.NoError(err) {
+		assert.ElementsMatch([]uuid.UUID{user1.ID, user2.ID}, arr)
+	}
+
+	arr, err = repo.GetJoinedUserIDs(uuid.Nil)
+	if assert.NoError(err) {
+		assert.Empty(arr)
+	}
+}
+
+func TestRepositoryImpl_GetJoinedChannelIDs(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user := setupWithUser(t, common)
+
+	ch1 := mustMakeChannel(t, repo, random)
+	ch2 := mustMakeChannel(t, repo, random)
+	require.NoError(repo.JoinChannel(user.ID, ch1.ID))
+	require.NoError(repo.JoinChannel(user.ID, ch2.ID))
+
+	arr, err := repo.GetJoinedChannelIDs(user.ID)
+	if assert.NoError(err) {
+		assert.ElementsMatch([]uuid.UUID{ch1.ID, ch2.ID}, arr)
+	}
+
+	arr, err = repo.GetJoinedChannelIDs(uuid.Nil)
+	if assert.NoError(err) {
+		assert.Empty(arr)
+	}
+}
+
 func TestRepositoryImpl_CreatePublicChannel(t *testing.T) {
 	t.Parallel()
 	repo, assert, _, user := setupWithUser(t, common)
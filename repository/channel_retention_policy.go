@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/traPtitech/traQ/model"
+)
+
+// ChannelRetentionPolicyRepository チャンネルメッセージ保持ポリシーリポジトリ
+type ChannelRetentionPolicyRepository interface {
+	// SetChannelRetentionPolicy 指定したチャンネルのメッセージ保持ポリシーを設定します
+	//
+	// daysに0を指定すると、祖先チャンネルのポリシーを継承せずメッセージを無期限に保持します。
+	// 成功した場合、nilを返します。
+	// 存在しないチャンネルを指定した場合、ErrNotFoundを返します。
+	// 引数に問題がある場合、ArgumentErrorを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	SetChannelRetentionPolicy(channelID uuid.UUID, days int, exemptPinned, exemptClipped bool) error
+	// DeleteChannelRetentionPolicy 指定したチャンネルのメッセージ保持ポリシーを削除し、祖先チャンネルのポリシーを継承するようにします
+	//
+	// 成功した場合、nilを返します。
+	// ポリシーが設定されていない場合は何もせずnilを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	DeleteChannelRetentionPolicy(channelID uuid.UUID) error
+	// GetChannelRetentionPolicy 指定したチャンネルに直接設定されたメッセージ保持ポリシーを取得します
+	//
+	// 成功した場合、ポリシーとnilを返します。
+	// ポリシーが設定されていない場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	GetChannelRetentionPolicy(channelID uuid.UUID) (*model.ChannelRetentionPolicy, error)
+	// GetEffectiveChannelRetentionPolicy 指定したチャンネルに適用されるメッセージ保持ポリシーを取得します
+	//
+	// チャンネルにポリシーが設定されていない場合は、最も近い祖先チャンネルのポリシーを返します。
+	// 成功した場合、ポリシーとnilを返します。
+	// 適用されるポリシーが存在しない場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	GetEffectiveChannelRetentionPolicy(channelID uuid.UUID) (*model.ChannelRetentionPolicy, error)
+	// GetRetentionTargetChannels 有効なメッセージ保持ポリシーが適用される全てのチャンネルと、そのポリシーを取得します
+	//
+	// 成功した場合、チャンネルIDをキーとしたポリシーのマップとnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetRetentionTargetChannels() (map[uuid.UUID]*model.ChannelRetentionPolicy, error)
+	// GetExpiredMessageIDs 指定したチャンネルの指定した日時より前に投稿されたメッセージのIDを投稿日時の昇順で取得します
+	//
+	// exemptPinnedがtrueの場合はピン留めされたメッセージを、exemptClippedがtrueの場合はクリップされたメッセージを除外します。
+	// 成功した場合、メッセージIDの配列とnilを返します。負のlimitは無視されます。
+	// DBによるエラーを返すことがあります。
+	GetExpiredMessageIDs(channelID uuid.UUID, before time.Time, exemptPinned, exemptClipped bool, limit int) ([]uuid.UUID, error)
+}
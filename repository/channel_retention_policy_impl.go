@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jinzhu/gorm"
+	"github.com/traPtitech/traQ/model"
+)
+
+// SetChannelRetentionPolicy implements ChannelRetentionPolicyRepository interface.
+func (repo *GormRepository) SetChannelRetentionPolicy(channelID uuid.UUID, days int, exemptPinned, exemptClipped bool) error {
+	if channelID == uuid.Nil {
+		return ErrNilID
+	}
+	if days < 0 {
+		return ArgError("days", "Days must be non-negative")
+	}
+
+	return repo.transact(func(tx *gorm.DB) error {
+		if exists, err := dbExists(tx, &model.Channel{ID: channelID}); err != nil {
+			return err
+		} else if !exists {
+			return ErrNotFound
+		}
+
+		p := &model.ChannelRetentionPolicy{ChannelID: channelID}
+		r := tx.Model(p).Updates(map[string]interface{}{
+			"days":           days,
+			"exempt_pinned":  exemptPinned,
+			"exempt_clipped": exemptClipped,
+		})
+		if r.Error != nil {
+			return r.Error
+		}
+		if r.RowsAffected == 0 {
+			p.Days = days
+			p.ExemptPinned = exemptPinned
+			p.ExemptClipped = exemptClipped
+			return tx.Create(p).Error
+		}
+		return nil
+	})
+}
+
+// DeleteChannelRetentionPolicy implements ChannelRetentionPolicyRepository interface.
+func (repo *GormRepository) DeleteChannelRetentionPolicy(channelID uuid.UUID) error {
+	if channelID == uuid.Nil {
+		return ErrNilID
+	}
+	return repo.db.Delete(&model.ChannelRetentionPolicy{ChannelID: channelID}).Error
+}
+
+// GetChannelRetentionPolicy implements ChannelRetentionPolicyRepository interface.
+func (repo *GormRepository) GetChannelRetentionPolicy(channelID uuid.UUID) (*model.ChannelRetentionPolicy, error) {
+	return repo.getChannelRetentionPolicy(repo.db, channelID)
+}
+
+// GetEffectiveChannelRetentionPolicy implements ChannelRetentionPolicyRepository interface.
+func (repo *GormRepository) GetEffectiveChannelRetentionPolicy(channelID uuid.UUID) (*model.ChannelRetentionPolicy, error) {
+	return repo.getEffectiveChannelRetentionPolicy(repo.db, channelID)
+}
+
+// GetRetentionTargetChannels implements ChannelRetentionPolicyRepository interface.
+func (repo *GormRepository) GetRetentionTargetChannels() (map[uuid.UUID]*model.ChannelRetentionPolicy, error) {
+	var policies []*model.ChannelRetentionPolicy
+	if err := repo.db.Where("days > 0").Find(&policies).Error; err != nil {
+		return nil, err
+	}
+
+	result := make(map[uuid.UUID]*model.ChannelRetentionPolicy)
+	for _, p := range policies {
+		desc, err := repo.getDescendantChannelIDs(repo.db, p.ChannelID)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range append([]uuid.UUID{p.ChannelID}, desc...) {
+			// より近い祖先チャンネルのポリシーで上書きされていないかを確認
+			effective, err := repo.getEffectiveChannelRetentionPolicy(repo.db, id)
+			if err != nil {
+				return nil, err
+			}
+			if effective.ChannelID == p.ChannelID {
+				result[id] = p
+			}
+		}
+	}
+	return result, nil
+}
+
+// GetExpiredMessageIDs implements ChannelRetentionPolicyRepository interface.
+func (repo *GormRepository) GetExpiredMessageIDs(channelID uuid.UUID, before time.Time, exemptPinned, exemptClipped bool, limit int) ([]uuid.UUID, error) {
+	ids := make([]uuid.UUID, 0)
+	if channelID == uuid.Nil {
+		return ids, nil
+	}
+
+	tx := repo.db.
+		Model(&model.Message{}).
+		Where("messages.channel_id = ? AND messages.created_at < ?", channelID, before)
+	if exemptPinned {
+		tx = tx.Where("NOT EXISTS (SELECT 1 FROM pins WHERE pins.message_id = messages.id)")
+	}
+	if exemptClipped {
+		tx = tx.Where("NOT EXISTS (SELECT 1 FROM clips WHERE clips.message_id = messages.id)")
+	}
+	err := tx.
+		Order("messages.created_at").
+		Scopes(limitAndOffset(limit, 0)).
+		Pluck("messages.id", &ids).
+		Error
+	return ids, err
+}
+
+func (repo *GormRepository) getChannelRetentionPolicy(tx *gorm.DB, channelID uuid.UUID) (*model.ChannelRetentionPolicy, error) {
+	if channelID == uuid.Nil {
+		return nil, ErrNotFound
+	}
+	p := &model.ChannelRetentionPolicy{}
+	if err := tx.Where(&model.ChannelRetentionPolicy{ChannelID: channelID}).Take(p).Error; err != nil {
+		return nil, convertError(err)
+	}
+	return p, nil
+}
+
+func (repo *GormRepository) getEffectiveChannelRetentionPolicy(tx *gorm.DB, channelID uuid.UUID) (*model.ChannelRetentionPolicy, error) {
+	p, err := repo.getChannelRetentionPolicy(tx, channelID)
+	if err != ErrNotFound {
+		return p, err
+	}
+
+	// 近い祖先から順に探す
+	ascendants, err := repo.getAscendantChannelIDs(tx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ascendants {
+		p, err := repo.getChannelRetentionPolicy(tx, id)
+		if err != ErrNotFound {
+			return p, err
+		}
+	}
+	return nil, ErrNotFound
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+func TestRepositoryImpl_SetChannelRetentionPolicy(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, _, channel := setupWithUserAndChannel(t, common)
+
+	assert.Equal(ErrNilID, repo.SetChannelRetentionPolicy(uuid.Nil, 7, false, false))
+	assert.Equal(ErrNotFound, repo.SetChannelRetentionPolicy(uuid.Must(uuid.NewV4()), 7, false, false))
+	assert.True(IsArgError(repo.SetChannelRetentionPolicy(channel.ID, -1, false, false)))
+
+	if assert.NoError(repo.SetChannelRetentionPolicy(channel.ID, 7, true, false)) {
+		p, err := repo.GetChannelRetentionPolicy(channel.ID)
+		require.NoError(err)
+		assert.Equal(7, p.Days)
+		assert.True(p.ExemptPinned)
+		assert.False(p.ExemptClipped)
+	}
+	if assert.NoError(repo.SetChannelRetentionPolicy(channel.ID, 30, false, true)) {
+		p, err := repo.GetChannelRetentionPolicy(channel.ID)
+		require.NoError(err)
+		assert.Equal(30, p.Days)
+		assert.False(p.ExemptPinned)
+		assert.True(p.ExemptClipped)
+	}
+}
+
+func TestRepositoryImpl_DeleteChannelRetentionPolicy(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, _, channel := setupWithUserAndChannel(t, common)
+
+	require.NoError(repo.SetChannelRetentionPolicy(channel.ID, 7, false, false))
+
+	assert.Equal(ErrNilID, repo.DeleteChannelRetentionPolicy(uuid.Nil))
+	if assert.NoError(repo.DeleteChannelRetentionPolicy(channel.ID)) {
+		_, err := repo.GetChannelRetentionPolicy(channel.ID)
+		assert.Equal(ErrNotFound, err)
+	}
+	assert.NoError(repo.DeleteChannelRetentionPolicy(channel.ID))
+}
+
+func TestRepositoryImpl_GetEffectiveChannelRetentionPolicy(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, common)
+
+	child := mustMakeChannelDetail(t, repo, user.ID, random, channel.ID)
+	grandchild := mustMakeChannelDetail(t, repo, user.ID, random, child.ID)
+
+	_, err := repo.GetEffectiveChannelRetentionPolicy(grandchild.ID)
+	assert.Equal(ErrNotFound, err)
+
+	require.NoError(repo.SetChannelRetentionPolicy(channel.ID, 7, false, false))
+	p, err := repo.GetEffectiveChannelRetentionPolicy(grandchild.ID)
+	if assert.NoError(err) {
+		assert.Equal(channel.ID, p.ChannelID)
+	}
+
+	require.NoError(repo.SetChannelRetentionPolicy(child.ID, 0, false, false))
+	p, err = repo.GetEffectiveChannelRetentionPolicy(grandchild.ID)
+	if assert.NoError(err) {
+		assert.Equal(child.ID, p.ChannelID)
+		assert.False(p.IsEnabled())
+	}
+}
+
+func TestRepositoryImpl_GetRetentionTargetChannels(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, ex1)
+
+	child := mustMakeChannelDetail(t, repo, user.ID, random, channel.ID)
+	disabled := mustMakeChannelDetail(t, repo, user.ID, random, channel.ID)
+	underDisabled := mustMakeChannelDetail(t, repo, user.ID, random, disabled.ID)
+	require.NoError(repo.SetChannelRetentionPolicy(channel.ID, 7, false, false))
+	require.NoError(repo.SetChannelRetentionPolicy(disabled.ID, 0, false, false))
+
+	targets, err := repo.GetRetentionTargetChannels()
+	if assert.NoError(err) {
+		if assert.Contains(targets, channel.ID) {
+			assert.Equal(channel.ID, targets[channel.ID].ChannelID)
+		}
+		if assert.Contains(targets, child.ID) {
+			assert.Equal(channel.ID, targets[child.ID].ChannelID)
+		}
+		assert.NotContains(targets, disabled.ID)
+		assert.NotContains(targets, underDisabled.ID)
+	}
+}
+
+func TestRepositoryImpl_GetExpiredMessageIDs(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, common)
+
+	normal := mustMakeMessage(t, repo, user.ID, channel.ID)
+	pinned := mustMakeMessage(t, repo, user.ID, channel.ID)
+	mustMakePin(t, repo, pinned.ID, user.ID)
+	clipped := mustMakeMessage(t, repo, user.ID, channel.ID)
+	folder, err := repo.CreateClipFolder(user.ID, "retention")
+	require.NoError(err)
+	_, err = repo.CreateClip(clipped.ID, folder.ID, user.ID)
+	require.NoError(err)
+
+	ids, err := repo.GetExpiredMessageIDs(channel.ID, time.Now().Add(-time.Hour), false, false, -1)
+	if assert.NoError(err) {
+		assert.Len(ids, 0)
+	}
+
+	ids, err = repo.GetExpiredMessageIDs(channel.ID, time.Now().Add(time.Hour), false, false, -1)
+	if assert.NoError(err) {
+		assert.ElementsMatch([]uuid.UUID{normal.ID, pinned.ID, clipped.ID}, ids)
+	}
+
+	ids, err = repo.GetExpiredMessageIDs(channel.ID, time.Now().Add(time.Hour), true, true, -1)
+	if assert.NoError(err) {
+		assert.ElementsMatch([]uuid.UUID{normal.ID}, ids)
+	}
+
+	ids, err = repo.GetExpiredMessageIDs(channel.ID, time.Now().Add(time.Hour), false, false, 1)
+	if assert.NoError(err) {
+		assert.Equal([]uuid.UUID{normal.ID}, ids)
+	}
+}
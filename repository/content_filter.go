@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/traPtitech/traQ/model"
+	"gopkg.in/guregu/null.v3"
+)
+
+// CreateContentFilterRuleArgs コンテンツフィルタルール作成引数
+type CreateContentFilterRuleArgs struct {
+	Pattern     string
+	IsRegex     bool
+	ChannelID   uuid.UUID
+	Action      model.ContentFilterAction
+	Description string
+	CreatorID   uuid.UUID
+}
+
+// UpdateContentFilterRuleArgs コンテンツフィルタルール更新引数
+type UpdateContentFilterRuleArgs struct {
+	Pattern     null.String
+	IsRegex     null.Bool
+	Action      null.String
+	Description null.String
+}
+
+// ContentFilterRuleRepository コンテンツフィルタルールリポジトリ
+//
+// ここで管理されるルールはCreateMessage, CreateThreadReply, UpdateMessage, CreatePollで適用されます。
+// 保存されないテキストにはApplyContentFilterで適用します。
+type ContentFilterRuleRepository interface {
+	// CreateContentFilterRule コンテンツフィルタルールを作成します
+	//
+	// 成功した場合、ルールとnilを返します。
+	// パターンや動作が不正な場合、ArgumentErrorを返します。
+	// 存在しないチャンネルを指定した場合、ArgumentErrorを返します。
+	// 作成者にuuid.Nilを指定するとErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	CreateContentFilterRule(args CreateContentFilterRuleArgs) (*model.ContentFilterRule, error)
+	// UpdateContentFilterRule 指定したコンテンツフィルタルールを更新します
+	//
+	// 成功した場合、nilを返します。
+	// 存在しないルールを指定した場合、ErrNotFoundを返します。
+	// パターンや動作が不正な場合、ArgumentErrorを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	UpdateContentFilterRule(id uuid.UUID, args UpdateContentFilterRuleArgs) error
+	// DeleteContentFilterRule 指定したコンテンツフィルタルールを削除します
+	//
+	// 成功した場合、nilを返します。
+	// 存在しないルールを指定した場合、ErrNotFoundを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	DeleteContentFilterRule(id uuid.UUID) error
+	// GetContentFilterRule 指定したコンテンツフィルタルールを取得します
+	//
+	// 成功した場合、ルールとnilを返します。
+	// 存在しないルールを指定した場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	GetContentFilterRule(id uuid.UUID) (*model.ContentFilterRule, error)
+	// GetContentFilterRules 全てのコンテンツフィルタルールを作成日時の昇順で取得します
+	//
+	// 成功した場合、ルールの配列とnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetContentFilterRules() ([]*model.ContentFilterRule, error)
+	// ApplyContentFilter 指定したチャンネルに送信するテキストにコンテンツフィルタを適用します
+	//
+	// 成功した場合、伏せ字に置き換えたテキストとnilを返します。
+	// 通報ルールは保存されるメッセージにのみ適用されるため、ここでは無視されます。
+	// 拒否された場合、*ContentFilterErrorを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	ApplyContentFilter(channelID uuid.UUID, text string) (string, error)
+}
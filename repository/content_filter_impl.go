@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jinzhu/gorm"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/utils/contentfilter"
+)
+
+// contentFilterImpl コンパイル済みのコンテンツフィルタのキャッシュ
+type contentFilterImpl struct {
+	contentFilterLock   sync.RWMutex
+	contentFilter       *contentfilter.Filter
+	contentFilterLoaded bool
+}
+
+// CreateContentFilterRule implements ContentFilterRuleRepository interface.
+func (repo *GormRepository) CreateContentFilterRule(args CreateContentFilterRuleArgs) (*model.ContentFilterRule, error) {
+	if args.CreatorID == uuid.Nil {
+		return nil, ErrNilID
+	}
+	r := &model.ContentFilterRule{
+		ID:          uuid.Must(uuid.NewV4()),
+		Pattern:     args.Pattern,
+		IsRegex:     args.IsRegex,
+		ChannelID:   args.ChannelID,
+		Action:      args.Action,
+		Description: args.Description,
+		CreatorID:   args.CreatorID,
+	}
+	if err := validateContentFilterRule(r); err != nil {
+		return nil, err
+	}
+	if !r.IsGlobal() {
+		if ok, err := dbExists(repo.db, &model.Channel{ID: r.ChannelID}); err != nil {
+			return nil, err
+		} else if !ok {
+			return nil, ArgError("args.ChannelID", "the Channel is not found")
+		}
+	}
+
+	repo.contentFilterLock.Lock()
+	defer repo.contentFilterLock.Unlock()
+	if err := repo.db.Create(r).Error; err != nil {
+		return nil, err
+	}
+	repo.contentFilterLoaded = false
+	return r, nil
+}
+
+// UpdateContentFilterRule implements ContentFilterRuleRepository interface.
+func (repo *GormRepository) UpdateContentFilterRule(id uuid.UUID, args UpdateContentFilterRuleArgs) error {
+	if id == uuid.Nil {
+		return ErrNilID
+	}
+
+	repo.contentFilterLock.Lock()
+	defer repo.contentFilterLock.Unlock()
+	return repo.transact(func(tx *gorm.DB) error {
+		var r model.ContentFilterRule
+		if err := tx.Where(&model.ContentFilterRule{ID: id}).Take(&r).Error; err != nil {
+			return convertError(err)
+		}
+
+		changes := map[string]interface{}{}
+		if args.Pattern.Valid {
+			r.Pattern = args.Pattern.String
+			changes["pattern"] = r.Pattern
+		}
+		if args.IsRegex.Valid {
+			r.IsRegex = args.IsRegex.Bool
+			changes["is_regex"] = r.IsRegex
+		}
+		if args.Action.Valid {
+			r.Action = model.ContentFilterAction(args.Action.String)
+			changes["action"] = r.Action
+		}
+		if args.Description.Valid {
+			r.Description = args.Description.String
+			changes["description"] = r.Description
+		}
+		if len(changes) == 0 {
+			return nil
+		}
+		if err := validateContentFilterRule(&r); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&model.ContentFilterRule{ID: id}).Updates(changes).Error; err != nil {
+			return err
+		}
+		repo.contentFilterLoaded = false
+		return nil
+	})
+}
+
+// DeleteContentFilterRule implements ContentFilterRuleRepository interface.
+func (repo *GormRepository) DeleteContentFilterRule(id uuid.UUID) error {
+	if id == uuid.Nil {
+		return ErrNilID
+	}
+
+	repo.contentFilterLock.Lock()
+	defer repo.contentFilterLock.Unlock()
+	result := repo.db.Where(&model.ContentFilterRule{ID: id}).Delete(&model.ContentFilterRule{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	repo.contentFilterLoaded = false
+	return nil
+}
+
+// GetContentFilterRule implements ContentFilterRuleRepository interface.
+func (repo *GormRepository) GetContentFilterRule(id uuid.UUID) (*model.ContentFilterRule, error) {
+	if id == uuid.Nil {
+		return nil, ErrNotFound
+	}
+	var r model.ContentFilterRule
+	if err := repo.db.Where(&model.ContentFilterRule{ID: id}).Take(&r).Error; err != nil {
+		return nil, convertError(err)
+	}
+	return &r, nil
+}
+
+// GetContentFilterRules implements ContentFilterRuleRepository interface.
+func (repo *GormRepository) GetContentFilterRules() ([]*model.ContentFilterRule, error) {
+	rules := make([]*model.ContentFilterRule, 0)
+	return rules, repo.db.Order("created_at").Find(&rules).Error
+}
+
+// validateContentFilterRule ルールのパターンと動作を検証します
+func validateContentFilterRule(r *model.ContentFilterRule) error {
+	if len(r.Pattern) == 0 {
+		return ArgError("args.Pattern", "Pattern is required")
+	}
+	if _, err := contentfilter.Compile(r); err != nil {
+		return ArgError("args.Pattern", "invalid Pattern: "+err.Error())
+	}
+	if !r.Action.Valid() {
+		return ArgError("args.Action", "invalid Action")
+	}
+	return nil
+}
+
+// getContentFilter コンパイル済みのコンテンツフィルタを取得します
+func (repo *GormRepository) getContentFilter() (*contentfilter.Filter, error) {
+	repo.contentFilterLock.RLock()
+	f, loaded := repo.contentFilter, repo.contentFilterLoaded
+	repo.contentFilterLock.RUnlock()
+	if loaded {
+		return f, nil
+	}
+
+	repo.contentFilterLock.Lock()
+	defer repo.contentFilterLock.Unlock()
+	if repo.contentFilterLoaded {
+		return repo.contentFilter, nil
+	}
+	rules, err := repo.GetContentFilterRules()
+	if err != nil {
+		return nil, err
+	}
+	repo.contentFilter = contentfilter.New(rules)
+	repo.contentFilterLoaded = true
+	return repo.contentFilter, nil
+}
+
+// ApplyContentFilter implements ContentFilterRuleRepository interface.
+func (repo *GormRepository) ApplyContentFilter(channelID uuid.UUID, text string) (string, error) {
+	if channelID == uuid.Nil {
+		return "", ErrNilID
+	}
+	res, err := repo.applyContentFilter(repo.db, channelID, text)
+	if err != nil {
+		return "", err
+	}
+	return res.Text, nil
+}
+
+// applyContentFilter 指定したチャンネルに投稿されるテキストにコンテンツフィルタを適用します
+//
+// 拒否ルールに一致した場合は*ContentFilterErrorを返します。
+func (repo *GormRepository) applyContentFilter(tx *gorm.DB, channelID uuid.UUID, text string) (*contentfilter.Result, error) {
+	f, err := repo.getContentFilter()
+	if err != nil {
+		return nil, err
+	}
+
+	channels := []uuid.UUID{channelID}
+	if f.Scoped() {
+		ascendants, err := repo.getAscendantChannelIDs(tx, channelID)
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, ascendants...)
+	}
+
+	res := f.Apply(text, channels)
+	if res.RejectedBy != nil {
+		return nil, &ContentFilterError{RuleID: res.RejectedBy.ID}
+	}
+	return res, nil
+}
+
+// reportFlaggedMessage 通報ルールに一致したメッセージをシステムによる通報として登録します
+//
+// 通報者はuuid.Nilになります。既にシステムによる通報がある場合は理由を更新します。
+// 通報した場合、通報を記録したモデレーションケースを返します。
+func reportFlaggedMessage(tx *gorm.DB, messageID uuid.UUID, rules []*model.ContentFilterRule) (*model.ModerationCase, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	reasons := make([]string, len(rules))
+	for i, r := range rules {
+		reasons[i] = "content filter rule " + r.ID.String()
+		if len(r.Description) > 0 {
+			reasons[i] += ": " + r.Description
+		}
+	}
+	reason := strings.Join(reasons, "\n")
+	if err := tx.Exec(`INSERT INTO message_reports (id, message_id, reporter, reason, created_at) VALUES (?, ?, ?, ?, ?)
+ON DUPLICATE KEY UPDATE reason = VALUES(reason), created_at = VALUES(created_at), deleted_at = NULL`,
+		uuid.Must(uuid.NewV4()), messageID, uuid.Nil, reason, time.Now()).Error; err != nil {
+		return nil, err
+	}
+	return openModerationCase(tx, messageID, uuid.Nil, reason)
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/traPtitech/traQ/model"
+	"gopkg.in/guregu/null.v3"
+)
+
+func mustMakeContentFilterRule(t *testing.T, repo Repository, creatorID, channelID uuid.UUID, pattern string, action model.ContentFilterAction) *model.ContentFilterRule {
+	t.Helper()
+	r, err := repo.CreateContentFilterRule(CreateContentFilterRuleArgs{
+		Pattern:   pattern,
+		ChannelID: channelID,
+		Action:    action,
+		CreatorID: creatorID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestRepositoryImpl_CreateContentFilterRule(t *testing.T) {
+	t.Parallel()
+	repo, assert, _, user, channel := setupWithUserAndChannel(t, common)
+
+	_, err := repo.CreateContentFilterRule(CreateContentFilterRuleArgs{Pattern: "a", Action: model.ContentFilterActionFlag})
+	assert.Equal(ErrNilID, err)
+
+	for _, args := range []CreateContentFilterRuleArgs{
+		{Pattern: "", Action: model.ContentFilterActionFlag, ChannelID: channel.ID, CreatorID: user.ID},
+		{Pattern: "(", IsRegex: true, Action: model.ContentFilterActionFlag, ChannelID: channel.ID, CreatorID: user.ID},
+		{Pattern: "a", Action: "delete", ChannelID: channel.ID, CreatorID: user.ID},
+		{Pattern: "a", Action: model.ContentFilterActionFlag, ChannelID: uuid.Must(uuid.NewV4()), CreatorID: user.ID},
+	} {
+		_, err := repo.CreateContentFilterRule(args)
+		assert.True(IsArgError(err))
+	}
+
+	r, err := repo.CreateContentFilterRule(CreateContentFilterRuleArgs{
+		Pattern:     `ghp_[0-9a-zA-Z]{36}`,
+		IsRegex:     true,
+		ChannelID:   channel.ID,
+		Action:      model.ContentFilterActionReject,
+		Description: "GitHub token",
+		CreatorID:   user.ID,
+	})
+	if assert.NoError(err) {
+		assert.NotEqual(uuid.Nil, r.ID)
+		assert.True(r.IsRegex)
+		assert.Equal(channel.ID, r.ChannelID)
+		assert.Equal(model.ContentFilterActionReject, r.Action)
+		assert.Equal("GitHub token", r.Description)
+	}
+}
+
+func TestRepositoryImpl_UpdateContentFilterRule(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, common)
+
+	r := mustMakeContentFilterRule(t, repo, user.ID, channel.ID, "a", model.ContentFilterActionFlag)
+
+	assert.Equal(ErrNilID, repo.UpdateContentFilterRule(uuid.Nil, UpdateContentFilterRuleArgs{}))
+	assert.Equal(ErrNotFound, repo.UpdateContentFilterRule(uuid.Must(uuid.NewV4()), UpdateContentFilterRuleArgs{Pattern: null.StringFrom("b")}))
+	assert.True(IsArgError(repo.UpdateContentFilterRule(r.ID, UpdateContentFilterRuleArgs{Pattern: null.StringFrom("("), IsRegex: null.BoolFrom(true)})))
+	assert.True(IsArgError(repo.UpdateContentFilterRule(r.ID, UpdateContentFilterRuleArgs{Action: null.StringFrom("delete")})))
+
+	if assert.NoError(repo.UpdateContentFilterRule(r.ID, UpdateContentFilterRuleArgs{
+		Pattern:     null.StringFrom("b+"),
+		IsRegex:     null.BoolFrom(true),
+		Action:      null.StringFrom(string(model.ContentFilterActionMask)),
+		Description: null.StringFrom("desc"),
+	})) {
+		r, err := repo.GetContentFilterRule(r.ID)
+		require.NoError(err)
+		assert.Equal("b+", r.Pattern)
+		assert.True(r.IsRegex)
+		assert.Equal(model.ContentFilterActionMask, r.Action)
+		assert.Equal("desc", r.Description)
+	}
+}
+
+func TestRepositoryImpl_DeleteContentFilterRule(t *testing.T) {
+	t.Parallel()
+	repo, assert, _, user, channel := setupWithUserAndChannel(t, common)
+
+	r := mustMakeContentFilterRule(t, repo, user.ID, channel.ID, "a", model.ContentFilterActionFlag)
+
+	assert.Equal(ErrNilID, repo.DeleteContentFilterRule(uuid.Nil))
+	assert.Equal(ErrNotFound, repo.DeleteContentFilterRule(uuid.Must(uuid.NewV4())))
+	if assert.NoError(repo.DeleteContentFilterRule(r.ID)) {
+		_, err := repo.GetContentFilterRule(r.ID)
+		assert.Equal(ErrNotFound, err)
+	}
+}
+
+func TestRepositoryImpl_GetContentFilterRules(t *testing.T) {
+	t.Parallel()
+	repo, assert, _, user, channel := setupWithUserAndChannel(t, common)
+
+	r := mustMakeContentFilterRule(t, repo, user.ID, channel.ID, "a", model.ContentFilterActionFlag)
+
+	_, err := repo.GetContentFilterRule(uuid.Nil)
+	assert.Equal(ErrNotFound, err)
+	if rules, err := repo.GetContentFilterRules(); assert.NoError(err) {
+		found := false
+		for _, v := range rules {
+			found = found || v.ID == r.ID
+		}
+		assert.True(found)
+	}
+}
+
+func TestRepositoryImpl_ContentFilter(t *testing.T) {
+	t.Parallel()
+	repo, _, _, user := setupWithUser(t, common)
+
+	parent := mustMakeChannel(t, repo, random)
+	child := mustMakeChannelDetail(t, repo, user.ID, random, parent.ID)
+	reject := mustMakeContentFilterRule(t, repo, user.ID, parent.ID, "secret-token", model.ContentFilterActionReject)
+	flag := mustMakeContentFilterRule(t, repo, user.ID, parent.ID, "password", model.ContentFilterActionFlag)
+	mustMakeContentFilterRule(t, repo, user.ID, parent.ID, "ngword", model.ContentFilterActionMask)
+
+	t.Run("reject", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		_, err := repo.CreateMessage(user.ID, child.ID, "my secret-token")
+		if assert.True(IsContentFilterError(err)) {
+			assert.Equal(reject.ID, err.(*ContentFilterError).RuleID)
+		}
+
+		m := mustMakeMessage(t, repo, user.ID, child.ID)
+		_, err = repo.CreateThreadReply(user.ID, m.ID, "SECRET-TOKEN")
+		assert.True(IsContentFilterError(err))
+		assert.True(IsContentFilterError(repo.UpdateMessage(m.ID, "secret-token")))
+	})
+
+	t.Run("mask", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		if m, err := repo.CreateMessage(user.ID, child.ID, "this is ngword"); assert.NoError(err) {
+			assert.Equal("this is ******", m.Text)
+		}
+	})
+
+	t.Run("flag", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		m, err := repo.CreateMessage(user.ID, child.ID, "my password is hunter2")
+		if assert.NoError(err) {
+			assert.Equal("my password is hunter2", m.Text)
+			if reports, err := repo.GetMessageReportsByMessageID(m.ID); assert.NoError(err) && assert.Len(reports, 1) {
+				assert.Equal(uuid.Nil, reports[0].Reporter)
+				assert.Contains(reports[0].Reason, flag.ID.String())
+			}
+		}
+	})
+
+	t.Run("out of scope", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		_, err := repo.CreateMessage(user.ID, mustMakeChannel(t, repo, random).ID, "secret-token")
+		assert.NoError(err)
+	})
+}
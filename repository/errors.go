@@ -1,6 +1,10 @@
 package repository
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/gofrs/uuid"
+)
 
 var (
 	// ErrNilID 汎用エラー 引数のIDがNilです
@@ -11,6 +15,8 @@ var (
 	ErrAlreadyExists = errors.New("already exists")
 	// ErrForbidden 汎用エラー 禁止されています
 	ErrForbidden = errors.New("forbidden")
+	// ErrChannelArchived 汎用エラー チャンネルがアーカイブされているため変更できません
+	ErrChannelArchived = errors.New("the channel is archived")
 )
 
 // ArgumentError 引数エラー
@@ -37,3 +43,22 @@ func IsArgError(err error) bool {
 	_, ok := err.(*ArgumentError)
 	return ok
 }
+
+// ContentFilterError コンテンツフィルタによってメッセージが拒否されたエラー
+type ContentFilterError struct {
+	RuleID uuid.UUID
+}
+
+// Error エラーメッセージを返します
+func (e *ContentFilterError) Error() string {
+	return "the message was rejected by content filter rule " + e.RuleID.String()
+}
+
+// IsContentFilterError コンテンツフィルタによる拒否エラーかどうか
+func IsContentFilterError(err error) bool {
+	if err == nil {
+		return false
+	}
+	_, ok := err.(*ContentFilterError)
+	return ok
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"github.com/gofrs/uuid"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -27,3 +28,16 @@ func TestIsArgError(t *testing.T) {
 	assert.False(t, IsArgError(nil))
 	assert.False(t, IsArgError(ErrAlreadyExists))
 }
+
+func TestContentFilterError_Error(t *testing.T) {
+	t.Parallel()
+	id := uuid.Must(uuid.NewV4())
+	assert.Contains(t, (&ContentFilterError{RuleID: id}).Error(), id.String())
+}
+
+func TestIsContentFilterError(t *testing.T) {
+	t.Parallel()
+	assert.True(t, IsContentFilterError(&ContentFilterError{}))
+	assert.False(t, IsContentFilterError(nil))
+	assert.False(t, IsContentFilterError(ArgError("", "")))
+}
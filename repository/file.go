@@ -48,6 +48,15 @@ type FileRepository interface {
 	// 成功した場合、メタデータとnilを返します。
 	// DB, ファイルシステムによるエラーを返すことがあります。
 	SaveFileWithACL(name string, src io.Reader, size int64, mime string, fType string, creatorID uuid.UUID, read ACL) (*model.File, error)
+	// SaveChannelFile チャンネルに投稿するファイルを保存します
+	//
+	// 保存したファイルには、作成者とチャンネルにアクセス可能なユーザーのみがアクセスできます。
+	// アクセス可否は保存後のチャンネルのメンバーの変更にも追従します。
+	// mimeが指定されていない場合はnameの拡張子によって決まります。
+	// 成功した場合、メタデータとnilを返します。
+	// 存在しないチャンネルを指定した場合、ArgumentErrorを返します。
+	// DB, ファイルシステムによるエラーを返すことがあります。
+	SaveChannelFile(name string, src io.Reader, size int64, mime string, fType string, creatorID, channelID uuid.UUID) (*model.File, error)
 	// RegenerateThumbnail 指定したファイルのサムネイル画像を再生成します
 	//
 	// 成功した場合、trueとnilを返します。
@@ -61,6 +70,7 @@ type FileRepository interface {
 	// fileIDにuuid.Nilを指定した場合、ErrNilIDを返します。
 	// 存在しないfileIDを指定した場合、ErrNotFoundを返します。
 	// userIDにuuid.Nilを指定すると、全てのユーザーを指定します。全てのユーザーに関するACLの設定を返します。全てのユーザーがアクセス可能な場合にのみtrueを返すとは限りません。
+	// チャンネルに投稿されたファイルの場合、ACLの代わりに現在のチャンネルのアクセス可否に従います。
 	// DBによるエラーを返すことがあります。
 	IsFileAccessible(fileID, userID uuid.UUID) (bool, error)
 }
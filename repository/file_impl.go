@@ -52,8 +52,34 @@ func (repo *GormRepository) SaveFileWithACL(name string, src io.Reader, size int
 		Type:      fType,
 		CreatorID: creatorID,
 	}
-	if len(mimeType) == 0 {
-		f.Mime = mime.TypeByExtension(filepath.Ext(name))
+	return repo.saveFile(f, src, read)
+}
+
+// SaveChannelFile implements FileRepository interface.
+func (repo *GormRepository) SaveChannelFile(name string, src io.Reader, size int64, mimeType string, fType string, creatorID, channelID uuid.UUID) (*model.File, error) {
+	if ok, err := dbExists(repo.db, &model.Channel{ID: channelID}); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, ArgError("channelID", "the Channel is not found")
+	}
+
+	f := &model.File{
+		ID:        uuid.Must(uuid.NewV4()),
+		Name:      name,
+		Size:      size,
+		Mime:      mimeType,
+		Type:      fType,
+		CreatorID: creatorID,
+		ChannelID: channelID,
+	}
+	// アクセス可否はチャンネルから決まるため、作成者以外のACLは設定しない
+	return repo.saveFile(f, src, ACL{})
+}
+
+// saveFile ファイルのデータとメタデータ、ACLを保存します
+func (repo *GormRepository) saveFile(f *model.File, src io.Reader, read ACL) (*model.File, error) {
+	if len(f.Mime) == 0 {
+		f.Mime = mime.TypeByExtension(filepath.Ext(f.Name))
 		if len(f.Mime) == 0 {
 			f.Mime = echo.MIMEOctetStream
 		}
@@ -63,7 +89,7 @@ func (repo *GormRepository) SaveFileWithACL(name string, src io.Reader, size int
 	}
 
 	if read != nil {
-		read[creatorID] = true
+		read[f.CreatorID] = true
 	}
 
 	eg, ctx := errgroup.WithContext(context.Background())
@@ -231,10 +257,20 @@ func (repo *GormRepository) IsFileAccessible(fileID, userID uuid.UUID) (bool, er
 		return false, ErrNilID
 	}
 
-	if ok, err := dbExists(repo.db, &model.File{ID: fileID}); err != nil {
-		return false, err
-	} else if !ok {
-		return false, ErrNotFound
+	var f model.File
+	if err := repo.db.Where(&model.File{ID: fileID}).Take(&f).Error; err != nil {
+		return false, convertError(err)
+	}
+
+	// チャンネルに投稿されたファイルは、現在のチャンネルのアクセス可否に従う
+	if f.ChannelID != uuid.Nil {
+		if userID != uuid.Nil && userID == f.CreatorID {
+			return true, nil
+		}
+		if userID == uuid.Nil {
+			return dbExists(repo.db, &model.Channel{ID: f.ChannelID, IsPublic: true})
+		}
+		return repo.IsChannelAccessibleToUser(userID, f.ChannelID)
 	}
 
 	var result struct {
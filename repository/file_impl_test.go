@@ -133,6 +133,31 @@ func TestRepositoryImpl_SaveFile(t *testing.T) {
 	}
 }
 
+func TestRepositoryImpl_SaveChannelFile(t *testing.T) {
+	t.Parallel()
+	repo, _, _, user, channel := setupWithUserAndChannel(t, common)
+
+	t.Run("unknown channel", func(t *testing.T) {
+		t.Parallel()
+
+		buf := bytes.NewBufferString("test message")
+		_, err := repo.SaveChannelFile("test.txt", buf, int64(buf.Len()), "", model.FileTypeUserFile, user.ID, uuid.Must(uuid.NewV4()))
+		assert.True(t, IsArgError(err))
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		buf := bytes.NewBufferString("test message")
+		f, err := repo.SaveChannelFile("test.txt", buf, int64(buf.Len()), "", model.FileTypeUserFile, user.ID, channel.ID)
+		if assert.NoError(err) {
+			assert.Equal(channel.ID, f.ChannelID)
+			assert.Equal("text/plain; charset=utf-8", f.Mime)
+		}
+	})
+}
+
 func TestRepositoryImpl_IsFileAccessible(t *testing.T) {
 	t.Parallel()
 	repo, _, _, user := setupWithUser(t, common)
@@ -256,6 +281,58 @@ func TestRepositoryImpl_IsFileAccessible(t *testing.T) {
 		})
 	})
 
+	t.Run("Private channel", func(t *testing.T) {
+		t.Parallel()
+
+		member := mustMakeUser(t, repo, random)
+		ch := mustMakePrivateChannel(t, repo, random, []uuid.UUID{user.ID, member.ID})
+		buf := bytes.NewBufferString("test message")
+		f, err := repo.SaveChannelFile("test.txt", buf, int64(buf.Len()), "", model.FileTypeUserFile, user.ID, ch.ID)
+		require.NoError(t, err)
+
+		t.Run("any user", func(t *testing.T) {
+			t.Parallel()
+
+			ok, err := repo.IsFileAccessible(f.ID, uuid.Nil)
+			if assert.NoError(t, err) {
+				assert.False(t, ok)
+			}
+		})
+
+		t.Run("member", func(t *testing.T) {
+			t.Parallel()
+
+			ok, err := repo.IsFileAccessible(f.ID, member.ID)
+			if assert.NoError(t, err) {
+				assert.True(t, ok)
+			}
+		})
+
+		t.Run("non member", func(t *testing.T) {
+			t.Parallel()
+
+			user := mustMakeUser(t, repo, random)
+			ok, err := repo.IsFileAccessible(f.ID, user.ID)
+			if assert.NoError(t, err) {
+				assert.False(t, ok)
+			}
+		})
+	})
+
+	t.Run("Public channel", func(t *testing.T) {
+		t.Parallel()
+
+		ch := mustMakeChannel(t, repo, random)
+		buf := bytes.NewBufferString("test message")
+		f, err := repo.SaveChannelFile("test.txt", buf, int64(buf.Len()), "", model.FileTypeUserFile, user.ID, ch.ID)
+		require.NoError(t, err)
+
+		ok, err := repo.IsFileAccessible(f.ID, uuid.Nil)
+		if assert.NoError(t, err) {
+			assert.True(t, ok)
+		}
+	})
+
 	t.Run("Deny rule", func(t *testing.T) {
 		t.Parallel()
 
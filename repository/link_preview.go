@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"github.com/traPtitech/traQ/model"
+)
+
+// LinkPreviewRepository URLプレビューキャッシュリポジトリ
+type LinkPreviewRepository interface {
+	// SaveLinkPreview URLプレビューをキャッシュに保存します
+	//
+	// 同じURLのプレビューが既に存在する場合は上書きします。
+	// 成功した場合、nilを返します。
+	// 引数に問題がある場合、ArgumentErrorを返します。
+	// DBによるエラーを返すことがあります。
+	SaveLinkPreview(preview *model.LinkPreview) error
+	// GetLinkPreview 指定したURLのプレビューをキャッシュから取得します
+	//
+	// 成功した場合、プレビューとnilを返します。
+	// 存在しない場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	GetLinkPreview(url string) (*model.LinkPreview, error)
+	// GetLinkPreviews 指定したURLのプレビューをキャッシュから取得します
+	//
+	// 成功した場合、キャッシュに存在するプレビューの配列とnilを返します。順番はurlsの順です。
+	// DBによるエラーを返すことがあります。
+	GetLinkPreviews(urls []string) ([]*model.LinkPreview, error)
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"github.com/traPtitech/traQ/model"
+)
+
+// SaveLinkPreview implements LinkPreviewRepository interface.
+func (repo *GormRepository) SaveLinkPreview(preview *model.LinkPreview) error {
+	if preview == nil || len(preview.URL) == 0 {
+		return ArgError("preview", "URL is required")
+	}
+	preview.URLHash = model.LinkPreviewURLHash(preview.URL)
+
+	r := repo.db.Model(&model.LinkPreview{URLHash: preview.URLHash}).Updates(map[string]interface{}{
+		"type":        preview.Type,
+		"title":       preview.Title,
+		"description": preview.Description,
+		"image_url":   preview.ImageURL,
+		"site_name":   preview.SiteName,
+		"failed":      preview.Failed,
+		"fetched_at":  preview.FetchedAt,
+	})
+	if r.Error != nil {
+		return r.Error
+	}
+	if r.RowsAffected == 0 {
+		if err := repo.db.Create(preview).Error; err != nil {
+			if isMySQLDuplicatedRecordErr(err) {
+				// 同時に保存された
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// GetLinkPreview implements LinkPreviewRepository interface.
+func (repo *GormRepository) GetLinkPreview(url string) (*model.LinkPreview, error) {
+	var p model.LinkPreview
+	if err := repo.db.Take(&p, &model.LinkPreview{URLHash: model.LinkPreviewURLHash(url)}).Error; err != nil {
+		return nil, convertError(err)
+	}
+	return &p, nil
+}
+
+// GetLinkPreviews implements LinkPreviewRepository interface.
+func (repo *GormRepository) GetLinkPreviews(urls []string) ([]*model.LinkPreview, error) {
+	result := make([]*model.LinkPreview, 0, len(urls))
+	if len(urls) == 0 {
+		return result, nil
+	}
+	hashes := make([]string, len(urls))
+	for i, u := range urls {
+		hashes[i] = model.LinkPreviewURLHash(u)
+	}
+
+	var previews []*model.LinkPreview
+	if err := repo.db.Where("url_hash IN (?)", hashes).Find(&previews).Error; err != nil {
+		return nil, err
+	}
+	m := make(map[string]*model.LinkPreview, len(previews))
+	for _, p := range previews {
+		m[p.URLHash] = p
+	}
+	for _, h := range hashes {
+		if p, ok := m[h]; ok {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/traPtitech/traQ/model"
+)
+
+func TestRepositoryImpl_SaveLinkPreview(t *testing.T) {
+	t.Parallel()
+	repo, assert, require := setup(t, common)
+
+	assert.True(IsArgError(repo.SaveLinkPreview(nil)))
+	assert.True(IsArgError(repo.SaveLinkPreview(&model.LinkPreview{})))
+
+	url := "https://example.com/" + t.Name()
+	if assert.NoError(repo.SaveLinkPreview(&model.LinkPreview{URL: url, Type: "website", Title: "a", FetchedAt: time.Now()})) {
+		p, err := repo.GetLinkPreview(url)
+		require.NoError(err)
+		assert.Equal("a", p.Title)
+		assert.False(p.Failed)
+	}
+	if assert.NoError(repo.SaveLinkPreview(&model.LinkPreview{URL: url, Failed: true, FetchedAt: time.Now()})) {
+		p, err := repo.GetLinkPreview(url)
+		require.NoError(err)
+		assert.Empty(p.Title)
+		assert.True(p.Failed)
+	}
+}
+
+func TestRepositoryImpl_GetLinkPreview(t *testing.T) {
+	t.Parallel()
+	repo, assert, _ := setup(t, common)
+
+	_, err := repo.GetLinkPreview("https://example.com/not-found")
+	assert.Equal(ErrNotFound, err)
+}
+
+func TestRepositoryImpl_GetLinkPreviews(t *testing.T) {
+	t.Parallel()
+	repo, assert, require := setup(t, common)
+
+	urls := []string{"https://example.com/1/" + t.Name(), "https://example.com/2/" + t.Name(), "https://example.com/3/" + t.Name()}
+	require.NoError(repo.SaveLinkPreview(&model.LinkPreview{URL: urls[2], Title: "3", FetchedAt: time.Now()}))
+	require.NoError(repo.SaveLinkPreview(&model.LinkPreview{URL: urls[0], Title: "1", FetchedAt: time.Now()}))
+
+	previews, err := repo.GetLinkPreviews(urls)
+	if assert.NoError(err) && assert.Len(previews, 2) {
+		assert.Equal(urls[0], previews[0].URL)
+		assert.Equal(urls[2], previews[1].URL)
+	}
+
+	previews, err = repo.GetLinkPreviews(nil)
+	if assert.NoError(err) {
+		assert.Empty(previews)
+	}
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/traPtitech/traQ/model"
+)
+
+// UserMention ユーザーのメンション一覧の要素構造体
+type UserMention struct {
+	MessageID uuid.UUID
+	ChannelID uuid.UUID
+	// GroupID グループのメンションによってメンションされた場合のグループID
+	GroupID   uuid.UUID
+	CreatedAt time.Time
+	// Read メッセージがチャンネルの既読位置以前かどうか
+	Read bool
+	// Message メンションされたメッセージ
+	Message *model.Message `gorm:"-"`
+}
+
+// MentionsQuery GetMentions用クエリ
+type MentionsQuery struct {
+	// User メンションされたユーザーのID
+	User uuid.UUID
+	// Channel メッセージのチャンネルID 指定した場合、そのチャンネルのメンションのみを対象にします
+	Channel uuid.UUID
+	// UnreadOnly trueの場合、未読のメンションのみを対象にします
+	UnreadOnly bool
+	// Before 指定したメッセージのメンションより前のメンションのみを対象にします
+	Before uuid.UUID
+	// Limit 取得する最大件数
+	Limit int
+}
+
+// MentionRepository メンションリポジトリ
+//
+// メンションの既読・未読はチャンネルの既読位置で判定します。
+type MentionRepository interface {
+	// CreateMentions 指定したメッセージでメンションされたユーザーを記録します
+	//
+	// mentionsのキーはメンションされたユーザーのID、値はグループのメンションによる場合のグループID(直接の場合はuuid.Nil)です。
+	// 存在しないユーザーと既に記録されているメンションは無視します。
+	// 成功した場合、nilを返します。
+	// DBによるエラーを返すことがあります。
+	CreateMentions(message *model.Message, mentions map[uuid.UUID]uuid.UUID) error
+	// GetMentions 指定したクエリでメンションを新しい順に取得します
+	//
+	// 成功した場合、メッセージを含むメンションの配列と、Limit件より後にまだメンションが存在するかどうかとnilを返します。
+	// 削除されたメッセージ・チャンネルと、ユーザーがアクセスできないチャンネルのメンションは含まれません。負のlimitは無視されます。
+	// Beforeに指定したユーザーのメンションが存在しないメッセージを指定した場合、ArgumentErrorを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	GetMentions(query MentionsQuery) (mentions []*UserMention, more bool, err error)
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/jinzhu/gorm"
+	"github.com/traPtitech/traQ/model"
+)
+
+// CreateMentions implements MentionRepository interface.
+func (repo *GormRepository) CreateMentions(message *model.Message, mentions map[uuid.UUID]uuid.UUID) error {
+	if len(mentions) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, 0, len(mentions))
+	for id := range mentions {
+		ids = append(ids, id)
+	}
+
+	return repo.transact(func(tx *gorm.DB) error {
+		var users []uuid.UUID
+		if err := tx.Model(&model.User{}).Where("id IN (?)", ids).Pluck("id", &users).Error; err != nil {
+			return err
+		}
+		for _, id := range users {
+			if err := tx.Exec("INSERT IGNORE INTO mentions (user_id, message_id, channel_id, group_id, created_at) VALUES (?, ?, ?, ?, ?)",
+				id, message.ID, message.ChannelID, mentions[id], message.CreatedAt).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetMentions implements MentionRepository interface.
+func (repo *GormRepository) GetMentions(query MentionsQuery) (mentions []*UserMention, more bool, err error) {
+	if query.User == uuid.Nil {
+		return nil, false, ErrNilID
+	}
+	mentions = make([]*UserMention, 0)
+
+	tx := repo.db.
+		Table("mentions").
+		Select("mentions.message_id, mentions.channel_id, mentions.group_id, mentions.created_at, (crp.last_read_at IS NOT NULL AND mentions.created_at <= crp.last_read_at) AS `read`").
+		Joins("INNER JOIN messages ON messages.id = mentions.message_id AND messages.deleted_at IS NULL").
+		Joins("INNER JOIN channels ON channels.id = mentions.channel_id AND channels.deleted_at IS NULL").
+		Joins("LEFT JOIN channel_read_pointers crp ON crp.user_id = mentions.user_id AND crp.channel_id = mentions.channel_id").
+		Where("mentions.user_id = ?", query.User).
+		// 退出したプライベートチャンネルのメンションは含めない
+		Where("channels.is_public = TRUE OR EXISTS (SELECT 1 FROM users_private_channels upc WHERE upc.channel_id = mentions.channel_id AND upc.user_id = mentions.user_id)")
+	if query.Channel != uuid.Nil {
+		tx = tx.Where("mentions.channel_id = ?", query.Channel)
+	}
+	if query.UnreadOnly {
+		tx = tx.Where("crp.last_read_at IS NULL OR mentions.created_at > crp.last_read_at")
+	}
+	if query.Before != uuid.Nil {
+		var c model.Mention
+		if err := repo.db.Where(&model.Mention{UserID: query.User, MessageID: query.Before}).Take(&c).Error; err != nil {
+			if gorm.IsRecordNotFoundError(err) {
+				return nil, false, ArgError("query.Before", "the Mention is not found")
+			}
+			return nil, false, err
+		}
+		tx = tx.Where("mentions.created_at < ? OR (mentions.created_at = ? AND mentions.message_id < ?)", c.CreatedAt, c.CreatedAt, c.MessageID)
+	}
+	tx = tx.Order("mentions.created_at DESC").Order("mentions.message_id DESC")
+	if query.Limit > 0 {
+		// 続きがあるかどうかを判定するために1件多く取得する
+		tx = tx.Limit(query.Limit + 1)
+	}
+
+	if err := tx.Scan(&mentions).Error; err != nil {
+		return nil, false, err
+	}
+	if query.Limit > 0 && len(mentions) > query.Limit {
+		mentions, more = mentions[:query.Limit], true
+	}
+	if len(mentions) == 0 {
+		return mentions, more, nil
+	}
+
+	ids := make([]uuid.UUID, len(mentions))
+	for i, v := range mentions {
+		ids[i] = v.MessageID
+	}
+	var messages []*model.Message
+	if err := repo.db.Scopes(messagePreloads).Where("id IN (?)", ids).Find(&messages).Error; err != nil {
+		return nil, false, err
+	}
+	messageMap := make(map[uuid.UUID]*model.Message, len(messages))
+	for _, m := range messages {
+		messageMap[m.ID] = m
+	}
+	res := mentions[:0]
+	for _, v := range mentions {
+		// 取得の間に削除されたメッセージは除く
+		if v.Message = messageMap[v.MessageID]; v.Message != nil {
+			res = append(res, v)
+		}
+	}
+	return res, more, nil
+}
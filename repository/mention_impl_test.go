@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/traPtitech/traQ/model"
+)
+
+func TestRepositoryImpl_CreateMentions(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, common)
+
+	user2 := mustMakeUser(t, repo, random)
+	group := mustMakeUserGroup(t, repo, random, user.ID)
+	m := mustMakeMessage(t, repo, user.ID, channel.ID)
+
+	require.NoError(repo.CreateMentions(m, map[uuid.UUID]uuid.UUID{
+		user2.ID:                uuid.Nil,
+		user.ID:                 group.ID,
+		uuid.Must(uuid.NewV4()): uuid.Nil,
+	}))
+	// 既に記録されているメンションは無視する
+	require.NoError(repo.CreateMentions(m, map[uuid.UUID]uuid.UUID{user2.ID: group.ID}))
+
+	r, _, err := repo.GetMentions(MentionsQuery{User: user2.ID})
+	if assert.NoError(err) && assert.Len(r, 1) {
+		assert.Equal(m.ID, r[0].MessageID)
+		assert.Equal(channel.ID, r[0].ChannelID)
+		assert.Equal(uuid.Nil, r[0].GroupID)
+	}
+	r, _, err = repo.GetMentions(MentionsQuery{User: user.ID})
+	if assert.NoError(err) && assert.Len(r, 1) {
+		assert.Equal(group.ID, r[0].GroupID)
+	}
+}
+
+func TestRepositoryImpl_GetMentions(t *testing.T) {
+	t.Parallel()
+	repo, _, _, user, channel := setupWithUserAndChannel(t, common)
+
+	author := mustMakeUser(t, repo, random)
+	channel2 := mustMakeChannel(t, repo, random)
+	messages := make([]*model.Message, 0)
+	for i := 0; i < 3; i++ {
+		m := mustMakeMessage(t, repo, author.ID, channel.ID)
+		if err := repo.CreateMentions(m, map[uuid.UUID]uuid.UUID{user.ID: uuid.Nil}); err != nil {
+			t.Fatal(err)
+		}
+		messages = append(messages, m)
+	}
+	m := mustMakeMessage(t, repo, author.ID, channel2.ID)
+	if err := repo.CreateMentions(m, map[uuid.UUID]uuid.UUID{user.ID: uuid.Nil}); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("nil id", func(t *testing.T) {
+		t.Parallel()
+
+		_, _, err := repo.GetMentions(MentionsQuery{})
+		assert.Equal(t, ErrNilID, err)
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		t.Parallel()
+
+		_, _, err := repo.GetMentions(MentionsQuery{User: user.ID, Before: uuid.Must(uuid.NewV4())})
+		assert.True(t, IsArgError(err))
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		r, more, err := repo.GetMentions(MentionsQuery{User: user.ID, Channel: channel.ID, Limit: 2})
+		if assert.NoError(err) && assert.Len(r, 2) {
+			assert.True(more)
+			assert.Equal(messages[2].ID, r[0].MessageID)
+			assert.Equal(messages[1].ID, r[1].MessageID)
+			assert.False(r[0].Read)
+
+			r, more, err = repo.GetMentions(MentionsQuery{User: user.ID, Channel: channel.ID, Limit: 2, Before: r[1].MessageID})
+			if assert.NoError(err) && assert.Len(r, 1) {
+				assert.False(more)
+				assert.Equal(messages[0].ID, r[0].MessageID)
+			}
+		}
+
+		r, _, err = repo.GetMentions(MentionsQuery{User: user.ID})
+		if assert.NoError(err) {
+			assert.Len(r, 4)
+		}
+	})
+
+	t.Run("read", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		user := mustMakeUser(t, repo, random)
+		m1 := mustMakeMessage(t, repo, author.ID, channel.ID)
+		if err := repo.CreateMentions(m1, map[uuid.UUID]uuid.UUID{user.ID: uuid.Nil}); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.ReadChannel(user.ID, channel.ID); err != nil {
+			t.Fatal(err)
+		}
+		m2 := mustMakeMessage(t, repo, author.ID, channel.ID)
+		if err := repo.CreateMentions(m2, map[uuid.UUID]uuid.UUID{user.ID: uuid.Nil}); err != nil {
+			t.Fatal(err)
+		}
+
+		r, _, err := repo.GetMentions(MentionsQuery{User: user.ID})
+		if assert.NoError(err) && assert.Len(r, 2) {
+			assert.False(r[0].Read)
+			assert.True(r[1].Read)
+		}
+		r, _, err = repo.GetMentions(MentionsQuery{User: user.ID, UnreadOnly: true})
+		if assert.NoError(err) && assert.Len(r, 1) {
+			assert.Equal(m2.ID, r[0].MessageID)
+		}
+	})
+}
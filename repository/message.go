@@ -3,9 +3,77 @@ package repository
 import (
 	"github.com/gofrs/uuid"
 	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/utils/message"
+	"gopkg.in/guregu/null.v3"
 	"time"
 )
 
+// MessagesQuery GetMessages用クエリ
+type MessagesQuery struct {
+	// User 投稿者のユーザーID
+	User uuid.UUID
+	// Channel 投稿先のチャンネルID
+	Channel uuid.UUID
+	// Parent スレッドの親メッセージID 指定した場合、そのスレッドの返信メッセージのみを対象にします
+	Parent uuid.UUID
+	// ExcludeReplies trueの場合、スレッドの返信メッセージを対象にしません
+	ExcludeReplies bool
+	// Since 指定した日時以降に投稿されたメッセージのみを対象にします
+	Since null.Time
+	// Until 指定した日時以前に投稿されたメッセージのみを対象にします
+	Until null.Time
+	// Before 指定したメッセージより前に投稿されたメッセージのみを対象にします
+	Before uuid.UUID
+	// After 指定したメッセージより後に投稿されたメッセージのみを対象にします
+	After uuid.UUID
+	// Limit 取得する最大件数
+	Limit int
+	// Offset 取得するオフセット
+	Offset int
+	// Asc trueの場合、投稿日時の昇順で取得します
+	Asc bool
+}
+
+// MessageSearchQuery SearchMessages用クエリ
+type MessageSearchQuery struct {
+	// Searcher 検索を行うユーザーのID このユーザーがアクセス可能なチャンネルのメッセージのみを対象にします
+	Searcher uuid.UUID
+	// Words 本文に含まれる語句 全てを含むメッセージのみを対象にします
+	Words []string
+	// Channels 投稿先のチャンネルID いずれかのチャンネルに投稿されたメッセージのみを対象にします
+	Channels []uuid.UUID
+	// Users 投稿者のユーザーID いずれかのユーザーが投稿したメッセージのみを対象にします
+	Users []uuid.UUID
+	// Mentions メンションされたユーザー・グループのID 全てがメンションされているメッセージのみを対象にします
+	Mentions []uuid.UUID
+	// Since 指定した日時以降に投稿されたメッセージのみを対象にします
+	Since null.Time
+	// Until 指定した日時以前に投稿されたメッセージのみを対象にします
+	Until null.Time
+	// HasFile trueの場合、ファイルが添付されたメッセージのみを対象にします
+	HasFile bool
+	// Limit 取得する最大件数
+	Limit int
+	// Offset 取得するオフセット
+	Offset int
+}
+
+// MessageCitation メッセージ引用の解決結果
+type MessageCitation struct {
+	// MessageID 引用されたメッセージのID
+	MessageID uuid.UUID
+	// UserID 引用されたメッセージの投稿者のユーザーID
+	UserID uuid.UUID
+	// ChannelID 引用されたメッセージの投稿先チャンネルのID
+	ChannelID uuid.UUID
+	// ChannelPath 引用されたメッセージの投稿先チャンネルのパス
+	ChannelPath string
+	// Excerpt 引用されたメッセージの本文の抜粋
+	Excerpt string
+	// CreatedAt 引用されたメッセージの投稿日時
+	CreatedAt time.Time
+}
+
 // MessageRepository メッセージリポジトリ
 type MessageRepository interface {
 	// CreateMessage メッセージを作成します
@@ -13,14 +81,30 @@ type MessageRepository interface {
 	// 成功した場合、メッセージとnilを返します。
 	// 引数にuuid.Nilを指定するとErrNilIDを返します。
 	// textが空の場合、ArgumentErrorを返します。
+	// textはコンテンツフィルタで伏せ字にされることがあり、拒否された場合は*ContentFilterErrorを返します。
+	// アーカイブされたチャンネルを指定した場合、ErrChannelArchivedを返します。
 	// DBによるエラーを返すことがあります。
 	CreateMessage(userID, channelID uuid.UUID, text string) (*model.Message, error)
+	// CreateThreadReply 指定したメッセージのスレッドに返信メッセージを作成します
+	//
+	// 成功した場合、メッセージとnilを返します。
+	// 返信メッセージは親メッセージと同じチャンネルに作成されます。
+	// 存在しない親メッセージを指定した場合、ErrNotFoundを返します。
+	// 返信メッセージを親メッセージに指定した場合、ArgumentErrorを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// textが空の場合、ArgumentErrorを返します。
+	// textはコンテンツフィルタで伏せ字にされることがあり、拒否された場合は*ContentFilterErrorを返します。
+	// 親メッセージがアーカイブされたチャンネルにある場合、ErrChannelArchivedを返します。
+	// DBによるエラーを返すことがあります。
+	CreateThreadReply(userID, parentID uuid.UUID, text string) (*model.Message, error)
 	// UpdateMessage 指定したメッセージを更新します
 	//
 	// 成功した場合、nilを返します。
 	// textが空の場合、ArgumentErrorを返します。
 	// 存在しないメッセージを指定した場合、ErrNotFoundを返します。
 	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// textはコンテンツフィルタで伏せ字にされることがあり、拒否された場合は*ContentFilterErrorを返します。
+	// アーカイブされたチャンネルのメッセージを指定した場合、ErrChannelArchivedを返します。
 	// DBによるエラーを返すことがあります。
 	UpdateMessage(messageID uuid.UUID, text string) error
 	// DeleteMessage 指定したメッセージを削除します
@@ -28,8 +112,55 @@ type MessageRepository interface {
 	// 成功した場合、nilを返します。
 	// 存在しないメッセージを指定した場合、ErrNotFoundを返します。
 	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// アーカイブされたチャンネルのメッセージを指定した場合、ErrChannelArchivedを返します。
 	// DBによるエラーを返すことがあります。
 	DeleteMessage(messageID uuid.UUID) error
+	// DeleteExpiredMessage 保存期間を過ぎたメッセージを削除します
+	//
+	// DeleteMessageと異なり、アーカイブされたチャンネルのメッセージも削除します。
+	// 成功した場合、nilを返します。
+	// 存在しないメッセージを指定した場合、ErrNotFoundを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	DeleteExpiredMessage(messageID uuid.UUID) error
+	// GetDeletedMessages 削除されたメッセージを削除日時の降順で取得します
+	//
+	// channelID, userIDにuuid.Nil以外を指定した場合、そのチャンネル・ユーザーのメッセージのみを対象にします。
+	// 成功した場合、メッセージの配列とnilを返します。負のoffset, limitは無視されます。
+	// DBによるエラーを返すことがあります。
+	GetDeletedMessages(channelID, userID uuid.UUID, limit, offset int) ([]*model.Message, error)
+	// RestoreMessage 削除されたメッセージを復元します
+	//
+	// 成功した場合、nilを返します。スレッドとチャンネルの最新メッセージは復元後の状態に更新されます。
+	// 削除されていたピン留めは復元されません。
+	// 存在しないメッセージを指定した場合、ErrNotFoundを返します。
+	// 削除されていないメッセージや、チャンネル・親メッセージが削除されているメッセージを指定した場合、ArgumentErrorを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// アーカイブされたチャンネルのメッセージを指定した場合、ErrChannelArchivedを返します。
+	// DBによるエラーを返すことがあります。
+	RestoreMessage(messageID uuid.UUID) error
+	// MoveMessages 指定した範囲のメッセージを別のチャンネルに移動します
+	//
+	// firstIDからlastIDまで(作成日時順、両端を含む)のメッセージとそのスレッドの返信を、IDを保ったままchannelIDのチャンネルに移動します。
+	// 成功した場合、移動したメッセージのIDの配列とnilを返します。両チャンネルの最新メッセージは移動後の状態に更新されます。
+	// 存在しないメッセージを指定した場合、ErrNotFoundを返します。
+	// スレッドの返信や異なるチャンネルのメッセージ、存在しないチャンネル、移動元と同じチャンネル、DMチャンネルを指定した場合、ArgumentErrorを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// 移動元・移動先のどちらかがアーカイブされたチャンネルの場合、ErrChannelArchivedを返します。
+	// DBによるエラーを返すことがあります。
+	MoveMessages(firstID, lastID, channelID uuid.UUID) ([]uuid.UUID, error)
+	// GetMessageCitations 指定したユーザーから見たメッセージ引用を解決します
+	//
+	// 成功した場合、messageIDsの順に解決できた引用の配列とnilを返します。
+	// 存在しないメッセージや、ユーザーがアクセスできないチャンネルのメッセージは結果に含まれません。
+	// DBによるエラーを返すことがあります。
+	GetMessageCitations(userID uuid.UUID, messageIDs []uuid.UUID) ([]*MessageCitation, error)
+	// CanonicalizeMessageEmbeds 指定したユーザーが投稿するメッセージ本文の埋め込みを検証し、表示文字列を現在の名前に書き換えます
+	//
+	// 成功した場合、書き換えた本文と、最初に見つかった無効な埋め込み(無い場合はnil)とnilを返します。
+	// 無効な埋め込みは平文に置き換えます。埋め込み先が存在しない場合や、ユーザーがアクセスできないチャンネル・ファイルの場合は無効です。
+	// DBによるエラーを返すことがあります。
+	CanonicalizeMessageEmbeds(userID uuid.UUID, text string) (string, *message.EmbeddedInfo, error)
 	// GetMessageByID 指定したメッセージを取得します
 	//
 	// 成功した場合、メッセージとnilを返します。
@@ -48,36 +179,27 @@ type MessageRepository interface {
 	// 存在しないユーザーを指定した場合、空配列とnilを返します。
 	// DBによるエラーを返すことがあります。
 	GetMessagesByUserID(userID uuid.UUID, limit, offset int) ([]*model.Message, error)
-	// SetMessageUnread 指定したメッセージを未読にします
+	// GetMessages 指定したクエリでメッセージを取得します
 	//
-	// 成功した場合、nilを返します。
-	// 引数にuuid.Nilを指定するとErrNilIDを返します。
-	// DBによるエラーを返すことがあります。
-	SetMessageUnread(userID, messageID uuid.UUID, noticeable bool) error
-	// GetUnreadMessagesByUserID 指定したユーザーの未読メッセージをすべて取得します
-	//
-	// 成功した場合、メッセージの配列とnilを返します。
-	// 存在しないユーザーを指定した場合、空配列とnilを返します。
+	// 成功した場合、メッセージの配列と、Limit件より後にまだメッセージが存在するかどうかとnilを返します。
+	// 負のoffset, limitは無視されます。
+	// 存在しないメッセージ、またはChannelを指定した場合にそのチャンネル以外のメッセージをBefore, Afterに指定した場合、ArgumentErrorを返します。
 	// DBによるエラーを返すことがあります。
-	GetUnreadMessagesByUserID(userID uuid.UUID) ([]*model.Message, error)
-	// DeleteUnreadsByMessageID 指定したメッセージの未読レコードを全て削除します
+	GetMessages(query MessagesQuery) (messages []*model.Message, more bool, err error)
+	// SearchMessages 指定したクエリでメッセージを検索します
 	//
-	// 成功した場合、nilを返します。
+	// 成功した場合、投稿日時の降順のメッセージの配列と、Limit件より後にまだメッセージが存在するかどうかとnilを返します。
+	// 負のoffset, limitは無視されます。
+	// Searcherがアクセスできないチャンネルのメッセージは含まれません。
 	// 引数にuuid.Nilを指定するとErrNilIDを返します。
 	// DBによるエラーを返すことがあります。
-	DeleteUnreadsByMessageID(messageID uuid.UUID) error
-	// DeleteUnreadsByChannelID 指定したチャンネルに存在する、指定したユーザーの未読レコードをすべて削除します
+	SearchMessages(query MessageSearchQuery) (messages []*model.Message, more bool, err error)
+	// GetThreadParticipantIDs 指定したメッセージのスレッドの参加者のIDを取得します
 	//
-	// 成功した場合、nilを返します。
-	// 引数にuuid.Nilを指定するとErrNilIDを返します。
-	// DBによるエラーを返すことがあります。
-	DeleteUnreadsByChannelID(channelID, userID uuid.UUID) error
-	// GetUserUnreadChannels 指定したユーザーの未読チャンネル一覧を取得します
-	//
-	// 成功した場合、UserUnreadChannelの配列とnilを返します。
-	// 存在しないユーザーを指定した場合、空配列とnilを返します。
+	// 成功した場合、親メッセージの投稿者と返信メッセージの投稿者のUUIDの配列とnilを返します。
+	// 存在しないメッセージを指定した場合、空配列とnilを返します。
 	// DBによるエラーを返すことがあります。
-	GetUserUnreadChannels(userID uuid.UUID) ([]*UserUnreadChannel, error)
+	GetThreadParticipantIDs(parentID uuid.UUID) ([]uuid.UUID, error)
 	// GetChannelLatestMessagesByUserID 指定したユーザーが閲覧可能な全てのパブリックチャンネルの最新のメッセージの一覧を取得します
 	//
 	// 成功した場合、メッセージの配列とnilを返します。負のlimitは無視されます。
@@ -89,13 +211,13 @@ type MessageRepository interface {
 	// 存在しないメッセージを指定した場合、空配列とnilを返します。
 	// DBによるエラーを返すことがあります。
 	GetArchivedMessagesByID(messageID uuid.UUID) ([]*model.ArchivedMessage, error)
-}
-
-// UserUnreadChannel ユーザーの未読チャンネル構造体
-type UserUnreadChannel struct {
-	ChannelID  uuid.UUID `json:"channelId"`
-	Count      int       `json:"count"`
-	Noticeable bool      `json:"noticeable"`
-	Since      time.Time `json:"since"`
-	UpdatedAt  time.Time `json:"updatedAt"`
+	// SetMessageCreatedAt 指定したメッセージの投稿日時を変更します
+	//
+	// 外部サービスからのインポート時に元の投稿日時を保持するために使用します。
+	// メッセージに付いているスタンプの日時も同じ日時に変更します。
+	// 成功した場合、nilを返します。
+	// 存在しないメッセージを指定した場合、ErrNotFoundを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	SetMessageCreatedAt(messageID uuid.UUID, createdAt time.Time) error
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/traPtitech/traQ/utils/message"
 	"strings"
+	"time"
 
 	"github.com/gofrs/uuid"
 	"github.com/jinzhu/gorm"
@@ -21,6 +22,15 @@ func (repo *GormRepository) CreateMessage(userID, channelID uuid.UUID, text stri
 		return nil, ArgError("text", "Text is required")
 	}
 
+	if err := checkChannelNotArchived(repo.db, channelID); err != nil {
+		return nil, err
+	}
+	filtered, err := repo.applyContentFilter(repo.db, channelID, text)
+	if err != nil {
+		return nil, err
+	}
+	text = filtered.Text
+
 	m := &model.Message{
 		ID:        uuid.Must(uuid.NewV4()),
 		UserID:    userID,
@@ -28,10 +38,14 @@ func (repo *GormRepository) CreateMessage(userID, channelID uuid.UUID, text stri
 		Text:      text,
 		Stamps:    []model.MessageStamp{},
 	}
-	err := repo.transact(func(tx *gorm.DB) error {
+	var flagged *model.ModerationCase
+	err = repo.transact(func(tx *gorm.DB) (err error) {
 		if err := tx.Create(m).Error; err != nil {
 			return err
 		}
+		if flagged, err = reportFlaggedMessage(tx, m.ID, filtered.FlaggedBy); err != nil {
+			return err
+		}
 
 		clm := &model.ChannelLatestMessage{
 			ChannelID: m.ChannelID,
@@ -51,20 +65,122 @@ func (repo *GormRepository) CreateMessage(userID, channelID uuid.UUID, text stri
 		return nil, err
 	}
 
-	embedded, plain := message.Parse(text)
+	doc := message.ParseAST(text)
 	repo.hub.Publish(hub.Message{
 		Name: event.MessageCreated,
 		Fields: hub.Fields{
 			"message_id": m.ID,
 			"message":    m,
-			"embedded":   embedded,
-			"plain":      plain,
+			"embedded":   doc.Embedded(),
+			"plain":      doc.SingleLineText(),
+			"document":   doc,
 		},
 	})
 	messagesCounter.Inc()
+	if flagged != nil {
+		repo.publishModerationCaseUpdated(flagged)
+	}
 	return m, nil
 }
 
+// CreateThreadReply implements MessageRepository interface.
+func (repo *GormRepository) CreateThreadReply(userID, parentID uuid.UUID, text string) (*model.Message, error) {
+	if userID == uuid.Nil || parentID == uuid.Nil {
+		return nil, ErrNilID
+	}
+	if len(text) == 0 {
+		return nil, ArgError("text", "Text is required")
+	}
+
+	m := &model.Message{
+		ID:       uuid.Must(uuid.NewV4()),
+		UserID:   userID,
+		ParentID: parentID,
+		Text:     text,
+		Stamps:   []model.MessageStamp{},
+	}
+	var flagged *model.ModerationCase
+	err := repo.transact(func(tx *gorm.DB) error {
+		var parent model.Message
+		if err := tx.Where(&model.Message{ID: parentID}).Take(&parent).Error; err != nil {
+			return convertError(err)
+		}
+		if parent.IsThreadReply() {
+			return ArgError("parentID", "thread replies cannot have replies")
+		}
+
+		m.ChannelID = parent.ChannelID
+		if err := checkChannelNotArchived(tx, m.ChannelID); err != nil {
+			return err
+		}
+		filtered, err := repo.applyContentFilter(tx, m.ChannelID, text)
+		if err != nil {
+			return err
+		}
+		m.Text = filtered.Text
+
+		if err := tx.Create(m).Error; err != nil {
+			return err
+		}
+		if flagged, err = reportFlaggedMessage(tx, m.ID, filtered.FlaggedBy); err != nil {
+			return err
+		}
+		return updateMessageThread(tx, parentID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := message.ParseAST(m.Text)
+	repo.hub.Publish(hub.Message{
+		Name: event.ThreadReplyCreated,
+		Fields: hub.Fields{
+			"message_id": m.ID,
+			"message":    m,
+			"parent_id":  parentID,
+			"embedded":   doc.Embedded(),
+			"plain":      doc.SingleLineText(),
+			"document":   doc,
+		},
+	})
+	messagesCounter.Inc()
+	if flagged != nil {
+		repo.publishModerationCaseUpdated(flagged)
+	}
+	return m, nil
+}
+
+// updateMessageThread スレッドの返信数と最新の返信を更新します
+func updateMessageThread(tx *gorm.DB, parentID uuid.UUID) error {
+	count := 0
+	if err := tx.Model(&model.Message{}).Where(&model.Message{ParentID: parentID}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return tx.Where(&model.MessageThread{ParentID: parentID}).Delete(&model.MessageThread{}).Error
+	}
+
+	var latest model.Message
+	if err := tx.Where(&model.Message{ParentID: parentID}).Order("created_at DESC").Take(&latest).Error; err != nil {
+		return err
+	}
+	t := &model.MessageThread{
+		ParentID:          parentID,
+		ReplyCount:        count,
+		LatestReplyID:     latest.ID,
+		LatestReplyUserID: latest.UserID,
+		LatestReplyAt:     latest.CreatedAt,
+	}
+	r := tx.Model(&model.MessageThread{ParentID: parentID}).Updates(t)
+	if r.Error != nil {
+		return r.Error
+	}
+	if r.RowsAffected == 0 {
+		return tx.Create(t).Error
+	}
+	return nil
+}
+
 // UpdateMessage implements MessageRepository interface.
 func (repo *GormRepository) UpdateMessage(messageID uuid.UUID, text string) error {
 	if messageID == uuid.Nil {
@@ -75,14 +191,23 @@ func (repo *GormRepository) UpdateMessage(messageID uuid.UUID, text string) erro
 	}
 
 	var (
-		old model.Message
-		new model.Message
-		ok  bool
+		old     model.Message
+		new     model.Message
+		ok      bool
+		flagged *model.ModerationCase
 	)
 	err := repo.transact(func(tx *gorm.DB) error {
 		if err := tx.First(&old, &model.Message{ID: messageID}).Error; err != nil {
 			return convertError(err)
 		}
+		if err := checkChannelNotArchived(tx, old.ChannelID); err != nil {
+			return err
+		}
+
+		filtered, err := repo.applyContentFilter(tx, old.ChannelID, text)
+		if err != nil {
+			return err
+		}
 
 		// archiving
 		if err := tx.Create(&model.ArchivedMessage{
@@ -96,7 +221,10 @@ func (repo *GormRepository) UpdateMessage(messageID uuid.UUID, text string) erro
 		}
 
 		// update
-		if err := tx.Model(&old).Update("text", text).Error; err != nil {
+		if err := tx.Model(&old).Updates(map[string]interface{}{"text": filtered.Text, "edit_count": gorm.Expr("edit_count + 1")}).Error; err != nil {
+			return err
+		}
+		if flagged, err = reportFlaggedMessage(tx, messageID, filtered.FlaggedBy); err != nil {
 			return err
 		}
 
@@ -116,49 +244,459 @@ func (repo *GormRepository) UpdateMessage(messageID uuid.UUID, text string) erro
 			},
 		})
 	}
+	if flagged != nil {
+		repo.publishModerationCaseUpdated(flagged)
+	}
 	return nil
 }
 
 // DeleteMessage implements MessageRepository interface.
 func (repo *GormRepository) DeleteMessage(messageID uuid.UUID) error {
+	return repo.deleteMessage(messageID, false)
+}
+
+// DeleteExpiredMessage implements MessageRepository interface.
+func (repo *GormRepository) DeleteExpiredMessage(messageID uuid.UUID) error {
+	return repo.deleteMessage(messageID, true)
+}
+
+// deleteMessage 指定したメッセージを削除します
+//
+// ignoreArchivedがfalseの場合、アーカイブされたチャンネルのメッセージはErrChannelArchivedを返して削除しません。
+func (repo *GormRepository) deleteMessage(messageID uuid.UUID, ignoreArchived bool) error {
 	if messageID == uuid.Nil {
 		return ErrNilID
 	}
 
+	var m *model.Message
+	err := repo.transact(func(tx *gorm.DB) (err error) {
+		m, err = deleteMessage(tx, messageID, ignoreArchived)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	repo.publishMessageDeleted(m)
+	return nil
+}
+
+// deleteMessage トランザクション内でメッセージを削除し、削除したメッセージを返します
+//
+// イベントは発行しないため、コミット後にpublishMessageDeletedを呼び出してください。
+func deleteMessage(tx *gorm.DB, messageID uuid.UUID, ignoreArchived bool) (*model.Message, error) {
+	var m model.Message
+	if err := tx.Where(&model.Message{ID: messageID}).First(&m).Error; err != nil {
+		return nil, convertError(err)
+	}
+	if !ignoreArchived {
+		if err := checkChannelNotArchived(tx, m.ChannelID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Delete(&m).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where(&model.Pin{MessageID: messageID}).Delete(model.Pin{}).Error; err != nil {
+		return nil, err
+	}
+	if m.IsThreadReply() {
+		if err := updateMessageThread(tx, m.ParentID); err != nil {
+			return nil, err
+		}
+	}
+	return &m, nil
+}
+
+func (repo *GormRepository) publishMessageDeleted(m *model.Message) {
+	repo.hub.Publish(hub.Message{
+		Name: event.MessageDeleted,
+		Fields: hub.Fields{
+			"message_id": m.ID,
+			"message":    m,
+		},
+	})
+}
+
+// GetDeletedMessages implements MessageRepository interface.
+func (repo *GormRepository) GetDeletedMessages(channelID, userID uuid.UUID, limit, offset int) ([]*model.Message, error) {
+	messages := make([]*model.Message, 0)
+	tx := repo.db.Unscoped().Where("messages.deleted_at IS NOT NULL")
+	if channelID != uuid.Nil {
+		tx = tx.Where("messages.channel_id = ?", channelID)
+	}
+	if userID != uuid.Nil {
+		tx = tx.Where("messages.user_id = ?", userID)
+	}
+	err := tx.
+		Scopes(limitAndOffset(limit, offset)).
+		Order("messages.deleted_at DESC").
+		Find(&messages).
+		Error
+	return messages, err
+}
+
+// RestoreMessage implements MessageRepository interface.
+func (repo *GormRepository) RestoreMessage(messageID uuid.UUID) error {
+	if messageID == uuid.Nil {
+		return ErrNilID
+	}
+
+	var m model.Message
+	err := repo.transact(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where(&model.Message{ID: messageID}).First(&m).Error; err != nil {
+			return convertError(err)
+		}
+		if m.DeletedAt == nil {
+			return ArgError("messageID", "the message is not deleted")
+		}
+		if ok, err := dbExists(tx, &model.Channel{ID: m.ChannelID}); err != nil {
+			return err
+		} else if !ok {
+			return ArgError("messageID", "the channel of the message has been deleted")
+		}
+		if err := checkChannelNotArchived(tx, m.ChannelID); err != nil {
+			return err
+		}
+		if m.IsThreadReply() {
+			if ok, err := dbExists(tx, &model.Message{ID: m.ParentID}); err != nil {
+				return err
+			} else if !ok {
+				return ArgError("messageID", "the parent message has been deleted")
+			}
+		}
+
+		if err := tx.Unscoped().Model(&m).UpdateColumn("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		m.DeletedAt = nil
+		if m.IsThreadReply() {
+			return updateMessageThread(tx, m.ParentID)
+		}
+		return updateChannelLatestMessage(tx, m.ChannelID)
+	})
+	if err != nil {
+		return err
+	}
+
+	doc := message.ParseAST(m.Text)
+	repo.hub.Publish(hub.Message{
+		Name: event.MessageRestored,
+		Fields: hub.Fields{
+			"message_id": m.ID,
+			"message":    &m,
+			"embedded":   doc.Embedded(),
+			"plain":      doc.SingleLineText(),
+			"document":   doc,
+		},
+	})
+	return nil
+}
+
+// MoveMessages implements MessageRepository interface.
+func (repo *GormRepository) MoveMessages(firstID, lastID, channelID uuid.UUID) ([]uuid.UUID, error) {
+	if firstID == uuid.Nil || lastID == uuid.Nil || channelID == uuid.Nil {
+		return nil, ErrNilID
+	}
+
 	var (
-		m  model.Message
-		ok bool
+		fromChannelID uuid.UUID
+		ids           []uuid.UUID
 	)
 	err := repo.transact(func(tx *gorm.DB) error {
-		if err := tx.Where(&model.Message{ID: messageID}).First(&m).Error; err != nil {
+		var first, last model.Message
+		if err := tx.Where(&model.Message{ID: firstID}).First(&first).Error; err != nil {
 			return convertError(err)
 		}
+		if err := tx.Where(&model.Message{ID: lastID}).First(&last).Error; err != nil {
+			return convertError(err)
+		}
+		if first.IsThreadReply() || last.IsThreadReply() {
+			return ArgError("firstID", "thread replies cannot be moved individually")
+		}
+		if first.ChannelID != last.ChannelID {
+			return ArgError("lastID", "the messages must be in the same channel")
+		}
+		if last.CreatedAt.Before(first.CreatedAt) {
+			return ArgError("lastID", "the last message must not be older than the first message")
+		}
+		if first.ChannelID == channelID {
+			return ArgError("channelID", "the messages are already in the channel")
+		}
+		fromChannelID = first.ChannelID
 
-		if err := tx.Delete(&m).Error; err != nil {
+		var from, to model.Channel
+		if err := tx.Where(&model.Channel{ID: fromChannelID}).First(&from).Error; err != nil {
+			return convertError(err)
+		}
+		if err := tx.Where(&model.Channel{ID: channelID}).First(&to).Error; err != nil {
+			if gorm.IsRecordNotFoundError(err) {
+				return ArgError("channelID", "the Channel is not found")
+			}
 			return err
 		}
-		if err := tx.Where(&model.Unread{MessageID: messageID}).Delete(model.Unread{}).Error; err != nil {
+		if from.IsDMChannel() || to.IsDMChannel() {
+			return ArgError("channelID", "direct message channels are not allowed")
+		}
+		if from.IsArchived || to.IsArchived {
+			return ErrChannelArchived
+		}
+
+		// 削除済みのメッセージも復元時の整合性のため一緒に移動する
+		var parents []*model.Message
+		if err := tx.Unscoped().
+			Where("channel_id = ? AND parent_id = ? AND created_at BETWEEN ? AND ?", fromChannelID, uuid.Nil, first.CreatedAt, last.CreatedAt).
+			Find(&parents).
+			Error; err != nil {
 			return err
 		}
-		if err := tx.Where(&model.Pin{MessageID: messageID}).Delete(model.Pin{}).Error; err != nil {
+		parentIDs := make([]uuid.UUID, len(parents))
+		for i, m := range parents {
+			parentIDs[i] = m.ID
+		}
+		var replies []*model.Message
+		if err := tx.Unscoped().Where("parent_id IN (?)", parentIDs).Find(&replies).Error; err != nil {
 			return err
 		}
-		ok = true
-		return nil
+
+		var latest time.Time
+		for _, m := range append(parents, replies...) {
+			ids = append(ids, m.ID)
+			if m.CreatedAt.After(latest) {
+				latest = m.CreatedAt
+			}
+		}
+
+		// updated_atは変更しない
+		if err := tx.Unscoped().Model(&model.Message{}).Where("id IN (?)", ids).UpdateColumn("channel_id", channelID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Mention{}).Where("message_id IN (?)", ids).UpdateColumn("channel_id", channelID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Poll{}).Where("message_id IN (?)", ids).UpdateColumn("channel_id", channelID).Error; err != nil {
+			return err
+		}
+		// 移動元チャンネルに紐付いた添付ファイルは、移動先チャンネルのメンバーが閲覧できるようにする
+		var fileIDs []uuid.UUID
+		for _, m := range append(parents, replies...) {
+			for _, e := range message.ParseAST(m.Text).Embedded() {
+				if e.Type != "file" {
+					continue
+				}
+				if fid, err := uuid.FromString(e.ID); err == nil {
+					fileIDs = append(fileIDs, fid)
+				}
+			}
+		}
+		if len(fileIDs) > 0 {
+			if err := tx.Model(&model.File{}).Where("id IN (?) AND channel_id = ?", fileIDs, fromChannelID).UpdateColumn("channel_id", channelID).Error; err != nil {
+				return err
+			}
+		}
+		if err := updateChannelLatestMessage(tx, fromChannelID); err != nil {
+			return err
+		}
+		if err := updateChannelLatestMessage(tx, channelID); err != nil {
+			return err
+		}
+
+		// 移動したメッセージの通知が含まれ得る移動元チャンネルの通知カウントを、残ったメッセージから数え直す
+		return recountUnreadCounters(tx, fromChannelID, latest)
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if ok {
-		repo.hub.Publish(hub.Message{
-			Name: event.MessageDeleted,
-			Fields: hub.Fields{
-				"message_id": messageID,
-				"message":    &m,
-			},
+
+	repo.hub.Publish(hub.Message{
+		Name: event.MessageMoved,
+		Fields: hub.Fields{
+			"message_ids":     ids,
+			"from_channel_id": fromChannelID,
+			"to_channel_id":   channelID,
+		},
+	})
+	return ids, nil
+}
+
+// messageCitationExcerptLength メッセージ引用の抜粋の最大文字数
+const messageCitationExcerptLength = 100
+
+// GetMessageCitations implements MessageRepository interface.
+func (repo *GormRepository) GetMessageCitations(userID uuid.UUID, messageIDs []uuid.UUID) ([]*MessageCitation, error) {
+	res := make([]*MessageCitation, 0, len(messageIDs))
+	for _, id := range messageIDs {
+		m, err := repo.GetMessageByID(id)
+		if err != nil {
+			if err == ErrNotFound {
+				continue
+			}
+			return nil, err
+		}
+		if ok, err := repo.IsChannelAccessibleToUser(userID, m.ChannelID); err != nil {
+			return nil, err
+		} else if !ok {
+			continue
+		}
+		path, err := repo.GetChannelPath(m.ChannelID)
+		if err != nil {
+			return nil, err
+		}
+
+		excerpt := []rune(message.ParseAST(m.Text).SingleLineText())
+		if len(excerpt) > messageCitationExcerptLength {
+			excerpt = excerpt[:messageCitationExcerptLength]
+		}
+		res = append(res, &MessageCitation{
+			MessageID:   m.ID,
+			UserID:      m.UserID,
+			ChannelID:   m.ChannelID,
+			ChannelPath: path,
+			Excerpt:     string(excerpt),
+			CreatedAt:   m.CreatedAt,
 		})
 	}
-	return nil
+	return res, nil
+}
+
+// CanonicalizeMessageEmbeds implements MessageRepository interface.
+func (repo *GormRepository) CanonicalizeMessageEmbeds(userID uuid.UUID, text string) (string, *message.EmbeddedInfo, error) {
+	var (
+		invalid *message.EmbeddedInfo
+		dbErr   error
+	)
+	res := message.ReplaceEmbeds(text, func(token string, info *message.EmbeddedInfo) string {
+		if dbErr != nil {
+			return token
+		}
+		raw, ok, err := repo.resolveEmbedRaw(userID, info)
+		switch {
+		case err != nil:
+			dbErr = err
+			return token
+		case !ok:
+			if invalid == nil {
+				invalid = info
+			}
+			return info.Raw
+		case raw == info.Raw:
+			return token
+		default:
+			info.Raw = raw
+			return info.String()
+		}
+	})
+	if dbErr != nil {
+		return "", nil, dbErr
+	}
+	return res, invalid, nil
+}
+
+// resolveEmbedRaw 指定したユーザーから見た埋め込みの現在の表示文字列を返します
+//
+// 埋め込み先が存在しない、またはユーザーがアクセスできない場合はokにfalseを返します。
+// 検証対象外の種類の埋め込みはそのままの表示文字列を返します。
+func (repo *GormRepository) resolveEmbedRaw(userID uuid.UUID, info *message.EmbeddedInfo) (raw string, ok bool, err error) {
+	switch info.Type {
+	case "user", "group", "channel", "file", "stamp":
+	default:
+		return info.Raw, true, nil
+	}
+	id, err := uuid.FromString(info.ID)
+	if err != nil || id == uuid.Nil {
+		return "", false, nil
+	}
+	notFound := func(err error) (string, bool, error) {
+		if err == ErrNotFound {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+
+	switch info.Type {
+	case "user":
+		u, err := repo.GetUser(id)
+		if err != nil {
+			return notFound(err)
+		}
+		if u.Status == model.UserAccountStatusDeactivated {
+			return "", false, nil
+		}
+		return "@" + u.Name, true, nil
+	case "group":
+		g, err := repo.GetUserGroup(id)
+		if err != nil {
+			return notFound(err)
+		}
+		return "@" + g.Name, true, nil
+	case "channel":
+		ch, err := repo.GetChannel(id)
+		if err != nil {
+			return notFound(err)
+		}
+		if ch.IsDMChannel() {
+			return "", false, nil
+		}
+		// アクセスできないチャンネルの名前を漏らさない
+		if ok, err := repo.IsChannelAccessibleToUser(userID, id); err != nil {
+			return "", false, err
+		} else if !ok {
+			return "", false, nil
+		}
+		path, err := repo.GetChannelPath(id)
+		if err != nil {
+			return notFound(err)
+		}
+		return "#" + path, true, nil
+	case "file":
+		f, err := repo.GetFileMeta(id)
+		if err != nil {
+			return notFound(err)
+		}
+		// アクセスできないファイルの名前を漏らさない
+		if ok, err := repo.IsFileAccessible(id, userID); err != nil {
+			return notFound(err)
+		} else if !ok {
+			return "", false, nil
+		}
+		return f.Name, true, nil
+	case "stamp":
+		s, err := repo.GetStamp(id)
+		if err != nil {
+			return notFound(err)
+		}
+		return ":" + s.Name + ":", true, nil
+	}
+	return "", false, nil
+}
+
+// updateChannelLatestMessage チャンネルの最新メッセージを現在のメッセージから再計算します
+func updateChannelLatestMessage(tx *gorm.DB, channelID uuid.UUID) error {
+	var latest model.Message
+	err := tx.
+		Where(&model.Message{ChannelID: channelID}).
+		Where("parent_id = ?", uuid.Nil).
+		Order("created_at DESC").
+		Take(&latest).
+		Error
+	if gorm.IsRecordNotFoundError(err) {
+		return tx.Where(&model.ChannelLatestMessage{ChannelID: channelID}).Delete(&model.ChannelLatestMessage{}).Error
+	} else if err != nil {
+		return err
+	}
+
+	clm := &model.ChannelLatestMessage{
+		ChannelID: channelID,
+		MessageID: latest.ID,
+		DateTime:  latest.CreatedAt,
+	}
+	// 変更が無い場合にRowsAffectedが0になるため、存在確認してから更新する
+	if ok, err := dbExists(tx, &model.ChannelLatestMessage{ChannelID: channelID}); err != nil {
+		return err
+	} else if !ok {
+		return tx.Create(clm).Error
+	}
+	return tx.Model(&model.ChannelLatestMessage{ChannelID: channelID}).Updates(clm).Error
 }
 
 // GetMessageByID implements MessageRepository interface.
@@ -203,65 +741,166 @@ func (repo *GormRepository) GetMessagesByUserID(userID uuid.UUID, limit, offset
 	return arr, err
 }
 
-// SetMessageUnread implements MessageRepository interface.
-func (repo *GormRepository) SetMessageUnread(userID, messageID uuid.UUID, noticeable bool) error {
-	if userID == uuid.Nil || messageID == uuid.Nil {
-		return ErrNilID
+// GetMessages implements MessageRepository interface.
+func (repo *GormRepository) GetMessages(query MessagesQuery) (messages []*model.Message, more bool, err error) {
+	messages = make([]*model.Message, 0)
+
+	tx := repo.db.Scopes(messagePreloads)
+	if query.Channel != uuid.Nil {
+		tx = tx.Where("messages.channel_id = ?", query.Channel)
+	}
+	if query.User != uuid.Nil {
+		tx = tx.Where("messages.user_id = ?", query.User)
+	}
+	if query.Parent != uuid.Nil {
+		tx = tx.Where("messages.parent_id = ?", query.Parent)
+	} else if query.ExcludeReplies {
+		tx = tx.Where("messages.parent_id = ?", uuid.Nil)
+	}
+	if query.Since.Valid {
+		tx = tx.Where("messages.created_at >= ?", query.Since.Time)
+	}
+	if query.Until.Valid {
+		tx = tx.Where("messages.created_at <= ?", query.Until.Time)
+	}
+	if query.Before != uuid.Nil {
+		c, err := repo.getMessageCursor(query.Before, query.Channel)
+		if err != nil {
+			return nil, false, err
+		}
+		tx = tx.Where("messages.created_at < ? OR (messages.created_at = ? AND messages.id < ?)", c.CreatedAt, c.CreatedAt, c.ID)
+	}
+	if query.After != uuid.Nil {
+		c, err := repo.getMessageCursor(query.After, query.Channel)
+		if err != nil {
+			return nil, false, err
+		}
+		tx = tx.Where("messages.created_at > ? OR (messages.created_at = ? AND messages.id > ?)", c.CreatedAt, c.CreatedAt, c.ID)
 	}
-	var u model.Unread
-	return repo.db.Assign(map[string]bool{"noticeable": noticeable}).FirstOrCreate(&u, &model.Unread{UserID: userID, MessageID: messageID}).Error
-}
 
-// GetUnreadMessagesByUserID implements MessageRepository interface.
-func (repo *GormRepository) GetUnreadMessagesByUserID(userID uuid.UUID) (unreads []*model.Message, err error) {
-	unreads = make([]*model.Message, 0)
-	if userID == uuid.Nil {
-		return unreads, nil
+	if query.Asc {
+		tx = tx.Order("messages.created_at").Order("messages.id")
+	} else {
+		tx = tx.Order("messages.created_at DESC").Order("messages.id DESC")
 	}
-	err = repo.db.
-		Joins("INNER JOIN unreads ON unreads.message_id = messages.id AND unreads.user_id = ?", userID.String()).
-		Order("messages.created_at").
-		Find(&unreads).
-		Error
-	return unreads, err
+	if query.Offset > 0 {
+		tx = tx.Offset(query.Offset)
+	}
+	if query.Limit > 0 {
+		// 続きがあるかどうかを判定するために1件多く取得する
+		tx = tx.Limit(query.Limit + 1)
+	}
+
+	if err := tx.Find(&messages).Error; err != nil {
+		return nil, false, err
+	}
+	if query.Limit > 0 && len(messages) > query.Limit {
+		return messages[:query.Limit], true, nil
+	}
+	return messages, false, nil
 }
 
-// DeleteUnreadsByMessageID implements MessageRepository interface.
-func (repo *GormRepository) DeleteUnreadsByMessageID(messageID uuid.UUID) error {
-	if messageID == uuid.Nil {
-		return ErrNilID
+// SearchMessages implements MessageRepository interface.
+func (repo *GormRepository) SearchMessages(query MessageSearchQuery) (messages []*model.Message, more bool, err error) {
+	if query.Searcher == uuid.Nil {
+		return nil, false, ErrNilID
+	}
+	messages = make([]*model.Message, 0)
+
+	tx := repo.db.
+		Scopes(messagePreloads).
+		Joins("INNER JOIN channels ON channels.id = messages.channel_id AND channels.deleted_at IS NULL").
+		Where("channels.is_public = TRUE OR messages.channel_id IN (SELECT users_private_channels.channel_id FROM users_private_channels WHERE users_private_channels.user_id = ?)", query.Searcher)
+
+	if against := makeFullTextQuery(query.Words); len(against) > 0 {
+		tx = tx.Where("MATCH (messages.text) AGAINST (? IN BOOLEAN MODE)", against)
+	}
+	if len(query.Channels) > 0 {
+		tx = tx.Where("messages.channel_id IN (?)", query.Channels)
+	}
+	if len(query.Users) > 0 {
+		tx = tx.Where("messages.user_id IN (?)", query.Users)
+	}
+	for _, id := range query.Mentions {
+		tx = tx.Where("messages.text REGEXP ?", `"id"[[:space:]]*:[[:space:]]*"`+id.String()+`"`)
+	}
+	if query.Since.Valid {
+		tx = tx.Where("messages.created_at >= ?", query.Since.Time)
+	}
+	if query.Until.Valid {
+		tx = tx.Where("messages.created_at <= ?", query.Until.Time)
+	}
+	if query.HasFile {
+		tx = tx.Where("messages.text REGEXP ?", `"type"[[:space:]]*:[[:space:]]*"file"`)
+	}
+
+	tx = tx.Order("messages.created_at DESC").Order("messages.id DESC")
+	if query.Offset > 0 {
+		tx = tx.Offset(query.Offset)
+	}
+	if query.Limit > 0 {
+		// 続きがあるかどうかを判定するために1件多く取得する
+		tx = tx.Limit(query.Limit + 1)
+	}
+
+	if err := tx.Find(&messages).Error; err != nil {
+		return nil, false, err
+	}
+	if query.Limit > 0 && len(messages) > query.Limit {
+		return messages[:query.Limit], true, nil
 	}
-	return repo.db.Where(&model.Unread{MessageID: messageID}).Delete(model.Unread{}).Error
+	return messages, false, nil
 }
 
-// GetUserUnreadChannels implements MessageRepository interface.
-func (repo *GormRepository) GetUserUnreadChannels(userID uuid.UUID) ([]*UserUnreadChannel, error) {
-	res := make([]*UserUnreadChannel, 0)
-	if userID == uuid.Nil {
-		return res, nil
+// makeFullTextQuery 語句の配列から全文検索(BOOLEAN MODE)用のクエリを生成する
+func makeFullTextQuery(words []string) string {
+	var sb strings.Builder
+	for _, w := range words {
+		// ダブルクォートはフレーズの区切りになるため除去する
+		w = strings.TrimSpace(strings.Replace(w, `"`, "", -1))
+		if len(w) == 0 {
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(`+"`)
+		sb.WriteString(w)
+		sb.WriteByte('"')
 	}
-	return res, repo.db.Raw(`SELECT m.channel_id AS channel_id, COUNT(m.id) AS count, MAX(u.noticeable) AS noticeable, MIN(m.created_at) AS since, MAX(m.created_at) AS updated_at FROM unreads u JOIN messages m on u.message_id = m.id WHERE u.user_id = ? GROUP BY m.channel_id`, userID).Scan(&res).Error
+	return sb.String()
 }
 
-// DeleteUnreadsByChannelID implements MessageRepository interface.
-func (repo *GormRepository) DeleteUnreadsByChannelID(channelID, userID uuid.UUID) error {
-	if channelID == uuid.Nil || userID == uuid.Nil {
-		return ErrNilID
+// getMessageCursor カーソルとして指定されたメッセージを取得する
+//
+// channelIDが指定されている場合、そのチャンネルのメッセージでなければArgumentErrorを返します。
+func (repo *GormRepository) getMessageCursor(messageID, channelID uuid.UUID) (*model.Message, error) {
+	var m model.Message
+	// 削除されたメッセージもカーソルとして使えるようにする
+	if err := repo.db.Unscoped().Select("id, channel_id, created_at").Where(&model.Message{ID: messageID}).Take(&m).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, ArgError("cursor", "the message doesn't exist")
+		}
+		return nil, err
 	}
-	result := repo.db.Exec("DELETE unreads FROM unreads INNER JOIN messages ON unreads.user_id = ? AND unreads.message_id = messages.id WHERE messages.channel_id = ?", userID, channelID)
-	if result.Error != nil {
-		return result.Error
+	if channelID != uuid.Nil && m.ChannelID != channelID {
+		return nil, ArgError("cursor", "the message doesn't belong to the channel")
 	}
-	if result.RowsAffected > 0 {
-		repo.hub.Publish(hub.Message{
-			Name: event.ChannelRead,
-			Fields: hub.Fields{
-				"channel_id": channelID,
-				"user_id":    userID,
-			},
-		})
+	return &m, nil
+}
+
+// GetThreadParticipantIDs implements MessageRepository interface.
+func (repo *GormRepository) GetThreadParticipantIDs(parentID uuid.UUID) (users []uuid.UUID, err error) {
+	users = make([]uuid.UUID, 0)
+	if parentID == uuid.Nil {
+		return users, nil
 	}
-	return nil
+	err = repo.db.
+		Model(&model.Message{}).
+		Where("id = ? OR parent_id = ?", parentID, parentID).
+		Pluck("DISTINCT user_id", &users).
+		Error
+	return users, err
 }
 
 // GetChannelLatestMessagesByUserID implements MessageRepository interface.
@@ -270,7 +909,7 @@ func (repo *GormRepository) GetChannelLatestMessagesByUserID(userID uuid.UUID, l
 	switch {
 	case subscribeOnly:
 		query = `
-SELECT m.id, m.user_id, m.channel_id, m.text, m.created_at, m.updated_at, m.deleted_at
+SELECT m.id, m.user_id, m.channel_id, m.parent_id, m.text, m.edit_count, m.created_at, m.updated_at, m.deleted_at
 FROM channel_latest_messages clm
          INNER JOIN users_subscribe_channels s ON clm.channel_id = s.channel_id
          INNER JOIN messages m ON clm.message_id = m.id
@@ -284,7 +923,7 @@ ORDER BY clm.date_time DESC
 		query = strings.Replace(query, "USER_ID", userID.String(), -1)
 	default:
 		query = `
-SELECT m.id, m.user_id, m.channel_id, m.text, m.created_at, m.updated_at, m.deleted_at
+SELECT m.id, m.user_id, m.channel_id, m.parent_id, m.text, m.edit_count, m.created_at, m.updated_at, m.deleted_at
 FROM channel_latest_messages clm
          INNER JOIN messages m ON clm.message_id = m.id
          INNER JOIN channels c ON clm.channel_id = c.id
@@ -317,10 +956,39 @@ func (repo *GormRepository) GetArchivedMessagesByID(messageID uuid.UUID) ([]*mod
 	return r, err
 }
 
+// SetMessageCreatedAt implements MessageRepository interface.
+func (repo *GormRepository) SetMessageCreatedAt(messageID uuid.UUID, createdAt time.Time) error {
+	if messageID == uuid.Nil {
+		return ErrNilID
+	}
+	return repo.transact(func(tx *gorm.DB) error {
+		var m model.Message
+		if err := tx.Take(&m, &model.Message{ID: messageID}).Error; err != nil {
+			return convertError(err)
+		}
+
+		times := map[string]interface{}{"created_at": createdAt, "updated_at": createdAt}
+		if err := tx.Model(&model.Message{ID: messageID}).UpdateColumns(times).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.MessageStamp{}).Where(&model.MessageStamp{MessageID: messageID}).UpdateColumns(times).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.ChannelLatestMessage{}).Where(&model.ChannelLatestMessage{MessageID: messageID}).UpdateColumn("date_time", createdAt).Error; err != nil {
+			return err
+		}
+		if m.IsThreadReply() {
+			return updateMessageThread(tx, m.ParentID)
+		}
+		return nil
+	})
+}
+
 func messagePreloads(db *gorm.DB) *gorm.DB {
 	return db.
 		Preload("Stamps", func(db *gorm.DB) *gorm.DB {
 			return db.Order("updated_at")
 		}).
-		Preload("Pin")
+		Preload("Pin").
+		Preload("Thread")
 }
@@ -1,10 +1,12 @@
 package repository
 
 import (
+	"bytes"
 	"github.com/gofrs/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/traPtitech/traQ/model"
 	"testing"
+	"time"
 )
 
 func TestRepositoryImpl_CreateMessage(t *testing.T) {
@@ -56,6 +58,7 @@ func TestRepositoryImpl_UpdateMessage(t *testing.T) {
 	m, err := repo.GetMessageByID(m.ID)
 	if assert.NoError(err) {
 		assert.Equal("new message", m.Text)
+		assert.Equal(1, m.EditCount)
 		assert.Equal(1, count(t, getDB(repo).Model(&model.ArchivedMessage{}).Where(&model.ArchivedMessage{MessageID: m.ID, Text: originalText})))
 	}
 }
@@ -75,6 +78,283 @@ func TestRepositoryImpl_DeleteMessage(t *testing.T) {
 	assert.EqualError(repo.DeleteMessage(m.ID), ErrNotFound.Error())
 }
 
+func TestRepositoryImpl_GetDeletedMessages(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, common)
+
+	user2 := mustMakeUser(t, repo, random)
+	m1 := mustMakeMessage(t, repo, user.ID, channel.ID)
+	m2 := mustMakeMessage(t, repo, user2.ID, channel.ID)
+	mustMakeMessage(t, repo, user.ID, channel.ID)
+	require.NoError(repo.DeleteMessage(m1.ID))
+	require.NoError(repo.DeleteMessage(m2.ID))
+
+	r, err := repo.GetDeletedMessages(channel.ID, uuid.Nil, 0, 0)
+	if assert.NoError(err) && assert.Len(r, 2) {
+		assert.Equal(m2.ID, r[0].ID)
+		assert.Equal(m1.ID, r[1].ID)
+		assert.NotNil(r[0].DeletedAt)
+	}
+
+	r, err = repo.GetDeletedMessages(channel.ID, user2.ID, 0, 0)
+	if assert.NoError(err) && assert.Len(r, 1) {
+		assert.Equal(m2.ID, r[0].ID)
+	}
+
+	r, err = repo.GetDeletedMessages(channel.ID, uuid.Nil, 1, 1)
+	if assert.NoError(err) && assert.Len(r, 1) {
+		assert.Equal(m1.ID, r[0].ID)
+	}
+}
+
+func TestRepositoryImpl_RestoreMessage(t *testing.T) {
+	t.Parallel()
+	repo, _, _, user, channel := setupWithUserAndChannel(t, common)
+
+	t.Run("nil id", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, ErrNilID, repo.RestoreMessage(uuid.Nil))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, ErrNotFound, repo.RestoreMessage(uuid.Must(uuid.NewV4())))
+	})
+
+	t.Run("not deleted", func(t *testing.T) {
+		t.Parallel()
+
+		m := mustMakeMessage(t, repo, user.ID, channel.ID)
+		assert.True(t, IsArgError(repo.RestoreMessage(m.ID)))
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		assert, require := assertAndRequire(t)
+
+		ch := mustMakeChannel(t, repo, random)
+		m := mustMakeMessage(t, repo, user.ID, ch.ID)
+		require.NoError(repo.DeleteMessage(m.ID))
+
+		if assert.NoError(repo.RestoreMessage(m.ID)) {
+			r, err := repo.GetMessageByID(m.ID)
+			if assert.NoError(err) {
+				assert.Nil(r.DeletedAt)
+			}
+			var clm model.ChannelLatestMessage
+			if assert.NoError(repo.(*GormRepository).db.Where(&model.ChannelLatestMessage{ChannelID: ch.ID}).Take(&clm).Error) {
+				assert.Equal(m.ID, clm.MessageID)
+			}
+		}
+	})
+
+	t.Run("thread reply", func(t *testing.T) {
+		t.Parallel()
+		assert, require := assertAndRequire(t)
+
+		parent := mustMakeMessage(t, repo, user.ID, channel.ID)
+		reply, err := repo.CreateThreadReply(user.ID, parent.ID, "reply")
+		require.NoError(err)
+		require.NoError(repo.DeleteMessage(reply.ID))
+
+		if assert.NoError(repo.RestoreMessage(reply.ID)) {
+			p, err := repo.GetMessageByID(parent.ID)
+			if assert.NoError(err) && assert.NotNil(p.Thread) {
+				assert.Equal(1, p.Thread.ReplyCount)
+				assert.Equal(reply.ID, p.Thread.LatestReplyID)
+			}
+		}
+
+		require.NoError(repo.DeleteMessage(reply.ID))
+		require.NoError(repo.DeleteMessage(parent.ID))
+		assert.True(IsArgError(repo.RestoreMessage(reply.ID)))
+	})
+}
+
+func TestRepositoryImpl_MoveMessages(t *testing.T) {
+	t.Parallel()
+	repo, _, _, user, channel := setupWithUserAndChannel(t, common)
+
+	t.Run("nil id", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := repo.MoveMessages(uuid.Nil, uuid.Nil, channel.ID)
+		assert.Equal(t, ErrNilID, err)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+
+		id := uuid.Must(uuid.NewV4())
+		_, err := repo.MoveMessages(id, id, channel.ID)
+		assert.Equal(t, ErrNotFound, err)
+	})
+
+	t.Run("same channel", func(t *testing.T) {
+		t.Parallel()
+
+		m := mustMakeMessage(t, repo, user.ID, channel.ID)
+		_, err := repo.MoveMessages(m.ID, m.ID, channel.ID)
+		assert.True(t, IsArgError(err))
+	})
+
+	t.Run("channel not found", func(t *testing.T) {
+		t.Parallel()
+
+		m := mustMakeMessage(t, repo, user.ID, channel.ID)
+		_, err := repo.MoveMessages(m.ID, m.ID, uuid.Must(uuid.NewV4()))
+		assert.True(t, IsArgError(err))
+	})
+
+	t.Run("thread reply", func(t *testing.T) {
+		t.Parallel()
+		assert, require := assertAndRequire(t)
+
+		parent := mustMakeMessage(t, repo, user.ID, channel.ID)
+		reply, err := repo.CreateThreadReply(user.ID, parent.ID, "reply")
+		require.NoError(err)
+		_, err = repo.MoveMessages(reply.ID, reply.ID, mustMakeChannel(t, repo, random).ID)
+		assert.True(IsArgError(err))
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		assert, require := assertAndRequire(t)
+
+		from := mustMakeChannel(t, repo, random)
+		to := mustMakeChannel(t, repo, random)
+		remain := mustMakeMessage(t, repo, user.ID, from.ID)
+		m1 := mustMakeMessage(t, repo, user.ID, from.ID)
+		reply, err := repo.CreateThreadReply(user.ID, m1.ID, "reply")
+		require.NoError(err)
+		m2 := mustMakeMessage(t, repo, user.ID, from.ID)
+
+		ids, err := repo.MoveMessages(m1.ID, m2.ID, to.ID)
+		if assert.NoError(err) {
+			assert.ElementsMatch([]uuid.UUID{m1.ID, m2.ID, reply.ID}, ids)
+			for _, id := range ids {
+				m, err := repo.GetMessageByID(id)
+				if assert.NoError(err) {
+					assert.Equal(to.ID, m.ChannelID)
+				}
+			}
+			var clm model.ChannelLatestMessage
+			if assert.NoError(repo.(*GormRepository).db.Where(&model.ChannelLatestMessage{ChannelID: from.ID}).Take(&clm).Error) {
+				assert.Equal(remain.ID, clm.MessageID)
+			}
+			if assert.NoError(repo.(*GormRepository).db.Where(&model.ChannelLatestMessage{ChannelID: to.ID}).Take(&clm).Error) {
+				assert.Equal(m2.ID, clm.MessageID)
+			}
+		}
+	})
+
+	t.Run("unread counters", func(t *testing.T) {
+		t.Parallel()
+		assert, require := assertAndRequire(t)
+
+		reader := mustMakeUser(t, repo, random)
+		from := mustMakeChannel(t, repo, random)
+		to := mustMakeChannel(t, repo, random)
+		moved := mustMakeMessage(t, repo, user.ID, from.ID)
+		remain := mustMakeMessage(t, repo, user.ID, from.ID)
+		for _, m := range []*model.Message{moved, remain} {
+			require.NoError(repo.CreateMentions(m, map[uuid.UUID]uuid.UUID{reader.ID: uuid.Nil}))
+			require.NoError(repo.IncrementUnreadCounters(from.ID, m.CreatedAt, map[uuid.UUID]bool{reader.ID: true}))
+		}
+
+		_, err := repo.MoveMessages(moved.ID, moved.ID, to.ID)
+		require.NoError(err)
+
+		// 移動していないメッセージのメンションは残る
+		p, err := repo.GetReadPointer(reader.ID, from.ID)
+		if assert.NoError(err) {
+			assert.Equal(1, p.MentionCount)
+			assert.Equal(1, p.NotifiedCount)
+			if assert.NotNil(p.FirstNotifiedAt) && assert.NotNil(p.LastNotifiedAt) {
+				assert.True(remain.CreatedAt.Equal(*p.FirstNotifiedAt))
+				assert.True(remain.CreatedAt.Equal(*p.LastNotifiedAt))
+			}
+		}
+	})
+
+	t.Run("channel file", func(t *testing.T) {
+		t.Parallel()
+		assert, require := assertAndRequire(t)
+
+		member := mustMakeUser(t, repo, random)
+		from := mustMakePrivateChannel(t, repo, random, []uuid.UUID{user.ID})
+		to := mustMakePrivateChannel(t, repo, random, []uuid.UUID{user.ID, member.ID})
+		buf := bytes.NewBufferString("test")
+		f, err := repo.SaveChannelFile("test.txt", buf, int64(buf.Len()), "", model.FileTypeUserFile, user.ID, from.ID)
+		require.NoError(err)
+		m, err := repo.CreateMessage(user.ID, from.ID, `!{"raw":"test.txt","type":"file","id":"`+f.ID.String()+`"}`)
+		require.NoError(err)
+
+		_, err = repo.MoveMessages(m.ID, m.ID, to.ID)
+		require.NoError(err)
+
+		// 移動先チャンネルのメンバーが添付ファイルを閲覧できる
+		moved, err := repo.GetFileMeta(f.ID)
+		if assert.NoError(err) {
+			assert.Equal(to.ID, moved.ChannelID)
+		}
+		ok, err := repo.IsFileAccessible(f.ID, member.ID)
+		if assert.NoError(err) {
+			assert.True(ok)
+		}
+	})
+}
+
+func TestRepositoryImpl_GetMessageCitations(t *testing.T) {
+	t.Parallel()
+	repo, assert, _, user, channel := setupWithUserAndChannel(t, common)
+
+	other := mustMakeUser(t, repo, random)
+	private := mustMakePrivateChannel(t, repo, random, []uuid.UUID{user.ID})
+	m1 := mustMakeMessage(t, repo, user.ID, channel.ID)
+	m2 := mustMakeMessage(t, repo, user.ID, private.ID)
+	ids := []uuid.UUID{m2.ID, uuid.Must(uuid.NewV4()), m1.ID}
+
+	r, err := repo.GetMessageCitations(user.ID, ids)
+	if assert.NoError(err) && assert.Len(r, 2) {
+		assert.Equal(m2.ID, r[0].MessageID)
+		assert.Equal(private.ID, r[0].ChannelID)
+		assert.Equal(m1.ID, r[1].MessageID)
+		assert.Equal(user.ID, r[1].UserID)
+		assert.Equal(channel.Name, r[1].ChannelPath)
+		assert.Equal(m1.Text, r[1].Excerpt)
+	}
+
+	r, err = repo.GetMessageCitations(other.ID, ids)
+	if assert.NoError(err) && assert.Len(r, 1) {
+		assert.Equal(m1.ID, r[0].MessageID)
+	}
+}
+
+func TestRepositoryImpl_CanonicalizeMessageEmbeds(t *testing.T) {
+	t.Parallel()
+	repo, assert, _, user, channel := setupWithUserAndChannel(t, common)
+
+	other := mustMakeUser(t, repo, random)
+	private := mustMakePrivateChannel(t, repo, random, []uuid.UUID{user.ID})
+	text := `!{"raw":"#a","type":"channel","id":"` + channel.ID.String() + `"} !{"raw":"#b","type":"channel","id":"` + private.ID.String() + `"}`
+
+	res, invalid, err := repo.CanonicalizeMessageEmbeds(user.ID, text)
+	if assert.NoError(err) {
+		assert.Nil(invalid)
+		assert.Equal(`!{"raw":"#`+channel.Name+`","type":"channel","id":"`+channel.ID.String()+`"} !{"raw":"#`+private.Name+`","type":"channel","id":"`+private.ID.String()+`"}`, res)
+	}
+
+	// アクセスできないチャンネルは無効として平文にする
+	res, invalid, err = repo.CanonicalizeMessageEmbeds(other.ID, text)
+	if assert.NoError(err) && assert.NotNil(invalid) {
+		assert.Equal(private.ID.String(), invalid.ID)
+		assert.Equal(`!{"raw":"#`+channel.Name+`","type":"channel","id":"`+channel.ID.String()+`"} #b`, res)
+	}
+}
+
 func TestRepositoryImpl_GetMessagesByChannelID(t *testing.T) {
 	t.Parallel()
 	repo, assert, _, user, channel := setupWithUserAndChannel(t, common)
@@ -141,93 +421,6 @@ func TestRepositoryImpl_GetMessageByID(t *testing.T) {
 	assert.Error(err)
 }
 
-func TestRepositoryImpl_SetMessageUnread(t *testing.T) {
-	t.Parallel()
-	repo, assert, _, user, channel := setupWithUserAndChannel(t, common)
-
-	m := mustMakeMessage(t, repo, user.ID, channel.ID)
-
-	assert.Error(repo.SetMessageUnread(uuid.Nil, m.ID, true))
-	assert.Error(repo.SetMessageUnread(user.ID, uuid.Nil, true))
-	if assert.NoError(repo.SetMessageUnread(user.ID, m.ID, true)) {
-		assert.Equal(1, count(t, getDB(repo).Model(model.Unread{}).Where(model.Unread{UserID: user.ID})))
-	}
-	assert.NoError(repo.SetMessageUnread(user.ID, m.ID, true))
-}
-
-func TestRepositoryImpl_GetUnreadMessagesByUserID(t *testing.T) {
-	t.Parallel()
-	repo, assert, _, user, channel := setupWithUserAndChannel(t, common)
-
-	for i := 0; i < 10; i++ {
-		mustMakeMessageUnread(t, repo, user.ID, mustMakeMessage(t, repo, user.ID, channel.ID).ID)
-	}
-
-	if unreads, err := repo.GetUnreadMessagesByUserID(user.ID); assert.NoError(err) {
-		assert.Len(unreads, 10)
-	}
-	if unreads, err := repo.GetUnreadMessagesByUserID(uuid.Nil); assert.NoError(err) {
-		assert.Len(unreads, 0)
-	}
-}
-
-func TestRepositoryImpl_DeleteUnreadsByMessageID(t *testing.T) {
-	t.Parallel()
-	repo, _, _, user, channel := setupWithUserAndChannel(t, common)
-
-	testMessage := mustMakeMessage(t, repo, user.ID, channel.ID)
-	testMessage2 := mustMakeMessage(t, repo, user.ID, channel.ID)
-	for i := 0; i < 10; i++ {
-		mustMakeMessageUnread(t, repo, mustMakeUser(t, repo, random).ID, testMessage.ID)
-		mustMakeMessageUnread(t, repo, mustMakeUser(t, repo, random).ID, testMessage2.ID)
-	}
-
-	t.Run("nil id", func(t *testing.T) {
-		t.Parallel()
-
-		assert.EqualError(t, repo.DeleteUnreadsByMessageID(uuid.Nil), ErrNilID.Error())
-	})
-
-	t.Run("success", func(t *testing.T) {
-		t.Parallel()
-		assert := assert.New(t)
-
-		if assert.NoError(repo.DeleteUnreadsByMessageID(testMessage.ID)) {
-			assert.Equal(0, count(t, getDB(repo).Model(model.Unread{}).Where(&model.Unread{MessageID: testMessage.ID})))
-		}
-		if assert.NoError(repo.DeleteUnreadsByMessageID(testMessage2.ID)) {
-			assert.Equal(0, count(t, getDB(repo).Model(model.Unread{}).Where(&model.Unread{MessageID: testMessage2.ID})))
-		}
-	})
-}
-
-func TestRepositoryImpl_DeleteUnreadsByChannelID(t *testing.T) {
-	t.Parallel()
-	repo, _, _, user, channel := setupWithUserAndChannel(t, common)
-
-	creator := mustMakeUser(t, repo, random)
-	channel2 := mustMakeChannel(t, repo, random)
-	testMessage := mustMakeMessage(t, repo, creator.ID, channel.ID)
-	testMessage2 := mustMakeMessage(t, repo, creator.ID, channel2.ID)
-	mustMakeMessageUnread(t, repo, user.ID, testMessage.ID)
-	mustMakeMessageUnread(t, repo, user.ID, testMessage2.ID)
-
-	t.Run("nil id", func(t *testing.T) {
-		t.Parallel()
-
-		assert.EqualError(t, repo.DeleteUnreadsByChannelID(channel.ID, uuid.Nil), ErrNilID.Error())
-		assert.EqualError(t, repo.DeleteUnreadsByChannelID(uuid.Nil, user.ID), ErrNilID.Error())
-	})
-
-	t.Run("success", func(t *testing.T) {
-		t.Parallel()
-		assert := assert.New(t)
-		if assert.NoError(repo.DeleteUnreadsByChannelID(channel.ID, user.ID)) {
-			assert.Equal(1, count(t, getDB(repo).Model(model.Unread{}).Where(&model.Unread{UserID: user.ID})))
-		}
-	})
-}
-
 func TestRepositoryImpl_GetChannelLatestMessagesByUserID(t *testing.T) {
 	t.Parallel()
 	repo, _, require, user := setupWithUser(t, ex1)
@@ -243,6 +436,8 @@ func TestRepositoryImpl_GetChannelLatestMessagesByUserID(t *testing.T) {
 		}
 		latests = append(latests, mustMakeMessage(t, repo, user.ID, ch.ID).ID)
 	}
+	edited := latests[0]
+	require.NoError(repo.UpdateMessage(edited, "edited"))
 
 	t.Run("SubTest1", func(t *testing.T) {
 		t.Parallel()
@@ -252,6 +447,9 @@ func TestRepositoryImpl_GetChannelLatestMessagesByUserID(t *testing.T) {
 		derefs := make([]uuid.UUID, len(arr))
 		for i := range arr {
 			derefs[i] = arr[i].ID
+			if arr[i].ID == edited {
+				assert.Equal(1, arr[i].EditCount)
+			}
 		}
 		if assert.NoError(err) {
 			assert.ElementsMatch(derefs, latests)
@@ -328,3 +526,216 @@ func TestRepositoryImpl_GetArchivedMessagesByID(t *testing.T) {
 		}
 	})
 }
+
+func TestRepositoryImpl_GetMessages(t *testing.T) {
+	t.Parallel()
+	repo, _, _, user, channel := setupWithUserAndChannel(t, common)
+
+	messages := make([]*model.Message, 10)
+	for i := 0; i < 10; i++ {
+		messages[i] = mustMakeMessage(t, repo, user.ID, channel.ID)
+	}
+
+	t.Run("limit", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		r, more, err := repo.GetMessages(MessagesQuery{Channel: channel.ID, Limit: 3})
+		if assert.NoError(err) && assert.Len(r, 3) {
+			assert.True(more)
+			assert.Equal(messages[9].ID, r[0].ID)
+		}
+
+		r, more, err = repo.GetMessages(MessagesQuery{Channel: channel.ID, Limit: 10})
+		if assert.NoError(err) {
+			assert.Len(r, 10)
+			assert.False(more)
+		}
+	})
+
+	t.Run("cursor", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		r, more, err := repo.GetMessages(MessagesQuery{Channel: channel.ID, Before: messages[5].ID, Limit: 2})
+		if assert.NoError(err) && assert.Len(r, 2) {
+			assert.True(more)
+			assert.Equal(messages[4].ID, r[0].ID)
+			assert.Equal(messages[3].ID, r[1].ID)
+		}
+
+		r, more, err = repo.GetMessages(MessagesQuery{Channel: channel.ID, After: messages[5].ID, Asc: true})
+		if assert.NoError(err) && assert.Len(r, 4) {
+			assert.False(more)
+			assert.Equal(messages[6].ID, r[0].ID)
+		}
+
+		_, _, err = repo.GetMessages(MessagesQuery{Channel: channel.ID, Before: uuid.Must(uuid.NewV4())})
+		assert.True(IsArgError(err))
+
+		// 他のチャンネルのメッセージはカーソルに使えない
+		other := mustMakeMessage(t, repo, user.ID, mustMakeChannel(t, repo, random).ID)
+		_, _, err = repo.GetMessages(MessagesQuery{Channel: channel.ID, Before: other.ID})
+		assert.True(IsArgError(err))
+		_, _, err = repo.GetMessages(MessagesQuery{Channel: channel.ID, After: other.ID})
+		assert.True(IsArgError(err))
+	})
+
+	t.Run("user", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		r, _, err := repo.GetMessages(MessagesQuery{User: user.ID})
+		if assert.NoError(err) {
+			assert.Len(r, 10)
+		}
+	})
+}
+
+func TestRepositoryImpl_SearchMessages(t *testing.T) {
+	t.Parallel()
+	repo, _, require, user, channel := setupWithUserAndChannel(t, ex2)
+
+	other := mustMakeUser(t, repo, random)
+	private := mustMakePrivateChannel(t, repo, random, []uuid.UUID{other.ID})
+
+	m1, err := repo.CreateMessage(user.ID, channel.ID, "トラップの部室")
+	require.NoError(err)
+	m2, err := repo.CreateMessage(other.ID, channel.ID, `部室 !{"raw":"@user","type":"user","id":"`+user.ID.String()+`"}`)
+	require.NoError(err)
+	_, err = repo.CreateMessage(other.ID, private.ID, "部室の鍵")
+	require.NoError(err)
+
+	t.Run("nil id", func(t *testing.T) {
+		t.Parallel()
+
+		_, _, err := repo.SearchMessages(MessageSearchQuery{})
+		assert.EqualError(t, err, ErrNilID.Error())
+	})
+
+	t.Run("words", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		r, more, err := repo.SearchMessages(MessageSearchQuery{Searcher: user.ID, Words: []string{"部室"}, Channels: []uuid.UUID{channel.ID, private.ID}})
+		if assert.NoError(err) && assert.Len(r, 2) {
+			assert.False(more)
+			assert.Equal(m2.ID, r[0].ID)
+			assert.Equal(m1.ID, r[1].ID)
+		}
+
+		r, _, err = repo.SearchMessages(MessageSearchQuery{Searcher: other.ID, Words: []string{"部室"}, Channels: []uuid.UUID{channel.ID, private.ID}})
+		if assert.NoError(err) {
+			assert.Len(r, 3)
+		}
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		r, _, err := repo.SearchMessages(MessageSearchQuery{Searcher: user.ID, Channels: []uuid.UUID{channel.ID}, Users: []uuid.UUID{other.ID}, Mentions: []uuid.UUID{user.ID}})
+		if assert.NoError(err) && assert.Len(r, 1) {
+			assert.Equal(m2.ID, r[0].ID)
+		}
+	})
+}
+
+func TestRepositoryImpl_CreateThreadReply(t *testing.T) {
+	t.Parallel()
+	repo, _, require, user, channel := setupWithUserAndChannel(t, common)
+
+	parent := mustMakeMessage(t, repo, user.ID, channel.ID)
+	reply, err := repo.CreateThreadReply(user.ID, parent.ID, "reply")
+	require.NoError(err)
+
+	t.Run("failures", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := repo.CreateThreadReply(user.ID, parent.ID, "")
+		assert.Error(t, err)
+
+		_, err = repo.CreateThreadReply(user.ID, uuid.Nil, "a")
+		assert.EqualError(t, err, ErrNilID.Error())
+
+		_, err = repo.CreateThreadReply(user.ID, uuid.Must(uuid.NewV4()), "a")
+		assert.EqualError(t, err, ErrNotFound.Error())
+
+		_, err = repo.CreateThreadReply(user.ID, reply.ID, "a")
+		assert.True(t, IsArgError(err))
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		m, err := repo.CreateThreadReply(user.ID, parent.ID, "test")
+		if assert.NoError(err) {
+			assert.Equal(parent.ID, m.ParentID)
+			assert.Equal(channel.ID, m.ChannelID)
+		}
+
+		p, err := repo.GetMessageByID(parent.ID)
+		if assert.NoError(err) && assert.NotNil(p.Thread) {
+			assert.True(p.Thread.ReplyCount >= 2)
+		}
+	})
+}
+
+func TestRepositoryImpl_GetThreadParticipantIDs(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, common)
+
+	other := mustMakeUser(t, repo, random)
+	parent := mustMakeMessage(t, repo, user.ID, channel.ID)
+	for i := 0; i < 3; i++ {
+		_, err := repo.CreateThreadReply(other.ID, parent.ID, "reply")
+		require.NoError(err)
+	}
+
+	ids, err := repo.GetThreadParticipantIDs(parent.ID)
+	if assert.NoError(err) {
+		assert.ElementsMatch([]uuid.UUID{user.ID, other.ID}, ids)
+	}
+
+	ids, err = repo.GetThreadParticipantIDs(uuid.Nil)
+	if assert.NoError(err) {
+		assert.Len(ids, 0)
+	}
+}
+
+func TestRepositoryImpl_SetMessageCreatedAt(t *testing.T) {
+	t.Parallel()
+	repo, assert, _, user, channel := setupWithUserAndChannel(t, common)
+
+	createdAt := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := mustMakeMessage(t, repo, user.ID, channel.ID)
+	s := mustMakeStamp(t, repo, random, user.ID)
+	mustAddMessageStamp(t, repo, m.ID, s.ID, user.ID)
+
+	assert.EqualError(repo.SetMessageCreatedAt(uuid.Nil, createdAt), ErrNilID.Error())
+	assert.EqualError(repo.SetMessageCreatedAt(uuid.Must(uuid.NewV4()), createdAt), ErrNotFound.Error())
+
+	if assert.NoError(repo.SetMessageCreatedAt(m.ID, createdAt)) {
+		m, err := repo.GetMessageByID(m.ID)
+		if assert.NoError(err) {
+			assert.True(createdAt.Equal(m.CreatedAt))
+			assert.True(createdAt.Equal(m.UpdatedAt))
+			if assert.Len(m.Stamps, 1) {
+				assert.True(createdAt.Equal(m.Stamps[0].CreatedAt))
+			}
+		}
+	}
+
+	t.Run("ThreadReply", func(t *testing.T) {
+		t.Parallel()
+		parent := mustMakeMessage(t, repo, user.ID, channel.ID)
+		reply, err := repo.CreateThreadReply(user.ID, parent.ID, "reply")
+		if assert.NoError(err) && assert.NoError(repo.SetMessageCreatedAt(reply.ID, createdAt)) {
+			m, err := repo.GetMessageByID(parent.ID)
+			if assert.NoError(err) && assert.NotNil(m.Thread) {
+				assert.True(createdAt.Equal(m.Thread.LatestReplyAt))
+			}
+		}
+	})
+}
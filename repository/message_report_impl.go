@@ -2,6 +2,7 @@ package repository
 
 import (
 	"github.com/gofrs/uuid"
+	"github.com/jinzhu/gorm"
 	"github.com/traPtitech/traQ/model"
 )
 
@@ -19,12 +20,22 @@ func (repo *GormRepository) CreateMessageReport(messageID, reporterID uuid.UUID,
 		Reporter:  reporterID,
 		Reason:    reason,
 	}
-	if err := repo.db.Create(r).Error; err != nil {
-		if isMySQLDuplicatedRecordErr(err) {
-			return ErrAlreadyExists
+	var c *model.ModerationCase
+	err := repo.transact(func(tx *gorm.DB) error {
+		if err := tx.Create(r).Error; err != nil {
+			if isMySQLDuplicatedRecordErr(err) {
+				return ErrAlreadyExists
+			}
+			return err
 		}
+		var err error
+		c, err = openModerationCase(tx, messageID, reporterID, reason)
+		return err
+	})
+	if err != nil {
 		return err
 	}
+	repo.publishModerationCaseUpdated(c)
 	return nil
 }
 
@@ -11,12 +11,14 @@ type MessageStampRepository interface {
 	//
 	// 成功した場合、そのメッセージスタンプとnilを返します。
 	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// アーカイブされたチャンネルのメッセージを指定した場合、ErrChannelArchivedを返します。
 	// DBによるエラーを返すことがあります。
 	AddStampToMessage(messageID, stampID, userID uuid.UUID) (ms *model.MessageStamp, err error)
 	// RemoveStampFromMessage 指定したメッセージから指定したユーザーの指定したスタンプを全て削除します
 	//
 	// 成功した、或いは既に削除されていた場合、nilを返します。
 	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// アーカイブされたチャンネルのメッセージを指定した場合、ErrChannelArchivedを返します。
 	// DBによるエラーを返すことがあります。
 	RemoveStampFromMessage(messageID, stampID, userID uuid.UUID) (err error)
 	// GetMessageStamps 指定したメッセージのスタンプを全て取得します
@@ -12,6 +12,9 @@ func (repo *GormRepository) AddStampToMessage(messageID, stampID, userID uuid.UU
 	if messageID == uuid.Nil || stampID == uuid.Nil || userID == uuid.Nil {
 		return nil, ErrNilID
 	}
+	if err := checkMessageChannelNotArchived(repo.db, messageID); err != nil {
+		return nil, err
+	}
 
 	err = repo.db.
 		Set("gorm:insert_option", "ON DUPLICATE KEY UPDATE count = count + 1, updated_at = now()").
@@ -44,6 +47,9 @@ func (repo *GormRepository) RemoveStampFromMessage(messageID, stampID, userID uu
 	if messageID == uuid.Nil || stampID == uuid.Nil || userID == uuid.Nil {
 		return ErrNilID
 	}
+	if err := checkMessageChannelNotArchived(repo.db, messageID); err != nil {
+		return err
+	}
 	result := repo.db.Delete(&model.MessageStamp{MessageID: messageID, StampID: stampID, UserID: userID})
 	if result.Error != nil {
 		return result.Error
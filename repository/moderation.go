@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/traPtitech/traQ/model"
+	"gopkg.in/guregu/null.v3"
+)
+
+// UpdateModerationCaseArgs モデレーションケース更新引数
+type UpdateModerationCaseArgs struct {
+	State      null.String
+	AssigneeID uuid.NullUUID
+}
+
+// ModerationRepository モデレーションリポジトリ
+//
+// モデレーションケースはメッセージが通報された時に作成され、同じメッセージへの通報は1つのケースにまとめられます。
+type ModerationRepository interface {
+	// GetModerationCases モデレーションケースを更新日時の降順で取得します
+	//
+	// stateに空文字列を指定した場合、全ての状態のケースを取得します。
+	// 成功した場合、ケースの配列とnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetModerationCases(state model.ModerationCaseState, offset, limit int) ([]*model.ModerationCase, error)
+	// GetModerationCase 指定したモデレーションケースを取得します
+	//
+	// 成功した場合、ケースとnilを返します。
+	// 存在しないケースを指定した場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	GetModerationCase(id uuid.UUID) (*model.ModerationCase, error)
+	// GetModerationCaseByMessageID 指定したメッセージのモデレーションケースを取得します
+	//
+	// 成功した場合、ケースとnilを返します。
+	// ケースが存在しない場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	GetModerationCaseByMessageID(messageID uuid.UUID) (*model.ModerationCase, error)
+	// UpdateModerationCase 指定したモデレーションケースの状態・担当者を変更します
+	//
+	// 成功した場合、nilを返します。変更内容は監査ログに記録されます。
+	// 存在しないケースを指定した場合、ErrNotFoundを返します。
+	// 状態や担当者が不正な場合、ArgumentErrorを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	UpdateModerationCase(id, actorID uuid.UUID, args UpdateModerationCaseArgs) error
+	// AddModerationNote 指定したモデレーションケースにメモを残します
+	//
+	// 成功した場合、メモの監査ログとnilを返します。
+	// 存在しないケースを指定した場合、ErrNotFoundを返します。
+	// メモが空の場合、ArgumentErrorを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	AddModerationNote(caseID, actorID uuid.UUID, note string) (*model.ModerationLog, error)
+	// TakeModerationAction 指定したモデレーションケースのメッセージ・投稿者に対処を実行します
+	//
+	// 成功した場合、nilを返します。実行した対処は監査ログに記録され、ケースは対処済みになります。
+	// 対処と監査ログの記録は同じトランザクションで行われるため、記録に失敗した場合は対処も行われません。
+	// 存在しないケースを指定した場合、ErrNotFoundを返します。
+	// 対処が不正な場合や、既に削除されたメッセージを削除しようとした場合、ArgumentErrorを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	TakeModerationAction(caseID, actorID uuid.UUID, action model.ModerationActionType, comment string) error
+	// GetModerationLogs 指定したモデレーションケースの監査ログを作成日時の昇順で全て取得します
+	//
+	// 成功した場合、監査ログの配列とnilを返します。
+	// 存在しないケースを指定した場合は空配列とnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetModerationLogs(caseID uuid.UUID) ([]*model.ModerationLog, error)
+}
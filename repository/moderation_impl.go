@@ -0,0 +1,284 @@
+package repository
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/jinzhu/gorm"
+	"github.com/leandro-lugaresi/hub"
+	"github.com/traPtitech/traQ/event"
+	"github.com/traPtitech/traQ/model"
+	"time"
+)
+
+// GetModerationCases implements ModerationRepository interface.
+func (repo *GormRepository) GetModerationCases(state model.ModerationCaseState, offset, limit int) (arr []*model.ModerationCase, err error) {
+	arr = make([]*model.ModerationCase, 0)
+	tx := repo.db
+	if len(state) > 0 {
+		tx = tx.Where(&model.ModerationCase{State: state})
+	}
+	err = tx.Scopes(limitAndOffset(limit, offset)).Order("updated_at DESC").Find(&arr).Error
+	return arr, err
+}
+
+// GetModerationCase implements ModerationRepository interface.
+func (repo *GormRepository) GetModerationCase(id uuid.UUID) (*model.ModerationCase, error) {
+	if id == uuid.Nil {
+		return nil, ErrNotFound
+	}
+	c := &model.ModerationCase{}
+	if err := repo.db.Where(&model.ModerationCase{ID: id}).First(c).Error; err != nil {
+		return nil, convertError(err)
+	}
+	return c, nil
+}
+
+// GetModerationCaseByMessageID implements ModerationRepository interface.
+func (repo *GormRepository) GetModerationCaseByMessageID(messageID uuid.UUID) (*model.ModerationCase, error) {
+	if messageID == uuid.Nil {
+		return nil, ErrNotFound
+	}
+	c := &model.ModerationCase{}
+	if err := repo.db.Where(&model.ModerationCase{MessageID: messageID}).First(c).Error; err != nil {
+		return nil, convertError(err)
+	}
+	return c, nil
+}
+
+// UpdateModerationCase implements ModerationRepository interface.
+func (repo *GormRepository) UpdateModerationCase(id, actorID uuid.UUID, args UpdateModerationCaseArgs) error {
+	if id == uuid.Nil || actorID == uuid.Nil {
+		return ErrNilID
+	}
+	if args.State.Valid && !model.ModerationCaseState(args.State.String).Valid() {
+		return ArgError("args.State", "invalid State")
+	}
+
+	var (
+		c       model.ModerationCase
+		changed bool
+	)
+	err := repo.transact(func(tx *gorm.DB) error {
+		if err := tx.Set("gorm:query_option", "FOR UPDATE").Where(&model.ModerationCase{ID: id}).First(&c).Error; err != nil {
+			return convertError(err)
+		}
+
+		changes := map[string]interface{}{}
+		if args.State.Valid && model.ModerationCaseState(args.State.String) != c.State {
+			changes["state"] = args.State.String
+			if err := createModerationLog(tx, c.ID, actorID, model.ModerationLogStateChanged, args.State.String); err != nil {
+				return err
+			}
+		}
+		if args.AssigneeID.Valid && args.AssigneeID.UUID != c.AssigneeID {
+			if args.AssigneeID.UUID != uuid.Nil {
+				if ok, err := dbExists(tx, &model.User{ID: args.AssigneeID.UUID}); err != nil {
+					return err
+				} else if !ok {
+					return ArgError("args.AssigneeID", "the User is not found")
+				}
+			}
+			changes["assignee_id"] = args.AssigneeID.UUID
+			if err := createModerationLog(tx, c.ID, actorID, model.ModerationLogAssigned, args.AssigneeID.UUID.String()); err != nil {
+				return err
+			}
+		}
+		if len(changes) == 0 {
+			return nil
+		}
+		changed = true
+		return tx.Model(&c).Updates(changes).Error
+	})
+	if err != nil {
+		return err
+	}
+	if changed {
+		repo.publishModerationCaseUpdated(&c)
+	}
+	return nil
+}
+
+// AddModerationNote implements ModerationRepository interface.
+func (repo *GormRepository) AddModerationNote(caseID, actorID uuid.UUID, note string) (*model.ModerationLog, error) {
+	if caseID == uuid.Nil || actorID == uuid.Nil {
+		return nil, ErrNilID
+	}
+	if len(note) == 0 {
+		return nil, ArgError("note", "note is required")
+	}
+
+	var (
+		c model.ModerationCase
+		l *model.ModerationLog
+	)
+	err := repo.transact(func(tx *gorm.DB) error {
+		if err := tx.Where(&model.ModerationCase{ID: caseID}).First(&c).Error; err != nil {
+			return convertError(err)
+		}
+		l = &model.ModerationLog{
+			ID:      uuid.Must(uuid.NewV4()),
+			CaseID:  caseID,
+			ActorID: actorID,
+			Type:    model.ModerationLogNote,
+			Detail:  note,
+		}
+		if err := tx.Create(l).Error; err != nil {
+			return err
+		}
+		return tx.Model(&c).Update("updated_at", time.Now()).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	repo.publishModerationCaseUpdated(&c)
+	return l, nil
+}
+
+// TakeModerationAction implements ModerationRepository interface.
+func (repo *GormRepository) TakeModerationAction(caseID, actorID uuid.UUID, action model.ModerationActionType, comment string) error {
+	if caseID == uuid.Nil || actorID == uuid.Nil {
+		return ErrNilID
+	}
+	if !action.Valid() {
+		return ArgError("action", "invalid action")
+	}
+
+	var (
+		c       model.ModerationCase
+		m       model.Message
+		deleted *model.Message
+	)
+	// 対処と監査ログの記録、ケースの状態の更新は同じトランザクションで行う
+	err := repo.transact(func(tx *gorm.DB) (err error) {
+		if err := tx.Set("gorm:query_option", "FOR UPDATE").Where(&model.ModerationCase{ID: caseID}).First(&c).Error; err != nil {
+			return convertError(err)
+		}
+		if err := tx.Unscoped().Where(&model.Message{ID: c.MessageID}).First(&m).Error; err != nil {
+			return convertError(err)
+		}
+
+		switch action {
+		case model.ModerationActionDeleteMessage:
+			if m.DeletedAt != nil {
+				return ArgError("action", "the message has already been deleted")
+			}
+			// モデレーションによる削除はアーカイブされたチャンネルでも行う
+			if deleted, err = deleteMessage(tx, m.ID, true); err != nil {
+				return err
+			}
+		case model.ModerationActionSuspendUser:
+			if err := tx.Model(&model.User{ID: m.UserID}).Update("status", model.UserAccountStatusSuspended).Error; err != nil {
+				return err
+			}
+		}
+
+		detail := string(action)
+		if len(comment) > 0 {
+			detail += ": " + comment
+		}
+		if err := createModerationLog(tx, c.ID, actorID, model.ModerationLogAction, detail); err != nil {
+			return err
+		}
+		if c.State != model.ModerationCaseStateResolved {
+			if err := createModerationLog(tx, c.ID, actorID, model.ModerationLogStateChanged, string(model.ModerationCaseStateResolved)); err != nil {
+				return err
+			}
+		}
+		return tx.Model(&c).Updates(map[string]interface{}{
+			"state":      model.ModerationCaseStateResolved,
+			"updated_at": time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case model.ModerationActionDeleteMessage:
+		repo.publishMessageDeleted(deleted)
+	case model.ModerationActionSuspendUser:
+		repo.hub.Publish(hub.Message{
+			Name: event.UserAccountStatusUpdated,
+			Fields: hub.Fields{
+				"user_id": m.UserID,
+				"status":  model.UserAccountStatusSuspended,
+			},
+		})
+	case model.ModerationActionWarn:
+		repo.hub.Publish(hub.Message{
+			Name: event.ModerationWarned,
+			Fields: hub.Fields{
+				"case_id": c.ID,
+				"user_id": m.UserID,
+				"message": comment,
+			},
+		})
+	}
+	repo.publishModerationCaseUpdated(&c)
+	return nil
+}
+
+// GetModerationLogs implements ModerationRepository interface.
+func (repo *GormRepository) GetModerationLogs(caseID uuid.UUID) (arr []*model.ModerationLog, err error) {
+	arr = make([]*model.ModerationLog, 0)
+	if caseID == uuid.Nil {
+		return arr, nil
+	}
+	err = repo.db.Where(&model.ModerationLog{CaseID: caseID}).Order("created_at").Find(&arr).Error
+	return arr, err
+}
+
+func (repo *GormRepository) publishModerationCaseUpdated(c *model.ModerationCase) {
+	repo.hub.Publish(hub.Message{
+		Name: event.ModerationCaseUpdated,
+		Fields: hub.Fields{
+			"case_id":    c.ID,
+			"message_id": c.MessageID,
+		},
+	})
+}
+
+// openModerationCase 通報されたメッセージのモデレーションケースに通報を記録します
+//
+// ケースが存在しない場合は作成し、対応が完了したケースの場合は未対応に戻します。
+func openModerationCase(tx *gorm.DB, messageID, reporterID uuid.UUID, reason string) (*model.ModerationCase, error) {
+	c := &model.ModerationCase{}
+	err := tx.Where(&model.ModerationCase{MessageID: messageID}).First(c).Error
+	switch {
+	case gorm.IsRecordNotFoundError(err):
+		c = &model.ModerationCase{
+			ID:        uuid.Must(uuid.NewV4()),
+			MessageID: messageID,
+			State:     model.ModerationCaseStateOpen,
+		}
+		if err := tx.Create(c).Error; err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	case c.State.IsClosed():
+		if err := tx.Model(c).Update("state", model.ModerationCaseStateOpen).Error; err != nil {
+			return nil, err
+		}
+		if err := createModerationLog(tx, c.ID, uuid.Nil, model.ModerationLogStateChanged, string(model.ModerationCaseStateOpen)); err != nil {
+			return nil, err
+		}
+	default:
+		if err := tx.Model(c).Update("updated_at", time.Now()).Error; err != nil {
+			return nil, err
+		}
+	}
+	if err := createModerationLog(tx, c.ID, reporterID, model.ModerationLogReported, reason); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func createModerationLog(tx *gorm.DB, caseID, actorID uuid.UUID, logType model.ModerationLogType, detail string) error {
+	return tx.Create(&model.ModerationLog{
+		ID:      uuid.Must(uuid.NewV4()),
+		CaseID:  caseID,
+		ActorID: actorID,
+		Type:    logType,
+		Detail:  detail,
+	}).Error
+}
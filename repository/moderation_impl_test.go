@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/traPtitech/traQ/model"
+	"gopkg.in/guregu/null.v3"
+)
+
+func mustMakeModerationCase(t *testing.T, repo Repository, reporterID, messageID uuid.UUID) *model.ModerationCase {
+	t.Helper()
+	if err := repo.CreateMessageReport(messageID, reporterID, "reason"); err != nil {
+		t.Fatal(err)
+	}
+	c, err := repo.GetModerationCaseByMessageID(messageID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestRepositoryImpl_CreateMessageReport_ModerationCase(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, common)
+
+	user2 := mustMakeUser(t, repo, random)
+	m := mustMakeMessage(t, repo, user.ID, channel.ID)
+
+	require.NoError(repo.CreateMessageReport(m.ID, user.ID, "spam"))
+	require.NoError(repo.CreateMessageReport(m.ID, user2.ID, "rude"))
+
+	c, err := repo.GetModerationCaseByMessageID(m.ID)
+	require.NoError(err)
+	assert.Equal(model.ModerationCaseStateOpen, c.State)
+	assert.Equal(uuid.Nil, c.AssigneeID)
+
+	logs, err := repo.GetModerationLogs(c.ID)
+	require.NoError(err)
+	if assert.Len(logs, 2) {
+		assert.Equal(model.ModerationLogReported, logs[0].Type)
+		assert.Equal(user.ID, logs[0].ActorID)
+		assert.Equal("spam", logs[0].Detail)
+		assert.Equal(user2.ID, logs[1].ActorID)
+	}
+
+	// 対応済みのケースへの通報は未対応に戻す
+	require.NoError(repo.UpdateModerationCase(c.ID, user.ID, UpdateModerationCaseArgs{State: null.StringFrom(string(model.ModerationCaseStateDismissed))}))
+	user3 := mustMakeUser(t, repo, random)
+	require.NoError(repo.CreateMessageReport(m.ID, user3.ID, "again"))
+	c, err = repo.GetModerationCaseByMessageID(m.ID)
+	require.NoError(err)
+	assert.Equal(model.ModerationCaseStateOpen, c.State)
+}
+
+func TestRepositoryImpl_GetModerationCases(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, ex3)
+
+	c1 := mustMakeModerationCase(t, repo, user.ID, mustMakeMessage(t, repo, user.ID, channel.ID).ID)
+	c2 := mustMakeModerationCase(t, repo, user.ID, mustMakeMessage(t, repo, user.ID, channel.ID).ID)
+	require.NoError(repo.UpdateModerationCase(c2.ID, user.ID, UpdateModerationCaseArgs{State: null.StringFrom(string(model.ModerationCaseStateInReview))}))
+
+	cases, err := repo.GetModerationCases("", 0, 0)
+	require.NoError(err)
+	if assert.Len(cases, 2) {
+		assert.Equal(c2.ID, cases[0].ID)
+		assert.Equal(c1.ID, cases[1].ID)
+	}
+
+	cases, err = repo.GetModerationCases(model.ModerationCaseStateOpen, 0, 0)
+	require.NoError(err)
+	if assert.Len(cases, 1) {
+		assert.Equal(c1.ID, cases[0].ID)
+	}
+}
+
+func TestRepositoryImpl_GetModerationCase(t *testing.T) {
+	t.Parallel()
+	repo, assert, _, user, channel := setupWithUserAndChannel(t, common)
+
+	c := mustMakeModerationCase(t, repo, user.ID, mustMakeMessage(t, repo, user.ID, channel.ID).ID)
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := repo.GetModerationCase(uuid.Nil)
+		assert.Equal(ErrNotFound, err)
+		_, err = repo.GetModerationCase(uuid.Must(uuid.NewV4()))
+		assert.Equal(ErrNotFound, err)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := repo.GetModerationCase(c.ID)
+		if assert.NoError(err) {
+			assert.Equal(c.MessageID, r.MessageID)
+		}
+	})
+}
+
+func TestRepositoryImpl_UpdateModerationCase(t *testing.T) {
+	t.Parallel()
+	repo, _, _, user, channel := setupWithUserAndChannel(t, common)
+
+	c := mustMakeModerationCase(t, repo, user.ID, mustMakeMessage(t, repo, user.ID, channel.ID).ID)
+
+	t.Run("nil id", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, ErrNilID, repo.UpdateModerationCase(uuid.Nil, user.ID, UpdateModerationCaseArgs{}))
+		assert.Equal(t, ErrNilID, repo.UpdateModerationCase(c.ID, uuid.Nil, UpdateModerationCaseArgs{}))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, ErrNotFound, repo.UpdateModerationCase(uuid.Must(uuid.NewV4()), user.ID, UpdateModerationCaseArgs{}))
+	})
+
+	t.Run("invalid args", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		assert.True(IsArgError(repo.UpdateModerationCase(c.ID, user.ID, UpdateModerationCaseArgs{State: null.StringFrom("closed")})))
+		assert.True(IsArgError(repo.UpdateModerationCase(c.ID, user.ID, UpdateModerationCaseArgs{AssigneeID: uuid.NullUUID{UUID: uuid.Must(uuid.NewV4()), Valid: true}})))
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		c := mustMakeModerationCase(t, repo, user.ID, mustMakeMessage(t, repo, user.ID, channel.ID).ID)
+		if assert.NoError(repo.UpdateModerationCase(c.ID, user.ID, UpdateModerationCaseArgs{
+			State:      null.StringFrom(string(model.ModerationCaseStateInReview)),
+			AssigneeID: uuid.NullUUID{UUID: user.ID, Valid: true},
+		})) {
+			r, err := repo.GetModerationCase(c.ID)
+			if assert.NoError(err) {
+				assert.Equal(model.ModerationCaseStateInReview, r.State)
+				assert.Equal(user.ID, r.AssigneeID)
+			}
+			logs, err := repo.GetModerationLogs(c.ID)
+			if assert.NoError(err) && assert.Len(logs, 3) {
+				assert.Equal(model.ModerationLogStateChanged, logs[1].Type)
+				assert.Equal(string(model.ModerationCaseStateInReview), logs[1].Detail)
+				assert.Equal(model.ModerationLogAssigned, logs[2].Type)
+				assert.Equal(user.ID.String(), logs[2].Detail)
+			}
+		}
+	})
+}
+
+func TestRepositoryImpl_AddModerationNote(t *testing.T) {
+	t.Parallel()
+	repo, assert, _, user, channel := setupWithUserAndChannel(t, common)
+
+	c := mustMakeModerationCase(t, repo, user.ID, mustMakeMessage(t, repo, user.ID, channel.ID).ID)
+
+	_, err := repo.AddModerationNote(uuid.Nil, user.ID, "note")
+	assert.Equal(ErrNilID, err)
+	_, err = repo.AddModerationNote(uuid.Must(uuid.NewV4()), user.ID, "note")
+	assert.Equal(ErrNotFound, err)
+	_, err = repo.AddModerationNote(c.ID, user.ID, "")
+	assert.True(IsArgError(err))
+
+	l, err := repo.AddModerationNote(c.ID, user.ID, "note")
+	if assert.NoError(err) {
+		assert.Equal(model.ModerationLogNote, l.Type)
+		assert.Equal("note", l.Detail)
+		assert.Equal(user.ID, l.ActorID)
+	}
+}
+
+func TestRepositoryImpl_TakeModerationAction(t *testing.T) {
+	t.Parallel()
+	repo, _, _, user, channel := setupWithUserAndChannel(t, common)
+
+	t.Run("invalid args", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		c := mustMakeModerationCase(t, repo, user.ID, mustMakeMessage(t, repo, user.ID, channel.ID).ID)
+		assert.Equal(ErrNilID, repo.TakeModerationAction(uuid.Nil, user.ID, model.ModerationActionWarn, ""))
+		assert.Equal(ErrNotFound, repo.TakeModerationAction(uuid.Must(uuid.NewV4()), user.ID, model.ModerationActionWarn, ""))
+		assert.True(IsArgError(repo.TakeModerationAction(c.ID, user.ID, "ban", "")))
+	})
+
+	t.Run("delete message", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		m := mustMakeMessage(t, repo, user.ID, channel.ID)
+		c := mustMakeModerationCase(t, repo, user.ID, m.ID)
+		if assert.NoError(repo.TakeModerationAction(c.ID, user.ID, model.ModerationActionDeleteMessage, "bye")) {
+			_, err := repo.GetMessageByID(m.ID)
+			assert.Equal(ErrNotFound, err)
+
+			logs, err := repo.GetModerationLogs(c.ID)
+			if assert.NoError(err) && assert.Len(logs, 3) {
+				details := map[model.ModerationLogType]string{}
+				for _, l := range logs {
+					details[l.Type] = l.Detail
+				}
+				assert.Equal("delete_message: bye", details[model.ModerationLogAction])
+				assert.Equal(string(model.ModerationCaseStateResolved), details[model.ModerationLogStateChanged])
+			}
+
+			mc, err := repo.GetModerationCase(c.ID)
+			if assert.NoError(err) {
+				assert.Equal(model.ModerationCaseStateResolved, mc.State)
+			}
+		}
+		assert.True(IsArgError(repo.TakeModerationAction(c.ID, user.ID, model.ModerationActionDeleteMessage, "")))
+	})
+
+	t.Run("suspend user", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		target := mustMakeUser(t, repo, random)
+		c := mustMakeModerationCase(t, repo, user.ID, mustMakeMessage(t, repo, target.ID, channel.ID).ID)
+		if assert.NoError(repo.TakeModerationAction(c.ID, user.ID, model.ModerationActionSuspendUser, "")) {
+			u, err := repo.GetUser(target.ID)
+			if assert.NoError(err) {
+				assert.Equal(model.UserAccountStatusSuspended, u.Status)
+			}
+		}
+	})
+}
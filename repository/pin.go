@@ -11,6 +11,7 @@ type PinRepository interface {
 	//
 	// 成功した、或いは既にピン留めされていた場合、ピン留めのUUIDとnilを返します。既にピン留めされていた場合にユーザーIDは上書きされません。
 	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// アーカイブされたチャンネルのメッセージを指定した場合、ErrChannelArchivedを返します。
 	// DBによるエラーを返すことがあります。
 	CreatePin(messageID, userID uuid.UUID) (uuid.UUID, error)
 	// GetPin 指定したピン留めを取得します
@@ -29,6 +30,7 @@ type PinRepository interface {
 	//
 	// 成功した、或いは既にピン留めされていなかった場合にnilを返します。
 	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// アーカイブされたチャンネルのピン留めを指定した場合、ErrChannelArchivedを返します。
 	// DBによるエラーを返すことがあります。
 	DeletePin(id uuid.UUID) error
 	// GetPinsByChannelID 指定したチャンネルのピン留めを全て取得します
@@ -13,6 +13,9 @@ func (repo *GormRepository) CreatePin(messageID, userID uuid.UUID) (uuid.UUID, e
 	if messageID == uuid.Nil || userID == uuid.Nil {
 		return uuid.Nil, ErrNilID
 	}
+	if err := checkMessageChannelNotArchived(repo.db, messageID); err != nil {
+		return uuid.Nil, err
+	}
 	var p model.Pin
 	err := repo.db.
 		Where(&model.Pin{MessageID: messageID}).
@@ -69,6 +72,9 @@ func (repo *GormRepository) DeletePin(id uuid.UUID) error {
 			}
 			return err
 		}
+		if err := checkMessageChannelNotArchived(tx, pin.MessageID); err != nil {
+			return err
+		}
 		ok = true
 		return tx.Delete(&model.Pin{ID: id}).Error
 	})
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/traPtitech/traQ/model"
+	"gopkg.in/guregu/null.v3"
+)
+
+// CreatePollArgs 投票作成引数
+type CreatePollArgs struct {
+	ChannelID      uuid.UUID
+	CreatorID      uuid.UUID
+	Question       string
+	Options        []string
+	MultipleChoice bool
+	Anonymous      bool
+	Deadline       null.Time
+}
+
+// PollRepository 投票リポジトリ
+type PollRepository interface {
+	// CreatePoll 投票を作成します
+	//
+	// 成功した場合、選択肢を含む投票とnilを返します。
+	// 質問文と選択肢には投稿先チャンネルのコンテンツフィルタを適用します。拒否された場合、*ContentFilterErrorを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// 質問文・選択肢・締め切り日時が不正な場合、ArgumentErrorを返します。
+	// アーカイブされたチャンネルを指定した場合、ErrChannelArchivedを返します。
+	// DBによるエラーを返すことがあります。
+	CreatePoll(args CreatePollArgs) (*model.Poll, error)
+	// DeletePoll 指定した投票を選択肢と票を含めて削除します
+	//
+	// 成功した場合、nilを返します。
+	// 存在しない投票を指定した場合、ErrNotFoundを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	DeletePoll(id uuid.UUID) error
+	// AttachPollToMessage 投票を埋め込んだメッセージを記録します
+	//
+	// 成功した場合、nilを返します。
+	// 存在しない投票を指定した場合、ErrNotFoundを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	AttachPollToMessage(pollID, messageID uuid.UUID) error
+	// GetPoll 指定した投票を選択肢を含めて取得します
+	//
+	// 成功した場合、投票とnilを返します。選択肢は表示順に並びます。
+	// 存在しない投票を指定した場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	GetPoll(id uuid.UUID) (*model.Poll, error)
+	// GetPollVotes 指定した投票の票を投票日時の昇順で全て取得します
+	//
+	// 成功した場合、票の配列とnilを返します。
+	// 存在しない投票を指定した場合は空配列とnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetPollVotes(pollID uuid.UUID) ([]*model.PollVote, error)
+	// VotePoll 指定したユーザーの票を指定した選択肢で置き換えます
+	//
+	// 成功した場合、nilを返します。
+	// 存在しない投票を指定した場合、ErrNotFoundを返します。
+	// 締め切られた投票を指定した場合、ErrForbiddenを返します。
+	// 投票に存在しない選択肢や、単一選択の投票で複数の選択肢を指定した場合、ArgumentErrorを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// アーカイブされたチャンネルの投票を指定した場合、ErrChannelArchivedを返します。
+	// DBによるエラーを返すことがあります。
+	VotePoll(pollID, userID uuid.UUID, optionIDs []uuid.UUID) error
+	// RetractPollVote 指定したユーザーの票を取り消します
+	//
+	// 成功した場合、nilを返します。票が無い場合もnilを返します。
+	// 存在しない投票を指定した場合、ErrNotFoundを返します。
+	// 締め切られた投票を指定した場合、ErrForbiddenを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// アーカイブされたチャンネルの投票を指定した場合、ErrChannelArchivedを返します。
+	// DBによるエラーを返すことがあります。
+	RetractPollVote(pollID, userID uuid.UUID) error
+}
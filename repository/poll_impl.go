@@ -0,0 +1,269 @@
+package repository
+
+import (
+	"time"
+	"unicode/utf8"
+
+	"github.com/gofrs/uuid"
+	"github.com/jinzhu/gorm"
+	"github.com/leandro-lugaresi/hub"
+	"github.com/traPtitech/traQ/event"
+	"github.com/traPtitech/traQ/model"
+)
+
+// 投票の選択肢の最大数
+const maxPollOptions = 20
+
+// CreatePoll implements PollRepository interface.
+func (repo *GormRepository) CreatePoll(args CreatePollArgs) (*model.Poll, error) {
+	if args.ChannelID == uuid.Nil || args.CreatorID == uuid.Nil {
+		return nil, ErrNilID
+	}
+	if err := checkChannelNotArchived(repo.db, args.ChannelID); err != nil {
+		return nil, err
+	}
+
+	// 質問文と選択肢はメッセージとは別に表示されるため、それぞれにフィルタを適用する
+	filtered, err := repo.applyContentFilter(repo.db, args.ChannelID, args.Question)
+	if err != nil {
+		return nil, err
+	}
+	args.Question = filtered.Text
+	options := make([]string, len(args.Options))
+	for i, label := range args.Options {
+		filtered, err := repo.applyContentFilter(repo.db, args.ChannelID, label)
+		if err != nil {
+			return nil, err
+		}
+		options[i] = filtered.Text
+	}
+	args.Options = options
+
+	if len(args.Question) == 0 || utf8.RuneCountInString(args.Question) > 1000 {
+		return nil, ArgError("args.Question", "Question must be non-empty and shorter than 1001 characters")
+	}
+	if len(args.Options) < 2 || len(args.Options) > maxPollOptions {
+		return nil, ArgError("args.Options", "the number of Options must be between 2 and 20")
+	}
+	labels := make(map[string]bool, len(args.Options))
+	for _, label := range args.Options {
+		if len(label) == 0 || utf8.RuneCountInString(label) > 100 {
+			return nil, ArgError("args.Options", "each Option must be non-empty and shorter than 101 characters")
+		}
+		if labels[label] {
+			return nil, ArgError("args.Options", "Options must be unique")
+		}
+		labels[label] = true
+	}
+	if args.Deadline.Valid && !args.Deadline.Time.After(time.Now()) {
+		return nil, ArgError("args.Deadline", "Deadline must be in the future")
+	}
+
+	p := &model.Poll{
+		ID:             uuid.Must(uuid.NewV4()),
+		ChannelID:      args.ChannelID,
+		CreatorID:      args.CreatorID,
+		Question:       args.Question,
+		MultipleChoice: args.MultipleChoice,
+		Anonymous:      args.Anonymous,
+		Deadline:       args.Deadline.Ptr(),
+		Options:        make([]*model.PollOption, len(args.Options)),
+	}
+	for i, label := range args.Options {
+		p.Options[i] = &model.PollOption{
+			ID:       uuid.Must(uuid.NewV4()),
+			PollID:   p.ID,
+			Label:    label,
+			Position: i,
+		}
+	}
+
+	err = repo.transact(func(tx *gorm.DB) error {
+		if err := tx.Create(p).Error; err != nil {
+			return err
+		}
+		for _, o := range p.Options {
+			if err := tx.Create(o).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// DeletePoll implements PollRepository interface.
+func (repo *GormRepository) DeletePoll(id uuid.UUID) error {
+	if id == uuid.Nil {
+		return ErrNilID
+	}
+	return repo.transact(func(tx *gorm.DB) error {
+		if err := tx.Where(&model.PollVote{PollID: id}).Delete(&model.PollVote{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where(&model.PollOption{PollID: id}).Delete(&model.PollOption{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&model.Poll{ID: id})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+// AttachPollToMessage implements PollRepository interface.
+func (repo *GormRepository) AttachPollToMessage(pollID, messageID uuid.UUID) error {
+	if pollID == uuid.Nil || messageID == uuid.Nil {
+		return ErrNilID
+	}
+	result := repo.db.Model(&model.Poll{ID: pollID}).Update("message_id", messageID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetPoll implements PollRepository interface.
+func (repo *GormRepository) GetPoll(id uuid.UUID) (*model.Poll, error) {
+	if id == uuid.Nil {
+		return nil, ErrNotFound
+	}
+	var p model.Poll
+	err := repo.db.
+		Where(&model.Poll{ID: id}).
+		Preload("Options", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position")
+		}).
+		Take(&p).
+		Error
+	if err != nil {
+		return nil, convertError(err)
+	}
+	return &p, nil
+}
+
+// GetPollVotes implements PollRepository interface.
+func (repo *GormRepository) GetPollVotes(pollID uuid.UUID) ([]*model.PollVote, error) {
+	votes := make([]*model.PollVote, 0)
+	if pollID == uuid.Nil {
+		return votes, nil
+	}
+	return votes, repo.db.Where(&model.PollVote{PollID: pollID}).Order("created_at").Find(&votes).Error
+}
+
+// VotePoll implements PollRepository interface.
+func (repo *GormRepository) VotePoll(pollID, userID uuid.UUID, optionIDs []uuid.UUID) error {
+	if pollID == uuid.Nil || userID == uuid.Nil {
+		return ErrNilID
+	}
+	if len(optionIDs) == 0 {
+		return ArgError("optionIDs", "at least one option is required")
+	}
+
+	var p model.Poll
+	err := repo.transact(func(tx *gorm.DB) error {
+		if err := tx.Where(&model.Poll{ID: pollID}).Take(&p).Error; err != nil {
+			return convertError(err)
+		}
+		if p.IsClosed(time.Now()) {
+			return ErrForbidden
+		}
+		if err := checkChannelNotArchived(tx, p.ChannelID); err != nil {
+			return err
+		}
+
+		var options []uuid.UUID
+		if err := tx.Model(&model.PollOption{}).Where(&model.PollOption{PollID: pollID}).Pluck("id", &options).Error; err != nil {
+			return err
+		}
+		valid := make(map[uuid.UUID]bool, len(options))
+		for _, id := range options {
+			valid[id] = true
+		}
+		selected := make(map[uuid.UUID]bool, len(optionIDs))
+		for _, id := range optionIDs {
+			if !valid[id] {
+				return ArgError("optionIDs", "invalid option")
+			}
+			selected[id] = true
+		}
+		if !p.MultipleChoice && len(selected) > 1 {
+			return ArgError("optionIDs", "the poll accepts only one option")
+		}
+
+		if err := tx.Where(&model.PollVote{PollID: pollID, UserID: userID}).Delete(&model.PollVote{}).Error; err != nil {
+			return err
+		}
+		for id := range selected {
+			if err := tx.Create(&model.PollVote{PollID: pollID, UserID: userID, OptionID: id}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	repo.publishPollUpdated(&p)
+	return nil
+}
+
+// RetractPollVote implements PollRepository interface.
+func (repo *GormRepository) RetractPollVote(pollID, userID uuid.UUID) error {
+	if pollID == uuid.Nil || userID == uuid.Nil {
+		return ErrNilID
+	}
+
+	var (
+		p       model.Poll
+		deleted bool
+	)
+	err := repo.transact(func(tx *gorm.DB) error {
+		if err := tx.Where(&model.Poll{ID: pollID}).Take(&p).Error; err != nil {
+			return convertError(err)
+		}
+		if p.IsClosed(time.Now()) {
+			return ErrForbidden
+		}
+		if err := checkChannelNotArchived(tx, p.ChannelID); err != nil {
+			return err
+		}
+
+		result := tx.Where(&model.PollVote{PollID: pollID, UserID: userID}).Delete(&model.PollVote{})
+		if result.Error != nil {
+			return result.Error
+		}
+		deleted = result.RowsAffected > 0
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if deleted {
+		repo.publishPollUpdated(&p)
+	}
+	return nil
+}
+
+func (repo *GormRepository) publishPollUpdated(p *model.Poll) {
+	repo.hub.Publish(hub.Message{
+		Name: event.PollUpdated,
+		Fields: hub.Fields{
+			"poll_id":    p.ID,
+			"channel_id": p.ChannelID,
+			"message_id": p.MessageID,
+		},
+	})
+}
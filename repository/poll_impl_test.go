@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/traPtitech/traQ/model"
+	"gopkg.in/guregu/null.v3"
+)
+
+func mustMakePoll(t *testing.T, repo Repository, userID, channelID uuid.UUID, multipleChoice bool) *model.Poll {
+	t.Helper()
+	p, err := repo.CreatePoll(CreatePollArgs{
+		ChannelID:      channelID,
+		CreatorID:      userID,
+		Question:       "question",
+		Options:        []string{"a", "b", "c"},
+		MultipleChoice: multipleChoice,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestRepositoryImpl_CreatePoll(t *testing.T) {
+	t.Parallel()
+	repo, _, _, user, channel := setupWithUserAndChannel(t, common)
+
+	t.Run("nil id", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := repo.CreatePoll(CreatePollArgs{CreatorID: user.ID, Question: "q", Options: []string{"a", "b"}})
+		assert.Equal(t, ErrNilID, err)
+	})
+
+	t.Run("invalid args", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		base := CreatePollArgs{ChannelID: channel.ID, CreatorID: user.ID, Question: "q", Options: []string{"a", "b"}}
+		cases := []func(a *CreatePollArgs){
+			func(a *CreatePollArgs) { a.Question = "" },
+			func(a *CreatePollArgs) { a.Options = []string{"a"} },
+			func(a *CreatePollArgs) { a.Options = []string{"a", ""} },
+			func(a *CreatePollArgs) { a.Options = []string{"a", "a"} },
+			func(a *CreatePollArgs) { a.Deadline = null.TimeFrom(time.Now().Add(-time.Minute)) },
+		}
+		for _, f := range cases {
+			args := base
+			f(&args)
+			_, err := repo.CreatePoll(args)
+			assert.True(IsArgError(err))
+		}
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		p, err := repo.CreatePoll(CreatePollArgs{
+			ChannelID: channel.ID,
+			CreatorID: user.ID,
+			Question:  "q",
+			Options:   []string{"a", "b"},
+			Anonymous: true,
+			Deadline:  null.TimeFrom(time.Now().Add(time.Hour)),
+		})
+		if assert.NoError(err) {
+			assert.NotEqual(uuid.Nil, p.ID)
+			assert.Equal(uuid.Nil, p.MessageID)
+			assert.True(p.Anonymous)
+			assert.False(p.MultipleChoice)
+			assert.NotNil(p.Deadline)
+			if assert.Len(p.Options, 2) {
+				assert.Equal("a", p.Options[0].Label)
+				assert.Equal("b", p.Options[1].Label)
+			}
+		}
+	})
+}
+
+func TestRepositoryImpl_AttachPollToMessage(t *testing.T) {
+	t.Parallel()
+	repo, assert, _, user, channel := setupWithUserAndChannel(t, common)
+
+	p := mustMakePoll(t, repo, user.ID, channel.ID, false)
+	m := mustMakeMessage(t, repo, user.ID, channel.ID)
+
+	assert.Equal(ErrNilID, repo.AttachPollToMessage(uuid.Nil, m.ID))
+	assert.Equal(ErrNotFound, repo.AttachPollToMessage(uuid.Must(uuid.NewV4()), m.ID))
+	if assert.NoError(repo.AttachPollToMessage(p.ID, m.ID)) {
+		p, err := repo.GetPoll(p.ID)
+		if assert.NoError(err) {
+			assert.Equal(m.ID, p.MessageID)
+		}
+	}
+}
+
+func TestRepositoryImpl_GetPoll(t *testing.T) {
+	t.Parallel()
+	repo, assert, _, user, channel := setupWithUserAndChannel(t, common)
+
+	p := mustMakePoll(t, repo, user.ID, channel.ID, false)
+
+	_, err := repo.GetPoll(uuid.Nil)
+	assert.Equal(ErrNotFound, err)
+	_, err = repo.GetPoll(uuid.Must(uuid.NewV4()))
+	assert.Equal(ErrNotFound, err)
+
+	r, err := repo.GetPoll(p.ID)
+	if assert.NoError(err) {
+		assert.Equal(p.ID, r.ID)
+		assert.Equal(channel.ID, r.ChannelID)
+		assert.Equal("question", r.Question)
+		if assert.Len(r.Options, 3) {
+			for i, o := range r.Options {
+				assert.Equal(p.Options[i].ID, o.ID)
+			}
+		}
+	}
+}
+
+func TestRepositoryImpl_VotePoll(t *testing.T) {
+	t.Parallel()
+	repo, _, _, user, channel := setupWithUserAndChannel(t, common)
+
+	t.Run("nil id", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, ErrNilID, repo.VotePoll(uuid.Nil, user.ID, nil))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, ErrNotFound, repo.VotePoll(uuid.Must(uuid.NewV4()), user.ID, []uuid.UUID{uuid.Must(uuid.NewV4())}))
+	})
+
+	t.Run("single choice", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		p := mustMakePoll(t, repo, user.ID, channel.ID, false)
+		other := mustMakePoll(t, repo, user.ID, channel.ID, false)
+
+		assert.True(IsArgError(repo.VotePoll(p.ID, user.ID, nil)))
+		assert.True(IsArgError(repo.VotePoll(p.ID, user.ID, []uuid.UUID{other.Options[0].ID})))
+		assert.True(IsArgError(repo.VotePoll(p.ID, user.ID, []uuid.UUID{p.Options[0].ID, p.Options[1].ID})))
+
+		assert.NoError(repo.VotePoll(p.ID, user.ID, []uuid.UUID{p.Options[0].ID}))
+		assert.NoError(repo.VotePoll(p.ID, user.ID, []uuid.UUID{p.Options[1].ID}))
+		if votes, err := repo.GetPollVotes(p.ID); assert.NoError(err) && assert.Len(votes, 1) {
+			assert.Equal(p.Options[1].ID, votes[0].OptionID)
+			assert.Equal(user.ID, votes[0].UserID)
+		}
+	})
+
+	t.Run("multiple choice", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		p := mustMakePoll(t, repo, user.ID, channel.ID, true)
+		assert.NoError(repo.VotePoll(p.ID, user.ID, []uuid.UUID{p.Options[0].ID, p.Options[2].ID, p.Options[0].ID}))
+		if votes, err := repo.GetPollVotes(p.ID); assert.NoError(err) {
+			assert.Len(votes, 2)
+		}
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		p := mustMakePoll(t, repo, user.ID, channel.ID, false)
+		getDB(repo).Model(p).Update("deadline", time.Now().Add(-time.Minute))
+		assert.Equal(ErrForbidden, repo.VotePoll(p.ID, user.ID, []uuid.UUID{p.Options[0].ID}))
+	})
+}
+
+func TestRepositoryImpl_RetractPollVote(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, common)
+
+	p := mustMakePoll(t, repo, user.ID, channel.ID, false)
+	require.NoError(repo.VotePoll(p.ID, user.ID, []uuid.UUID{p.Options[0].ID}))
+
+	assert.Equal(ErrNilID, repo.RetractPollVote(p.ID, uuid.Nil))
+	assert.Equal(ErrNotFound, repo.RetractPollVote(uuid.Must(uuid.NewV4()), user.ID))
+	if assert.NoError(repo.RetractPollVote(p.ID, user.ID)) {
+		votes, err := repo.GetPollVotes(p.ID)
+		require.NoError(err)
+		assert.Len(votes, 0)
+	}
+	assert.NoError(repo.RetractPollVote(p.ID, user.ID))
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/traPtitech/traQ/model"
+)
+
+// ReminderRepository リマインダーリポジトリ
+type ReminderRepository interface {
+	// CreateReminder リマインダーを作成します
+	//
+	// 成功した場合、リマインダーとnilを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	CreateReminder(userID, messageID uuid.UUID, remindAt time.Time) (*model.Reminder, error)
+	// SnoozeReminder 指定したリマインダーの通知予定日時を変更し、通知待ちに戻します
+	//
+	// 通知済み・通知失敗のリマインダーも再び通知待ちになります。
+	// 成功した場合、nilを返します。
+	// 存在しないリマインダーを指定した場合、ErrNotFoundを返します。
+	// 通知処理中のリマインダーを指定した場合、ErrForbiddenを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	SnoozeReminder(id uuid.UUID, remindAt time.Time) error
+	// DeleteReminder 指定したリマインダーを削除します
+	//
+	// 成功した場合、nilを返します。
+	// 存在しないリマインダーを指定した場合、ErrNotFoundを返します。
+	// 通知処理中のリマインダーを指定した場合、ErrForbiddenを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	DeleteReminder(id uuid.UUID) error
+	// GetReminder 指定したリマインダーを取得します
+	//
+	// 成功した場合、リマインダーとnilを返します。
+	// 存在しないリマインダーを指定した場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	GetReminder(id uuid.UUID) (*model.Reminder, error)
+	// GetRemindersByUserID 指定したユーザーのリマインダーを通知予定日時の昇順で全て取得します
+	//
+	// 成功した場合、リマインダーの配列とnilを返します。
+	// 存在しないユーザーを指定した場合は空配列とnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetRemindersByUserID(userID uuid.UUID) ([]*model.Reminder, error)
+	// GetDueReminders 指定した日時までに通知予定の通知待ちリマインダーを通知予定日時の昇順で取得します
+	//
+	// 成功した場合、リマインダーの配列とnilを返します。負のlimitは無視されます。
+	// DBによるエラーを返すことがあります。
+	GetDueReminders(now time.Time, limit int) ([]*model.Reminder, error)
+	// ClaimReminder 指定した通知待ちのリマインダーを通知処理中にします
+	//
+	// 状態の遷移はアトミックに行われ、複数のプロセスから同時に呼び出された場合でも
+	// trueを返すのは一つの呼び出しのみです。
+	// 成功した場合、trueとnilを返します。
+	// 既に他で通知処理が開始されていた場合や存在しない場合、falseとnilを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	ClaimReminder(id uuid.UUID) (bool, error)
+	// MarkReminderSent 指定した通知処理中のリマインダーを通知済みにします
+	//
+	// 成功した場合、nilを返します。
+	// 存在しないリマインダーを指定した場合、ErrNotFoundを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	MarkReminderSent(id uuid.UUID) error
+	// MarkReminderFailed 指定した通知処理中のリマインダーを通知失敗にします
+	//
+	// 成功した場合、nilを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	MarkReminderFailed(id uuid.UUID, reason string) error
+	// FailStaleReminders 指定した日時以前から通知処理中のままのリマインダーを通知失敗にします
+	//
+	// 通知処理中にプロセスが終了した場合に使用します。二重通知を防ぐため、これらは再通知されません。
+	// 成功した場合、通知失敗にした件数とnilを返します。
+	// DBによるエラーを返すことがあります。
+	FailStaleReminders(before time.Time) (int, error)
+	// GetReminderBotUser リマインダーを通知するシステムBotユーザーを取得します
+	//
+	// 存在しない場合は作成します。
+	// 成功した場合、ユーザーとnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetReminderBotUser() (*model.User, error)
+}
@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jinzhu/gorm"
+	"github.com/leandro-lugaresi/hub"
+	"github.com/traPtitech/traQ/event"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/rbac/role"
+)
+
+// CreateReminder implements ReminderRepository interface.
+func (repo *GormRepository) CreateReminder(userID, messageID uuid.UUID, remindAt time.Time) (*model.Reminder, error) {
+	if userID == uuid.Nil || messageID == uuid.Nil {
+		return nil, ErrNilID
+	}
+	r := &model.Reminder{
+		ID:        uuid.Must(uuid.NewV4()),
+		UserID:    userID,
+		MessageID: messageID,
+		RemindAt:  remindAt,
+		State:     model.ReminderPending,
+	}
+	if err := repo.db.Create(r).Error; err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// SnoozeReminder implements ReminderRepository interface.
+func (repo *GormRepository) SnoozeReminder(id uuid.UUID, remindAt time.Time) error {
+	if id == uuid.Nil {
+		return ErrNilID
+	}
+	return repo.transact(func(tx *gorm.DB) error {
+		var r model.Reminder
+		if err := tx.Where(&model.Reminder{ID: id}).First(&r).Error; err != nil {
+			return convertError(err)
+		}
+
+		// 通知処理中のものは変更できない
+		result := tx.Model(&model.Reminder{}).
+			Where("id = ? AND state <> ?", id, model.ReminderSending).
+			Updates(map[string]interface{}{"remind_at": remindAt, "state": model.ReminderPending, "error": ""})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrForbidden
+		}
+		return nil
+	})
+}
+
+// DeleteReminder implements ReminderRepository interface.
+func (repo *GormRepository) DeleteReminder(id uuid.UUID) error {
+	if id == uuid.Nil {
+		return ErrNilID
+	}
+	return repo.transact(func(tx *gorm.DB) error {
+		var r model.Reminder
+		if err := tx.Where(&model.Reminder{ID: id}).First(&r).Error; err != nil {
+			return convertError(err)
+		}
+
+		// 通知処理中のものは削除できない
+		result := tx.Where("id = ? AND state <> ?", id, model.ReminderSending).Delete(&model.Reminder{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrForbidden
+		}
+		return nil
+	})
+}
+
+// GetReminder implements ReminderRepository interface.
+func (repo *GormRepository) GetReminder(id uuid.UUID) (*model.Reminder, error) {
+	if id == uuid.Nil {
+		return nil, ErrNotFound
+	}
+	r := &model.Reminder{}
+	if err := repo.db.Where(&model.Reminder{ID: id}).Take(r).Error; err != nil {
+		return nil, convertError(err)
+	}
+	return r, nil
+}
+
+// GetRemindersByUserID implements ReminderRepository interface.
+func (repo *GormRepository) GetRemindersByUserID(userID uuid.UUID) (arr []*model.Reminder, err error) {
+	arr = make([]*model.Reminder, 0)
+	if userID == uuid.Nil {
+		return arr, nil
+	}
+	err = repo.db.Where(&model.Reminder{UserID: userID}).Order("remind_at").Find(&arr).Error
+	return arr, err
+}
+
+// GetDueReminders implements ReminderRepository interface.
+func (repo *GormRepository) GetDueReminders(now time.Time, limit int) (arr []*model.Reminder, err error) {
+	arr = make([]*model.Reminder, 0)
+	err = repo.db.
+		Where("state = ? AND remind_at <= ?", model.ReminderPending, now).
+		Order("remind_at").
+		Scopes(limitAndOffset(limit, 0)).
+		Find(&arr).
+		Error
+	return arr, err
+}
+
+// ClaimReminder implements ReminderRepository interface.
+func (repo *GormRepository) ClaimReminder(id uuid.UUID) (bool, error) {
+	if id == uuid.Nil {
+		return false, ErrNilID
+	}
+	result := repo.db.
+		Model(&model.Reminder{}).
+		Where("id = ? AND state = ?", id, model.ReminderPending).
+		Updates(map[string]interface{}{"state": model.ReminderSending})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// MarkReminderSent implements ReminderRepository interface.
+func (repo *GormRepository) MarkReminderSent(id uuid.UUID) error {
+	if id == uuid.Nil {
+		return ErrNilID
+	}
+	var r model.Reminder
+	if err := repo.db.Where(&model.Reminder{ID: id}).Take(&r).Error; err != nil {
+		return convertError(err)
+	}
+	result := repo.db.
+		Model(&model.Reminder{}).
+		Where("id = ? AND state = ?", id, model.ReminderSending).
+		Updates(map[string]interface{}{"state": model.ReminderSent})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		repo.hub.Publish(hub.Message{
+			Name: event.ReminderFired,
+			Fields: hub.Fields{
+				"reminder_id": r.ID,
+				"user_id":     r.UserID,
+				"message_id":  r.MessageID,
+			},
+		})
+	}
+	return nil
+}
+
+// MarkReminderFailed implements ReminderRepository interface.
+func (repo *GormRepository) MarkReminderFailed(id uuid.UUID, reason string) error {
+	if id == uuid.Nil {
+		return ErrNilID
+	}
+	return repo.db.
+		Model(&model.Reminder{}).
+		Where("id = ? AND state = ?", id, model.ReminderSending).
+		Updates(map[string]interface{}{"state": model.ReminderFailed, "error": reason}).
+		Error
+}
+
+// FailStaleReminders implements ReminderRepository interface.
+func (repo *GormRepository) FailStaleReminders(before time.Time) (int, error) {
+	result := repo.db.
+		Model(&model.Reminder{}).
+		Where("state = ? AND updated_at < ?", model.ReminderSending, before).
+		Updates(map[string]interface{}{"state": model.ReminderFailed, "error": "interrupted while sending"})
+	return int(result.RowsAffected), result.Error
+}
+
+// GetReminderBotUser implements ReminderRepository interface.
+func (repo *GormRepository) GetReminderBotUser() (*model.User, error) {
+	u := &model.User{}
+	err := repo.db.Where(&model.User{Name: model.ReminderBotUserName}).Take(u).Error
+	if err == nil {
+		return u, nil
+	}
+	if !gorm.IsRecordNotFoundError(err) {
+		return nil, err
+	}
+
+	iconID, err := repo.GenerateIconFile(model.ReminderBotUserName)
+	if err != nil {
+		return nil, err
+	}
+	u = &model.User{
+		ID:          uuid.Must(uuid.NewV4()),
+		Name:        model.ReminderBotUserName,
+		DisplayName: "リマインダー",
+		Icon:        iconID,
+		Bot:         true,
+		Status:      model.UserAccountStatusActive,
+		Role:        role.Bot.ID(),
+	}
+	if err := repo.db.Create(u).Error; err != nil {
+		// 他のプロセスが同時に作成した場合
+		existing := &model.User{}
+		if err := repo.db.Where(&model.User{Name: model.ReminderBotUserName}).Take(existing).Error; err == nil {
+			return existing, nil
+		}
+		return nil, err
+	}
+	repo.hub.Publish(hub.Message{
+		Name: event.UserCreated,
+		Fields: hub.Fields{
+			"user_id": u.ID,
+			"user":    u,
+		},
+	})
+	return u, nil
+}
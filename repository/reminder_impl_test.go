@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/traPtitech/traQ/model"
+)
+
+func TestRepositoryImpl_CreateReminder(t *testing.T) {
+	t.Parallel()
+	repo, assert, _, user, channel := setupWithUserAndChannel(t, common)
+
+	m := mustMakeMessage(t, repo, user.ID, channel.ID)
+	at := time.Now().Add(time.Hour)
+
+	_, err := repo.CreateReminder(uuid.Nil, m.ID, at)
+	assert.Equal(ErrNilID, err)
+	_, err = repo.CreateReminder(user.ID, uuid.Nil, at)
+	assert.Equal(ErrNilID, err)
+
+	r, err := repo.CreateReminder(user.ID, m.ID, at)
+	if assert.NoError(err) {
+		assert.NotEqual(uuid.Nil, r.ID)
+		assert.Equal(user.ID, r.UserID)
+		assert.Equal(m.ID, r.MessageID)
+		assert.Equal(model.ReminderPending, r.State)
+	}
+}
+
+func TestRepositoryImpl_SnoozeReminder(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, common)
+
+	m := mustMakeMessage(t, repo, user.ID, channel.ID)
+	r, err := repo.CreateReminder(user.ID, m.ID, time.Now())
+	require.NoError(err)
+
+	at := time.Now().Add(time.Hour).Truncate(time.Microsecond)
+	assert.Equal(ErrNilID, repo.SnoozeReminder(uuid.Nil, at))
+	assert.Equal(ErrNotFound, repo.SnoozeReminder(uuid.Must(uuid.NewV4()), at))
+
+	ok, err := repo.ClaimReminder(r.ID)
+	require.NoError(err)
+	require.True(ok)
+	assert.Equal(ErrForbidden, repo.SnoozeReminder(r.ID, at))
+
+	require.NoError(repo.MarkReminderSent(r.ID))
+	if assert.NoError(repo.SnoozeReminder(r.ID, at)) {
+		r, err := repo.GetReminder(r.ID)
+		require.NoError(err)
+		assert.Equal(model.ReminderPending, r.State)
+		assert.True(at.Equal(r.RemindAt))
+	}
+}
+
+func TestRepositoryImpl_DeleteReminder(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, common)
+
+	m := mustMakeMessage(t, repo, user.ID, channel.ID)
+	r1, err := repo.CreateReminder(user.ID, m.ID, time.Now().Add(time.Hour))
+	require.NoError(err)
+	r2, err := repo.CreateReminder(user.ID, m.ID, time.Now().Add(time.Hour))
+	require.NoError(err)
+	ok, err := repo.ClaimReminder(r2.ID)
+	require.NoError(err)
+	require.True(ok)
+
+	assert.Equal(ErrNilID, repo.DeleteReminder(uuid.Nil))
+	assert.Equal(ErrNotFound, repo.DeleteReminder(uuid.Must(uuid.NewV4())))
+	assert.Equal(ErrForbidden, repo.DeleteReminder(r2.ID))
+	if assert.NoError(repo.DeleteReminder(r1.ID)) {
+		_, err := repo.GetReminder(r1.ID)
+		assert.Equal(ErrNotFound, err)
+	}
+}
+
+func TestRepositoryImpl_GetRemindersByUserID(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, common)
+
+	m := mustMakeMessage(t, repo, user.ID, channel.ID)
+	r1, err := repo.CreateReminder(user.ID, m.ID, time.Now().Add(2*time.Hour))
+	require.NoError(err)
+	r2, err := repo.CreateReminder(user.ID, m.ID, time.Now().Add(time.Hour))
+	require.NoError(err)
+
+	arr, err := repo.GetRemindersByUserID(user.ID)
+	if assert.NoError(err) && assert.Len(arr, 2) {
+		assert.Equal(r2.ID, arr[0].ID)
+		assert.Equal(r1.ID, arr[1].ID)
+	}
+
+	arr, err = repo.GetRemindersByUserID(uuid.Nil)
+	if assert.NoError(err) {
+		assert.Len(arr, 0)
+	}
+}
+
+func TestRepositoryImpl_GetDueReminders(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, ex1)
+
+	m := mustMakeMessage(t, repo, user.ID, channel.ID)
+	due, err := repo.CreateReminder(user.ID, m.ID, time.Now().Add(-time.Minute))
+	require.NoError(err)
+	notDue, err := repo.CreateReminder(user.ID, m.ID, time.Now().Add(time.Hour))
+	require.NoError(err)
+
+	arr, err := repo.GetDueReminders(time.Now(), -1)
+	if assert.NoError(err) {
+		ids := make([]uuid.UUID, len(arr))
+		for i, v := range arr {
+			ids[i] = v.ID
+		}
+		assert.Contains(ids, due.ID)
+		assert.NotContains(ids, notDue.ID)
+	}
+}
+
+func TestRepositoryImpl_ClaimReminder(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, common)
+
+	m := mustMakeMessage(t, repo, user.ID, channel.ID)
+	r, err := repo.CreateReminder(user.ID, m.ID, time.Now())
+	require.NoError(err)
+
+	_, err = repo.ClaimReminder(uuid.Nil)
+	assert.Equal(ErrNilID, err)
+
+	ok, err := repo.ClaimReminder(r.ID)
+	if assert.NoError(err) {
+		assert.True(ok)
+	}
+	ok, err = repo.ClaimReminder(r.ID)
+	if assert.NoError(err) {
+		assert.False(ok)
+	}
+}
+
+func TestRepositoryImpl_MarkReminderSent(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, common)
+
+	m := mustMakeMessage(t, repo, user.ID, channel.ID)
+	r, err := repo.CreateReminder(user.ID, m.ID, time.Now())
+	require.NoError(err)
+	ok, err := repo.ClaimReminder(r.ID)
+	require.NoError(err)
+	require.True(ok)
+
+	assert.Equal(ErrNilID, repo.MarkReminderSent(uuid.Nil))
+	assert.Equal(ErrNotFound, repo.MarkReminderSent(uuid.Must(uuid.NewV4())))
+	if assert.NoError(repo.MarkReminderSent(r.ID)) {
+		r, err := repo.GetReminder(r.ID)
+		require.NoError(err)
+		assert.Equal(model.ReminderSent, r.State)
+	}
+}
+
+func TestRepositoryImpl_MarkReminderFailed(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, common)
+
+	m := mustMakeMessage(t, repo, user.ID, channel.ID)
+	r, err := repo.CreateReminder(user.ID, m.ID, time.Now())
+	require.NoError(err)
+	ok, err := repo.ClaimReminder(r.ID)
+	require.NoError(err)
+	require.True(ok)
+
+	assert.Equal(ErrNilID, repo.MarkReminderFailed(uuid.Nil, "reason"))
+	if assert.NoError(repo.MarkReminderFailed(r.ID, "reason")) {
+		r, err := repo.GetReminder(r.ID)
+		require.NoError(err)
+		assert.Equal(model.ReminderFailed, r.State)
+		assert.Equal("reason", r.Error)
+	}
+}
+
+func TestRepositoryImpl_FailStaleReminders(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, ex2)
+
+	m := mustMakeMessage(t, repo, user.ID, channel.ID)
+	r, err := repo.CreateReminder(user.ID, m.ID, time.Now())
+	require.NoError(err)
+	ok, err := repo.ClaimReminder(r.ID)
+	require.NoError(err)
+	require.True(ok)
+
+	n, err := repo.FailStaleReminders(time.Now().Add(time.Minute))
+	if assert.NoError(err) {
+		assert.True(n >= 1)
+		r, err := repo.GetReminder(r.ID)
+		require.NoError(err)
+		assert.Equal(model.ReminderFailed, r.State)
+	}
+}
+
+func TestRepositoryImpl_GetReminderBotUser(t *testing.T) {
+	t.Parallel()
+	repo, assert, _ := setup(t, common)
+
+	u1, err := repo.GetReminderBotUser()
+	if assert.NoError(err) {
+		assert.Equal(model.ReminderBotUserName, u1.Name)
+		assert.True(u1.Bot)
+	}
+	u2, err := repo.GetReminderBotUser()
+	if assert.NoError(err) {
+		assert.Equal(u1.ID, u2.ID)
+	}
+}
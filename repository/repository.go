@@ -28,4 +28,14 @@ type Repository interface {
 	WebhookRepository
 	OAuth2Repository
 	BotRepository
+	ScheduledMessageRepository
+	ChannelExportRepository
+	ChannelRetentionPolicyRepository
+	LinkPreviewRepository
+	UnreadRepository
+	PollRepository
+	ContentFilterRuleRepository
+	ModerationRepository
+	MentionRepository
+	ReminderRepository
 }
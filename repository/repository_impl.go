@@ -11,12 +11,15 @@ import (
 	"github.com/traPtitech/traQ/event"
 	"github.com/traPtitech/traQ/model"
 	"github.com/traPtitech/traQ/rbac/role"
+	"github.com/traPtitech/traQ/utils/message"
 	"github.com/traPtitech/traQ/utils/storage"
 	"time"
 )
 
 const (
 	errMySQLDuplicatedRecord uint16 = 1062
+
+	messageTextFullTextIndex = "messages_text_fulltext_idx"
 )
 
 var (
@@ -41,6 +44,7 @@ type GormRepository struct {
 	hub *hub.Hub
 	channelImpl
 	fileImpl
+	contentFilterImpl
 	*heartbeatImpl
 }
 
@@ -51,6 +55,41 @@ func (repo *GormRepository) Sync() (bool, error) {
 		return false, fmt.Errorf("failed to sync Table schema: %v", err)
 	}
 
+	// 既存メッセージの編集回数をアーカイブから埋める
+	if err := repo.runMigration("message_edit_count", repo.fillMessageEditCount); err != nil {
+		return false, fmt.Errorf("failed to fill messages.edit_count: %v", err)
+	}
+
+	// 非公開チャンネルに埋め込まれている既存のファイルをチャンネルに紐付ける
+	if err := repo.runMigration("file_channels", repo.migrateFileChannels); err != nil {
+		return false, fmt.Errorf("failed to migrate files.channel_id: %v", err)
+	}
+
+	// パブリックチャンネルの購読者を参加メンバーとする
+	if err := repo.runMigration("channel_members", repo.fillChannelMembers); err != nil {
+		return false, fmt.Errorf("failed to fill channel_members: %v", err)
+	}
+
+	// 全文検索インデックス同期
+	if err := repo.syncFullTextIndex(); err != nil {
+		return false, fmt.Errorf("failed to sync fulltext index: %v", err)
+	}
+
+	// メッセージ単位の未読から既読位置への移行
+	unreadsMigrated, err := dbExists(repo.db, &model.Migration{ID: "channel_read_pointers"})
+	if err != nil {
+		return false, err
+	}
+	if err := repo.runMigration("channel_read_pointers", repo.migrateUnreads); err != nil {
+		return false, fmt.Errorf("failed to migrate unreads: %v", err)
+	}
+	// 移行元の未読は、移行が記録された後の同期で削除する
+	if unreadsMigrated {
+		if err := repo.runMigration("drop_unreads", repo.dropMigratedUnreads); err != nil {
+			return false, fmt.Errorf("failed to drop migrated unreads: %v", err)
+		}
+	}
+
 	// 外部キー制約同期
 	for _, c := range model.Constraints {
 		if err := repo.db.Table(c[0]).AddForeignKey(c[1], c[2], c[3], c[4]).Error; err != nil {
@@ -60,7 +99,7 @@ func (repo *GormRepository) Sync() (bool, error) {
 
 	// サーバーユーザーの確認
 	c := 0
-	err := repo.db.Model(&model.User{}).Where(&model.User{Role: role.Admin.ID()}).Limit(1).Count(&c).Error
+	err = repo.db.Model(&model.User{}).Where(&model.User{Role: role.Admin.ID()}).Limit(1).Count(&c).Error
 	if err != nil {
 		return false, err
 	}
@@ -91,6 +130,148 @@ func (repo *GormRepository) Sync() (bool, error) {
 	return false, nil
 }
 
+// syncFullTextIndex メッセージ本文の全文検索インデックスを作成します
+func (repo *GormRepository) syncFullTextIndex() error {
+	if repo.db.Dialect().HasIndex("messages", messageTextFullTextIndex) {
+		return nil
+	}
+	// 日本語を検索できるようにngramパーサーを使う
+	err := repo.db.Exec(fmt.Sprintf("CREATE FULLTEXT INDEX %s ON messages (text) WITH PARSER ngram", messageTextFullTextIndex)).Error
+	if err != nil {
+		// ngramパーサーが使用できない場合(MariaDBなど)は標準のパーサーを使う
+		return repo.db.Exec(fmt.Sprintf("CREATE FULLTEXT INDEX %s ON messages (text)", messageTextFullTextIndex)).Error
+	}
+	return nil
+}
+
+func (repo *GormRepository) fillMessageEditCount() error {
+	return repo.db.Exec("UPDATE messages m JOIN (SELECT message_id, COUNT(*) AS c FROM archived_messages GROUP BY message_id) a ON m.id = a.message_id SET m.edit_count = a.c").Error
+}
+
+func (repo *GormRepository) fillChannelMembers() error {
+	return repo.db.Exec("INSERT IGNORE INTO channel_members (channel_id, user_id, created_at) SELECT s.channel_id, s.user_id, ? FROM users_subscribe_channels s JOIN channels c ON c.id = s.channel_id WHERE c.is_public = TRUE", time.Now()).Error
+}
+
+// migrateFileChannels 非公開チャンネル・DMのメッセージにのみ埋め込まれている公開ファイルを、最初に埋め込まれたチャンネルに紐付けます
+//
+// チャンネルに紐付いていないファイルのみを更新するため、再実行できます。
+func (repo *GormRepository) migrateFileChannels() error {
+	rows, err := repo.db.Raw(`SELECT m.channel_id, m.text, c.is_public FROM messages m INNER JOIN channels c ON c.id = m.channel_id WHERE m.text LIKE ? ORDER BY m.created_at`, `%"type":"file"%`).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var (
+		publicFiles = map[uuid.UUID]bool{}
+		fileChannel = map[uuid.UUID]uuid.UUID{}
+		order       []uuid.UUID
+	)
+	for rows.Next() {
+		var (
+			channelID uuid.UUID
+			text      string
+			isPublic  bool
+		)
+		if err := rows.Scan(&channelID, &text, &isPublic); err != nil {
+			return err
+		}
+		for _, e := range message.ParseAST(text).Embedded() {
+			if e.Type != "file" {
+				continue
+			}
+			fid, err := uuid.FromString(e.ID)
+			if err != nil {
+				continue
+			}
+			if isPublic {
+				publicFiles[fid] = true
+			} else if _, ok := fileChannel[fid]; !ok {
+				fileChannel[fid] = channelID
+				order = append(order, fid)
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	return repo.transact(func(tx *gorm.DB) error {
+		for _, fid := range order {
+			if publicFiles[fid] {
+				continue
+			}
+			// ACLで既に閲覧者が制限されているファイルはそのままにする
+			err := tx.Exec(`UPDATE files SET channel_id = ? WHERE id = ? AND type = ? AND channel_id = ? AND id IN (SELECT file_id FROM files_acl WHERE user_id = ? AND allow = TRUE)`,
+				fileChannel[fid], fid, model.FileTypeUserFile, uuid.Nil, uuid.Nil).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// runMigration 実行済みとして記録されていないデータ移行を実行し、成功した場合に記録します
+//
+// 移行に失敗した場合は記録されず、次回の同期時に再度実行されます。そのため移行処理は再実行できるようにしてください。
+func (repo *GormRepository) runMigration(id string, migrate func() error) error {
+	if ok, err := dbExists(repo.db, &model.Migration{ID: id}); err != nil {
+		return err
+	} else if ok {
+		return nil
+	}
+	if err := migrate(); err != nil {
+		return err
+	}
+	return repo.db.Create(&model.Migration{ID: id, AppliedAt: time.Now()}).Error
+}
+
+// migrateUnreads unreadsテーブルの未読を既読位置に変換し、unreadsテーブルをunreads_migratedに退避します
+//
+// MySQLではDDLが暗黙的にコミットされるため、既読位置の作成をコミットした後にテーブルを退避します。
+// 既読位置の作成は既存の既読位置を上書きしないため、退避前に失敗した場合も再実行できます。
+func (repo *GormRepository) migrateUnreads() error {
+	if !repo.db.HasTable("unreads") {
+		return nil
+	}
+
+	err := repo.transact(func(tx *gorm.DB) error {
+		now := time.Now()
+		// 未読メッセージがある場合は、最も古い未読メッセージの直前を既読位置とする
+		err := tx.Exec(`INSERT IGNORE INTO channel_read_pointers (user_id, channel_id, last_read_at, mention_count, notified_count, first_notified_at, last_notified_at, updated_at)
+SELECT u.user_id, m.channel_id, MIN(m.created_at) - INTERVAL 1 MICROSECOND, SUM(u.noticeable), COUNT(*), MIN(m.created_at), MAX(m.created_at), ?
+FROM unreads u INNER JOIN messages m ON m.id = u.message_id
+GROUP BY u.user_id, m.channel_id`, now).Error
+		if err != nil {
+			return err
+		}
+
+		// 未読メッセージが無い場合は、全て既読とする
+		queries := []string{
+			`INSERT IGNORE INTO channel_read_pointers (user_id, channel_id, last_read_at, updated_at) SELECT user_id, channel_id, ?, ? FROM users_subscribe_channels`,
+			`INSERT IGNORE INTO channel_read_pointers (user_id, channel_id, last_read_at, updated_at) SELECT user_id, channel_id, ?, ? FROM users_private_channels`,
+			`INSERT IGNORE INTO channel_read_pointers (user_id, channel_id, last_read_at, updated_at) SELECT u.id, c.id, ?, ? FROM users u CROSS JOIN channels c WHERE c.is_forced = TRUE`,
+		}
+		for _, q := range queries {
+			if err := tx.Exec(q, now, now).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return repo.db.Exec("RENAME TABLE unreads TO unreads_migrated").Error
+}
+
+// dropMigratedUnreads 既読位置への移行後に退避したunreads_migratedテーブルを削除します
+func (repo *GormRepository) dropMigratedUnreads() error {
+	return repo.db.DropTableIfExists("unreads_migrated").Error
+}
+
 // GetFS implements Repository interface.
 func (repo *GormRepository) GetFS() storage.FileStorage {
 	return repo.FS
@@ -161,11 +161,6 @@ func mustMakeMessage(t *testing.T, repo Repository, userID, channelID uuid.UUID)
 	return m
 }
 
-func mustMakeMessageUnread(t *testing.T, repo Repository, userID, messageID uuid.UUID) {
-	t.Helper()
-	require.NoError(t, repo.SetMessageUnread(userID, messageID, false))
-}
-
 func mustMakeUser(t *testing.T, repo Repository, userName string) *model.User {
 	t.Helper()
 	if userName == random {
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/traPtitech/traQ/model"
+	"gopkg.in/guregu/null.v3"
+)
+
+// UpdateScheduledMessageArgs 予約投稿メッセージ更新引数
+type UpdateScheduledMessageArgs struct {
+	Text        null.String
+	ScheduledAt null.Time
+}
+
+// ScheduledMessageRepository 予約投稿メッセージリポジトリ
+type ScheduledMessageRepository interface {
+	// CreateScheduledMessage 予約投稿メッセージを作成します
+	//
+	// 成功した場合、予約投稿メッセージとnilを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	CreateScheduledMessage(userID, channelID uuid.UUID, text string, scheduledAt time.Time) (*model.ScheduledMessage, error)
+	// UpdateScheduledMessage 指定した予約投稿メッセージを更新します
+	//
+	// 成功した場合、nilを返します。
+	// 存在しない予約投稿メッセージを指定した場合、ErrNotFoundを返します。
+	// 既に投稿処理が開始されている予約投稿メッセージを指定した場合、ErrForbiddenを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	UpdateScheduledMessage(id uuid.UUID, args UpdateScheduledMessageArgs) error
+	// DeleteScheduledMessage 指定した予約投稿メッセージを削除します
+	//
+	// 成功した場合、nilを返します。
+	// 存在しない予約投稿メッセージを指定した場合、ErrNotFoundを返します。
+	// 投稿処理中の予約投稿メッセージを指定した場合、ErrForbiddenを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	DeleteScheduledMessage(id uuid.UUID) error
+	// GetScheduledMessage 指定した予約投稿メッセージを取得します
+	//
+	// 成功した場合、予約投稿メッセージとnilを返します。
+	// 存在しない予約投稿メッセージを指定した場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	GetScheduledMessage(id uuid.UUID) (*model.ScheduledMessage, error)
+	// GetScheduledMessagesByUserID 指定したユーザーの予約投稿メッセージを投稿予定日時の昇順で全て取得します
+	//
+	// 成功した場合、予約投稿メッセージの配列とnilを返します。
+	// 存在しないユーザーを指定した場合は空配列とnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetScheduledMessagesByUserID(userID uuid.UUID) ([]*model.ScheduledMessage, error)
+	// GetDueScheduledMessages 指定した日時までに投稿予定の投稿待ち予約投稿メッセージを投稿予定日時の昇順で取得します
+	//
+	// 成功した場合、予約投稿メッセージの配列とnilを返します。負のlimitは無視されます。
+	// DBによるエラーを返すことがあります。
+	GetDueScheduledMessages(now time.Time, limit int) ([]*model.ScheduledMessage, error)
+	// ClaimScheduledMessage 指定した投稿待ちの予約投稿メッセージを投稿処理中にします
+	//
+	// 状態の遷移はアトミックに行われ、複数のプロセスから同時に呼び出された場合でも
+	// trueを返すのは一つの呼び出しのみです。
+	// 成功した場合、trueとnilを返します。
+	// 既に他で投稿処理が開始されていた場合や存在しない場合、falseとnilを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	ClaimScheduledMessage(id uuid.UUID) (bool, error)
+	// MarkScheduledMessageSent 指定した投稿処理中の予約投稿メッセージを投稿済みにします
+	//
+	// 成功した場合、nilを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	MarkScheduledMessageSent(id, messageID uuid.UUID) error
+	// MarkScheduledMessageFailed 指定した投稿処理中の予約投稿メッセージを投稿失敗にします
+	//
+	// 成功した場合、nilを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	MarkScheduledMessageFailed(id uuid.UUID, reason string) error
+	// FailStaleScheduledMessages 指定した日時以前から投稿処理中のままの予約投稿メッセージを投稿失敗にします
+	//
+	// 投稿処理中にプロセスが終了した場合に使用します。二重投稿を防ぐため、これらは再投稿されません。
+	// 成功した場合、投稿失敗にした件数とnilを返します。
+	// DBによるエラーを返すことがあります。
+	FailStaleScheduledMessages(before time.Time) (int, error)
+}
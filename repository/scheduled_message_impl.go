@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jinzhu/gorm"
+	"github.com/traPtitech/traQ/model"
+)
+
+// CreateScheduledMessage implements ScheduledMessageRepository interface.
+func (repo *GormRepository) CreateScheduledMessage(userID, channelID uuid.UUID, text string, scheduledAt time.Time) (*model.ScheduledMessage, error) {
+	if userID == uuid.Nil || channelID == uuid.Nil {
+		return nil, ErrNilID
+	}
+	m := &model.ScheduledMessage{
+		ID:          uuid.Must(uuid.NewV4()),
+		UserID:      userID,
+		ChannelID:   channelID,
+		Text:        text,
+		ScheduledAt: scheduledAt,
+		State:       model.ScheduledMessagePending,
+	}
+	if err := repo.db.Create(m).Error; err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// UpdateScheduledMessage implements ScheduledMessageRepository interface.
+func (repo *GormRepository) UpdateScheduledMessage(id uuid.UUID, args UpdateScheduledMessageArgs) error {
+	if id == uuid.Nil {
+		return ErrNilID
+	}
+
+	changes := map[string]interface{}{}
+	if args.Text.Valid {
+		changes["text"] = args.Text.String
+	}
+	if args.ScheduledAt.Valid {
+		changes["scheduled_at"] = args.ScheduledAt.Time
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+
+	return repo.transact(func(tx *gorm.DB) error {
+		var m model.ScheduledMessage
+		if err := tx.Where(&model.ScheduledMessage{ID: id}).First(&m).Error; err != nil {
+			return convertError(err)
+		}
+
+		// 投稿待ちの場合のみ更新できる
+		result := tx.Model(&model.ScheduledMessage{}).Where("id = ? AND state = ?", id, model.ScheduledMessagePending).Updates(changes)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrForbidden
+		}
+		return nil
+	})
+}
+
+// DeleteScheduledMessage implements ScheduledMessageRepository interface.
+func (repo *GormRepository) DeleteScheduledMessage(id uuid.UUID) error {
+	if id == uuid.Nil {
+		return ErrNilID
+	}
+	return repo.transact(func(tx *gorm.DB) error {
+		var m model.ScheduledMessage
+		if err := tx.Where(&model.ScheduledMessage{ID: id}).First(&m).Error; err != nil {
+			return convertError(err)
+		}
+
+		// 投稿処理中のものは削除できない
+		result := tx.Where("id = ? AND state <> ?", id, model.ScheduledMessageSending).Delete(&model.ScheduledMessage{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrForbidden
+		}
+		return nil
+	})
+}
+
+// GetScheduledMessage implements ScheduledMessageRepository interface.
+func (repo *GormRepository) GetScheduledMessage(id uuid.UUID) (*model.ScheduledMessage, error) {
+	if id == uuid.Nil {
+		return nil, ErrNotFound
+	}
+	m := &model.ScheduledMessage{}
+	if err := repo.db.Where(&model.ScheduledMessage{ID: id}).Take(m).Error; err != nil {
+		return nil, convertError(err)
+	}
+	return m, nil
+}
+
+// GetScheduledMessagesByUserID implements ScheduledMessageRepository interface.
+func (repo *GormRepository) GetScheduledMessagesByUserID(userID uuid.UUID) (arr []*model.ScheduledMessage, err error) {
+	arr = make([]*model.ScheduledMessage, 0)
+	if userID == uuid.Nil {
+		return arr, nil
+	}
+	err = repo.db.Where(&model.ScheduledMessage{UserID: userID}).Order("scheduled_at").Find(&arr).Error
+	return arr, err
+}
+
+// GetDueScheduledMessages implements ScheduledMessageRepository interface.
+func (repo *GormRepository) GetDueScheduledMessages(now time.Time, limit int) (arr []*model.ScheduledMessage, err error) {
+	arr = make([]*model.ScheduledMessage, 0)
+	err = repo.db.
+		Where("state = ? AND scheduled_at <= ?", model.ScheduledMessagePending, now).
+		Order("scheduled_at").
+		Scopes(limitAndOffset(limit, 0)).
+		Find(&arr).
+		Error
+	return arr, err
+}
+
+// ClaimScheduledMessage implements ScheduledMessageRepository interface.
+func (repo *GormRepository) ClaimScheduledMessage(id uuid.UUID) (bool, error) {
+	if id == uuid.Nil {
+		return false, ErrNilID
+	}
+	result := repo.db.
+		Model(&model.ScheduledMessage{}).
+		Where("id = ? AND state = ?", id, model.ScheduledMessagePending).
+		Updates(map[string]interface{}{"state": model.ScheduledMessageSending})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// MarkScheduledMessageSent implements ScheduledMessageRepository interface.
+func (repo *GormRepository) MarkScheduledMessageSent(id, messageID uuid.UUID) error {
+	if id == uuid.Nil || messageID == uuid.Nil {
+		return ErrNilID
+	}
+	return repo.db.
+		Model(&model.ScheduledMessage{}).
+		Where("id = ? AND state = ?", id, model.ScheduledMessageSending).
+		Updates(map[string]interface{}{"state": model.ScheduledMessageSent, "message_id": messageID}).
+		Error
+}
+
+// MarkScheduledMessageFailed implements ScheduledMessageRepository interface.
+func (repo *GormRepository) MarkScheduledMessageFailed(id uuid.UUID, reason string) error {
+	if id == uuid.Nil {
+		return ErrNilID
+	}
+	return repo.db.
+		Model(&model.ScheduledMessage{}).
+		Where("id = ? AND state = ?", id, model.ScheduledMessageSending).
+		Updates(map[string]interface{}{"state": model.ScheduledMessageFailed, "error": reason}).
+		Error
+}
+
+// FailStaleScheduledMessages implements ScheduledMessageRepository interface.
+func (repo *GormRepository) FailStaleScheduledMessages(before time.Time) (int, error) {
+	result := repo.db.
+		Model(&model.ScheduledMessage{}).
+		Where("state = ? AND updated_at < ?", model.ScheduledMessageSending, before).
+		Updates(map[string]interface{}{"state": model.ScheduledMessageFailed, "error": "interrupted while sending"})
+	return int(result.RowsAffected), result.Error
+}
@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/traPtitech/traQ/model"
+	"gopkg.in/guregu/null.v3"
+)
+
+func TestRepositoryImpl_CreateScheduledMessage(t *testing.T) {
+	t.Parallel()
+	repo, assert, _, user, channel := setupWithUserAndChannel(t, common)
+
+	at := time.Now().Add(time.Hour)
+
+	_, err := repo.CreateScheduledMessage(uuid.Nil, channel.ID, "test", at)
+	assert.Equal(ErrNilID, err)
+	_, err = repo.CreateScheduledMessage(user.ID, uuid.Nil, "test", at)
+	assert.Equal(ErrNilID, err)
+
+	m, err := repo.CreateScheduledMessage(user.ID, channel.ID, "test", at)
+	if assert.NoError(err) {
+		assert.NotEqual(uuid.Nil, m.ID)
+		assert.Equal(user.ID, m.UserID)
+		assert.Equal(channel.ID, m.ChannelID)
+		assert.Equal("test", m.Text)
+		assert.Equal(model.ScheduledMessagePending, m.State)
+		assert.Equal(uuid.Nil, m.MessageID)
+	}
+}
+
+func TestRepositoryImpl_UpdateScheduledMessage(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, common)
+
+	m, err := repo.CreateScheduledMessage(user.ID, channel.ID, "test", time.Now().Add(time.Hour))
+	require.NoError(err)
+
+	assert.Equal(ErrNilID, repo.UpdateScheduledMessage(uuid.Nil, UpdateScheduledMessageArgs{Text: null.StringFrom("a")}))
+	assert.Equal(ErrNotFound, repo.UpdateScheduledMessage(uuid.Must(uuid.NewV4()), UpdateScheduledMessageArgs{Text: null.StringFrom("a")}))
+
+	at := time.Now().Add(2 * time.Hour).Truncate(time.Microsecond)
+	if assert.NoError(repo.UpdateScheduledMessage(m.ID, UpdateScheduledMessageArgs{Text: null.StringFrom("updated"), ScheduledAt: null.TimeFrom(at)})) {
+		m, err := repo.GetScheduledMessage(m.ID)
+		require.NoError(err)
+		assert.Equal("updated", m.Text)
+		assert.True(at.Equal(m.ScheduledAt))
+	}
+
+	ok, err := repo.ClaimScheduledMessage(m.ID)
+	require.NoError(err)
+	require.True(ok)
+	assert.Equal(ErrForbidden, repo.UpdateScheduledMessage(m.ID, UpdateScheduledMessageArgs{Text: null.StringFrom("a")}))
+}
+
+func TestRepositoryImpl_DeleteScheduledMessage(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, common)
+
+	m1, err := repo.CreateScheduledMessage(user.ID, channel.ID, "test", time.Now().Add(time.Hour))
+	require.NoError(err)
+	m2, err := repo.CreateScheduledMessage(user.ID, channel.ID, "test", time.Now().Add(time.Hour))
+	require.NoError(err)
+	ok, err := repo.ClaimScheduledMessage(m2.ID)
+	require.NoError(err)
+	require.True(ok)
+
+	assert.Equal(ErrNilID, repo.DeleteScheduledMessage(uuid.Nil))
+	assert.Equal(ErrNotFound, repo.DeleteScheduledMessage(uuid.Must(uuid.NewV4())))
+	assert.Equal(ErrForbidden, repo.DeleteScheduledMessage(m2.ID))
+	if assert.NoError(repo.DeleteScheduledMessage(m1.ID)) {
+		_, err := repo.GetScheduledMessage(m1.ID)
+		assert.Equal(ErrNotFound, err)
+	}
+}
+
+func TestRepositoryImpl_GetScheduledMessage(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, common)
+
+	m, err := repo.CreateScheduledMessage(user.ID, channel.ID, "test", time.Now().Add(time.Hour))
+	require.NoError(err)
+
+	_, err = repo.GetScheduledMessage(uuid.Nil)
+	assert.Equal(ErrNotFound, err)
+	_, err = repo.GetScheduledMessage(uuid.Must(uuid.NewV4()))
+	assert.Equal(ErrNotFound, err)
+
+	r, err := repo.GetScheduledMessage(m.ID)
+	if assert.NoError(err) {
+		assert.Equal(m.ID, r.ID)
+		assert.Equal(m.Text, r.Text)
+	}
+}
+
+func TestRepositoryImpl_GetScheduledMessagesByUserID(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, common)
+
+	m1, err := repo.CreateScheduledMessage(user.ID, channel.ID, "test", time.Now().Add(2*time.Hour))
+	require.NoError(err)
+	m2, err := repo.CreateScheduledMessage(user.ID, channel.ID, "test", time.Now().Add(time.Hour))
+	require.NoError(err)
+
+	arr, err := repo.GetScheduledMessagesByUserID(user.ID)
+	if assert.NoError(err) && assert.Len(arr, 2) {
+		assert.Equal(m2.ID, arr[0].ID)
+		assert.Equal(m1.ID, arr[1].ID)
+	}
+
+	arr, err = repo.GetScheduledMessagesByUserID(uuid.Nil)
+	if assert.NoError(err) {
+		assert.Len(arr, 0)
+	}
+}
+
+func TestRepositoryImpl_GetDueScheduledMessages(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, ex1)
+
+	due, err := repo.CreateScheduledMessage(user.ID, channel.ID, "test", time.Now().Add(-time.Minute))
+	require.NoError(err)
+	notDue, err := repo.CreateScheduledMessage(user.ID, channel.ID, "test", time.Now().Add(time.Hour))
+	require.NoError(err)
+
+	arr, err := repo.GetDueScheduledMessages(time.Now(), -1)
+	if assert.NoError(err) {
+		ids := make([]uuid.UUID, len(arr))
+		for i, v := range arr {
+			ids[i] = v.ID
+		}
+		assert.Contains(ids, due.ID)
+		assert.NotContains(ids, notDue.ID)
+	}
+}
+
+func TestRepositoryImpl_ClaimScheduledMessage(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, common)
+
+	m, err := repo.CreateScheduledMessage(user.ID, channel.ID, "test", time.Now())
+	require.NoError(err)
+
+	_, err = repo.ClaimScheduledMessage(uuid.Nil)
+	assert.Equal(ErrNilID, err)
+
+	ok, err := repo.ClaimScheduledMessage(m.ID)
+	if assert.NoError(err) {
+		assert.True(ok)
+	}
+	ok, err = repo.ClaimScheduledMessage(m.ID)
+	if assert.NoError(err) {
+		assert.False(ok)
+	}
+	ok, err = repo.ClaimScheduledMessage(uuid.Must(uuid.NewV4()))
+	if assert.NoError(err) {
+		assert.False(ok)
+	}
+}
+
+func TestRepositoryImpl_MarkScheduledMessageSent(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, common)
+
+	m, err := repo.CreateScheduledMessage(user.ID, channel.ID, "test", time.Now())
+	require.NoError(err)
+	ok, err := repo.ClaimScheduledMessage(m.ID)
+	require.NoError(err)
+	require.True(ok)
+	posted := mustMakeMessage(t, repo, user.ID, channel.ID)
+
+	assert.Equal(ErrNilID, repo.MarkScheduledMessageSent(uuid.Nil, posted.ID))
+	assert.Equal(ErrNilID, repo.MarkScheduledMessageSent(m.ID, uuid.Nil))
+	if assert.NoError(repo.MarkScheduledMessageSent(m.ID, posted.ID)) {
+		m, err := repo.GetScheduledMessage(m.ID)
+		require.NoError(err)
+		assert.Equal(model.ScheduledMessageSent, m.State)
+		assert.Equal(posted.ID, m.MessageID)
+	}
+}
+
+func TestRepositoryImpl_MarkScheduledMessageFailed(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, common)
+
+	m, err := repo.CreateScheduledMessage(user.ID, channel.ID, "test", time.Now())
+	require.NoError(err)
+	ok, err := repo.ClaimScheduledMessage(m.ID)
+	require.NoError(err)
+	require.True(ok)
+
+	assert.Equal(ErrNilID, repo.MarkScheduledMessageFailed(uuid.Nil, "reason"))
+	if assert.NoError(repo.MarkScheduledMessageFailed(m.ID, "reason")) {
+		m, err := repo.GetScheduledMessage(m.ID)
+		require.NoError(err)
+		assert.Equal(model.ScheduledMessageFailed, m.State)
+		assert.Equal("reason", m.Error)
+	}
+}
+
+func TestRepositoryImpl_FailStaleScheduledMessages(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, ex2)
+
+	m, err := repo.CreateScheduledMessage(user.ID, channel.ID, "test", time.Now())
+	require.NoError(err)
+	ok, err := repo.ClaimScheduledMessage(m.ID)
+	require.NoError(err)
+	require.True(ok)
+
+	n, err := repo.FailStaleScheduledMessages(time.Now().Add(time.Minute))
+	if assert.NoError(err) {
+		assert.True(n >= 1)
+		m, err := repo.GetScheduledMessage(m.ID)
+		require.NoError(err)
+		assert.Equal(model.ScheduledMessageFailed, m.State)
+	}
+}
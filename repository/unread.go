@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/traPtitech/traQ/model"
+)
+
+// UserUnreadChannel ユーザーの未読チャンネル構造体
+type UserUnreadChannel struct {
+	ChannelID  uuid.UUID `json:"channelId"`
+	Count      int       `json:"count"`
+	Noticeable bool      `json:"noticeable"`
+	Since      time.Time `json:"since"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// UnreadRepository 未読管理リポジトリ
+//
+// 未読はユーザー・チャンネル毎の既読位置で管理します。
+// 通知を購読しているチャンネル・プライベートチャンネル・強制通知チャンネルでは既読位置以降の全てのメッセージが、
+// それ以外のチャンネルでは既読位置以降に自分に通知されたメッセージ(メンション・スレッドの返信)が未読になります。
+type UnreadRepository interface {
+	// ReadChannel 指定したユーザーの指定したチャンネルの既読位置を現在にし、未読の通知数をリセットします
+	//
+	// 成功した場合、nilを返します。存在しないチャンネルを指定した場合は何もせずnilを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	ReadChannel(userID, channelID uuid.UUID) error
+	// UpdateReadPointers 指定したユーザーの指定したチャンネルの既読位置を指定したメッセージまで進めます
+	//
+	// 既読位置が既にreadAtより後のユーザーは変更しません。未読の通知数は変更しません。
+	// 成功した場合、nilを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	UpdateReadPointers(channelID, messageID uuid.UUID, readAt time.Time, userIDs []uuid.UUID) error
+	// IncrementUnreadCounters 指定したユーザーの指定したチャンネルの未読の通知数を1増やします
+	//
+	// usersの値がtrueのユーザーはメンションの数も1増やします。
+	// 成功した場合、nilを返します。
+	// 引数にuuid.Nilを指定するとErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	IncrementUnreadCounters(channelID uuid.UUID, notifiedAt time.Time, users map[uuid.UUID]bool) error
+	// GetReadPointer 指定したユーザーの指定したチャンネルの既読位置を取得します
+	//
+	// 成功した場合、既読位置とnilを返します。
+	// 存在しない場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	GetReadPointer(userID, channelID uuid.UUID) (*model.ChannelReadPointer, error)
+	// GetUserUnreadChannels 指定したユーザーの未読チャンネル一覧を取得します
+	//
+	// 成功した場合、最新の未読メッセージの日時の降順でUserUnreadChannelの配列とnilを返します。
+	// 既読位置が無い購読チャンネルは、チャンネルに参加した日時以降のメッセージを未読とします。
+	// 存在しないユーザーを指定した場合、空配列とnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetUserUnreadChannels(userID uuid.UUID) ([]*UserUnreadChannel, error)
+}
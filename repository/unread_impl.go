@@ -0,0 +1,366 @@
+package repository
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jinzhu/gorm"
+	"github.com/leandro-lugaresi/hub"
+	"github.com/traPtitech/traQ/event"
+	"github.com/traPtitech/traQ/model"
+)
+
+// 未読メッセージ数を数えるクエリ1回あたりのチャンネル数
+const unreadCountChunkSize = 100
+
+// ReadChannel implements UnreadRepository interface.
+func (repo *GormRepository) ReadChannel(userID, channelID uuid.UUID) error {
+	if userID == uuid.Nil || channelID == uuid.Nil {
+		return ErrNilID
+	}
+
+	if exists, err := dbExists(repo.db, &model.Channel{ID: channelID}); err != nil {
+		return err
+	} else if !exists {
+		return nil
+	}
+
+	var clm model.ChannelLatestMessage
+	if err := repo.db.Where(&model.ChannelLatestMessage{ChannelID: channelID}).Take(&clm).Error; err != nil && !gorm.IsRecordNotFoundError(err) {
+		return err
+	}
+
+	now := time.Now()
+	err := repo.db.Exec(`INSERT INTO channel_read_pointers (user_id, channel_id, last_read_at, last_read_message_id, mention_count, notified_count, updated_at) VALUES (?, ?, ?, ?, 0, 0, ?)
+ON DUPLICATE KEY UPDATE last_read_at = VALUES(last_read_at), last_read_message_id = VALUES(last_read_message_id), mention_count = 0, notified_count = 0, first_notified_at = NULL, last_notified_at = NULL, updated_at = VALUES(updated_at)`,
+		userID, channelID, now, clm.MessageID, now).Error
+	if err != nil {
+		return err
+	}
+
+	repo.hub.Publish(hub.Message{
+		Name: event.ChannelRead,
+		Fields: hub.Fields{
+			"channel_id": channelID,
+			"user_id":    userID,
+		},
+	})
+	return nil
+}
+
+// UpdateReadPointers implements UnreadRepository interface.
+func (repo *GormRepository) UpdateReadPointers(channelID, messageID uuid.UUID, readAt time.Time, userIDs []uuid.UUID) error {
+	if channelID == uuid.Nil {
+		return ErrNilID
+	}
+	return updateReadPointers(repo.db, channelID, messageID, readAt, userIDs)
+}
+
+// updateReadPointers 既読位置をreadAtまで進めます
+func updateReadPointers(tx *gorm.DB, channelID, messageID uuid.UUID, readAt time.Time, userIDs []uuid.UUID) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	values := make([]string, 0, len(userIDs))
+	args := make([]interface{}, 0, len(userIDs)*5)
+	for _, id := range userIDs {
+		if id == uuid.Nil {
+			return ErrNilID
+		}
+		values = append(values, "(?, ?, ?, ?, ?)")
+		args = append(args, id, channelID, readAt, messageID, now)
+	}
+	// last_read_atを参照するためlast_read_message_idを先に更新する
+	return tx.Exec(`INSERT INTO channel_read_pointers (user_id, channel_id, last_read_at, last_read_message_id, updated_at) VALUES `+strings.Join(values, ", ")+`
+ON DUPLICATE KEY UPDATE
+last_read_message_id = IF(last_read_at IS NULL OR last_read_at < VALUES(last_read_at), VALUES(last_read_message_id), last_read_message_id),
+last_read_at = IF(last_read_at IS NULL OR last_read_at < VALUES(last_read_at), VALUES(last_read_at), last_read_at),
+updated_at = VALUES(updated_at)`, args...).Error
+}
+
+// ensureReadPointers 既読位置が無いユーザーの既読位置をreadAtにします
+//
+// 既に既読位置があるユーザーの既読位置は変更しません。
+func ensureReadPointers(tx *gorm.DB, channelID uuid.UUID, readAt time.Time, userIDs []uuid.UUID) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	values := make([]string, 0, len(userIDs))
+	args := make([]interface{}, 0, len(userIDs)*4)
+	for _, id := range userIDs {
+		if id == uuid.Nil {
+			return ErrNilID
+		}
+		values = append(values, "(?, ?, ?, ?)")
+		args = append(args, id, channelID, readAt, now)
+	}
+	return tx.Exec(`INSERT IGNORE INTO channel_read_pointers (user_id, channel_id, last_read_at, updated_at) VALUES `+strings.Join(values, ", "), args...).Error
+}
+
+// IncrementUnreadCounters implements UnreadRepository interface.
+func (repo *GormRepository) IncrementUnreadCounters(channelID uuid.UUID, notifiedAt time.Time, users map[uuid.UUID]bool) error {
+	if channelID == uuid.Nil {
+		return ErrNilID
+	}
+	if len(users) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	values := make([]string, 0, len(users))
+	args := make([]interface{}, 0, len(users)*6)
+	for id, mentioned := range users {
+		if id == uuid.Nil {
+			return ErrNilID
+		}
+		mention := 0
+		if mentioned {
+			mention = 1
+		}
+		values = append(values, "(?, ?, ?, 1, ?, ?, ?)")
+		args = append(args, id, channelID, mention, notifiedAt, notifiedAt, now)
+	}
+	// notified_countを参照するためfirst_notified_atを先に更新する
+	return repo.db.Exec(`INSERT INTO channel_read_pointers (user_id, channel_id, mention_count, notified_count, first_notified_at, last_notified_at, updated_at) VALUES `+strings.Join(values, ", ")+`
+ON DUPLICATE KEY UPDATE
+mention_count = mention_count + VALUES(mention_count),
+first_notified_at = IF(notified_count = 0, VALUES(first_notified_at), first_notified_at),
+notified_count = notified_count + 1,
+last_notified_at = VALUES(last_notified_at),
+updated_at = VALUES(updated_at)`, args...).Error
+}
+
+// recountUnreadCounters 指定したチャンネルの、until以前に通知されたメッセージを含む既読位置の通知カウントを数え直します
+//
+// 既読位置以降の自分以外が投稿した削除されていないメッセージのうち、
+// 自分がメンションされたメッセージと、自分が参加しているスレッドの返信を通知されたメッセージとして数えます。
+func recountUnreadCounters(tx *gorm.DB, channelID uuid.UUID, until time.Time) error {
+	var pointers []*model.ChannelReadPointer
+	if err := tx.Where("channel_id = ? AND notified_count > 0 AND first_notified_at <= ?", channelID, until).Find(&pointers).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, p := range pointers {
+		var since time.Time
+		if p.LastReadAt != nil {
+			since = *p.LastReadAt
+		}
+
+		var notified struct {
+			Count int
+			First *time.Time
+			Last  *time.Time
+		}
+		err := tx.Raw(`SELECT COUNT(m.id) AS count, MIN(m.created_at) AS first, MAX(m.created_at) AS last FROM messages m
+WHERE m.channel_id = ? AND m.deleted_at IS NULL AND m.user_id <> ? AND m.created_at > ?
+  AND (m.id IN (SELECT message_id FROM mentions WHERE user_id = ?)
+    OR (m.parent_id <> ? AND EXISTS (SELECT 1 FROM messages t WHERE (t.id = m.parent_id OR t.parent_id = m.parent_id) AND t.user_id = ? AND t.created_at < m.created_at)))`,
+			channelID, p.UserID, since, p.UserID, uuid.Nil, p.UserID).
+			Scan(&notified).
+			Error
+		if err != nil {
+			return err
+		}
+
+		var mentions int
+		err = tx.
+			Table("mentions x").
+			Joins("INNER JOIN messages m ON m.id = x.message_id").
+			Where("x.user_id = ? AND m.channel_id = ? AND m.deleted_at IS NULL AND m.user_id <> ? AND m.created_at > ?", p.UserID, channelID, p.UserID, since).
+			Count(&mentions).
+			Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.
+			Model(&model.ChannelReadPointer{}).
+			Where(&model.ChannelReadPointer{UserID: p.UserID, ChannelID: channelID}).
+			Updates(map[string]interface{}{
+				"mention_count":     mentions,
+				"notified_count":    notified.Count,
+				"first_notified_at": notified.First,
+				"last_notified_at":  notified.Last,
+				"updated_at":        now,
+			}).
+			Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetReadPointer implements UnreadRepository interface.
+func (repo *GormRepository) GetReadPointer(userID, channelID uuid.UUID) (*model.ChannelReadPointer, error) {
+	if userID == uuid.Nil || channelID == uuid.Nil {
+		return nil, ErrNotFound
+	}
+	var p model.ChannelReadPointer
+	if err := repo.db.Where(&model.ChannelReadPointer{UserID: userID, ChannelID: channelID}).Take(&p).Error; err != nil {
+		return nil, convertError(err)
+	}
+	return &p, nil
+}
+
+// GetUserUnreadChannels implements UnreadRepository interface.
+func (repo *GormRepository) GetUserUnreadChannels(userID uuid.UUID) ([]*UserUnreadChannel, error) {
+	res := make([]*UserUnreadChannel, 0)
+	if userID == uuid.Nil {
+		return res, nil
+	}
+	var user model.User
+	if err := repo.db.Where(&model.User{ID: userID}).Take(&user).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return res, nil
+		}
+		return nil, err
+	}
+
+	// 全てのメッセージを未読の対象にするチャンネルと、その対象になった日時(不明な場合はゼロ値)
+	tracked := map[uuid.UUID]time.Time{}
+	forced := map[uuid.UUID]bool{}
+
+	// 購読しているチャンネルは、既読位置が無い場合は参加日時以降を対象にする
+	var subscribed []struct {
+		ChannelID uuid.UUID
+		JoinedAt  *time.Time
+	}
+	err := repo.db.
+		Table("users_subscribe_channels s").
+		Select("s.channel_id, cm.created_at AS joined_at").
+		Joins("INNER JOIN channels c ON c.id = s.channel_id AND c.deleted_at IS NULL").
+		Joins("LEFT JOIN channel_members cm ON cm.channel_id = s.channel_id AND cm.user_id = s.user_id").
+		Where("s.user_id = ?", userID).
+		Scan(&subscribed).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range subscribed {
+		if v.JoinedAt != nil {
+			tracked[v.ChannelID] = *v.JoinedAt
+		} else {
+			tracked[v.ChannelID] = time.Time{}
+		}
+	}
+
+	var channels []*model.Channel
+	if err := repo.db.Where("is_forced = TRUE OR id IN (SELECT channel_id FROM users_private_channels WHERE user_id = ?)", userID).Find(&channels).Error; err != nil {
+		return nil, err
+	}
+	for _, ch := range channels {
+		since := ch.CreatedAt
+		if ch.IsForced {
+			forced[ch.ID] = true
+			if user.CreatedAt.After(since) {
+				since = user.CreatedAt
+			}
+		}
+		if since.After(tracked[ch.ID]) {
+			tracked[ch.ID] = since
+		}
+	}
+
+	var pointers []*model.ChannelReadPointer
+	err = repo.db.
+		Joins("INNER JOIN channels ON channels.id = channel_read_pointers.channel_id AND channels.deleted_at IS NULL").
+		Where("channel_read_pointers.user_id = ?", userID).
+		Find(&pointers).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	pointerMap := make(map[uuid.UUID]*model.ChannelReadPointer, len(pointers))
+	for _, p := range pointers {
+		pointerMap[p.ChannelID] = p
+	}
+
+	// 全てのメッセージが対象のチャンネルは既読位置以降のメッセージを数える
+	since := map[uuid.UUID]time.Time{}
+	for id, t := range tracked {
+		if p := pointerMap[id]; p != nil && p.LastReadAt != nil && p.LastReadAt.After(t) {
+			t = *p.LastReadAt
+		}
+		if t.IsZero() {
+			// 既読位置が不明
+			continue
+		}
+		since[id] = t
+	}
+	counts, err := repo.countMessagesSince(userID, since)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range counts {
+		c.Noticeable = forced[c.ChannelID]
+		if p := pointerMap[c.ChannelID]; p != nil && p.MentionCount > 0 {
+			c.Noticeable = true
+		}
+		res = append(res, c)
+	}
+
+	// それ以外のチャンネルは通知されたメッセージを数える
+	for _, p := range pointers {
+		if _, ok := tracked[p.ChannelID]; ok || p.NotifiedCount == 0 || p.FirstNotifiedAt == nil || p.LastNotifiedAt == nil {
+			continue
+		}
+		res = append(res, &UserUnreadChannel{
+			ChannelID:  p.ChannelID,
+			Count:      p.NotifiedCount,
+			Noticeable: p.MentionCount > 0,
+			Since:      *p.FirstNotifiedAt,
+			UpdatedAt:  *p.LastNotifiedAt,
+		})
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].UpdatedAt.After(res[j].UpdatedAt)
+	})
+	return res, nil
+}
+
+// countMessagesSince 各チャンネルの指定日時より後の、userID以外が投稿したメッセージを数えます
+func (repo *GormRepository) countMessagesSince(userID uuid.UUID, since map[uuid.UUID]time.Time) ([]*UserUnreadChannel, error) {
+	res := make([]*UserUnreadChannel, 0)
+	conds := make([]string, 0, unreadCountChunkSize)
+	args := make([]interface{}, 0, unreadCountChunkSize*2+1)
+	flush := func() error {
+		if len(conds) == 0 {
+			return nil
+		}
+		var counts []*UserUnreadChannel
+		err := repo.db.Raw(`SELECT channel_id, COUNT(id) AS count, MIN(created_at) AS since, MAX(created_at) AS updated_at FROM messages WHERE deleted_at IS NULL AND user_id <> ? AND (`+strings.Join(conds, " OR ")+`) GROUP BY channel_id`, args...).Scan(&counts).Error
+		if err != nil {
+			return err
+		}
+		res = append(res, counts...)
+		conds = conds[:0]
+		args = args[:0]
+		return nil
+	}
+
+	for id, t := range since {
+		if len(conds) == 0 {
+			args = append(args, userID)
+		}
+		conds = append(conds, "(channel_id = ? AND created_at > ?)")
+		args = append(args, id, t)
+		if len(conds) >= unreadCountChunkSize {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/traPtitech/traQ/model"
+	"testing"
+	"time"
+)
+
+func TestRepositoryImpl_ReadChannel(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, common)
+
+	m := mustMakeMessage(t, repo, mustMakeUser(t, repo, random).ID, channel.ID)
+	require.NoError(repo.IncrementUnreadCounters(channel.ID, m.CreatedAt, map[uuid.UUID]bool{user.ID: true}))
+
+	assert.EqualError(repo.ReadChannel(uuid.Nil, channel.ID), ErrNilID.Error())
+	assert.EqualError(repo.ReadChannel(user.ID, uuid.Nil), ErrNilID.Error())
+
+	if assert.NoError(repo.ReadChannel(user.ID, channel.ID)) {
+		p, err := repo.GetReadPointer(user.ID, channel.ID)
+		require.NoError(err)
+		assert.Equal(m.ID, p.LastReadMessageID)
+		assert.NotNil(p.LastReadAt)
+		assert.Equal(0, p.MentionCount)
+		assert.Equal(0, p.NotifiedCount)
+		assert.Nil(p.FirstNotifiedAt)
+		assert.Nil(p.LastNotifiedAt)
+	}
+}
+
+func TestRepositoryImpl_UpdateReadPointers(t *testing.T) {
+	t.Parallel()
+	repo, _, _, user, channel := setupWithUserAndChannel(t, common)
+
+	t.Run("nil id", func(t *testing.T) {
+		t.Parallel()
+
+		assert.EqualError(t, repo.UpdateReadPointers(uuid.Nil, uuid.Nil, time.Now(), []uuid.UUID{user.ID}), ErrNilID.Error())
+		assert.EqualError(t, repo.UpdateReadPointers(channel.ID, uuid.Nil, time.Now(), []uuid.UUID{uuid.Nil}), ErrNilID.Error())
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		m1 := mustMakeMessage(t, repo, user.ID, channel.ID)
+		m2 := mustMakeMessage(t, repo, user.ID, channel.ID)
+		if assert.NoError(repo.UpdateReadPointers(channel.ID, m2.ID, m2.CreatedAt, []uuid.UUID{user.ID})) {
+			p, err := repo.GetReadPointer(user.ID, channel.ID)
+			if assert.NoError(err) {
+				assert.Equal(m2.ID, p.LastReadMessageID)
+			}
+		}
+		// 古いメッセージでは既読位置は戻らない
+		if assert.NoError(repo.UpdateReadPointers(channel.ID, m1.ID, m1.CreatedAt, []uuid.UUID{user.ID})) {
+			p, err := repo.GetReadPointer(user.ID, channel.ID)
+			if assert.NoError(err) {
+				assert.Equal(m2.ID, p.LastReadMessageID)
+			}
+		}
+	})
+}
+
+func TestRepositoryImpl_IncrementUnreadCounters(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, common)
+
+	assert.EqualError(repo.IncrementUnreadCounters(uuid.Nil, time.Now(), map[uuid.UUID]bool{user.ID: true}), ErrNilID.Error())
+	assert.EqualError(repo.IncrementUnreadCounters(channel.ID, time.Now(), map[uuid.UUID]bool{uuid.Nil: true}), ErrNilID.Error())
+	assert.NoError(repo.IncrementUnreadCounters(channel.ID, time.Now(), nil))
+
+	first := time.Now().Truncate(time.Microsecond)
+	last := first.Add(time.Second)
+	require.NoError(repo.IncrementUnreadCounters(channel.ID, first, map[uuid.UUID]bool{user.ID: false}))
+	require.NoError(repo.IncrementUnreadCounters(channel.ID, last, map[uuid.UUID]bool{user.ID: true}))
+
+	p, err := repo.GetReadPointer(user.ID, channel.ID)
+	if assert.NoError(err) {
+		assert.Equal(1, p.MentionCount)
+		assert.Equal(2, p.NotifiedCount)
+		assert.True(first.Equal(*p.FirstNotifiedAt))
+		assert.True(last.Equal(*p.LastNotifiedAt))
+	}
+}
+
+func TestRepositoryImpl_GetReadPointer(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, channel := setupWithUserAndChannel(t, common)
+
+	_, err := repo.GetReadPointer(user.ID, channel.ID)
+	assert.Equal(ErrNotFound, err)
+	_, err = repo.GetReadPointer(uuid.Nil, channel.ID)
+	assert.Equal(ErrNotFound, err)
+
+	require.NoError(repo.ReadChannel(user.ID, channel.ID))
+	p, err := repo.GetReadPointer(user.ID, channel.ID)
+	if assert.NoError(err) {
+		assert.Equal(user.ID, p.UserID)
+		assert.Equal(channel.ID, p.ChannelID)
+	}
+}
+
+func TestRepositoryImpl_GetUserUnreadChannels(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user := setupWithUser(t, common)
+
+	creator := mustMakeUser(t, repo, random)
+	subscribed := mustMakeChannel(t, repo, random)
+	mentioned := mustMakeChannel(t, repo, random)
+	other := mustMakeChannel(t, repo, random)
+	require.NoError(repo.SubscribeChannel(user.ID, subscribed.ID))
+
+	for i := 0; i < 3; i++ {
+		mustMakeMessage(t, repo, creator.ID, subscribed.ID)
+		mustMakeMessage(t, repo, creator.ID, other.ID)
+	}
+	// 自分のメッセージは未読にならない
+	mustMakeMessage(t, repo, user.ID, subscribed.ID)
+	m := mustMakeMessage(t, repo, creator.ID, mentioned.ID)
+	require.NoError(repo.IncrementUnreadCounters(mentioned.ID, m.CreatedAt, map[uuid.UUID]bool{user.ID: true}))
+
+	if unreads, err := repo.GetUserUnreadChannels(user.ID); assert.NoError(err) && assert.Len(unreads, 2) {
+		assert.Equal(mentioned.ID, unreads[0].ChannelID)
+		assert.Equal(1, unreads[0].Count)
+		assert.True(unreads[0].Noticeable)
+		assert.Equal(subscribed.ID, unreads[1].ChannelID)
+		assert.Equal(3, unreads[1].Count)
+		assert.False(unreads[1].Noticeable)
+	}
+
+	require.NoError(repo.ReadChannel(user.ID, subscribed.ID))
+	if unreads, err := repo.GetUserUnreadChannels(user.ID); assert.NoError(err) {
+		assert.Len(unreads, 1)
+	}
+
+	if unreads, err := repo.GetUserUnreadChannels(uuid.Nil); assert.NoError(err) {
+		assert.Len(unreads, 0)
+	}
+}
+
+func TestRepositoryImpl_GetUserUnreadChannels_MissingPointer(t *testing.T) {
+	t.Parallel()
+	r, assert, require, user := setupWithUser(t, common)
+	repo := r.(*GormRepository)
+	creator := mustMakeUser(t, repo, random)
+
+	// 既読位置が無いまま参加していた購読チャンネルは、参加日時以降が未読になる
+	joined := mustMakeChannel(t, repo, random)
+	mustMakeMessage(t, repo, creator.ID, joined.ID)
+	require.NoError(repo.db.Create(&model.ChannelMember{ChannelID: joined.ID, UserID: user.ID}).Error)
+	require.NoError(repo.db.Create(&model.UserSubscribeChannel{UserID: user.ID, ChannelID: joined.ID}).Error)
+	mustMakeMessage(t, repo, creator.ID, joined.ID)
+
+	// 既読位置が無い購読チャンネルに参加すると、参加時が既読位置になる
+	subscribed := mustMakeChannel(t, repo, random)
+	require.NoError(repo.db.Create(&model.UserSubscribeChannel{UserID: user.ID, ChannelID: subscribed.ID}).Error)
+	mustMakeMessage(t, repo, creator.ID, subscribed.ID)
+	require.NoError(repo.JoinChannel(user.ID, subscribed.ID))
+	_, err := repo.GetReadPointer(user.ID, subscribed.ID)
+	require.NoError(err)
+	mustMakeMessage(t, repo, creator.ID, subscribed.ID)
+
+	if unreads, err := repo.GetUserUnreadChannels(user.ID); assert.NoError(err) && assert.Len(unreads, 2) {
+		assert.Equal(subscribed.ID, unreads[0].ChannelID)
+		assert.Equal(1, unreads[0].Count)
+		assert.Equal(joined.ID, unreads[1].ChannelID)
+		assert.Equal(1, unreads[1].Count)
+	}
+}
@@ -54,6 +54,11 @@ func blockByMessageChannel(h *Handlers, bot *model.Bot, c echo.Context) (bool, e
 	return blockByChannelID(h, bot, c, getMessageFromContext(c).ChannelID)
 }
 
+// blockByPollChannel BOTが参加しているチャンネル以外の投票へのリクエストを拒否
+func blockByPollChannel(h *Handlers, bot *model.Bot, c echo.Context) (bool, error) {
+	return blockByChannelID(h, bot, c, getPollFromContext(c).ChannelID)
+}
+
 // blockUnlessSubscribingEvent BOTが指定したイベントを購読していない場合にリクエストを拒否
 func blockUnlessSubscribingEvent(event model.BotEvent) botGuardFunc {
 	return func(h *Handlers, bot *model.Bot, c echo.Context) (b bool, e error) {
@@ -69,7 +74,15 @@ func blockByChannelID(h *Handlers, bot *model.Bot, c echo.Context, channelID uui
 
 	for _, v := range ids {
 		if v == channelID {
-			return true, nil
+			// アーカイブされたチャンネルへは読み取りのみ許可
+			if c.Request().Method == echo.GET {
+				return true, nil
+			}
+			ch, err := h.Repo.GetChannel(channelID)
+			if err != nil {
+				return false, err
+			}
+			return !ch.IsArchived, nil
 		}
 	}
 	return false, nil
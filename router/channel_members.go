@@ -0,0 +1,94 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/gofrs/uuid"
+	"github.com/labstack/echo"
+	"github.com/traPtitech/traQ/repository"
+)
+
+// GetChannelMembers GET /channels/:channelID/members
+func (h *Handlers) GetChannelMembers(c echo.Context) error {
+	ch := getChannelFromContext(c)
+
+	var (
+		members []uuid.UUID
+		err     error
+	)
+	if ch.IsPublic {
+		members, err = h.Repo.GetJoinedUserIDs(ch.ID)
+	} else {
+		members, err = h.Repo.GetPrivateChannelMemberIDs(ch.ID)
+	}
+	if err != nil {
+		return internalServerError(err, h.requestContextLogger(c))
+	}
+
+	return c.JSON(http.StatusOK, members)
+}
+
+// GetJoinedChannels GET /users/me/channels
+func (h *Handlers) GetJoinedChannels(c echo.Context) error {
+	userID := getRequestUserID(c)
+
+	channels, err := h.Repo.GetChannelsByUserID(userID)
+	if err != nil {
+		return internalServerError(err, h.requestContextLogger(c))
+	}
+	joined, err := h.Repo.GetJoinedChannelIDs(userID)
+	if err != nil {
+		return internalServerError(err, h.requestContextLogger(c))
+	}
+	joinedSet := make(map[uuid.UUID]bool, len(joined))
+	for _, id := range joined {
+		joinedSet[id] = true
+	}
+
+	// 参加しているパブリックチャンネル・強制通知チャンネル・DM以外のプライベートチャンネル
+	res := make([]uuid.UUID, 0)
+	for _, ch := range channels {
+		if ch.IsArchived || ch.IsDMChannel() {
+			continue
+		}
+		if !ch.IsPublic || ch.IsForced || joinedSet[ch.ID] {
+			res = append(res, ch.ID)
+		}
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// PutJoinedChannel PUT /users/me/channels/:channelID
+func (h *Handlers) PutJoinedChannel(c echo.Context) error {
+	userID := getRequestUserID(c)
+	ch := getChannelFromContext(c)
+
+	if err := h.Repo.JoinChannel(userID, ch.ID); err != nil {
+		switch err {
+		case repository.ErrForbidden:
+			return forbidden("the channel cannot be joined")
+		default:
+			return internalServerError(err, h.requestContextLogger(c))
+		}
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// DeleteJoinedChannel DELETE /users/me/channels/:channelID
+func (h *Handlers) DeleteJoinedChannel(c echo.Context) error {
+	userID := getRequestUserID(c)
+	ch := getChannelFromContext(c)
+
+	// プライベートチャンネルのメンバーはここでは変更できない
+	if !ch.IsPublic {
+		return forbidden("private channel's membership is not configurable")
+	}
+
+	if err := h.Repo.LeaveChannel(userID, ch.ID); err != nil {
+		return internalServerError(err, h.requestContextLogger(c))
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
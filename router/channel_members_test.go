@@ -0,0 +1,169 @@
+package router
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/traPtitech/traQ/sessions"
+)
+
+func TestHandlers_GetChannelMembers(t *testing.T) {
+	t.Parallel()
+	repo, server, _, require, session, _, user, _ := setupWithUsers(t, common1)
+
+	t.Run("NotLoggedIn", func(t *testing.T) {
+		t.Parallel()
+		ch := mustMakeChannel(t, repo, random)
+		e := makeExp(t, server)
+		e.GET("/api/1.0/channels/{channelID}/members", ch.ID).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("Public", func(t *testing.T) {
+		t.Parallel()
+		ch := mustMakeChannel(t, repo, random)
+		require.NoError(repo.JoinChannel(user.ID, ch.ID))
+
+		e := makeExp(t, server)
+		e.GET("/api/1.0/channels/{channelID}/members", ch.ID).
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Array().
+			ContainsOnly(user.ID.String())
+	})
+
+	t.Run("Private", func(t *testing.T) {
+		t.Parallel()
+		ch := mustMakePrivateChannel(t, repo, random, []uuid.UUID{user.ID})
+
+		e := makeExp(t, server)
+		e.GET("/api/1.0/channels/{channelID}/members", ch.ID).
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Array().
+			ContainsOnly(user.ID.String())
+	})
+}
+
+func TestHandlers_GetJoinedChannels(t *testing.T) {
+	t.Parallel()
+	repo, server, _, require, _, _, _, _ := setupWithUsers(t, common1)
+
+	user := mustMakeUser(t, repo, random)
+	session := generateSession(t, user.ID)
+	joined := mustMakeChannel(t, repo, random)
+	notJoined := mustMakeChannel(t, repo, random)
+	private := mustMakePrivateChannel(t, repo, random, []uuid.UUID{user.ID})
+	require.NoError(repo.JoinChannel(user.ID, joined.ID))
+
+	e := makeExp(t, server)
+	arr := e.GET("/api/1.0/users/me/channels").
+		WithCookie(sessions.CookieName, session).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Array()
+	arr.Contains(joined.ID.String(), private.ID.String())
+	arr.NotContains(notJoined.ID.String())
+}
+
+func TestHandlers_PutJoinedChannel(t *testing.T) {
+	t.Parallel()
+	repo, server, _, require, _, _, _, _ := setupWithUsers(t, common1)
+
+	t.Run("NotLoggedIn", func(t *testing.T) {
+		t.Parallel()
+		ch := mustMakeChannel(t, repo, random)
+		e := makeExp(t, server)
+		e.PUT("/api/1.0/users/me/channels/{channelID}", ch.ID).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("Successful", func(t *testing.T) {
+		t.Parallel()
+		user := mustMakeUser(t, repo, random)
+		ch := mustMakeChannel(t, repo, random)
+
+		e := makeExp(t, server)
+		e.PUT("/api/1.0/users/me/channels/{channelID}", ch.ID).
+			WithCookie(sessions.CookieName, generateSession(t, user.ID)).
+			Expect().
+			Status(http.StatusNoContent)
+
+		members, err := repo.GetJoinedUserIDs(ch.ID)
+		require.NoError(err)
+		assert.ElementsMatch(t, []uuid.UUID{user.ID}, members)
+
+		// 参加すると購読状態になる
+		subscribers, err := repo.GetSubscribingUserIDs(ch.ID)
+		require.NoError(err)
+		assert.ElementsMatch(t, []uuid.UUID{user.ID}, subscribers)
+	})
+
+	t.Run("Private", func(t *testing.T) {
+		t.Parallel()
+		user := mustMakeUser(t, repo, random)
+		ch := mustMakePrivateChannel(t, repo, random, []uuid.UUID{user.ID})
+
+		e := makeExp(t, server)
+		e.PUT("/api/1.0/users/me/channels/{channelID}", ch.ID).
+			WithCookie(sessions.CookieName, generateSession(t, user.ID)).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+}
+
+func TestHandlers_DeleteJoinedChannel(t *testing.T) {
+	t.Parallel()
+	repo, server, _, require, _, _, _, _ := setupWithUsers(t, common1)
+
+	t.Run("NotLoggedIn", func(t *testing.T) {
+		t.Parallel()
+		ch := mustMakeChannel(t, repo, random)
+		e := makeExp(t, server)
+		e.DELETE("/api/1.0/users/me/channels/{channelID}", ch.ID).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("Successful", func(t *testing.T) {
+		t.Parallel()
+		user := mustMakeUser(t, repo, random)
+		ch := mustMakeChannel(t, repo, random)
+		require.NoError(repo.JoinChannel(user.ID, ch.ID))
+
+		e := makeExp(t, server)
+		e.DELETE("/api/1.0/users/me/channels/{channelID}", ch.ID).
+			WithCookie(sessions.CookieName, generateSession(t, user.ID)).
+			Expect().
+			Status(http.StatusNoContent)
+
+		members, err := repo.GetJoinedUserIDs(ch.ID)
+		require.NoError(err)
+		assert.Empty(t, members)
+
+		subscribers, err := repo.GetSubscribingUserIDs(ch.ID)
+		require.NoError(err)
+		assert.Empty(t, subscribers)
+	})
+
+	t.Run("Private", func(t *testing.T) {
+		t.Parallel()
+		user := mustMakeUser(t, repo, random)
+		ch := mustMakePrivateChannel(t, repo, random, []uuid.UUID{user.ID})
+
+		e := makeExp(t, server)
+		e.DELETE("/api/1.0/users/me/channels/{channelID}", ch.ID).
+			WithCookie(sessions.CookieName, generateSession(t, user.ID)).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+}
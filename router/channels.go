@@ -1,6 +1,7 @@
 package router
 
 import (
+	"fmt"
 	"github.com/gofrs/uuid"
 	"github.com/traPtitech/traQ/repository"
 	"net/http"
@@ -20,6 +21,7 @@ type PostChannel struct {
 // GetChannels GET /channels
 func (h *Handlers) GetChannels(c echo.Context) error {
 	userID := getRequestUserID(c)
+	includeArchived := c.QueryParam("archived") == "1"
 
 	channelList, err := h.Repo.GetChannelsByUserID(userID)
 	if err != nil {
@@ -28,6 +30,11 @@ func (h *Handlers) GetChannels(c echo.Context) error {
 
 	chMap := make(map[string]*channelResponse, len(channelList))
 	for _, ch := range channelList {
+		// アーカイブされたチャンネルはデフォルトでは含めない(子孫も全てアーカイブされている)
+		if ch.IsArchived && !includeArchived {
+			continue
+		}
+
 		entry, ok := chMap[ch.ID.String()]
 		if !ok {
 			entry = &channelResponse{}
@@ -41,6 +48,8 @@ func (h *Handlers) GetChannels(c echo.Context) error {
 		entry.Force = ch.IsForced
 		entry.Private = !ch.IsPublic
 		entry.DM = ch.IsDMChannel()
+		entry.SlowModeInterval = ch.SlowModeInterval
+		entry.Archived = ch.IsArchived
 
 		if !ch.IsPublic {
 			// プライベートチャンネルのメンバー取得
@@ -160,9 +169,10 @@ func (h *Handlers) PatchChannelByChannelID(c echo.Context) error {
 	channelID := getRequestParamAsUUID(c, paramChannelID)
 
 	var req struct {
-		Name       *string `json:"name"`
-		Visibility *bool   `json:"visibility"`
-		Force      *bool   `json:"force"`
+		Name             *string `json:"name"`
+		Visibility       *bool   `json:"visibility"`
+		Force            *bool   `json:"force"`
+		SlowModeInterval *int    `json:"slowModeInterval" validate:"omitempty,min=0,max=21600"`
 	}
 	if err := bindAndValidate(c, &req); err != nil {
 		return badRequest(err)
@@ -188,6 +198,17 @@ func (h *Handlers) PatchChannelByChannelID(c echo.Context) error {
 			return internalServerError(err, h.requestContextLogger(c))
 		}
 	}
+
+	if req.SlowModeInterval != nil {
+		if err := h.Repo.UpdateChannelSlowModeInterval(channelID, *req.SlowModeInterval); err != nil {
+			switch {
+			case repository.IsArgError(err):
+				return badRequest(err)
+			default:
+				return internalServerError(err, h.requestContextLogger(c))
+			}
+		}
+	}
 	return c.NoContent(http.StatusNoContent)
 }
 
@@ -265,6 +286,44 @@ func (h *Handlers) DeleteChannelByChannelID(c echo.Context) error {
 	return c.NoContent(http.StatusNoContent)
 }
 
+// PutChannelArchive PUT /channels/:channelID/archive
+func (h *Handlers) PutChannelArchive(c echo.Context) error {
+	userID := getRequestUserID(c)
+	channelID := getRequestParamAsUUID(c, paramChannelID)
+
+	if err := h.Repo.UpdateChannelArchived(channelID, true, userID); err != nil {
+		switch err {
+		case repository.ErrForbidden:
+			return forbidden("the channel cannot be archived")
+		default:
+			return internalServerError(err, h.requestContextLogger(c))
+		}
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// DeleteChannelArchive DELETE /channels/:channelID/archive
+func (h *Handlers) DeleteChannelArchive(c echo.Context) error {
+	userID := getRequestUserID(c)
+	ch := getChannelFromContext(c)
+
+	if ch.IsDMChannel() {
+		return forbidden("the channel is a direct message channel")
+	}
+
+	if err := h.Repo.UpdateChannelArchived(ch.ID, false, userID); err != nil {
+		switch err {
+		case repository.ErrForbidden:
+			return forbidden("the parent channel is archived")
+		default:
+			return internalServerError(err, h.requestContextLogger(c))
+		}
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
 // GetTopic GET /channels/:channelID/topic
 func (h *Handlers) GetTopic(c echo.Context) error {
 	ch := getChannelFromContext(c)
@@ -291,3 +350,122 @@ func (h *Handlers) PutTopic(c echo.Context) error {
 
 	return c.NoContent(http.StatusNoContent)
 }
+
+// GetChannelRetentionPolicy GET /channels/:channelID/retention
+func (h *Handlers) GetChannelRetentionPolicy(c echo.Context) error {
+	type response struct {
+		Configured      bool      `json:"configured"`
+		SourceChannelID uuid.UUID `json:"sourceChannelId"`
+		Days            int       `json:"days"`
+		ExemptPinned    bool      `json:"exemptPinned"`
+		ExemptClipped   bool      `json:"exemptClipped"`
+	}
+	channelID := getRequestParamAsUUID(c, paramChannelID)
+
+	p, err := h.Repo.GetEffectiveChannelRetentionPolicy(channelID)
+	if err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return c.JSON(http.StatusOK, &response{})
+		default:
+			return internalServerError(err, h.requestContextLogger(c))
+		}
+	}
+
+	return c.JSON(http.StatusOK, &response{
+		Configured:      p.ChannelID == channelID,
+		SourceChannelID: p.ChannelID,
+		Days:            p.Days,
+		ExemptPinned:    p.ExemptPinned,
+		ExemptClipped:   p.ExemptClipped,
+	})
+}
+
+// PutChannelRetentionPolicy PUT /channels/:channelID/retention
+func (h *Handlers) PutChannelRetentionPolicy(c echo.Context) error {
+	channelID := getRequestParamAsUUID(c, paramChannelID)
+
+	var req struct {
+		Days          int  `json:"days"          validate:"min=0"`
+		ExemptPinned  bool `json:"exemptPinned"`
+		ExemptClipped bool `json:"exemptClipped"`
+	}
+	if err := bindAndValidate(c, &req); err != nil {
+		return badRequest(err)
+	}
+
+	if err := h.Repo.SetChannelRetentionPolicy(channelID, req.Days, req.ExemptPinned, req.ExemptClipped); err != nil {
+		switch {
+		case repository.IsArgError(err):
+			return badRequest(err)
+		case err == repository.ErrNotFound:
+			return notFound()
+		default:
+			return internalServerError(err, h.requestContextLogger(c))
+		}
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// DeleteChannelRetentionPolicy DELETE /channels/:channelID/retention
+func (h *Handlers) DeleteChannelRetentionPolicy(c echo.Context) error {
+	channelID := getRequestParamAsUUID(c, paramChannelID)
+
+	if err := h.Repo.DeleteChannelRetentionPolicy(channelID); err != nil {
+		return internalServerError(err, h.requestContextLogger(c))
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// PostChannelExport POST /channels/:channelID/exports
+func (h *Handlers) PostChannelExport(c echo.Context) error {
+	userID := getRequestUserID(c)
+	ch := getChannelFromContext(c)
+
+	var req struct {
+		Descendants bool `json:"descendants"`
+	}
+	if err := bindAndValidate(c, &req); err != nil {
+		return badRequest(err)
+	}
+	if ch.IsDMChannel() {
+		return badRequest("dm channels cannot be exported")
+	}
+
+	// アーカイブの作成には時間がかかるため、バックグラウンドで処理する
+	e, err := h.Repo.CreateChannelExport(ch.ID, userID, req.Descendants)
+	if err != nil {
+		return internalServerError(err, h.requestContextLogger(c))
+	}
+
+	return c.JSON(http.StatusAccepted, e)
+}
+
+// GetChannelExport GET /channels/:channelID/exports/:exportID
+func (h *Handlers) GetChannelExport(c echo.Context) error {
+	return c.JSON(http.StatusOK, getChannelExportFromContext(c))
+}
+
+// GetChannelExportArchive GET /channels/:channelID/exports/:exportID/download
+func (h *Handlers) GetChannelExportArchive(c echo.Context) error {
+	e := getChannelExportFromContext(c)
+	if e.State != model.ChannelExportCompleted {
+		return conflict("the export is not completed")
+	}
+
+	meta, file, err := h.Repo.OpenFile(e.FileID)
+	if err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return notFound()
+		default:
+			return internalServerError(err, h.requestContextLogger(c))
+		}
+	}
+	defer file.Close()
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%s", meta.Name))
+	return c.Stream(http.StatusOK, meta.Mime, file)
+}
@@ -1,13 +1,18 @@
 package router
 
 import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
 	"github.com/gofrs/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/traPtitech/traQ/exporter"
 	"github.com/traPtitech/traQ/repository"
 	"github.com/traPtitech/traQ/sessions"
 	"github.com/traPtitech/traQ/utils"
 	"net/http"
+	"strings"
 	"testing"
 )
 
@@ -313,6 +318,43 @@ func TestHandlers_PatchChannelByChannelID(t *testing.T) {
 		assert.True(ch.IsForced)
 	})
 
+	t.Run("Successful2", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		assert, require := assertAndRequire(t)
+
+		ch := mustMakeChannel(t, repo, random)
+		e.PATCH("/api/1.0/channels/{channelID}", ch.ID.String()).
+			WithCookie(sessions.CookieName, adminSession).
+			WithJSON(map[string]interface{}{"slowModeInterval": 30}).
+			Expect().
+			Status(http.StatusNoContent)
+
+		ch, err := repo.GetChannel(ch.ID)
+		require.NoError(err)
+		assert.Equal(30, ch.SlowModeInterval)
+
+		e.GET("/api/1.0/channels/{channelID}", ch.ID.String()).
+			WithCookie(sessions.CookieName, adminSession).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object().
+			Value("slowModeInterval").
+			Number().
+			Equal(30)
+	})
+
+	t.Run("invalid slow mode interval", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.PATCH("/api/1.0/channels/{channelID}", pubCh.ID.String()).
+			WithCookie(sessions.CookieName, adminSession).
+			WithJSON(map[string]interface{}{"slowModeInterval": -1}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
 	// 権限がない
 	t.Run("Failure1", func(t *testing.T) {
 		t.Parallel()
@@ -365,6 +407,175 @@ func TestHandlers_DeleteChannelByChannelID(t *testing.T) {
 	})
 }
 
+func TestHandlers_PutChannelArchive(t *testing.T) {
+	t.Parallel()
+	repo, server, _, require, session, adminSession, user, _ := setupWithUsers(t, common1)
+
+	t.Run("NotLoggedIn", func(t *testing.T) {
+		t.Parallel()
+		ch := mustMakeChannel(t, repo, random)
+		e := makeExp(t, server)
+		e.PUT("/api/1.0/channels/{channelID}/archive", ch.ID).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		t.Parallel()
+		ch := mustMakeChannel(t, repo, random)
+		e := makeExp(t, server)
+		e.PUT("/api/1.0/channels/{channelID}/archive", ch.ID).
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
+	t.Run("Successful", func(t *testing.T) {
+		t.Parallel()
+		ch := mustMakeChannel(t, repo, random)
+		child, err := repo.CreateChildChannel(utils.RandAlphabetAndNumberString(20), ch.ID, user.ID)
+		require.NoError(err)
+		m := mustMakeMessage(t, repo, user.ID, ch.ID)
+		pinned := mustMakeMessage(t, repo, user.ID, ch.ID)
+		pinID, err := repo.CreatePin(pinned.ID, user.ID)
+		require.NoError(err)
+
+		e := makeExp(t, server)
+		e.PUT("/api/1.0/channels/{channelID}/archive", ch.ID).
+			WithCookie(sessions.CookieName, adminSession).
+			Expect().
+			Status(http.StatusNoContent)
+
+		e.GET("/api/1.0/channels/{channelID}", child.ID).
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object().
+			Value("archived").
+			Boolean().
+			True()
+
+		// デフォルトのチャンネルツリーには含まれない
+		ids := make([]string, 0)
+		for _, v := range e.GET("/api/1.0/channels").
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Array().
+			Iter() {
+			ids = append(ids, v.Object().Value("channelId").String().Raw())
+		}
+		assert.NotContains(t, ids, ch.ID.String())
+		assert.NotContains(t, ids, child.ID.String())
+
+		e.GET("/api/1.0/channels").
+			WithCookie(sessions.CookieName, session).
+			WithQuery("archived", 1).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Array().
+			Length().
+			Gt(len(ids))
+
+		// 読み取りはできるが、書き込みはできない
+		e.GET("/api/1.0/channels/{channelID}/messages", ch.ID).
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusOK)
+		e.POST("/api/1.0/channels/{channelID}/messages", ch.ID).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]string{"text": "test message"}).
+			Expect().
+			Status(http.StatusForbidden)
+		e.POST("/api/1.0/messages/{messageID}/thread", m.ID).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]string{"text": "test message"}).
+			Expect().
+			Status(http.StatusForbidden)
+		e.PUT("/api/1.0/messages/{messageID}", m.ID).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]string{"text": "edited"}).
+			Expect().
+			Status(http.StatusForbidden)
+		e.POST("/api/1.0/pins").
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]uuid.UUID{"messageId": m.ID}).
+			Expect().
+			Status(http.StatusForbidden)
+		e.DELETE("/api/1.0/pins/{pinID}", pinID).
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusForbidden)
+		e.DELETE("/api/1.0/messages/{messageID}", m.ID).
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusForbidden)
+		_, err = repo.GetMessageByID(m.ID)
+		require.NoError(err)
+
+		// 子チャンネルは作れない
+		e.POST("/api/1.0/channels/{channelID}/children", ch.ID).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]string{"name": utils.RandAlphabetAndNumberString(20)}).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+}
+
+func TestHandlers_DeleteChannelArchive(t *testing.T) {
+	t.Parallel()
+	repo, server, _, require, session, adminSession, user, admin := setupWithUsers(t, common1)
+
+	ch := mustMakeChannel(t, repo, random)
+	child, err := repo.CreateChildChannel(utils.RandAlphabetAndNumberString(20), ch.ID, user.ID)
+	require.NoError(err)
+	require.NoError(repo.UpdateChannelArchived(ch.ID, true, user.ID))
+
+	e := makeExp(t, server)
+	e.DELETE("/api/1.0/channels/{channelID}/archive", ch.ID).
+		WithCookie(sessions.CookieName, session).
+		Expect().
+		Status(http.StatusForbidden)
+
+	// 親がアーカイブされている
+	e.DELETE("/api/1.0/channels/{channelID}/archive", child.ID).
+		WithCookie(sessions.CookieName, adminSession).
+		Expect().
+		Status(http.StatusForbidden)
+
+	// DMチャンネル
+	dm, err := repo.GetDirectMessageChannel(admin.ID, user.ID)
+	require.NoError(err)
+	e.DELETE("/api/1.0/channels/{channelID}/archive", dm.ID).
+		WithCookie(sessions.CookieName, adminSession).
+		Expect().
+		Status(http.StatusForbidden).
+		JSON().
+		Object().
+		Value("message").
+		Equal("the channel is a direct message channel")
+
+	e.DELETE("/api/1.0/channels/{channelID}/archive", ch.ID).
+		WithCookie(sessions.CookieName, adminSession).
+		Expect().
+		Status(http.StatusNoContent)
+
+	for _, id := range []uuid.UUID{ch.ID, child.ID} {
+		c, err := repo.GetChannel(id)
+		require.NoError(err)
+		assert.False(t, c.IsArchived)
+	}
+
+	e.POST("/api/1.0/channels/{channelID}/messages", ch.ID).
+		WithCookie(sessions.CookieName, session).
+		WithJSON(map[string]string{"text": "test message"}).
+		Expect().
+		Status(http.StatusCreated)
+}
+
 func TestHandlers_PutChannelParent(t *testing.T) {
 	t.Parallel()
 	repo, server, _, _, session, adminSession := setup(t, common1)
@@ -479,3 +690,333 @@ func TestHandlers_PutTopic(t *testing.T) {
 		assert.Equal(t, newTopic, ch.Topic)
 	})
 }
+
+func TestHandlers_GetChannelRetentionPolicy(t *testing.T) {
+	t.Parallel()
+	repo, server, _, require, session, _ := setup(t, common1)
+
+	parent := mustMakeChannel(t, repo, random)
+	child, err := repo.CreateChildChannel(utils.RandAlphabetAndNumberString(20), parent.ID, uuid.Nil)
+	require.NoError(err)
+	other := mustMakeChannel(t, repo, random)
+	require.NoError(repo.SetChannelRetentionPolicy(parent.ID, 30, true, false))
+
+	t.Run("NotLoggedIn", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.GET("/api/1.0/channels/{channelID}/retention", parent.ID.String()).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("Configured", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		obj := e.GET("/api/1.0/channels/{channelID}/retention", parent.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object()
+		obj.Value("configured").Boolean().True()
+		obj.Value("sourceChannelId").String().Equal(parent.ID.String())
+		obj.Value("days").Number().Equal(30)
+		obj.Value("exemptPinned").Boolean().True()
+		obj.Value("exemptClipped").Boolean().False()
+	})
+
+	t.Run("Inherited", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		obj := e.GET("/api/1.0/channels/{channelID}/retention", child.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object()
+		obj.Value("configured").Boolean().False()
+		obj.Value("sourceChannelId").String().Equal(parent.ID.String())
+		obj.Value("days").Number().Equal(30)
+	})
+
+	t.Run("NotConfigured", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		obj := e.GET("/api/1.0/channels/{channelID}/retention", other.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object()
+		obj.Value("configured").Boolean().False()
+		obj.Value("sourceChannelId").String().Equal(uuid.Nil.String())
+		obj.Value("days").Number().Equal(0)
+	})
+}
+
+func TestHandlers_PutChannelRetentionPolicy(t *testing.T) {
+	t.Parallel()
+	repo, server, _, _, session, adminSession := setup(t, common1)
+
+	t.Run("NotLoggedIn", func(t *testing.T) {
+		t.Parallel()
+		ch := mustMakeChannel(t, repo, random)
+		e := makeExp(t, server)
+		e.PUT("/api/1.0/channels/{channelID}/retention", ch.ID.String()).
+			WithJSON(map[string]interface{}{"days": 7}).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		t.Parallel()
+		ch := mustMakeChannel(t, repo, random)
+		e := makeExp(t, server)
+		e.PUT("/api/1.0/channels/{channelID}/retention", ch.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]interface{}{"days": 7}).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
+	t.Run("BadRequest", func(t *testing.T) {
+		t.Parallel()
+		ch := mustMakeChannel(t, repo, random)
+		e := makeExp(t, server)
+		e.PUT("/api/1.0/channels/{channelID}/retention", ch.ID.String()).
+			WithCookie(sessions.CookieName, adminSession).
+			WithJSON(map[string]interface{}{"days": -1}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("Successful1", func(t *testing.T) {
+		t.Parallel()
+		ch := mustMakeChannel(t, repo, random)
+		e := makeExp(t, server)
+		e.PUT("/api/1.0/channels/{channelID}/retention", ch.ID.String()).
+			WithCookie(sessions.CookieName, adminSession).
+			WithJSON(map[string]interface{}{"days": 7, "exemptClipped": true}).
+			Expect().
+			Status(http.StatusNoContent)
+
+		p, err := repo.GetChannelRetentionPolicy(ch.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 7, p.Days)
+		assert.False(t, p.ExemptPinned)
+		assert.True(t, p.ExemptClipped)
+	})
+}
+
+func TestHandlers_DeleteChannelRetentionPolicy(t *testing.T) {
+	t.Parallel()
+	repo, server, _, _, session, adminSession := setup(t, common1)
+
+	t.Run("Forbidden", func(t *testing.T) {
+		t.Parallel()
+		ch := mustMakeChannel(t, repo, random)
+		require.NoError(t, repo.SetChannelRetentionPolicy(ch.ID, 7, false, false))
+		e := makeExp(t, server)
+		e.DELETE("/api/1.0/channels/{channelID}/retention", ch.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
+	t.Run("Successful1", func(t *testing.T) {
+		t.Parallel()
+		ch := mustMakeChannel(t, repo, random)
+		require.NoError(t, repo.SetChannelRetentionPolicy(ch.ID, 7, false, false))
+		e := makeExp(t, server)
+		e.DELETE("/api/1.0/channels/{channelID}/retention", ch.ID.String()).
+			WithCookie(sessions.CookieName, adminSession).
+			Expect().
+			Status(http.StatusNoContent)
+
+		_, err := repo.GetChannelRetentionPolicy(ch.ID)
+		assert.Equal(t, repository.ErrNotFound, err)
+	})
+}
+
+func TestHandlers_ChannelExport(t *testing.T) {
+	t.Parallel()
+	repo, server, _, _, session, adminSession, user, admin := setupWithUsers(t, common1)
+
+	parent := mustMakeChannel(t, repo, random)
+	child, err := repo.CreateChildChannel(utils.RandAlphabetAndNumberString(20), parent.ID, user.ID)
+	require.NoError(t, err)
+
+	file := mustMakeFile(t, repo, user.ID)
+	m, err := repo.CreateMessage(user.ID, parent.ID, fmt.Sprintf(`!{"type":"file","raw":"","id":"%s"}`, file.ID))
+	require.NoError(t, err)
+	require.NoError(t, repo.UpdateMessage(m.ID, fmt.Sprintf(`edited !{"type":"file","raw":"","id":"%s"}`, file.ID)))
+	mustMakeMessage(t, repo, user.ID, child.ID)
+
+	// ChannelExportWorkerの処理を行う
+	runExport := func(t *testing.T, id uuid.UUID) {
+		t.Helper()
+		ok, err := repo.ClaimChannelExport(id)
+		require.NoError(t, err)
+		require.True(t, ok)
+		e, err := repo.GetChannelExport(id)
+		require.NoError(t, err)
+		f, err := exporter.SaveArchive(repo, e)
+		require.NoError(t, err)
+		require.NoError(t, repo.MarkChannelExportCompleted(id, f.ID))
+	}
+	readZip := func(t *testing.T, body string) map[string]*zip.File {
+		t.Helper()
+		zr, err := zip.NewReader(strings.NewReader(body), int64(len(body)))
+		require.NoError(t, err)
+		files := map[string]*zip.File{}
+		for _, f := range zr.File {
+			files[f.Name] = f
+		}
+		return files
+	}
+
+	t.Run("NotLoggedIn", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.POST("/api/1.0/channels/{channelID}/exports", parent.ID.String()).
+			WithJSON(map[string]bool{"descendants": false}).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.POST("/api/1.0/channels/{channelID}/exports", parent.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]bool{"descendants": false}).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.GET("/api/1.0/channels/{channelID}/exports/{exportID}", parent.ID.String(), uuid.Must(uuid.NewV4())).
+			WithCookie(sessions.CookieName, adminSession).
+			Expect().
+			Status(http.StatusNotFound)
+
+		// 他のユーザーのエクスポートは見えない
+		other, err := repo.CreateChannelExport(parent.ID, user.ID, false)
+		require.NoError(t, err)
+		e.GET("/api/1.0/channels/{channelID}/exports/{exportID}", parent.ID.String(), other.ID).
+			WithCookie(sessions.CookieName, adminSession).
+			Expect().
+			Status(http.StatusNotFound)
+	})
+
+	t.Run("Successful1", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		id := e.POST("/api/1.0/channels/{channelID}/exports", parent.ID.String()).
+			WithCookie(sessions.CookieName, adminSession).
+			WithJSON(map[string]bool{"descendants": false}).
+			Expect().
+			Status(http.StatusAccepted).
+			JSON().
+			Object().
+			ValueEqual("state", "pending").
+			Value("id").
+			String().
+			Raw()
+
+		// 完了するまではダウンロードできない
+		e.GET("/api/1.0/channels/{channelID}/exports/{exportID}/download", parent.ID.String(), id).
+			WithCookie(sessions.CookieName, adminSession).
+			Expect().
+			Status(http.StatusConflict)
+
+		runExport(t, uuid.FromStringOrNil(id))
+
+		e.GET("/api/1.0/channels/{channelID}/exports/{exportID}", parent.ID.String(), id).
+			WithCookie(sessions.CookieName, adminSession).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object().
+			ValueEqual("state", "completed")
+
+		res := e.GET("/api/1.0/channels/{channelID}/exports/{exportID}/download", parent.ID.String(), id).
+			WithCookie(sessions.CookieName, adminSession).
+			Expect().
+			Status(http.StatusOK)
+		res.ContentType("application/zip")
+
+		files := readZip(t, res.Body().Raw())
+		for _, name := range []string{
+			"index.html",
+			"channels.json",
+			"users.json",
+			"stamps.json",
+			"files.json",
+			fmt.Sprintf("channels/%s/messages.json", parent.ID),
+			fmt.Sprintf("channels/%s/index.html", parent.ID),
+			fmt.Sprintf("files/%s/%s", file.ID, file.Name),
+		} {
+			assert.Contains(t, files, name)
+		}
+		assert.NotContains(t, files, fmt.Sprintf("channels/%s/messages.json", child.ID))
+
+		r, err := files[fmt.Sprintf("channels/%s/messages.json", parent.ID)].Open()
+		require.NoError(t, err)
+		defer r.Close()
+		var messages []struct {
+			ID      uuid.UUID `json:"id"`
+			Content string    `json:"content"`
+			History []struct {
+				Content string `json:"content"`
+			} `json:"history"`
+		}
+		require.NoError(t, json.NewDecoder(r).Decode(&messages))
+		if assert.Len(t, messages, 1) {
+			assert.Equal(t, m.ID, messages[0].ID)
+			assert.True(t, strings.HasPrefix(messages[0].Content, "edited"))
+			assert.Len(t, messages[0].History, 1)
+		}
+
+		// アーカイブはエクスポートしたユーザーのみがアクセスできる
+		ex, err := repo.GetChannelExport(uuid.FromStringOrNil(id))
+		require.NoError(t, err)
+		ok, err := repo.IsFileAccessible(ex.FileID, admin.ID)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		ok, err = repo.IsFileAccessible(ex.FileID, user.ID)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("Successful2", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		id := e.POST("/api/1.0/channels/{channelID}/exports", parent.ID.String()).
+			WithCookie(sessions.CookieName, adminSession).
+			WithJSON(map[string]bool{"descendants": true}).
+			Expect().
+			Status(http.StatusAccepted).
+			JSON().
+			Object().
+			ValueEqual("descendants", true).
+			Value("id").
+			String().
+			Raw()
+
+		runExport(t, uuid.FromStringOrNil(id))
+
+		body := e.GET("/api/1.0/channels/{channelID}/exports/{exportID}/download", parent.ID.String(), id).
+			WithCookie(sessions.CookieName, adminSession).
+			Expect().
+			Status(http.StatusOK).
+			Body().
+			Raw()
+
+		files := readZip(t, body)
+		assert.Contains(t, files, fmt.Sprintf("channels/%s/messages.json", parent.ID))
+		assert.Contains(t, files, fmt.Sprintf("channels/%s/messages.json", child.ID))
+	})
+}
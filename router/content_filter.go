@@ -0,0 +1,106 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/gofrs/uuid"
+	"github.com/labstack/echo"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"gopkg.in/guregu/null.v3"
+)
+
+// GetContentFilterRules GET /content-filters
+func (h *Handlers) GetContentFilterRules(c echo.Context) error {
+	rules, err := h.Repo.GetContentFilterRules()
+	if err != nil {
+		return internalServerError(err, h.requestContextLogger(c))
+	}
+	return c.JSON(http.StatusOK, rules)
+}
+
+// PostContentFilterRule POST /content-filters
+func (h *Handlers) PostContentFilterRule(c echo.Context) error {
+	userID := getRequestUserID(c)
+
+	var req struct {
+		Pattern     string    `json:"pattern"     validate:"required"`
+		IsRegex     bool      `json:"isRegex"`
+		ChannelID   uuid.UUID `json:"channelId"`
+		Action      string    `json:"action"      validate:"required,oneof=reject flag mask"`
+		Description string    `json:"description"`
+	}
+	if err := bindAndValidate(c, &req); err != nil {
+		return badRequest(err)
+	}
+
+	r, err := h.Repo.CreateContentFilterRule(repository.CreateContentFilterRuleArgs{
+		Pattern:     req.Pattern,
+		IsRegex:     req.IsRegex,
+		ChannelID:   req.ChannelID,
+		Action:      model.ContentFilterAction(req.Action),
+		Description: req.Description,
+		CreatorID:   userID,
+	})
+	if err != nil {
+		switch {
+		case repository.IsArgError(err):
+			return badRequest(err)
+		default:
+			return internalServerError(err, h.requestContextLogger(c))
+		}
+	}
+	return c.JSON(http.StatusCreated, r)
+}
+
+// GetContentFilterRule GET /content-filters/:ruleID
+func (h *Handlers) GetContentFilterRule(c echo.Context) error {
+	return c.JSON(http.StatusOK, getContentFilterRuleFromContext(c))
+}
+
+// PatchContentFilterRule PATCH /content-filters/:ruleID
+func (h *Handlers) PatchContentFilterRule(c echo.Context) error {
+	r := getContentFilterRuleFromContext(c)
+
+	var req struct {
+		Pattern     null.String `json:"pattern"`
+		IsRegex     null.Bool   `json:"isRegex"`
+		Action      null.String `json:"action"`
+		Description null.String `json:"description"`
+	}
+	if err := bindAndValidate(c, &req); err != nil {
+		return badRequest(err)
+	}
+
+	if err := h.Repo.UpdateContentFilterRule(r.ID, repository.UpdateContentFilterRuleArgs{
+		Pattern:     req.Pattern,
+		IsRegex:     req.IsRegex,
+		Action:      req.Action,
+		Description: req.Description,
+	}); err != nil {
+		switch {
+		case err == repository.ErrNotFound:
+			return notFound()
+		case repository.IsArgError(err):
+			return badRequest(err)
+		default:
+			return internalServerError(err, h.requestContextLogger(c))
+		}
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// DeleteContentFilterRule DELETE /content-filters/:ruleID
+func (h *Handlers) DeleteContentFilterRule(c echo.Context) error {
+	r := getContentFilterRuleFromContext(c)
+
+	if err := h.Repo.DeleteContentFilterRule(r.ID); err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return notFound()
+		default:
+			return internalServerError(err, h.requestContextLogger(c))
+		}
+	}
+	return c.NoContent(http.StatusNoContent)
+}
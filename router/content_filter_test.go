@@ -0,0 +1,246 @@
+package router
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/sessions"
+)
+
+func mustMakeContentFilterRule(t *testing.T, repo repository.Repository, creatorID, channelID uuid.UUID, pattern string, action model.ContentFilterAction) *model.ContentFilterRule {
+	t.Helper()
+	r, err := repo.CreateContentFilterRule(repository.CreateContentFilterRuleArgs{
+		Pattern:   pattern,
+		ChannelID: channelID,
+		Action:    action,
+		CreatorID: creatorID,
+	})
+	require.NoError(t, err)
+	return r
+}
+
+func TestHandlers_GetContentFilterRules(t *testing.T) {
+	t.Parallel()
+	repo, server, _, _, session, adminSession, _, adminUser := setupWithUsers(t, common4)
+
+	channel := mustMakeChannel(t, repo, random)
+	mustMakeContentFilterRule(t, repo, adminUser.ID, channel.ID, "spam", model.ContentFilterActionReject)
+
+	t.Run("NotLoggedIn", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.GET("/api/1.0/content-filters").
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.GET("/api/1.0/content-filters").
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
+	t.Run("Successful1", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.GET("/api/1.0/content-filters").
+			WithCookie(sessions.CookieName, adminSession).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Array().
+			NotEmpty()
+	})
+}
+
+func TestHandlers_PostContentFilterRule(t *testing.T) {
+	t.Parallel()
+	repo, server, _, require, session, adminSession, _, adminUser := setupWithUsers(t, common4)
+
+	channel := mustMakeChannel(t, repo, random)
+
+	t.Run("Forbidden", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.POST("/api/1.0/content-filters").
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]interface{}{"pattern": "spam", "action": "reject"}).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
+	t.Run("BadRequest", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.POST("/api/1.0/content-filters").
+			WithCookie(sessions.CookieName, adminSession).
+			WithJSON(map[string]interface{}{"pattern": "spam", "action": "ban"}).
+			Expect().
+			Status(http.StatusBadRequest)
+		e.POST("/api/1.0/content-filters").
+			WithCookie(sessions.CookieName, adminSession).
+			WithJSON(map[string]interface{}{"pattern": "(", "isRegex": true, "action": "reject", "channelId": channel.ID}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("Successful1", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		obj := e.POST("/api/1.0/content-filters").
+			WithCookie(sessions.CookieName, adminSession).
+			WithJSON(map[string]interface{}{"pattern": "sp[a4]m", "isRegex": true, "action": "mask", "channelId": channel.ID}).
+			Expect().
+			Status(http.StatusCreated).
+			JSON().
+			Object()
+
+		obj.Value("pattern").String().Equal("sp[a4]m")
+		obj.Value("isRegex").Boolean().True()
+		obj.Value("action").String().Equal("mask")
+		obj.Value("channelId").String().Equal(channel.ID.String())
+		obj.Value("creatorId").String().Equal(adminUser.ID.String())
+
+		id := uuid.FromStringOrNil(obj.Value("id").String().Raw())
+		_, err := repo.GetContentFilterRule(id)
+		require.NoError(err)
+	})
+}
+
+func TestHandlers_PatchContentFilterRule(t *testing.T) {
+	t.Parallel()
+	repo, server, assert, require, session, adminSession, _, adminUser := setupWithUsers(t, common4)
+
+	channel := mustMakeChannel(t, repo, random)
+	rule := mustMakeContentFilterRule(t, repo, adminUser.ID, channel.ID, "spam", model.ContentFilterActionReject)
+
+	t.Run("Forbidden", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.PATCH("/api/1.0/content-filters/{ruleID}", rule.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]interface{}{"action": "flag"}).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.PATCH("/api/1.0/content-filters/{ruleID}", uuid.Must(uuid.NewV4()).String()).
+			WithCookie(sessions.CookieName, adminSession).
+			WithJSON(map[string]interface{}{"action": "flag"}).
+			Expect().
+			Status(http.StatusNotFound)
+	})
+
+	t.Run("Successful1", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.PATCH("/api/1.0/content-filters/{ruleID}", rule.ID.String()).
+			WithCookie(sessions.CookieName, adminSession).
+			WithJSON(map[string]interface{}{"action": "flag", "description": "desc"}).
+			Expect().
+			Status(http.StatusNoContent)
+
+		r, err := repo.GetContentFilterRule(rule.ID)
+		require.NoError(err)
+		assert.Equal(model.ContentFilterActionFlag, r.Action)
+		assert.Equal("desc", r.Description)
+		assert.Equal("spam", r.Pattern)
+	})
+}
+
+func TestHandlers_DeleteContentFilterRule(t *testing.T) {
+	t.Parallel()
+	repo, server, _, require, session, adminSession, _, adminUser := setupWithUsers(t, common4)
+
+	channel := mustMakeChannel(t, repo, random)
+	rule := mustMakeContentFilterRule(t, repo, adminUser.ID, channel.ID, "spam", model.ContentFilterActionReject)
+
+	t.Run("Forbidden", func(t *testing.T) {
+		e := makeExp(t, server)
+		e.DELETE("/api/1.0/content-filters/{ruleID}", rule.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
+	t.Run("Successful1", func(t *testing.T) {
+		e := makeExp(t, server)
+		e.DELETE("/api/1.0/content-filters/{ruleID}", rule.ID.String()).
+			WithCookie(sessions.CookieName, adminSession).
+			Expect().
+			Status(http.StatusNoContent)
+
+		_, err := repo.GetContentFilterRule(rule.ID)
+		require.Equal(repository.ErrNotFound, err)
+	})
+}
+
+func TestContentFilter_PostMessage(t *testing.T) {
+	t.Parallel()
+	repo, server, assert, require, session, _, _, adminUser := setupWithUsers(t, common4)
+
+	channel := mustMakeChannel(t, repo, random)
+	reject := mustMakeContentFilterRule(t, repo, adminUser.ID, channel.ID, "forbidden", model.ContentFilterActionReject)
+	mustMakeContentFilterRule(t, repo, adminUser.ID, channel.ID, "secret", model.ContentFilterActionMask)
+	mustMakeContentFilterRule(t, repo, adminUser.ID, channel.ID, "suspicious", model.ContentFilterActionFlag)
+
+	t.Run("Rejected", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.POST("/api/1.0/channels/{channelID}/messages", channel.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]string{"text": "this is FORBIDDEN text"}).
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().
+			Object().
+			Value("ruleId").
+			String().
+			Equal(reject.ID.String())
+	})
+
+	t.Run("Masked", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.POST("/api/1.0/channels/{channelID}/messages", channel.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]string{"text": "my secret"}).
+			Expect().
+			Status(http.StatusCreated).
+			JSON().
+			Object().
+			Value("content").
+			String().
+			Equal("my ******")
+	})
+
+	t.Run("Flagged", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		obj := e.POST("/api/1.0/channels/{channelID}/messages", channel.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]string{"text": "suspicious link"}).
+			Expect().
+			Status(http.StatusCreated).
+			JSON().
+			Object()
+
+		obj.Value("content").String().Equal("suspicious link")
+		mid := uuid.FromStringOrNil(obj.Value("messageId").String().Raw())
+		reports, err := repo.GetMessageReportsByMessageID(mid)
+		require.NoError(err)
+		if assert.Len(reports, 1) {
+			assert.Equal(uuid.Nil, reports[0].Reporter)
+		}
+	})
+}
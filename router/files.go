@@ -21,6 +21,23 @@ func (h *Handlers) PostFile(c echo.Context) error {
 		return badRequest(err)
 	}
 
+	// 投稿先チャンネル
+	channelID := uuid.Nil
+	if s := c.FormValue("channel_id"); len(s) != 0 {
+		channelID, err = uuid.FromString(s)
+		if err != nil {
+			return badRequest(fmt.Sprintf("invalid channel id: %s", s))
+		}
+		if len(c.FormValue("acl_readable")) != 0 {
+			return badRequest("channel_id and acl_readable cannot be specified at the same time")
+		}
+		if ok, err := h.Repo.IsChannelAccessibleToUser(userID, channelID); err != nil {
+			return internalServerError(err, h.requestContextLogger(c))
+		} else if !ok {
+			return badRequest(fmt.Sprintf("unknown channel id: %s", channelID))
+		}
+	}
+
 	// アクセスコントロールリスト作成
 	aclRead := repository.ACL{}
 	if s := c.FormValue("acl_readable"); len(s) != 0 && s != "all" {
@@ -43,7 +60,13 @@ func (h *Handlers) PostFile(c echo.Context) error {
 	}
 	defer src.Close()
 
-	file, err := h.Repo.SaveFileWithACL(uploadedFile.Filename, src, uploadedFile.Size, uploadedFile.Header.Get(echo.HeaderContentType), model.FileTypeUserFile, userID, aclRead)
+	var file *model.File
+	if channelID != uuid.Nil {
+		// チャンネルのメンバーのみアクセス可能
+		file, err = h.Repo.SaveChannelFile(uploadedFile.Filename, src, uploadedFile.Size, uploadedFile.Header.Get(echo.HeaderContentType), model.FileTypeUserFile, userID, channelID)
+	} else {
+		file, err = h.Repo.SaveFileWithACL(uploadedFile.Filename, src, uploadedFile.Size, uploadedFile.Header.Get(echo.HeaderContentType), model.FileTypeUserFile, userID, aclRead)
+	}
 	if err != nil {
 		return internalServerError(err, h.requestContextLogger(c))
 	}
@@ -120,6 +120,83 @@ func TestHandlers_PostFile(t *testing.T) {
 			assert.False(t, ok)
 		})
 	})
+
+	t.Run("Bad Request (Invalid Channel)", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.POST("/api/1.0/files").
+			WithCookie(sessions.CookieName, session).
+			WithMultipart().
+			WithFileBytes("file", "test.txt", []byte("aaa")).
+			WithFormField("channel_id", "bad id").
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("Bad Request (Inaccessible Channel)", func(t *testing.T) {
+		t.Parallel()
+		other := mustMakeUser(t, repo, random)
+		ch := mustMakePrivateChannel(t, repo, random, []uuid.UUID{other.ID})
+		e := makeExp(t, server)
+		e.POST("/api/1.0/files").
+			WithCookie(sessions.CookieName, session).
+			WithMultipart().
+			WithFileBytes("file", "test.txt", []byte("aaa")).
+			WithFormField("channel_id", ch.ID).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("Bad Request (Channel with ACL)", func(t *testing.T) {
+		t.Parallel()
+		ch := mustMakePrivateChannel(t, repo, random, []uuid.UUID{user.ID})
+		e := makeExp(t, server)
+		e.POST("/api/1.0/files").
+			WithCookie(sessions.CookieName, session).
+			WithMultipart().
+			WithFileBytes("file", "test.txt", []byte("aaa")).
+			WithFormField("channel_id", ch.ID).
+			WithFormField("acl_readable", user.ID).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("Success with Private Channel", func(t *testing.T) {
+		t.Parallel()
+		member := mustMakeUser(t, repo, random)
+		ch := mustMakePrivateChannel(t, repo, random, []uuid.UUID{user.ID, member.ID})
+		e := makeExp(t, server)
+		obj := e.POST("/api/1.0/files").
+			WithCookie(sessions.CookieName, session).
+			WithMultipart().
+			WithFileBytes("file", "test.txt", []byte("test file")).
+			WithFormField("channel_id", ch.ID).
+			Expect().
+			Status(http.StatusCreated).
+			JSON().
+			Object()
+
+		fileID := uuid.FromStringOrNil(obj.Value("fileId").String().Raw())
+
+		e.GET("/api/1.0/files/{fileID}", fileID).
+			WithCookie(sessions.CookieName, generateSession(t, member.ID)).
+			Expect().
+			Status(http.StatusOK)
+
+		// チャンネルのメンバーでないユーザーはアクセスできない
+		outsider := mustMakeUser(t, repo, random)
+		e.GET("/api/1.0/files/{fileID}", fileID).
+			WithCookie(sessions.CookieName, generateSession(t, outsider.ID)).
+			Expect().
+			Status(http.StatusForbidden)
+
+		// メンバーに追加されるとアクセスできるようになる
+		require.NoError(t, repo.(*TestRepository).AddPrivateChannelMember(ch.ID, outsider.ID))
+		e.GET("/api/1.0/files/{fileID}", fileID).
+			WithCookie(sessions.CookieName, generateSession(t, outsider.ID)).
+			Expect().
+			Status(http.StatusOK)
+	})
 }
 
 func TestHandlers_GetFileByID(t *testing.T) {
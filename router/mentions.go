@@ -0,0 +1,63 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/gofrs/uuid"
+	"github.com/labstack/echo"
+	"github.com/traPtitech/traQ/repository"
+)
+
+// GetMyMentions GET /users/me/mentions
+func (h *Handlers) GetMyMentions(c echo.Context) error {
+	userID := getRequestUserID(c)
+
+	var req struct {
+		Limit     int    `query:"limit" validate:"min=0,max=200"`
+		Before    string `query:"before"`
+		ChannelID string `query:"channelId"`
+		Unread    bool   `query:"unread"`
+	}
+	if err := bindAndValidate(c, &req); err != nil {
+		return badRequest(err)
+	}
+
+	q := repository.MentionsQuery{
+		User:       userID,
+		UnreadOnly: req.Unread,
+		Limit:      req.Limit,
+	}
+	if q.Limit == 0 {
+		q.Limit = 50
+	}
+	if len(req.Before) > 0 {
+		id, err := uuid.FromString(req.Before)
+		if err != nil {
+			return badRequest("invalid before")
+		}
+		q.Before = id
+	}
+	if len(req.ChannelID) > 0 {
+		id, err := uuid.FromString(req.ChannelID)
+		if err != nil {
+			return badRequest("invalid channelId")
+		}
+		q.Channel = id
+	}
+
+	mentions, more, err := h.Repo.GetMentions(q)
+	if err != nil {
+		if repository.IsArgError(err) {
+			return badRequest(err)
+		}
+		return internalServerError(err, h.requestContextLogger(c))
+	}
+
+	res := make([]*mentionResponse, len(mentions))
+	for i, v := range mentions {
+		res[i] = formatMention(v)
+	}
+
+	setMoreHeader(c, more)
+	return c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,119 @@
+package router
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/traPtitech/traQ/sessions"
+)
+
+func TestHandlers_GetMyMentions(t *testing.T) {
+	t.Parallel()
+	repo, server, _, require, _, _, testUser, _ := setupWithUsers(t, common3)
+
+	user := mustMakeUser(t, repo, random)
+	session := generateSession(t, user.ID)
+	group := mustMakeUserGroup(t, repo, random, testUser.ID)
+	channel := mustMakeChannel(t, repo, random)
+	channel2 := mustMakeChannel(t, repo, random)
+
+	m1 := mustMakeMessage(t, repo, testUser.ID, channel.ID)
+	require.NoError(repo.CreateMentions(m1, map[uuid.UUID]uuid.UUID{user.ID: uuid.Nil}))
+	require.NoError(repo.ReadChannel(user.ID, channel.ID))
+	m2 := mustMakeMessage(t, repo, testUser.ID, channel.ID)
+	require.NoError(repo.CreateMentions(m2, map[uuid.UUID]uuid.UUID{user.ID: group.ID}))
+	m3 := mustMakeMessage(t, repo, testUser.ID, channel2.ID)
+	require.NoError(repo.CreateMentions(m3, map[uuid.UUID]uuid.UUID{user.ID: uuid.Nil}))
+
+	t.Run("NotLoggedIn", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.GET("/api/1.0/users/me/mentions").
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("BadRequest", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.GET("/api/1.0/users/me/mentions").
+			WithCookie(sessions.CookieName, session).
+			WithQuery("before", "invalid").
+			Expect().
+			Status(http.StatusBadRequest)
+		e.GET("/api/1.0/users/me/mentions").
+			WithCookie(sessions.CookieName, session).
+			WithQuery("before", uuid.Must(uuid.NewV4()).String()).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("Successful1", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		res := e.GET("/api/1.0/users/me/mentions").
+			WithCookie(sessions.CookieName, session).
+			Expect()
+		res.Status(http.StatusOK)
+		res.Header(headerMore).Equal("false")
+		arr := res.JSON().Array()
+		arr.Length().Equal(3)
+		arr.Element(0).Object().Value("messageId").String().Equal(m3.ID.String())
+		arr.Element(0).Object().Value("groupId").Null()
+		arr.Element(1).Object().Value("messageId").String().Equal(m2.ID.String())
+		arr.Element(1).Object().Value("groupId").String().Equal(group.ID.String())
+		arr.Element(1).Object().Value("read").Boolean().False()
+		arr.Element(1).Object().Value("message").Object().Value("messageId").String().Equal(m2.ID.String())
+		arr.Element(2).Object().Value("messageId").String().Equal(m1.ID.String())
+		arr.Element(2).Object().Value("read").Boolean().True()
+	})
+
+	t.Run("Successful2", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		res := e.GET("/api/1.0/users/me/mentions").
+			WithCookie(sessions.CookieName, session).
+			WithQuery("limit", 1).
+			WithQuery("before", m3.ID.String()).
+			Expect()
+		res.Status(http.StatusOK)
+		res.Header(headerMore).Equal("true")
+		arr := res.JSON().Array()
+		arr.Length().Equal(1)
+		arr.First().Object().Value("messageId").String().Equal(m2.ID.String())
+	})
+
+	t.Run("Successful3", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		arr := e.GET("/api/1.0/users/me/mentions").
+			WithCookie(sessions.CookieName, session).
+			WithQuery("channelId", channel.ID.String()).
+			WithQuery("unread", true).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Array()
+		arr.Length().Equal(1)
+		arr.First().Object().Value("messageId").String().Equal(m2.ID.String())
+	})
+	t.Run("InaccessibleChannel", func(t *testing.T) {
+		t.Parallel()
+		user := mustMakeUser(t, repo, random)
+		session := generateSession(t, user.ID)
+		pCh := mustMakePrivateChannel(t, repo, random, []uuid.UUID{testUser.ID})
+		m := mustMakeMessage(t, repo, testUser.ID, pCh.ID)
+		require.NoError(repo.CreateMentions(m, map[uuid.UUID]uuid.UUID{user.ID: uuid.Nil}))
+
+		e := makeExp(t, server)
+		e.GET("/api/1.0/users/me/mentions").
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Array().
+			Length().
+			Equal(0)
+	})
+}
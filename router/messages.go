@@ -4,15 +4,236 @@ import (
 	"fmt"
 	"github.com/gofrs/uuid"
 	"github.com/labstack/echo"
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"github.com/traPtitech/traQ/model"
 	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/unfurler"
+	"github.com/traPtitech/traQ/utils/message"
+	"gopkg.in/guregu/null.v3"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 )
 
+// messagesQuery メッセージ一覧取得APIのクエリパラメータ
+type messagesQuery struct {
+	Limit  int    `query:"limit"`
+	Offset int    `query:"offset"`
+	Since  string `query:"since"`
+	Until  string `query:"until"`
+	Before string `query:"before"`
+	After  string `query:"after"`
+	Order  string `query:"order"`
+}
+
+// convert リポジトリ用のクエリに変換します。limitの最大値はmaxLimitです
+func (q *messagesQuery) convert(maxLimit int) (repository.MessagesQuery, error) {
+	r := repository.MessagesQuery{
+		Limit:  q.Limit,
+		Offset: q.Offset,
+	}
+	if r.Limit > maxLimit || r.Limit <= 0 {
+		r.Limit = maxLimit
+	}
+
+	if len(q.Since) > 0 {
+		t, err := time.Parse(time.RFC3339Nano, q.Since)
+		if err != nil {
+			return r, fmt.Errorf("invalid since: %s", q.Since)
+		}
+		r.Since = null.TimeFrom(t)
+	}
+	if len(q.Until) > 0 {
+		t, err := time.Parse(time.RFC3339Nano, q.Until)
+		if err != nil {
+			return r, fmt.Errorf("invalid until: %s", q.Until)
+		}
+		r.Until = null.TimeFrom(t)
+	}
+	if len(q.Before) > 0 {
+		id, err := uuid.FromString(q.Before)
+		if err != nil {
+			return r, fmt.Errorf("invalid before: %s", q.Before)
+		}
+		r.Before = id
+	}
+	if len(q.After) > 0 {
+		id, err := uuid.FromString(q.After)
+		if err != nil {
+			return r, fmt.Errorf("invalid after: %s", q.After)
+		}
+		r.After = id
+	}
+
+	switch strings.ToLower(q.Order) {
+	case "", "desc":
+		r.Asc = false
+	case "asc":
+		r.Asc = true
+	default:
+		return r, fmt.Errorf("invalid order: %s", q.Order)
+	}
+	return r, nil
+}
+
+// cacheKey singleflight用のキーを生成します
+func (q *messagesQuery) cacheKey(r repository.MessagesQuery) string {
+	return fmt.Sprintf("%d/%d/%s/%s/%s/%s/%t", r.Limit, r.Offset, q.Since, q.Until, r.Before, r.After, r.Asc)
+}
+
+type messagesResult struct {
+	messages []*messageResponse
+	more     bool
+}
+
+// setMoreHeader 続きのメッセージが存在するかどうかをヘッダーに設定します
+func setMoreHeader(c echo.Context, more bool) {
+	c.Response().Header().Set(headerMore, strconv.FormatBool(more))
+}
+
+// resolveCitations メッセージ中の引用を閲覧者がアクセス可能なものだけ解決して設定します
+//
+// singleflightで共有されているメッセージは、コピーしてから渡す必要があります。
+func (h *Handlers) resolveCitations(userID uuid.UUID, ms ...*messageResponse) error {
+	for _, m := range ms {
+		var ids []uuid.UUID
+		for _, v := range message.ParseAST(m.Content).CitedMessageIDs() {
+			if id, err := uuid.FromString(v); err == nil {
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) == 0 {
+			continue
+		}
+
+		citations, err := h.Repo.GetMessageCitations(userID, ids)
+		if err != nil {
+			return err
+		}
+		m.Citations = make([]*citationResponse, len(citations))
+		for i, v := range citations {
+			m.Citations[i] = formatCitation(v)
+		}
+	}
+	return nil
+}
+
 // GetMessageByID GET /messages/:messageID
 func (h *Handlers) GetMessageByID(c echo.Context) error {
+	userID := getRequestUserID(c)
 	m := getMessageFromContext(c)
-	return c.JSON(http.StatusOK, formatMessage(m))
+
+	res := formatMessage(m)
+	if err := h.resolveCitations(userID, res); err != nil {
+		return internalServerError(err, h.requestContextLogger(c))
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+// messageRevisionResponse メッセージの編集履歴の各版
+type messageRevisionResponse struct {
+	RevisionID uuid.UUID          `json:"revisionId"`
+	UserID     uuid.UUID          `json:"userId"`
+	Content    string             `json:"content"`
+	DateTime   time.Time          `json:"dateTime"`
+	Current    bool               `json:"current"`
+	Diff       []lineDiffResponse `json:"diff,omitempty"`
+}
+
+// lineDiffResponse 行単位の差分
+type lineDiffResponse struct {
+	Type string `json:"type"`
+	Line string `json:"line"`
+}
+
+// GetMessageHistory GET /messages/:messageID/history
+func (h *Handlers) GetMessageHistory(c echo.Context) error {
+	m := getMessageFromContext(c)
+
+	var req struct {
+		Diff bool `query:"diff"`
+	}
+	if err := bindAndValidate(c, &req); err != nil {
+		return badRequest(err)
+	}
+
+	archives, err := h.Repo.GetArchivedMessagesByID(m.ID)
+	if err != nil {
+		return internalServerError(err, h.requestContextLogger(c))
+	}
+
+	// 古い版から順に並べ、最後に現在の版を置く
+	res := make([]*messageRevisionResponse, 0, len(archives)+1)
+	for _, v := range archives {
+		res = append(res, &messageRevisionResponse{
+			RevisionID: v.ID,
+			UserID:     v.UserID,
+			Content:    v.Text,
+			DateTime:   v.DateTime,
+		})
+	}
+	res = append(res, &messageRevisionResponse{
+		RevisionID: m.ID,
+		UserID:     m.UserID,
+		Content:    m.Text,
+		DateTime:   m.UpdatedAt,
+		Current:    true,
+	})
+
+	if req.Diff {
+		for i := 0; i < len(res)-1; i++ {
+			res[i].Diff = makeLineDiff(res[i].Content, res[i+1].Content)
+		}
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// GetMessagePreviews GET /messages/:messageID/previews
+func (h *Handlers) GetMessagePreviews(c echo.Context) error {
+	m := getMessageFromContext(c)
+
+	previews, err := h.Repo.GetLinkPreviews(unfurler.ExtractURLs(m.Text))
+	if err != nil {
+		return internalServerError(err, h.requestContextLogger(c))
+	}
+
+	// 取得に失敗したURLは返さない
+	res := make([]*model.LinkPreview, 0, len(previews))
+	for _, v := range previews {
+		if !v.Failed {
+			res = append(res, v)
+		}
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+// makeLineDiff textからnextへの行単位の差分を生成します
+func makeLineDiff(text, next string) []lineDiffResponse {
+	dmp := diffmatchpatch.New()
+	a, b, lines := dmp.DiffLinesToChars(text, next)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(a, b, false), lines)
+
+	res := make([]lineDiffResponse, 0)
+	for _, d := range diffs {
+		var t string
+		switch d.Type {
+		case diffmatchpatch.DiffInsert:
+			t = "insert"
+		case diffmatchpatch.DiffDelete:
+			t = "delete"
+		default:
+			t = "equal"
+		}
+		for _, line := range strings.SplitAfter(d.Text, "\n") {
+			if len(line) == 0 {
+				continue
+			}
+			res = append(res, lineDiffResponse{Type: t, Line: strings.TrimSuffix(line, "\n")})
+		}
+	}
+	return res
 }
 
 // PutMessageByID PUT /messages/:messageID
@@ -22,7 +243,8 @@ func (h *Handlers) PutMessageByID(c echo.Context) error {
 	m := getMessageFromContext(c)
 
 	var req struct {
-		Text string `json:"text"`
+		Text         string `json:"text"`
+		StrictEmbeds bool   `json:"strictEmbeds"`
 	}
 	if err := bindAndValidate(c, &req); err != nil {
 		return badRequest(err)
@@ -33,10 +255,17 @@ func (h *Handlers) PutMessageByID(c echo.Context) error {
 		return forbidden("This is not your message")
 	}
 
-	if err := h.Repo.UpdateMessage(messageID, req.Text); err != nil {
+	text, err := h.canonicalizeEmbeds(c, req.Text, req.StrictEmbeds)
+	if err != nil {
+		return err
+	}
+
+	if err := h.Repo.UpdateMessage(messageID, text); err != nil {
 		switch {
-		case repository.IsArgError(err):
+		case repository.IsArgError(err), repository.IsContentFilterError(err):
 			return badRequest(err)
+		case err == repository.ErrChannelArchived:
+			return forbidden("the channel is archived")
 		default:
 			return internalServerError(err, h.requestContextLogger(c))
 		}
@@ -78,7 +307,12 @@ func (h *Handlers) DeleteMessageByID(c echo.Context) error {
 	}
 
 	if err := h.Repo.DeleteMessage(messageID); err != nil {
-		return internalServerError(err, h.requestContextLogger(c))
+		switch err {
+		case repository.ErrChannelArchived:
+			return forbidden("the channel is archived")
+		default:
+			return internalServerError(err, h.requestContextLogger(c))
+		}
 	}
 
 	return c.NoContent(http.StatusNoContent)
@@ -89,27 +323,31 @@ func (h *Handlers) GetMessagesByChannelID(c echo.Context) error {
 	userID := getRequestUserID(c)
 	channelID := getRequestParamAsUUID(c, paramChannelID)
 
-	var req struct {
-		Limit  int `query:"limit"`
-		Offset int `query:"offset"`
-	}
+	var req messagesQuery
 	if err := bindAndValidate(c, &req); err != nil {
 		return badRequest(err)
 	}
 
-	if req.Limit > 200 || req.Limit == 0 {
-		req.Limit = 200
+	q, err := req.convert(200)
+	if err != nil {
+		return badRequest(err)
 	}
+	q.Channel = channelID
+	q.ExcludeReplies = true
 
-	resI, err, _ := h.messagesResponseCacheGroup.Do(fmt.Sprintf("%s/%d/%d", channelID, req.Limit, req.Offset), func() (interface{}, error) {
-		messages, err := h.Repo.GetMessagesByChannelID(channelID, req.Limit, req.Offset)
-		return formatMessages(messages), err
+	resI, err, _ := h.messagesResponseCacheGroup.Do(fmt.Sprintf("%s/%s", channelID, req.cacheKey(q)), func() (interface{}, error) {
+		messages, more, err := h.Repo.GetMessages(q)
+		return &messagesResult{messages: formatMessages(messages), more: more}, err
 	})
 	if err != nil {
+		if repository.IsArgError(err) {
+			return badRequest(err)
+		}
 		return internalServerError(err, h.requestContextLogger(c))
 	}
 
-	res := resI.([]*messageResponse)
+	r := resI.(*messagesResult)
+	res := make([]*messageResponse, len(r.messages))
 	reports, err := h.Repo.GetMessageReportsByReporterID(userID)
 	if err != nil {
 		return internalServerError(err, h.requestContextLogger(c))
@@ -118,10 +356,17 @@ func (h *Handlers) GetMessagesByChannelID(c echo.Context) error {
 	for _, v := range reports {
 		hidden[v.MessageID] = true
 	}
-	for _, v := range res {
-		v.Reported = hidden[v.MessageID]
+	for i, v := range r.messages {
+		// singleflightで共有されているため、コピーしてから変更する
+		m := *v
+		m.Reported = hidden[m.MessageID]
+		res[i] = &m
+	}
+	if err := h.resolveCitations(userID, res...); err != nil {
+		return internalServerError(err, h.requestContextLogger(c))
 	}
 
+	setMoreHeader(c, r.more)
 	return c.JSON(http.StatusOK, res)
 }
 
@@ -131,23 +376,122 @@ func (h *Handlers) PostMessage(c echo.Context) error {
 	channelID := getRequestParamAsUUID(c, paramChannelID)
 
 	var req struct {
-		Text string `json:"text"`
+		Text         string `json:"text"`
+		StrictEmbeds bool   `json:"strictEmbeds"`
 	}
 	if err := bindAndValidate(c, &req); err != nil {
 		return badRequest(err)
 	}
 
-	m, err := h.Repo.CreateMessage(userID, channelID, req.Text)
+	ch := getChannelFromContext(c)
+
+	text, err := h.canonicalizeEmbeds(c, req.Text, req.StrictEmbeds)
+	if err != nil {
+		return err
+	}
+
+	if err := h.checkSlowMode(c, ch, userID); err != nil {
+		return err
+	}
+
+	m, err := h.Repo.CreateMessage(userID, channelID, text)
 	if err != nil {
 		switch {
-		case repository.IsArgError(err):
+		case repository.IsArgError(err), repository.IsContentFilterError(err):
 			return badRequest(err)
+		case err == repository.ErrChannelArchived:
+			return forbidden("the channel is archived")
 		default:
 			return internalServerError(err, h.requestContextLogger(c))
 		}
 	}
+	h.recordSlowMode(ch, userID)
 
-	return c.JSON(http.StatusCreated, formatMessage(m))
+	res := formatMessage(m)
+	if err := h.resolveCitations(userID, res); err != nil {
+		return internalServerError(err, h.requestContextLogger(c))
+	}
+	return c.JSON(http.StatusCreated, res)
+}
+
+// GetThreadMessages GET /messages/:messageID/thread
+func (h *Handlers) GetThreadMessages(c echo.Context) error {
+	userID := getRequestUserID(c)
+	m := getMessageFromContext(c)
+
+	var req messagesQuery
+	if err := bindAndValidate(c, &req); err != nil {
+		return badRequest(err)
+	}
+
+	q, err := req.convert(200)
+	if err != nil {
+		return badRequest(err)
+	}
+	q.Parent = m.ID
+
+	messages, more, err := h.Repo.GetMessages(q)
+	if err != nil {
+		if repository.IsArgError(err) {
+			return badRequest(err)
+		}
+		return internalServerError(err, h.requestContextLogger(c))
+	}
+
+	setMoreHeader(c, more)
+	res := formatMessages(messages)
+	if err := h.resolveCitations(userID, res...); err != nil {
+		return internalServerError(err, h.requestContextLogger(c))
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+// PostThreadMessage POST /messages/:messageID/thread
+func (h *Handlers) PostThreadMessage(c echo.Context) error {
+	userID := getRequestUserID(c)
+	parent := getMessageFromContext(c)
+
+	var req struct {
+		Text         string `json:"text"`
+		StrictEmbeds bool   `json:"strictEmbeds"`
+	}
+	if err := bindAndValidate(c, &req); err != nil {
+		return badRequest(err)
+	}
+
+	text, err := h.canonicalizeEmbeds(c, req.Text, req.StrictEmbeds)
+	if err != nil {
+		return err
+	}
+
+	ch, err := h.Repo.GetChannel(parent.ChannelID)
+	if err != nil {
+		return internalServerError(err, h.requestContextLogger(c))
+	}
+	if err := h.checkSlowMode(c, ch, userID); err != nil {
+		return err
+	}
+
+	m, err := h.Repo.CreateThreadReply(userID, parent.ID, text)
+	if err != nil {
+		switch {
+		case repository.IsArgError(err), repository.IsContentFilterError(err):
+			return badRequest(err)
+		case err == repository.ErrChannelArchived:
+			return forbidden("the channel is archived")
+		case err == repository.ErrNotFound:
+			return notFound()
+		default:
+			return internalServerError(err, h.requestContextLogger(c))
+		}
+	}
+	h.recordSlowMode(ch, userID)
+
+	res := formatMessage(m)
+	if err := h.resolveCitations(userID, res); err != nil {
+		return internalServerError(err, h.requestContextLogger(c))
+	}
+	return c.JSON(http.StatusCreated, res)
 }
 
 // GetDirectMessages GET /users/:userId/messages
@@ -155,16 +499,14 @@ func (h *Handlers) GetDirectMessages(c echo.Context) error {
 	myID := getRequestUserID(c)
 	targetID := getRequestParamAsUUID(c, paramUserID)
 
-	var req struct {
-		Limit  int `query:"limit"`
-		Offset int `query:"offset"`
-	}
+	var req messagesQuery
 	if err := bindAndValidate(c, &req); err != nil {
 		return badRequest(err)
 	}
 
-	if req.Limit > 200 || req.Limit == 0 {
-		req.Limit = 200
+	q, err := req.convert(200)
+	if err != nil {
+		return badRequest(err)
 	}
 
 	// DMチャンネルを取得
@@ -172,14 +514,24 @@ func (h *Handlers) GetDirectMessages(c echo.Context) error {
 	if err != nil {
 		return internalServerError(err, h.requestContextLogger(c))
 	}
+	q.Channel = ch.ID
+	q.ExcludeReplies = true
 
 	// メッセージ取得
-	messages, err := h.Repo.GetMessagesByChannelID(ch.ID, req.Limit, req.Offset)
+	messages, more, err := h.Repo.GetMessages(q)
 	if err != nil {
+		if repository.IsArgError(err) {
+			return badRequest(err)
+		}
 		return internalServerError(err, h.requestContextLogger(c))
 	}
 
-	return c.JSON(http.StatusOK, formatMessages(messages))
+	setMoreHeader(c, more)
+	res := formatMessages(messages)
+	if err := h.resolveCitations(myID, res...); err != nil {
+		return internalServerError(err, h.requestContextLogger(c))
+	}
+	return c.JSON(http.StatusOK, res)
 }
 
 // PostDirectMessage POST /users/:userId/messages
@@ -188,29 +540,106 @@ func (h *Handlers) PostDirectMessage(c echo.Context) error {
 	targetID := getRequestParamAsUUID(c, paramUserID)
 
 	var req struct {
-		Text string `json:"text"`
+		Text         string `json:"text"`
+		StrictEmbeds bool   `json:"strictEmbeds"`
 	}
 	if err := bindAndValidate(c, &req); err != nil {
 		return badRequest(err)
 	}
 
+	text, err := h.canonicalizeEmbeds(c, req.Text, req.StrictEmbeds)
+	if err != nil {
+		return err
+	}
+
 	// DMチャンネルを取得
 	ch, err := h.Repo.GetDirectMessageChannel(myID, targetID)
 	if err != nil {
 		return internalServerError(err, h.requestContextLogger(c))
 	}
 
-	m, err := h.Repo.CreateMessage(myID, ch.ID, req.Text)
+	m, err := h.Repo.CreateMessage(myID, ch.ID, text)
 	if err != nil {
 		switch {
-		case repository.IsArgError(err):
+		case repository.IsArgError(err), repository.IsContentFilterError(err):
+			return badRequest(err)
+		default:
+			return internalServerError(err, h.requestContextLogger(c))
+		}
+	}
+
+	res := formatMessage(m)
+	if err := h.resolveCitations(myID, res); err != nil {
+		return internalServerError(err, h.requestContextLogger(c))
+	}
+	return c.JSON(http.StatusCreated, res)
+}
+
+// PostEphemeralMessage POST /channels/:channelID/ephemeral
+func (h *Handlers) PostEphemeralMessage(c echo.Context) error {
+	userID := getRequestUserID(c)
+	ch := getChannelFromContext(c)
+
+	var req struct {
+		UserID uuid.UUID `json:"userId"`
+		Text   string    `json:"text"`
+	}
+	if err := bindAndValidate(c, &req); err != nil {
+		return badRequest(err)
+	}
+	if len(req.Text) == 0 {
+		return badRequest("text is required")
+	}
+	if err := checkChannelArchived(ch); err != nil {
+		return err
+	}
+
+	// 宛先ユーザーがチャンネルを閲覧できるか
+	if _, err := h.Repo.GetUser(req.UserID); err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return badRequest("the target user was not found")
+		default:
+			return internalServerError(err, h.requestContextLogger(c))
+		}
+	}
+	if ok, err := h.Repo.IsChannelAccessibleToUser(req.UserID, ch.ID); err != nil {
+		return internalServerError(err, h.requestContextLogger(c))
+	} else if !ok {
+		return badRequest("the target user cannot access the channel")
+	}
+
+	// 保存されないメッセージにもコンテンツフィルタを適用する
+	text, err := h.Repo.ApplyContentFilter(ch.ID, req.Text)
+	if err != nil {
+		switch {
+		case repository.IsContentFilterError(err):
 			return badRequest(err)
 		default:
 			return internalServerError(err, h.requestContextLogger(c))
 		}
 	}
 
-	return c.JSON(http.StatusCreated, formatMessage(m))
+	// 一時メッセージは保存せず、宛先ユーザーのみに送信する
+	res := &ephemeralMessageResponse{
+		MessageID:       uuid.Must(uuid.NewV4()),
+		UserID:          userID,
+		ParentChannelID: ch.ID,
+		Content:         text,
+		CreatedAt:       time.Now(),
+	}
+	go h.SSE.multicast(req.UserID, &eventData{
+		EventType: "EPHEMERAL_MESSAGE",
+		Payload: Payload{
+			"id":        res.MessageID,
+			"channelId": res.ParentChannelID,
+			"userId":    res.UserID,
+			"content":   res.Content,
+			"createdAt": res.CreatedAt,
+		},
+	})
+
+	return c.JSON(http.StatusCreated, res)
 }
 
 // PostMessageReport POST /messages/:messageID/report
@@ -252,12 +681,353 @@ func (h *Handlers) GetMessageReports(c echo.Context) error {
 	return c.JSON(http.StatusOK, reports)
 }
 
+// GetDeletedMessages GET /messages/deleted
+func (h *Handlers) GetDeletedMessages(c echo.Context) error {
+	var req struct {
+		ChannelID string `query:"channelId"`
+		UserID    string `query:"userId"`
+		Limit     int    `query:"limit"  validate:"min=0,max=200"`
+		Offset    int    `query:"offset" validate:"min=0"`
+	}
+	if err := bindAndValidate(c, &req); err != nil {
+		return badRequest(err)
+	}
+	var channelID, userID uuid.UUID
+	if len(req.ChannelID) > 0 {
+		id, err := uuid.FromString(req.ChannelID)
+		if err != nil {
+			return badRequest("invalid channelId")
+		}
+		channelID = id
+	}
+	if len(req.UserID) > 0 {
+		id, err := uuid.FromString(req.UserID)
+		if err != nil {
+			return badRequest("invalid userId")
+		}
+		userID = id
+	}
+	if channelID == uuid.Nil && userID == uuid.Nil {
+		return badRequest("channelId or userId is required")
+	}
+	if req.Limit == 0 {
+		req.Limit = 50
+	}
+
+	messages, err := h.Repo.GetDeletedMessages(channelID, userID, req.Limit, req.Offset)
+	if err != nil {
+		return internalServerError(err, h.requestContextLogger(c))
+	}
+
+	res := make([]*deletedMessageResponse, len(messages))
+	for i, m := range messages {
+		res[i] = &deletedMessageResponse{
+			messageResponse: formatMessage(m),
+			DeletedAt:       *m.DeletedAt,
+		}
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+// PostRestoreMessage POST /messages/deleted/:messageID/restore
+func (h *Handlers) PostRestoreMessage(c echo.Context) error {
+	messageID := getRequestParamAsUUID(c, paramMessageID)
+
+	if err := h.Repo.RestoreMessage(messageID); err != nil {
+		switch {
+		case err == repository.ErrNotFound, err == repository.ErrNilID:
+			return notFound()
+		case err == repository.ErrChannelArchived:
+			return forbidden("the channel is archived")
+		case repository.IsArgError(err):
+			return badRequest(err)
+		default:
+			return internalServerError(err, h.requestContextLogger(c))
+		}
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// PostMoveMessages POST /messages/:messageID/move
+func (h *Handlers) PostMoveMessages(c echo.Context) error {
+	userID := getRequestUserID(c)
+	m := getMessageFromContext(c)
+
+	var req struct {
+		ChannelID     uuid.UUID `json:"channelId"`
+		LastMessageID uuid.UUID `json:"lastMessageId"`
+	}
+	if err := bindAndValidate(c, &req); err != nil {
+		return badRequest(err)
+	}
+	if req.ChannelID == uuid.Nil {
+		return badRequest("channelId is required")
+	}
+	if req.LastMessageID == uuid.Nil {
+		req.LastMessageID = m.ID
+	}
+
+	ids, err := h.Repo.MoveMessages(m.ID, req.LastMessageID, req.ChannelID)
+	if err != nil {
+		switch {
+		case err == repository.ErrNotFound:
+			return badRequest("the last message is not found")
+		case err == repository.ErrChannelArchived:
+			return forbidden("the channel is archived")
+		case repository.IsArgError(err):
+			return badRequest(err)
+		default:
+			return internalServerError(err, h.requestContextLogger(c))
+		}
+	}
+
+	// 移動元チャンネルに移動のお知らせを投稿
+	path, err := h.Repo.GetChannelPath(req.ChannelID)
+	if err != nil {
+		return internalServerError(err, h.requestContextLogger(c))
+	}
+	link := &message.EmbeddedInfo{Raw: "#" + path, Type: "channel", ID: req.ChannelID.String()}
+	if _, err := h.Repo.CreateMessage(userID, m.ChannelID, fmt.Sprintf("%d件のメッセージを%sに移動しました", len(ids), link)); err != nil {
+		return internalServerError(err, h.requestContextLogger(c))
+	}
+
+	return c.JSON(http.StatusOK, ids)
+}
+
+// SearchMessages GET /messages/search
+func (h *Handlers) SearchMessages(c echo.Context) error {
+	userID := getRequestUserID(c)
+
+	var req struct {
+		Q      string `query:"q"`
+		Limit  int    `query:"limit"`
+		Offset int    `query:"offset"`
+	}
+	if err := bindAndValidate(c, &req); err != nil {
+		return badRequest(err)
+	}
+
+	q, err := h.parseMessageSearchQuery(userID, req.Q)
+	if err != nil {
+		if _, ok := err.(*echo.HTTPError); ok {
+			return err
+		}
+		return internalServerError(err, h.requestContextLogger(c))
+	}
+	q.Limit = req.Limit
+	q.Offset = req.Offset
+	if q.Limit > 100 || q.Limit <= 0 {
+		q.Limit = 100
+	}
+
+	messages, more, err := h.Repo.SearchMessages(q)
+	if err != nil {
+		return internalServerError(err, h.requestContextLogger(c))
+	}
+
+	setMoreHeader(c, more)
+	res := formatMessages(messages)
+	if err := h.resolveCitations(userID, res...); err != nil {
+		return internalServerError(err, h.requestContextLogger(c))
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+// parseMessageSearchQuery メッセージ検索クエリ文字列を解析します
+//
+// 空白区切りで以下の修飾子を解釈し、それ以外の語句は本文の検索語として扱います。
+//
+//	in:チャンネル(パスまたはID)
+//	from:ユーザー(名前またはID)
+//	to:ユーザー・グループ(名前またはID)
+//	since:日時 until:日時 (YYYY-MM-DDまたはRFC3339)
+//	has:file
+//
+// 不正なクエリの場合は400エラーを返します。
+func (h *Handlers) parseMessageSearchQuery(userID uuid.UUID, str string) (repository.MessageSearchQuery, error) {
+	q := repository.MessageSearchQuery{Searcher: userID}
+	var channelPaths map[string]uuid.UUID
+
+	for _, token := range strings.Fields(str) {
+		i := strings.Index(token, ":")
+		if i <= 0 || i == len(token)-1 {
+			q.Words = append(q.Words, token)
+			continue
+		}
+		key, value := strings.ToLower(token[:i]), token[i+1:]
+
+		switch key {
+		case "in":
+			value = strings.TrimPrefix(value, "#")
+			if id, err := uuid.FromString(value); err == nil {
+				q.Channels = append(q.Channels, id)
+				continue
+			}
+			if channelPaths == nil {
+				var err error
+				channelPaths, err = h.getAccessibleChannelPaths(userID)
+				if err != nil {
+					return q, err
+				}
+			}
+			id, ok := channelPaths[value]
+			if !ok {
+				return q, badRequest(fmt.Sprintf("unknown channel: %s", value))
+			}
+			q.Channels = append(q.Channels, id)
+
+		case "from":
+			value = strings.TrimPrefix(value, "@")
+			if id, err := uuid.FromString(value); err == nil {
+				q.Users = append(q.Users, id)
+				continue
+			}
+			user, err := h.Repo.GetUserByName(value)
+			if err != nil {
+				if err == repository.ErrNotFound {
+					return q, badRequest(fmt.Sprintf("unknown user: %s", value))
+				}
+				return q, err
+			}
+			q.Users = append(q.Users, user.ID)
+
+		case "to":
+			value = strings.TrimPrefix(value, "@")
+			if id, err := uuid.FromString(value); err == nil {
+				q.Mentions = append(q.Mentions, id)
+				continue
+			}
+			user, err := h.Repo.GetUserByName(value)
+			if err == nil {
+				q.Mentions = append(q.Mentions, user.ID)
+				continue
+			} else if err != repository.ErrNotFound {
+				return q, err
+			}
+			group, err := h.Repo.GetUserGroupByName(value)
+			if err != nil {
+				if err == repository.ErrNotFound {
+					return q, badRequest(fmt.Sprintf("unknown user or group: %s", value))
+				}
+				return q, err
+			}
+			q.Mentions = append(q.Mentions, group.ID)
+
+		case "since":
+			t, err := parseSearchDate(value, false)
+			if err != nil {
+				return q, badRequest(fmt.Sprintf("invalid since: %s", value))
+			}
+			q.Since = null.TimeFrom(t)
+
+		case "until":
+			t, err := parseSearchDate(value, true)
+			if err != nil {
+				return q, badRequest(fmt.Sprintf("invalid until: %s", value))
+			}
+			q.Until = null.TimeFrom(t)
+
+		case "has":
+			if strings.ToLower(value) != "file" {
+				return q, badRequest(fmt.Sprintf("invalid has: %s", value))
+			}
+			q.HasFile = true
+
+		default:
+			q.Words = append(q.Words, token)
+		}
+	}
+	return q, nil
+}
+
+// getAccessibleChannelPaths 指定したユーザーがアクセス可能なチャンネルのパスとIDのマップを取得します
+func (h *Handlers) getAccessibleChannelPaths(userID uuid.UUID) (map[string]uuid.UUID, error) {
+	channels, err := h.Repo.GetChannelsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	paths := make(map[string]uuid.UUID, len(channels))
+	for _, ch := range channels {
+		if ch.IsDMChannel() {
+			continue
+		}
+		path, err := h.Repo.GetChannelPath(ch.ID)
+		if err != nil {
+			return nil, err
+		}
+		paths[path] = ch.ID
+	}
+	return paths, nil
+}
+
+// parseSearchDate 検索クエリの日時を解析します。日付のみの場合、endOfDayがtrueならその日の終わりを返します
+func parseSearchDate(s string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", s, time.Local)
+	if err != nil {
+		return t, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return t, nil
+}
+
+// checkChannelArchived チャンネルがアーカイブされていないか確認します
+//
+// アーカイブされたチャンネルは読み取り専用のため、403エラーを返します。
+func checkChannelArchived(ch *model.Channel) error {
+	if ch.IsArchived {
+		return forbidden("the channel is archived")
+	}
+	return nil
+}
+
+// checkSlowMode スローモードのチャンネルでユーザーが投稿間隔を空けているか確認します
+//
+// 投稿可能な場合はnilを、できない場合は429エラーを返します。
+// 投稿日時は記録しないため、投稿に成功した後にrecordSlowModeを呼ぶ必要があります。
+func (h *Handlers) checkSlowMode(c echo.Context, ch *model.Channel, userID uuid.UUID) error {
+	interval := time.Duration(ch.SlowModeInterval) * time.Second
+	if wait := h.slowMode.Wait(slowModeKey(ch.ID, userID), interval); wait > 0 {
+		return tooManyRequests(c, wait)
+	}
+	return nil
+}
+
+// recordSlowMode スローモードのチャンネルでのユーザーの投稿日時を記録します
+//
+// 投稿に失敗した場合に次の投稿を待たせないよう、投稿に成功した後に呼び出します。
+func (h *Handlers) recordSlowMode(ch *model.Channel, userID uuid.UUID) {
+	h.slowMode.Record(slowModeKey(ch.ID, userID), time.Duration(ch.SlowModeInterval)*time.Second)
+}
+
+func slowModeKey(channelID, userID uuid.UUID) string {
+	return channelID.String() + "/" + userID.String()
+}
+
+// canonicalizeEmbeds リクエストユーザーが投稿するメッセージ本文の埋め込みを検証し、表示文字列を現在の名前に書き換えます
+//
+// 無効な埋め込みは、strictがtrueの場合は400エラーを返し、falseの場合は平文に置き換えます。
+func (h *Handlers) canonicalizeEmbeds(c echo.Context, text string, strict bool) (string, error) {
+	res, invalid, err := h.Repo.CanonicalizeMessageEmbeds(getRequestUserID(c), text)
+	if err != nil {
+		return "", internalServerError(err, h.requestContextLogger(c))
+	}
+	if strict && invalid != nil {
+		return "", badRequest(fmt.Sprintf("invalid %s embed: %s", invalid.Type, invalid.ID))
+	}
+	return res, nil
+}
+
 // DeleteUnread DELETE /users/me/unread/channels/:channelID
 func (h *Handlers) DeleteUnread(c echo.Context) error {
 	userID := getRequestUserID(c)
 	channelID := getRequestParamAsUUID(c, paramChannelID)
 
-	if err := h.Repo.DeleteUnreadsByChannelID(channelID, userID); err != nil {
+	if err := h.Repo.ReadChannel(userID, channelID); err != nil {
 		return internalServerError(err, h.requestContextLogger(c))
 	}
 
@@ -3,10 +3,14 @@ package router
 import (
 	"github.com/gofrs/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traPtitech/traQ/model"
 	"github.com/traPtitech/traQ/repository"
 	"github.com/traPtitech/traQ/sessions"
+	"github.com/traPtitech/traQ/utils"
 	"net/http"
 	"testing"
+	"time"
 )
 
 func TestHandlers_GetMessageByID(t *testing.T) {
@@ -70,6 +74,41 @@ func TestHandlers_GetMessageByID(t *testing.T) {
 		obj.Value("stampList").Array().Empty()
 	})
 
+	t.Run("Citations", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		text := `!{"raw":"q","type":"message","id":"` + message2.ID.String() + `"} !{"raw":"q","type":"message","id":"` + message.ID.String() + `"}`
+		quote, err := repo.CreateMessage(postmanID, channel.ID, text)
+		require.NoError(t, err)
+
+		arr := e.GET("/api/1.0/messages/{messageID}", quote.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object().
+			Value("citations").
+			Array()
+		arr.Length().Equal(1)
+		obj := arr.First().Object()
+		obj.Value("messageId").String().Equal(message.ID.String())
+		obj.Value("userId").String().Equal(testUser.ID.String())
+		obj.Value("channelId").String().Equal(channel.ID.String())
+		obj.Value("channelPath").String().Equal(channel.Name)
+		obj.Value("excerpt").String().Equal(message.Text)
+
+		e.GET("/api/1.0/messages/{messageID}", quote.ID.String()).
+			WithCookie(sessions.CookieName, generateSession(t, postmanID)).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object().
+			Value("citations").
+			Array().
+			Length().
+			Equal(2)
+	})
+
 	t.Run("Failure1", func(t *testing.T) {
 		t.Parallel()
 		e := makeExp(t, server)
@@ -124,6 +163,92 @@ func TestHandlers_PostMessage(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("SlowMode", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+
+		ch := mustMakeChannel(t, repo, random)
+		require.NoError(t, repo.UpdateChannelSlowModeInterval(ch.ID, 60))
+		mustMakeContentFilterRule(t, repo, testUser.ID, ch.ID, "rejected", model.ContentFilterActionReject)
+
+		// 投稿に失敗した場合は制限されない
+		e.POST("/api/1.0/channels/{channelID}/messages", ch.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]string{"text": "rejected message"}).
+			Expect().
+			Status(http.StatusBadRequest)
+		e.POST("/api/1.0/channels/{channelID}/messages", ch.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]string{"text": "test message"}).
+			Expect().
+			Status(http.StatusCreated)
+		res := e.POST("/api/1.0/channels/{channelID}/messages", ch.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]string{"text": "test message"}).
+			Expect()
+		res.Status(http.StatusTooManyRequests)
+		res.Header("Retry-After").Equal("60")
+
+		// 他のユーザーは制限されない
+		e.POST("/api/1.0/channels/{channelID}/messages", ch.ID.String()).
+			WithCookie(sessions.CookieName, generateSession(t, postmanID)).
+			WithJSON(map[string]string{"text": "test message"}).
+			Expect().
+			Status(http.StatusCreated)
+	})
+
+	t.Run("Embeds", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+
+		valid := `!{"raw":"@old","type":"user","id":"` + testUser.ID.String() + `"}`
+		invalid := `!{"raw":"@nobody","type":"user","id":"` + uuid.Must(uuid.NewV4()).String() + `"}`
+		text := valid + " " + invalid + " `" + invalid + "`"
+
+		e.POST("/api/1.0/channels/{channelID}/messages", channel.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]interface{}{"text": text}).
+			Expect().
+			Status(http.StatusCreated).
+			JSON().
+			Object().
+			Value("content").
+			String().
+			Equal(`!{"raw":"@` + testUser.Name + `","type":"user","id":"` + testUser.ID.String() + `"} @nobody ` + "`" + invalid + "`")
+
+		e.POST("/api/1.0/channels/{channelID}/messages", channel.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]interface{}{"text": text, "strictEmbeds": true}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("InaccessibleEmbeds", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+
+		// 投稿者がアクセスできないチャンネルの名前が表示文字列に書き込まれないこと
+		private := mustMakePrivateChannel(t, repo, random, []uuid.UUID{postmanID})
+		text := `!{"raw":"#guess","type":"channel","id":"` + private.ID.String() + `"}`
+
+		e.POST("/api/1.0/channels/{channelID}/messages", channel.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]interface{}{"text": text}).
+			Expect().
+			Status(http.StatusCreated).
+			JSON().
+			Object().
+			Value("content").
+			String().
+			Equal("#guess")
+
+		e.POST("/api/1.0/channels/{channelID}/messages", channel.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]interface{}{"text": text, "strictEmbeds": true}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
 	t.Run("Successful2", func(t *testing.T) {
 		t.Parallel()
 		e := makeExp(t, server)
@@ -180,8 +305,9 @@ func TestHandlers_GetMessagesByChannelID(t *testing.T) {
 	postmanID := mustMakeUser(t, repo, random).ID
 	privateID := mustMakePrivateChannel(t, repo, random, []uuid.UUID{postmanID}).ID
 
+	messages := make([]*model.Message, 5)
 	for i := 0; i < 5; i++ {
-		mustMakeMessage(t, repo, testUser.ID, channel.ID)
+		messages[i] = mustMakeMessage(t, repo, testUser.ID, channel.ID)
 	}
 
 	t.Run("NotLoggedIn", func(t *testing.T) {
@@ -220,6 +346,37 @@ func TestHandlers_GetMessagesByChannelID(t *testing.T) {
 			Equal(3)
 	})
 
+	t.Run("Successful3", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		res := e.GET("/api/1.0/channels/{channelID}/messages", channel.ID.String()).
+			WithQuery("limit", 1).
+			WithQuery("before", messages[2].ID.String()).
+			WithCookie(sessions.CookieName, session).
+			Expect()
+		res.Status(http.StatusOK)
+		res.Header(headerMore).Equal("true")
+		arr := res.JSON().Array()
+		arr.Length().Equal(1)
+		arr.First().Object().Value("messageId").String().Equal(messages[1].ID.String())
+	})
+
+	t.Run("Successful4", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		res := e.GET("/api/1.0/channels/{channelID}/messages", channel.ID.String()).
+			WithQuery("after", messages[2].ID.String()).
+			WithQuery("order", "asc").
+			WithCookie(sessions.CookieName, session).
+			Expect()
+		res.Status(http.StatusOK)
+		res.Header(headerMore).Equal("false")
+		arr := res.JSON().Array()
+		arr.Length().Equal(2)
+		arr.Element(0).Object().Value("messageId").String().Equal(messages[3].ID.String())
+		arr.Element(1).Object().Value("messageId").String().Equal(messages[4].ID.String())
+	})
+
 	t.Run("Failure1", func(t *testing.T) {
 		t.Parallel()
 		e := makeExp(t, server)
@@ -228,6 +385,234 @@ func TestHandlers_GetMessagesByChannelID(t *testing.T) {
 			Expect().
 			Status(http.StatusNotFound)
 	})
+
+	t.Run("Failure2", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.GET("/api/1.0/channels/{channelID}/messages", channel.ID.String()).
+			WithQuery("order", "random").
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("Failure3", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.GET("/api/1.0/channels/{channelID}/messages", channel.ID.String()).
+			WithQuery("before", uuid.Must(uuid.NewV4()).String()).
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("Failure4", func(t *testing.T) {
+		t.Parallel()
+		other := mustMakeMessage(t, repo, testUser.ID, mustMakeChannel(t, repo, random).ID)
+		e := makeExp(t, server)
+		e.GET("/api/1.0/channels/{channelID}/messages", channel.ID.String()).
+			WithQuery("after", other.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+}
+
+func TestHandlers_SearchMessages(t *testing.T) {
+	t.Parallel()
+	repo, server, _, require, session, _, testUser, _ := setupWithUsers(t, common2)
+
+	channel := mustMakeChannel(t, repo, random)
+	channel2 := mustMakeChannel(t, repo, random)
+	postman := mustMakeUser(t, repo, random)
+	privateID := mustMakePrivateChannel(t, repo, random, []uuid.UUID{postman.ID}).ID
+
+	m1, err := repo.CreateMessage(testUser.ID, channel.ID, "トラップの部室")
+	require.NoError(err)
+	m2, err := repo.CreateMessage(postman.ID, channel2.ID, "部室に行きます")
+	require.NoError(err)
+	m3, err := repo.CreateMessage(postman.ID, channel.ID, `!{"raw":"","type":"file","id":"`+uuid.Must(uuid.NewV4()).String()+`"}`)
+	require.NoError(err)
+	_, err = repo.CreateMessage(postman.ID, privateID, "部室の鍵")
+	require.NoError(err)
+
+	t.Run("NotLoggedIn", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.GET("/api/1.0/messages/search").
+			WithQuery("q", "部室").
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("Successful1", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		arr := e.GET("/api/1.0/messages/search").
+			WithQuery("q", "部室").
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Array()
+		arr.Length().Equal(2)
+		arr.Element(0).Object().Value("messageId").String().Equal(m2.ID.String())
+		arr.Element(1).Object().Value("messageId").String().Equal(m1.ID.String())
+	})
+
+	t.Run("Successful2", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		arr := e.GET("/api/1.0/messages/search").
+			WithQuery("q", "部室 in:#"+channel.Name).
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Array()
+		arr.Length().Equal(1)
+		arr.First().Object().Value("messageId").String().Equal(m1.ID.String())
+	})
+
+	t.Run("Successful3", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		arr := e.GET("/api/1.0/messages/search").
+			WithQuery("q", "from:@"+postman.Name+" has:file").
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Array()
+		arr.Length().Equal(1)
+		arr.First().Object().Value("messageId").String().Equal(m3.ID.String())
+	})
+
+	t.Run("Failure1", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.GET("/api/1.0/messages/search").
+			WithQuery("q", "in:#"+utils.RandAlphabetAndNumberString(20)).
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("Failure2", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.GET("/api/1.0/messages/search").
+			WithQuery("q", "since:yesterday").
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+}
+
+func TestHandlers_GetThreadMessages(t *testing.T) {
+	t.Parallel()
+	repo, server, _, require, session, _, testUser, _ := setupWithUsers(t, common2)
+
+	channel := mustMakeChannel(t, repo, random)
+	parent := mustMakeMessage(t, repo, testUser.ID, channel.ID)
+	replies := make([]*model.Message, 3)
+	for i := range replies {
+		m, err := repo.CreateThreadReply(testUser.ID, parent.ID, "reply")
+		require.NoError(err)
+		replies[i] = m
+	}
+
+	t.Run("NotLoggedIn", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.GET("/api/1.0/messages/{messageID}/thread", parent.ID.String()).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("Successful1", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		arr := e.GET("/api/1.0/messages/{messageID}/thread", parent.ID.String()).
+			WithQuery("order", "asc").
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Array()
+		arr.Length().Equal(3)
+		arr.First().Object().Value("messageId").String().Equal(replies[0].ID.String())
+		arr.First().Object().Value("parentMessageId").String().Equal(parent.ID.String())
+	})
+
+	t.Run("Successful2", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		obj := e.GET("/api/1.0/messages/{messageID}", parent.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object()
+		obj.Value("threadReplyCount").Number().Equal(3)
+		obj.Value("threadLatestReply").Object().Value("messageId").String().Equal(replies[2].ID.String())
+	})
+
+	t.Run("Successful3", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.GET("/api/1.0/channels/{channelID}/messages", channel.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Array().
+			Length().
+			Equal(1)
+	})
+}
+
+func TestHandlers_PostThreadMessage(t *testing.T) {
+	t.Parallel()
+	repo, server, _, require, session, _, testUser, _ := setupWithUsers(t, common2)
+
+	channel := mustMakeChannel(t, repo, random)
+	parent := mustMakeMessage(t, repo, testUser.ID, channel.ID)
+	reply, err := repo.CreateThreadReply(testUser.ID, parent.ID, "reply")
+	require.NoError(err)
+
+	t.Run("NotLoggedIn", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.POST("/api/1.0/messages/{messageID}/thread", parent.ID.String()).
+			WithJSON(map[string]string{"text": "reply"}).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("Successful1", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		obj := e.POST("/api/1.0/messages/{messageID}/thread", parent.ID.String()).
+			WithJSON(map[string]string{"text": "reply"}).
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusCreated).
+			JSON().
+			Object()
+		obj.Value("parentMessageId").String().Equal(parent.ID.String())
+		obj.Value("parentChannelId").String().Equal(channel.ID.String())
+	})
+
+	t.Run("Failure1", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.POST("/api/1.0/messages/{messageID}/thread", reply.ID.String()).
+			WithJSON(map[string]string{"text": "reply"}).
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
 }
 
 func TestHandlers_PutMessageByID(t *testing.T) {
@@ -284,6 +669,131 @@ func TestHandlers_PutMessageByID(t *testing.T) {
 	})
 }
 
+func TestHandlers_GetMessageHistory(t *testing.T) {
+	t.Parallel()
+	repo, server, _, require, session, _, testUser, _ := setupWithUsers(t, common2)
+
+	channel := mustMakeChannel(t, repo, random)
+	message := mustMakeMessage(t, repo, testUser.ID, channel.ID)
+	require.NoError(repo.UpdateMessage(message.ID, "popopo\nfoo"))
+	require.NoError(repo.UpdateMessage(message.ID, "bar\nfoo"))
+	postmanID := mustMakeUser(t, repo, random).ID
+	privateID := mustMakePrivateChannel(t, repo, random, []uuid.UUID{postmanID}).ID
+	message2 := mustMakeMessage(t, repo, postmanID, privateID)
+
+	t.Run("NotLoggedIn", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.GET("/api/1.0/messages/{messageID}/history", message.ID.String()).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("Successful1", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		arr := e.GET("/api/1.0/messages/{messageID}/history", message.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Array()
+		arr.Length().Equal(3)
+		arr.Element(0).Object().Value("content").String().Equal("popopo")
+		arr.Element(0).Object().Value("current").Boolean().False()
+		arr.Element(0).Object().NotContainsKey("diff")
+		arr.Element(1).Object().Value("content").String().Equal("popopo\nfoo")
+		arr.Element(2).Object().Value("content").String().Equal("bar\nfoo")
+		arr.Element(2).Object().Value("current").Boolean().True()
+	})
+
+	t.Run("Successful2", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		arr := e.GET("/api/1.0/messages/{messageID}/history", message.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			WithQuery("diff", true).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Array()
+		arr.Length().Equal(3)
+		arr.Element(1).Object().Value("diff").Equal([]map[string]string{
+			{"type": "delete", "line": "popopo"},
+			{"type": "insert", "line": "bar"},
+			{"type": "equal", "line": "foo"},
+		})
+		arr.Element(2).Object().NotContainsKey("diff")
+	})
+
+	t.Run("EditedFlag", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		obj := e.GET("/api/1.0/messages/{messageID}", message.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object()
+		obj.Value("edited").Boolean().True()
+		obj.Value("editCount").Number().Equal(2)
+	})
+
+	t.Run("Failure1", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.GET("/api/1.0/messages/{messageID}/history", message2.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusNotFound)
+	})
+}
+
+func TestHandlers_GetMessagePreviews(t *testing.T) {
+	t.Parallel()
+	repo, server, _, require, session, _, testUser, _ := setupWithUsers(t, common2)
+
+	channel := mustMakeChannel(t, repo, random)
+	message, err := repo.CreateMessage(testUser.ID, channel.ID, "https://example.com/a https://example.com/b https://example.com/c")
+	require.NoError(err)
+	require.NoError(repo.SaveLinkPreview(&model.LinkPreview{URL: "https://example.com/a", Type: "website", Title: "A", FetchedAt: time.Now()}))
+	require.NoError(repo.SaveLinkPreview(&model.LinkPreview{URL: "https://example.com/b", Failed: true, FetchedAt: time.Now()}))
+	postmanID := mustMakeUser(t, repo, random).ID
+	privateID := mustMakePrivateChannel(t, repo, random, []uuid.UUID{postmanID}).ID
+	message2 := mustMakeMessage(t, repo, postmanID, privateID)
+
+	t.Run("NotLoggedIn", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.GET("/api/1.0/messages/{messageID}/previews", message.ID.String()).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("Successful1", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		arr := e.GET("/api/1.0/messages/{messageID}/previews", message.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Array()
+		arr.Length().Equal(1)
+		arr.Element(0).Object().Value("url").String().Equal("https://example.com/a")
+		arr.Element(0).Object().Value("title").String().Equal("A")
+	})
+
+	t.Run("Failure1", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.GET("/api/1.0/messages/{messageID}/previews", message2.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusNotFound)
+	})
+}
+
 func TestHandlers_DeleteMessageByID(t *testing.T) {
 	t.Parallel()
 	repo, server, _, _, session, _, testUser, _ := setupWithUsers(t, common2)
@@ -402,6 +912,253 @@ func TestHandlers_PostMessageReport(t *testing.T) {
 	})
 }
 
+func TestHandlers_GetDeletedMessages(t *testing.T) {
+	t.Parallel()
+	repo, server, _, require, session, adminSession, testUser, adminUser := setupWithUsers(t, common2)
+
+	channel := mustMakeChannel(t, repo, random)
+	m1 := mustMakeMessage(t, repo, testUser.ID, channel.ID)
+	m2 := mustMakeMessage(t, repo, adminUser.ID, channel.ID)
+	mustMakeMessage(t, repo, testUser.ID, channel.ID)
+	require.NoError(repo.DeleteMessage(m1.ID))
+	require.NoError(repo.DeleteMessage(m2.ID))
+
+	t.Run("NotLoggedIn", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.GET("/api/1.0/messages/deleted").
+			WithQuery("channelId", channel.ID.String()).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.GET("/api/1.0/messages/deleted").
+			WithCookie(sessions.CookieName, session).
+			WithQuery("channelId", channel.ID.String()).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
+	t.Run("BadRequest", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.GET("/api/1.0/messages/deleted").
+			WithCookie(sessions.CookieName, adminSession).
+			Expect().
+			Status(http.StatusBadRequest)
+		e.GET("/api/1.0/messages/deleted").
+			WithCookie(sessions.CookieName, adminSession).
+			WithQuery("userId", "invalid").
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("Successful1", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		arr := e.GET("/api/1.0/messages/deleted").
+			WithCookie(sessions.CookieName, adminSession).
+			WithQuery("channelId", channel.ID.String()).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Array()
+
+		arr.Length().Equal(2)
+		arr.Element(0).Object().Value("messageId").String().Equal(m2.ID.String())
+		arr.Element(0).Object().ContainsKey("deletedAt")
+		arr.Element(1).Object().Value("messageId").String().Equal(m1.ID.String())
+	})
+
+	t.Run("Successful2", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		arr := e.GET("/api/1.0/messages/deleted").
+			WithCookie(sessions.CookieName, adminSession).
+			WithQuery("channelId", channel.ID.String()).
+			WithQuery("userId", testUser.ID.String()).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Array()
+
+		arr.Length().Equal(1)
+		arr.Element(0).Object().Value("messageId").String().Equal(m1.ID.String())
+	})
+}
+
+func TestHandlers_PostRestoreMessage(t *testing.T) {
+	t.Parallel()
+	repo, server, _, require, session, adminSession, testUser, _ := setupWithUsers(t, common2)
+
+	channel := mustMakeChannel(t, repo, random)
+
+	t.Run("Forbidden", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		m := mustMakeMessage(t, repo, testUser.ID, channel.ID)
+		require.NoError(repo.DeleteMessage(m.ID))
+		e.POST("/api/1.0/messages/deleted/{messageID}/restore", m.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.POST("/api/1.0/messages/deleted/{messageID}/restore", uuid.Must(uuid.NewV4()).String()).
+			WithCookie(sessions.CookieName, adminSession).
+			Expect().
+			Status(http.StatusNotFound)
+	})
+
+	t.Run("BadRequest", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		m := mustMakeMessage(t, repo, testUser.ID, channel.ID)
+		e.POST("/api/1.0/messages/deleted/{messageID}/restore", m.ID.String()).
+			WithCookie(sessions.CookieName, adminSession).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("Successful1", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		m := mustMakeMessage(t, repo, testUser.ID, channel.ID)
+		require.NoError(repo.DeleteMessage(m.ID))
+		e.POST("/api/1.0/messages/deleted/{messageID}/restore", m.ID.String()).
+			WithCookie(sessions.CookieName, adminSession).
+			Expect().
+			Status(http.StatusNoContent)
+
+		r, err := repo.GetMessageByID(m.ID)
+		require.NoError(err)
+		assert.Nil(t, r.DeletedAt)
+	})
+}
+
+func TestHandlers_PostEphemeralMessage(t *testing.T) {
+	t.Parallel()
+	repo, server, _, _, session, adminSession, testUser, _ := setupWithUsers(t, common2)
+
+	channel := mustMakeChannel(t, repo, random)
+	admin, err := repo.GetUserByName("traq")
+	require.NoError(t, err)
+	privateID := mustMakePrivateChannel(t, repo, random, []uuid.UUID{admin.ID}).ID
+
+	t.Run("NotLoggedIn", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.POST("/api/1.0/channels/{channelID}/ephemeral", channel.ID.String()).
+			WithJSON(map[string]interface{}{"userId": testUser.ID, "text": "test message"}).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.POST("/api/1.0/channels/{channelID}/ephemeral", channel.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]interface{}{"userId": testUser.ID, "text": "test message"}).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
+	t.Run("BadRequest", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.POST("/api/1.0/channels/{channelID}/ephemeral", channel.ID.String()).
+			WithCookie(sessions.CookieName, adminSession).
+			WithJSON(map[string]interface{}{"userId": testUser.ID, "text": ""}).
+			Expect().
+			Status(http.StatusBadRequest)
+		e.POST("/api/1.0/channels/{channelID}/ephemeral", channel.ID.String()).
+			WithCookie(sessions.CookieName, adminSession).
+			WithJSON(map[string]interface{}{"userId": uuid.Must(uuid.NewV4()), "text": "test message"}).
+			Expect().
+			Status(http.StatusBadRequest)
+		// 宛先ユーザーがアクセスできないチャンネル
+		e.POST("/api/1.0/channels/{channelID}/ephemeral", privateID.String()).
+			WithCookie(sessions.CookieName, adminSession).
+			WithJSON(map[string]interface{}{"userId": testUser.ID, "text": "test message"}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("ContentFilter", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		ch := mustMakeChannel(t, repo, random)
+		rule := mustMakeContentFilterRule(t, repo, admin.ID, ch.ID, "spam", model.ContentFilterActionReject)
+		mustMakeContentFilterRule(t, repo, admin.ID, ch.ID, "secret", model.ContentFilterActionMask)
+
+		e.POST("/api/1.0/channels/{channelID}/ephemeral", ch.ID.String()).
+			WithCookie(sessions.CookieName, adminSession).
+			WithJSON(map[string]interface{}{"userId": testUser.ID, "text": "spam message"}).
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().
+			Object().
+			Value("message").
+			String().
+			Contains(rule.ID.String())
+
+		e.POST("/api/1.0/channels/{channelID}/ephemeral", ch.ID.String()).
+			WithCookie(sessions.CookieName, adminSession).
+			WithJSON(map[string]interface{}{"userId": testUser.ID, "text": "secret message"}).
+			Expect().
+			Status(http.StatusCreated).
+			JSON().
+			Object().
+			Value("content").
+			String().
+			NotContains("secret")
+	})
+
+	t.Run("Successful1", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+
+		client := &sseClient{
+			userID:       testUser.ID,
+			connectionID: uuid.Must(uuid.NewV4()),
+			send:         make(chan *eventData, 1),
+		}
+		streamers[common2].connect <- client
+		defer func() { streamers[common2].disconnect <- client }()
+
+		obj := e.POST("/api/1.0/channels/{channelID}/ephemeral", channel.ID.String()).
+			WithCookie(sessions.CookieName, adminSession).
+			WithJSON(map[string]interface{}{"userId": testUser.ID, "text": "only you can see this"}).
+			Expect().
+			Status(http.StatusCreated).
+			JSON().
+			Object()
+		obj.Value("messageId").String().NotEmpty()
+		obj.Value("parentChannelId").String().Equal(channel.ID.String())
+		obj.Value("content").String().Equal("only you can see this")
+
+		select {
+		case ed := <-client.send:
+			assert.Equal(t, "EPHEMERAL_MESSAGE", ed.EventType)
+			assert.Equal(t, "only you can see this", ed.Payload["content"])
+		case <-time.After(time.Second):
+			assert.Fail(t, "ephemeral message was not delivered")
+		}
+
+		// 履歴には残らない
+		messages, _, err := repo.GetMessages(repository.MessagesQuery{Channel: channel.ID})
+		require.NoError(t, err)
+		assert.Empty(t, messages)
+	})
+}
+
 func TestHandlers_DeleteUnread(t *testing.T) {
 	t.Parallel()
 	repo, server, _, _, session, _, testUser, _ := setupWithUsers(t, common2)
@@ -425,5 +1182,105 @@ func TestHandlers_DeleteUnread(t *testing.T) {
 			WithCookie(sessions.CookieName, session).
 			Expect().
 			Status(http.StatusNoContent)
+
+		unread, err := repo.GetUserUnreadChannels(testUser.ID)
+		require.NoError(t, err)
+		assert.Empty(t, unread)
+	})
+}
+
+func TestHandlers_GetUnreadChannels(t *testing.T) {
+	t.Parallel()
+	repo, server, _, _, session, _, testUser, _ := setupWithUsers(t, common2)
+
+	channel := mustMakeChannel(t, repo, random)
+	message := mustMakeMessage(t, repo, testUser.ID, channel.ID)
+	mustMakeMessageUnread(t, repo, testUser.ID, message.ID)
+
+	t.Run("NotLoggedIn", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.GET("/api/1.0/users/me/unread/channels").
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("Successful1", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		obj := e.GET("/api/1.0/users/me/unread/channels").
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Array()
+
+		obj.Length().Equal(1)
+		obj.First().Object().Value("channelId").String().Equal(channel.ID.String())
+		obj.First().Object().Value("count").Number().Equal(1)
+		obj.First().Object().Value("noticeable").Boolean().False()
+	})
+}
+
+func TestHandlers_PostMoveMessages(t *testing.T) {
+	t.Parallel()
+	repo, server, _, require, session, adminSession, testUser, _ := setupWithUsers(t, common4)
+
+	t.Run("Forbidden", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		from := mustMakeChannel(t, repo, random)
+		to := mustMakeChannel(t, repo, random)
+		m := mustMakeMessage(t, repo, testUser.ID, from.ID)
+		e.POST("/api/1.0/messages/{messageID}/move", m.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]interface{}{"channelId": to.ID}).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
+	t.Run("BadRequest", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		from := mustMakeChannel(t, repo, random)
+		m := mustMakeMessage(t, repo, testUser.ID, from.ID)
+		e.POST("/api/1.0/messages/{messageID}/move", m.ID.String()).
+			WithCookie(sessions.CookieName, adminSession).
+			WithJSON(map[string]interface{}{}).
+			Expect().
+			Status(http.StatusBadRequest)
+		e.POST("/api/1.0/messages/{messageID}/move", m.ID.String()).
+			WithCookie(sessions.CookieName, adminSession).
+			WithJSON(map[string]interface{}{"channelId": from.ID}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("Successful1", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		from := mustMakeChannel(t, repo, random)
+		to := mustMakeChannel(t, repo, random)
+		m1 := mustMakeMessage(t, repo, testUser.ID, from.ID)
+		m2 := mustMakeMessage(t, repo, testUser.ID, from.ID)
+
+		e.POST("/api/1.0/messages/{messageID}/move", m1.ID.String()).
+			WithCookie(sessions.CookieName, adminSession).
+			WithJSON(map[string]interface{}{"channelId": to.ID, "lastMessageId": m2.ID}).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Array().
+			Length().
+			Equal(2)
+
+		for _, id := range []uuid.UUID{m1.ID, m2.ID} {
+			m, err := repo.GetMessageByID(id)
+			require.NoError(err)
+			assert.Equal(t, to.ID, m.ChannelID)
+		}
+		msgs, err := repo.GetMessagesByChannelID(from.ID, 0, 0)
+		require.NoError(err)
+		assert.Len(t, msgs, 1)
 	})
 }
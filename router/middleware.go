@@ -1,6 +1,9 @@
 package router
 
 import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/hex"
 	"fmt"
 	"github.com/gofrs/uuid"
 	"github.com/prometheus/client_golang/prometheus"
@@ -10,8 +13,11 @@ import (
 	"github.com/traPtitech/traQ/rbac/role"
 	"github.com/traPtitech/traQ/repository"
 	"github.com/traPtitech/traQ/sessions"
+	"github.com/traPtitech/traQ/utils"
+	"github.com/traPtitech/traQ/utils/ratelimit"
 	"go.uber.org/zap"
 	"golang.org/x/sync/singleflight"
+	"io/ioutil"
 	"net/http"
 	"strconv"
 	"strings"
@@ -403,6 +409,188 @@ func getClipFromContext(c echo.Context) *model.Clip {
 	return c.Get("paramClip").(*model.Clip)
 }
 
+// ValidateScheduledMessageID 'scheduledMessageID'パラメータの予約投稿メッセージを検証するミドルウェア
+func (h *Handlers) ValidateScheduledMessageID() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			userID := getRequestUserID(c)
+			id := getRequestParamAsUUID(c, paramScheduledMessageID)
+
+			m, err := h.Repo.GetScheduledMessage(id)
+			if err != nil {
+				switch err {
+				case repository.ErrNotFound:
+					return notFound()
+				default:
+					return internalServerError(err, h.requestContextLogger(c))
+				}
+			}
+
+			// 予約投稿メッセージがリクエストユーザーのものかを確認
+			if m.UserID != userID {
+				return notFound()
+			}
+
+			c.Set("paramScheduledMessage", m)
+			return next(c)
+		}
+	}
+}
+
+func getScheduledMessageFromContext(c echo.Context) *model.ScheduledMessage {
+	return c.Get("paramScheduledMessage").(*model.ScheduledMessage)
+}
+
+// ValidateChannelExportID 'exportID'パラメータのチャンネルエクスポートを検証するミドルウェア
+func (h *Handlers) ValidateChannelExportID() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			userID := getRequestUserID(c)
+			channelID := getRequestParamAsUUID(c, paramChannelID)
+			id := getRequestParamAsUUID(c, paramExportID)
+
+			e, err := h.Repo.GetChannelExport(id)
+			if err != nil {
+				switch err {
+				case repository.ErrNotFound:
+					return notFound()
+				default:
+					return internalServerError(err, h.requestContextLogger(c))
+				}
+			}
+
+			// エクスポートがリクエストユーザーによるこのチャンネルのものかを確認
+			if e.UserID != userID || e.ChannelID != channelID {
+				return notFound()
+			}
+
+			c.Set("paramChannelExport", e)
+			return next(c)
+		}
+	}
+}
+
+func getChannelExportFromContext(c echo.Context) *model.ChannelExport {
+	return c.Get("paramChannelExport").(*model.ChannelExport)
+}
+
+// ValidateReminderID 'reminderID'パラメータのリマインダーを検証するミドルウェア
+func (h *Handlers) ValidateReminderID() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			userID := getRequestUserID(c)
+			id := getRequestParamAsUUID(c, paramReminderID)
+
+			r, err := h.Repo.GetReminder(id)
+			if err != nil {
+				switch err {
+				case repository.ErrNotFound:
+					return notFound()
+				default:
+					return internalServerError(err, h.requestContextLogger(c))
+				}
+			}
+
+			// リマインダーがリクエストユーザーのものかを確認
+			if r.UserID != userID {
+				return notFound()
+			}
+
+			c.Set("paramReminder", r)
+			return next(c)
+		}
+	}
+}
+
+func getReminderFromContext(c echo.Context) *model.Reminder {
+	return c.Get("paramReminder").(*model.Reminder)
+}
+
+// ValidatePollID 'pollID'パラメータの投票を検証するミドルウェア
+func (h *Handlers) ValidatePollID() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			userID := getRequestUserID(c)
+			pollID := getRequestParamAsUUID(c, paramPollID)
+
+			p, err := h.Repo.GetPoll(pollID)
+			if err != nil {
+				switch err {
+				case repository.ErrNotFound:
+					return notFound()
+				default:
+					return internalServerError(err, h.requestContextLogger(c))
+				}
+			}
+			// メッセージの投稿が完了していない投票は存在しないものとして扱う
+			if p.MessageID == uuid.Nil {
+				return notFound()
+			}
+
+			if ok, err := h.Repo.IsChannelAccessibleToUser(userID, p.ChannelID); err != nil {
+				return internalServerError(err, h.requestContextLogger(c))
+			} else if !ok {
+				return notFound()
+			}
+
+			c.Set("paramPoll", p)
+			return next(c)
+		}
+	}
+}
+
+func getPollFromContext(c echo.Context) *model.Poll {
+	return c.Get("paramPoll").(*model.Poll)
+}
+
+// ValidateContentFilterRuleID 'ruleID'パラメータのコンテンツフィルタルールを検証するミドルウェア
+func (h *Handlers) ValidateContentFilterRuleID() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			r, err := h.Repo.GetContentFilterRule(getRequestParamAsUUID(c, paramRuleID))
+			if err != nil {
+				switch err {
+				case repository.ErrNotFound:
+					return notFound()
+				default:
+					return internalServerError(err, h.requestContextLogger(c))
+				}
+			}
+
+			c.Set("paramContentFilterRule", r)
+			return next(c)
+		}
+	}
+}
+
+func getContentFilterRuleFromContext(c echo.Context) *model.ContentFilterRule {
+	return c.Get("paramContentFilterRule").(*model.ContentFilterRule)
+}
+
+// ValidateModerationCaseID 'caseID'パラメータのモデレーションケースを検証するミドルウェア
+func (h *Handlers) ValidateModerationCaseID() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			mc, err := h.Repo.GetModerationCase(getRequestParamAsUUID(c, paramCaseID))
+			if err != nil {
+				switch err {
+				case repository.ErrNotFound:
+					return notFound()
+				default:
+					return internalServerError(err, h.requestContextLogger(c))
+				}
+			}
+
+			c.Set("paramModerationCase", mc)
+			return next(c)
+		}
+	}
+}
+
+func getModerationCaseFromContext(c echo.Context) *model.ModerationCase {
+	return c.Get("paramModerationCase").(*model.ModerationCase)
+}
+
 // ValidateClipFolderID 'folderID'パラメータのクリップフォルダを検証するミドルウェア
 func (h *Handlers) ValidateClipFolderID() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
@@ -535,6 +723,68 @@ func getWebhookFromContext(c echo.Context) model.Webhook {
 	return c.Get("paramWebhook").(model.Webhook)
 }
 
+// VerifyWebhookSignature Webhookのシークレットによる署名を検証するミドルウェア
+//
+// headerヘッダーの値からprefixを取り除いたものを、リクエストボディのHMAC-SHA1署名として検証します。
+// シークレットが設定されていないWebhookの場合は検証しません。
+// 読み込んだリクエストボディは、後続のハンドラーで再び読み込めるように戻します。
+func (h *Handlers) VerifyWebhookSignature(header, prefix string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			w := getWebhookFromContext(c)
+			if len(w.GetSecret()) == 0 {
+				return next(c)
+			}
+
+			sig, _ := hex.DecodeString(strings.TrimPrefix(c.Request().Header.Get(header), prefix))
+			if len(sig) == 0 {
+				return badRequest(fmt.Sprintf("missing %s header", header))
+			}
+
+			body, err := ioutil.ReadAll(c.Request().Body)
+			if err != nil {
+				return internalServerError(err, h.requestContextLogger(c))
+			}
+			if subtle.ConstantTimeCompare(utils.CalcHMACSHA1(body, w.GetSecret()), sig) != 1 {
+				return echo.NewHTTPError(http.StatusUnauthorized)
+			}
+			c.Request().Body = ioutil.NopCloser(bytes.NewReader(body))
+			return next(c)
+		}
+	}
+}
+
+// PostRateLimit メッセージ投稿のレートを制限するミドルウェア
+//
+// Webhookによる投稿はWebhook毎に、それ以外はリクエストユーザー毎に制限します。
+// 第三者が他人のWebhookの制限を使い切れないよう、Webhookの場合はVerifyWebhookSignatureの後に使用します。
+func (h *Handlers) PostRateLimit() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			var (
+				limiter *ratelimit.Limiter
+				key     string
+			)
+			if w, ok := c.Get("paramWebhook").(model.Webhook); ok {
+				limiter, key = h.webhookRateLimiter, w.GetID().String()
+			} else {
+				user := getRequestUser(c)
+				if user.Bot {
+					limiter = h.botRateLimiter
+				} else {
+					limiter = h.userRateLimiter
+				}
+				key = user.ID.String()
+			}
+
+			if ok, wait := limiter.Allow(key); !ok {
+				return tooManyRequests(c, wait)
+			}
+			return next(c)
+		}
+	}
+}
+
 // ValidateBotID 'botID'パラメータのBotを検証するミドルウェア
 func (h *Handlers) ValidateBotID(requestUserCheck bool) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
@@ -0,0 +1,134 @@
+package router
+
+import (
+	"encoding/hex"
+	"github.com/gofrs/uuid"
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/utils"
+	"github.com/traPtitech/traQ/utils/ratelimit"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandlers_PostRateLimit(t *testing.T) {
+	t.Parallel()
+
+	h := &Handlers{
+		userRateLimiter:    ratelimit.NewLimiter(1, 2),
+		webhookRateLimiter: ratelimit.NewLimiter(1, 1),
+	}
+	next := func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	}
+	do := func(set func(c echo.Context)) (http.Header, error) {
+		e := echo.New()
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodPost, "/", nil), rec)
+		set(c)
+		err := h.PostRateLimit()(next)(c)
+		return rec.Header(), err
+	}
+
+	t.Run("user", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		user := &model.User{ID: uuid.Must(uuid.NewV4())}
+		set := func(c echo.Context) { c.Set("user", user) }
+		for i := 0; i < 2; i++ {
+			_, err := do(set)
+			assert.NoError(err)
+		}
+		header, err := do(set)
+		if he, ok := err.(*echo.HTTPError); assert.True(ok) {
+			assert.Equal(http.StatusTooManyRequests, he.Code)
+			assert.Equal("60", header.Get("Retry-After"))
+		}
+
+		// 他のユーザーは制限されない
+		_, err = do(func(c echo.Context) { c.Set("user", &model.User{ID: uuid.Must(uuid.NewV4())}) })
+		assert.NoError(err)
+	})
+
+	t.Run("bot", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		// BOTの制限は設定されていない
+		bot := &model.User{ID: uuid.Must(uuid.NewV4()), Bot: true}
+		for i := 0; i < 5; i++ {
+			_, err := do(func(c echo.Context) { c.Set("user", bot) })
+			assert.NoError(err)
+		}
+	})
+
+	t.Run("webhook", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		w := &model.WebhookBot{ID: uuid.Must(uuid.NewV4())}
+		set := func(c echo.Context) { c.Set("paramWebhook", model.Webhook(w)) }
+		_, err := do(set)
+		assert.NoError(err)
+		_, err = do(set)
+		if he, ok := err.(*echo.HTTPError); assert.True(ok) {
+			assert.Equal(http.StatusTooManyRequests, he.Code)
+		}
+	})
+}
+
+func TestHandlers_VerifyWebhookSignature(t *testing.T) {
+	t.Parallel()
+
+	h := &Handlers{
+		webhookRateLimiter: ratelimit.NewLimiter(1, 1),
+	}
+	next := func(c echo.Context) error {
+		b, err := ioutil.ReadAll(c.Request().Body)
+		if err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, string(b))
+	}
+	w := &model.WebhookBot{ID: uuid.Must(uuid.NewV4()), Secret: "secret"}
+	do := func(body, sig string) (*httptest.ResponseRecorder, error) {
+		e := echo.New()
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		if len(sig) > 0 {
+			req.Header.Set(headerSignature, sig)
+		}
+		c := e.NewContext(req, rec)
+		c.Set("paramWebhook", model.Webhook(w))
+		err := h.VerifyWebhookSignature(headerSignature, "")(h.PostRateLimit()(next))(c)
+		return rec, err
+	}
+	assertCode := func(t *testing.T, code int, err error) {
+		t.Helper()
+		if he, ok := err.(*echo.HTTPError); assert.True(t, ok) {
+			assert.Equal(t, code, he.Code)
+		}
+	}
+
+	_, err := do("test", "")
+	assertCode(t, http.StatusBadRequest, err)
+
+	// 不正な署名のリクエストはレート制限を消費しない
+	for i := 0; i < 3; i++ {
+		_, err = do("test", "abcdef")
+		assertCode(t, http.StatusUnauthorized, err)
+	}
+
+	rec, err := do("test", hex.EncodeToString(utils.CalcHMACSHA1([]byte("test"), w.Secret)))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "test", rec.Body.String())
+	}
+
+	_, err = do("test", hex.EncodeToString(utils.CalcHMACSHA1([]byte("test"), w.Secret)))
+	assertCode(t, http.StatusTooManyRequests, err)
+}
@@ -0,0 +1,138 @@
+package router
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gofrs/uuid"
+	"github.com/labstack/echo"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"gopkg.in/guregu/null.v3"
+)
+
+// GetModerationCases GET /moderation/cases
+func (h *Handlers) GetModerationCases(c echo.Context) error {
+	p, _ := strconv.Atoi(c.QueryParam("p"))
+	state := model.ModerationCaseState(c.QueryParam("state"))
+	if len(state) > 0 && !state.Valid() {
+		return badRequest("invalid state")
+	}
+
+	cases, err := h.Repo.GetModerationCases(state, p*50, 50)
+	if err != nil {
+		return internalServerError(err, h.requestContextLogger(c))
+	}
+
+	res := make([]*moderationCaseResponse, len(cases))
+	for i, mc := range cases {
+		res[i], err = h.formatModerationCase(mc)
+		if err != nil {
+			return internalServerError(err, h.requestContextLogger(c))
+		}
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+// GetModerationCase GET /moderation/cases/:caseID
+func (h *Handlers) GetModerationCase(c echo.Context) error {
+	res, err := h.formatModerationCase(getModerationCaseFromContext(c))
+	if err != nil {
+		return internalServerError(err, h.requestContextLogger(c))
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+// PatchModerationCase PATCH /moderation/cases/:caseID
+func (h *Handlers) PatchModerationCase(c echo.Context) error {
+	userID := getRequestUserID(c)
+	mc := getModerationCaseFromContext(c)
+
+	var req struct {
+		State      null.String   `json:"state"`
+		AssigneeID uuid.NullUUID `json:"assigneeId"`
+	}
+	if err := bindAndValidate(c, &req); err != nil {
+		return badRequest(err)
+	}
+
+	if err := h.Repo.UpdateModerationCase(mc.ID, userID, repository.UpdateModerationCaseArgs{
+		State:      req.State,
+		AssigneeID: req.AssigneeID,
+	}); err != nil {
+		switch {
+		case repository.IsArgError(err):
+			return badRequest(err)
+		default:
+			return internalServerError(err, h.requestContextLogger(c))
+		}
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetModerationLogs GET /moderation/cases/:caseID/logs
+func (h *Handlers) GetModerationLogs(c echo.Context) error {
+	logs, err := h.Repo.GetModerationLogs(getModerationCaseFromContext(c).ID)
+	if err != nil {
+		return internalServerError(err, h.requestContextLogger(c))
+	}
+	return c.JSON(http.StatusOK, logs)
+}
+
+// PostModerationNote POST /moderation/cases/:caseID/notes
+func (h *Handlers) PostModerationNote(c echo.Context) error {
+	userID := getRequestUserID(c)
+	mc := getModerationCaseFromContext(c)
+
+	var req struct {
+		Content string `json:"content" validate:"required"`
+	}
+	if err := bindAndValidate(c, &req); err != nil {
+		return badRequest(err)
+	}
+
+	l, err := h.Repo.AddModerationNote(mc.ID, userID, req.Content)
+	if err != nil {
+		switch {
+		case repository.IsArgError(err):
+			return badRequest(err)
+		default:
+			return internalServerError(err, h.requestContextLogger(c))
+		}
+	}
+	return c.JSON(http.StatusCreated, l)
+}
+
+// PostModerationAction POST /moderation/cases/:caseID/actions
+func (h *Handlers) PostModerationAction(c echo.Context) error {
+	userID := getRequestUserID(c)
+	mc := getModerationCaseFromContext(c)
+
+	var req struct {
+		Type    string `json:"type"    validate:"required,oneof=delete_message suspend_user warn"`
+		Comment string `json:"comment"`
+	}
+	if err := bindAndValidate(c, &req); err != nil {
+		return badRequest(err)
+	}
+
+	if err := h.Repo.TakeModerationAction(mc.ID, userID, model.ModerationActionType(req.Type), req.Comment); err != nil {
+		switch {
+		case repository.IsArgError(err):
+			return badRequest(err)
+		case err == repository.ErrNotFound:
+			return notFound()
+		default:
+			return internalServerError(err, h.requestContextLogger(c))
+		}
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *Handlers) formatModerationCase(mc *model.ModerationCase) (*moderationCaseResponse, error) {
+	reports, err := h.Repo.GetMessageReportsByMessageID(mc.MessageID)
+	if err != nil {
+		return nil, err
+	}
+	return formatModerationCase(mc, reports), nil
+}
@@ -0,0 +1,348 @@
+package router
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/sessions"
+)
+
+func mustMakeModerationCase(t *testing.T, repo repository.Repository, reporterID, messageID uuid.UUID) *model.ModerationCase {
+	t.Helper()
+	require.NoError(t, repo.CreateMessageReport(messageID, reporterID, "reason"))
+	c, err := repo.GetModerationCaseByMessageID(messageID)
+	require.NoError(t, err)
+	return c
+}
+
+func TestHandlers_GetModerationCases(t *testing.T) {
+	t.Parallel()
+	repo, server, _, _, session, adminSession, testUser, _ := setupWithUsers(t, common5)
+
+	channel := mustMakeChannel(t, repo, random)
+	c := mustMakeModerationCase(t, repo, testUser.ID, mustMakeMessage(t, repo, testUser.ID, channel.ID).ID)
+
+	t.Run("NotLoggedIn", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.GET("/api/1.0/moderation/cases").
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.GET("/api/1.0/moderation/cases").
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
+	t.Run("BadRequest", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.GET("/api/1.0/moderation/cases").
+			WithCookie(sessions.CookieName, adminSession).
+			WithQuery("state", "closed").
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("Successful1", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		arr := e.GET("/api/1.0/moderation/cases").
+			WithCookie(sessions.CookieName, adminSession).
+			WithQuery("state", "open").
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Array()
+
+		found := false
+		for _, v := range arr.Iter() {
+			obj := v.Object()
+			obj.Value("state").String().Equal("open")
+			if obj.Value("caseId").String().Raw() == c.ID.String() {
+				found = true
+				obj.Value("reports").Array().Length().Equal(1)
+			}
+		}
+		require.True(t, found)
+	})
+}
+
+func TestHandlers_GetModerationCase(t *testing.T) {
+	t.Parallel()
+	repo, server, _, _, session, adminSession, testUser, adminUser := setupWithUsers(t, common5)
+
+	channel := mustMakeChannel(t, repo, random)
+	m := mustMakeMessage(t, repo, testUser.ID, channel.ID)
+	c := mustMakeModerationCase(t, repo, testUser.ID, m.ID)
+	require.NoError(t, repo.CreateMessageReport(m.ID, adminUser.ID, "reason"))
+
+	t.Run("Forbidden", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.GET("/api/1.0/moderation/cases/{caseID}", c.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.GET("/api/1.0/moderation/cases/{caseID}", uuid.Must(uuid.NewV4()).String()).
+			WithCookie(sessions.CookieName, adminSession).
+			Expect().
+			Status(http.StatusNotFound)
+	})
+
+	t.Run("Successful1", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		obj := e.GET("/api/1.0/moderation/cases/{caseID}", c.ID.String()).
+			WithCookie(sessions.CookieName, adminSession).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object()
+
+		obj.Value("caseId").String().Equal(c.ID.String())
+		obj.Value("messageId").String().Equal(m.ID.String())
+		obj.Value("state").String().Equal("open")
+		obj.Value("assigneeId").String().Equal(uuid.Nil.String())
+		obj.Value("reports").Array().Length().Equal(2)
+	})
+}
+
+func TestHandlers_PatchModerationCase(t *testing.T) {
+	t.Parallel()
+	repo, server, assert, require, session, adminSession, testUser, adminUser := setupWithUsers(t, common5)
+
+	channel := mustMakeChannel(t, repo, random)
+	c := mustMakeModerationCase(t, repo, testUser.ID, mustMakeMessage(t, repo, testUser.ID, channel.ID).ID)
+
+	t.Run("Forbidden", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.PATCH("/api/1.0/moderation/cases/{caseID}", c.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]interface{}{"state": "in_review"}).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
+	t.Run("BadRequest", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.PATCH("/api/1.0/moderation/cases/{caseID}", c.ID.String()).
+			WithCookie(sessions.CookieName, adminSession).
+			WithJSON(map[string]interface{}{"state": "closed"}).
+			Expect().
+			Status(http.StatusBadRequest)
+		e.PATCH("/api/1.0/moderation/cases/{caseID}", c.ID.String()).
+			WithCookie(sessions.CookieName, adminSession).
+			WithJSON(map[string]interface{}{"assigneeId": uuid.Must(uuid.NewV4())}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("Successful1", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		c := mustMakeModerationCase(t, repo, testUser.ID, mustMakeMessage(t, repo, testUser.ID, channel.ID).ID)
+		e.PATCH("/api/1.0/moderation/cases/{caseID}", c.ID.String()).
+			WithCookie(sessions.CookieName, adminSession).
+			WithJSON(map[string]interface{}{"state": "in_review", "assigneeId": adminUser.ID}).
+			Expect().
+			Status(http.StatusNoContent)
+
+		r, err := repo.GetModerationCase(c.ID)
+		require.NoError(err)
+		assert.Equal(model.ModerationCaseStateInReview, r.State)
+		assert.Equal(adminUser.ID, r.AssigneeID)
+
+		logs, err := repo.GetModerationLogs(c.ID)
+		require.NoError(err)
+		if assert.Len(logs, 3) {
+			assert.Equal(model.ModerationLogStateChanged, logs[1].Type)
+			assert.Equal(adminUser.ID, logs[1].ActorID)
+			assert.Equal(model.ModerationLogAssigned, logs[2].Type)
+		}
+	})
+}
+
+func TestHandlers_GetModerationLogs(t *testing.T) {
+	t.Parallel()
+	repo, server, _, _, session, adminSession, testUser, _ := setupWithUsers(t, common5)
+
+	channel := mustMakeChannel(t, repo, random)
+	c := mustMakeModerationCase(t, repo, testUser.ID, mustMakeMessage(t, repo, testUser.ID, channel.ID).ID)
+
+	t.Run("Forbidden", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.GET("/api/1.0/moderation/cases/{caseID}/logs", c.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
+	t.Run("Successful1", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		arr := e.GET("/api/1.0/moderation/cases/{caseID}/logs", c.ID.String()).
+			WithCookie(sessions.CookieName, adminSession).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Array()
+
+		arr.Length().Equal(1)
+		obj := arr.First().Object()
+		obj.Value("type").String().Equal("reported")
+		obj.Value("actorId").String().Equal(testUser.ID.String())
+		obj.Value("detail").String().Equal("reason")
+	})
+}
+
+func TestHandlers_PostModerationNote(t *testing.T) {
+	t.Parallel()
+	repo, server, _, _, session, adminSession, testUser, adminUser := setupWithUsers(t, common5)
+
+	channel := mustMakeChannel(t, repo, random)
+	c := mustMakeModerationCase(t, repo, testUser.ID, mustMakeMessage(t, repo, testUser.ID, channel.ID).ID)
+
+	t.Run("Forbidden", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.POST("/api/1.0/moderation/cases/{caseID}/notes", c.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]string{"content": "note"}).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
+	t.Run("BadRequest", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.POST("/api/1.0/moderation/cases/{caseID}/notes", c.ID.String()).
+			WithCookie(sessions.CookieName, adminSession).
+			WithJSON(map[string]string{"content": ""}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("Successful1", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		obj := e.POST("/api/1.0/moderation/cases/{caseID}/notes", c.ID.String()).
+			WithCookie(sessions.CookieName, adminSession).
+			WithJSON(map[string]string{"content": "looks fine"}).
+			Expect().
+			Status(http.StatusCreated).
+			JSON().
+			Object()
+
+		obj.Value("caseId").String().Equal(c.ID.String())
+		obj.Value("actorId").String().Equal(adminUser.ID.String())
+		obj.Value("type").String().Equal("note")
+		obj.Value("detail").String().Equal("looks fine")
+	})
+}
+
+func TestHandlers_PostModerationAction(t *testing.T) {
+	t.Parallel()
+	repo, server, assert, require, session, adminSession, testUser, _ := setupWithUsers(t, common5)
+
+	channel := mustMakeChannel(t, repo, random)
+
+	t.Run("Forbidden", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		c := mustMakeModerationCase(t, repo, testUser.ID, mustMakeMessage(t, repo, testUser.ID, channel.ID).ID)
+		e.POST("/api/1.0/moderation/cases/{caseID}/actions", c.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]string{"type": "warn"}).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
+	t.Run("BadRequest", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		c := mustMakeModerationCase(t, repo, testUser.ID, mustMakeMessage(t, repo, testUser.ID, channel.ID).ID)
+		e.POST("/api/1.0/moderation/cases/{caseID}/actions", c.ID.String()).
+			WithCookie(sessions.CookieName, adminSession).
+			WithJSON(map[string]string{"type": "ban"}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("DeleteMessage", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		m := mustMakeMessage(t, repo, testUser.ID, channel.ID)
+		c := mustMakeModerationCase(t, repo, testUser.ID, m.ID)
+		e.POST("/api/1.0/moderation/cases/{caseID}/actions", c.ID.String()).
+			WithCookie(sessions.CookieName, adminSession).
+			WithJSON(map[string]string{"type": "delete_message", "comment": "spam"}).
+			Expect().
+			Status(http.StatusNoContent)
+
+		_, err := repo.GetMessageByID(m.ID)
+		assert.Equal(repository.ErrNotFound, err)
+
+		logs, err := repo.GetModerationLogs(c.ID)
+		require.NoError(err)
+		if assert.Len(logs, 3) {
+			assert.Equal(model.ModerationLogAction, logs[1].Type)
+			assert.Equal("delete_message: spam", logs[1].Detail)
+			assert.Equal(model.ModerationLogStateChanged, logs[2].Type)
+			assert.Equal(string(model.ModerationCaseStateResolved), logs[2].Detail)
+		}
+		mc, err := repo.GetModerationCase(c.ID)
+		require.NoError(err)
+		assert.Equal(model.ModerationCaseStateResolved, mc.State)
+
+		e.POST("/api/1.0/moderation/cases/{caseID}/actions", c.ID.String()).
+			WithCookie(sessions.CookieName, adminSession).
+			WithJSON(map[string]string{"type": "delete_message"}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("SuspendUser", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		target := mustMakeUser(t, repo, random)
+		c := mustMakeModerationCase(t, repo, testUser.ID, mustMakeMessage(t, repo, target.ID, channel.ID).ID)
+		e.POST("/api/1.0/moderation/cases/{caseID}/actions", c.ID.String()).
+			WithCookie(sessions.CookieName, adminSession).
+			WithJSON(map[string]string{"type": "suspend_user"}).
+			Expect().
+			Status(http.StatusNoContent)
+
+		u, err := repo.GetUser(target.ID)
+		require.NoError(err)
+		assert.Equal(model.UserAccountStatusSuspended, u.Status)
+	})
+
+	t.Run("Warn", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		c := mustMakeModerationCase(t, repo, testUser.ID, mustMakeMessage(t, repo, testUser.ID, channel.ID).ID)
+		e.POST("/api/1.0/moderation/cases/{caseID}/actions", c.ID.String()).
+			WithCookie(sessions.CookieName, adminSession).
+			WithJSON(map[string]string{"type": "warn", "comment": "be nice"}).
+			Expect().
+			Status(http.StatusNoContent)
+	})
+}
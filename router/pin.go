@@ -49,7 +49,12 @@ func (h *Handlers) PostPin(c echo.Context) error {
 
 	pinID, err := h.Repo.CreatePin(m.ID, userID)
 	if err != nil {
-		return internalServerError(err, h.requestContextLogger(c))
+		switch err {
+		case repository.ErrChannelArchived:
+			return forbidden("the channel is archived")
+		default:
+			return internalServerError(err, h.requestContextLogger(c))
+		}
 	}
 
 	return c.JSON(http.StatusCreated, map[string]string{"id": pinID.String()})
@@ -66,7 +71,12 @@ func (h *Handlers) DeletePin(c echo.Context) error {
 	pinID := getRequestParamAsUUID(c, paramPinID)
 
 	if err := h.Repo.DeletePin(pinID); err != nil {
-		return internalServerError(err, h.requestContextLogger(c))
+		switch err {
+		case repository.ErrChannelArchived:
+			return forbidden("the channel is archived")
+		default:
+			return internalServerError(err, h.requestContextLogger(c))
+		}
 	}
 
 	return c.NoContent(http.StatusNoContent)
@@ -0,0 +1,161 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/gofrs/uuid"
+	"github.com/labstack/echo"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/utils/message"
+	"go.uber.org/zap"
+	"gopkg.in/guregu/null.v3"
+)
+
+// PostPoll POST /channels/:channelID/polls
+func (h *Handlers) PostPoll(c echo.Context) error {
+	userID := getRequestUserID(c)
+	ch := getChannelFromContext(c)
+
+	var req struct {
+		Question       string    `json:"question"       validate:"required,max=1000"`
+		Options        []string  `json:"options"        validate:"min=2,max=20"`
+		MultipleChoice bool      `json:"multipleChoice"`
+		Anonymous      bool      `json:"anonymous"`
+		Deadline       null.Time `json:"deadline"`
+		Text           string    `json:"text"`
+	}
+	if err := bindAndValidate(c, &req); err != nil {
+		return badRequest(err)
+	}
+
+	if err := h.checkSlowMode(c, ch, userID); err != nil {
+		return err
+	}
+
+	p, err := h.Repo.CreatePoll(repository.CreatePollArgs{
+		ChannelID:      ch.ID,
+		CreatorID:      userID,
+		Question:       req.Question,
+		Options:        req.Options,
+		MultipleChoice: req.MultipleChoice,
+		Anonymous:      req.Anonymous,
+		Deadline:       req.Deadline,
+	})
+	if err != nil {
+		switch {
+		case repository.IsArgError(err), repository.IsContentFilterError(err):
+			return badRequest(err)
+		case err == repository.ErrChannelArchived:
+			return forbidden("the channel is archived")
+		default:
+			return internalServerError(err, h.requestContextLogger(c))
+		}
+	}
+
+	// 投票を埋め込んだメッセージを投稿 投稿できなかった場合は投票を削除する
+	text := (&message.EmbeddedInfo{Raw: p.Question, Type: "poll", ID: p.ID.String()}).String()
+	if len(req.Text) > 0 {
+		text = req.Text + "\n" + text
+	}
+	m, err := h.Repo.CreateMessage(userID, ch.ID, text)
+	if err != nil {
+		h.deletePoll(c, p.ID)
+		switch {
+		case repository.IsArgError(err), repository.IsContentFilterError(err):
+			return badRequest(err)
+		default:
+			return internalServerError(err, h.requestContextLogger(c))
+		}
+	}
+	if err := h.Repo.AttachPollToMessage(p.ID, m.ID); err != nil {
+		if err := h.Repo.DeleteMessage(m.ID); err != nil {
+			h.requestContextLogger(c).Error("failed to DeleteMessage", zap.Error(err), zap.Stringer("messageId", m.ID))
+		}
+		h.deletePoll(c, p.ID)
+		return internalServerError(err, h.requestContextLogger(c))
+	}
+	p.MessageID = m.ID
+	h.recordSlowMode(ch, userID)
+
+	res, err := h.formatPoll(p, userID)
+	if err != nil {
+		return internalServerError(err, h.requestContextLogger(c))
+	}
+	return c.JSON(http.StatusCreated, res)
+}
+
+// deletePoll 投稿に失敗した投票を削除します
+func (h *Handlers) deletePoll(c echo.Context, pollID uuid.UUID) {
+	if err := h.Repo.DeletePoll(pollID); err != nil {
+		h.requestContextLogger(c).Error("failed to DeletePoll", zap.Error(err), zap.Stringer("pollId", pollID))
+	}
+}
+
+// GetPoll GET /polls/:pollID
+func (h *Handlers) GetPoll(c echo.Context) error {
+	res, err := h.formatPoll(getPollFromContext(c), getRequestUserID(c))
+	if err != nil {
+		return internalServerError(err, h.requestContextLogger(c))
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+// PutPollVotes PUT /polls/:pollID/votes
+func (h *Handlers) PutPollVotes(c echo.Context) error {
+	userID := getRequestUserID(c)
+	p := getPollFromContext(c)
+
+	var req struct {
+		OptionIDs []uuid.UUID `json:"optionIds" validate:"required"`
+	}
+	if err := bindAndValidate(c, &req); err != nil {
+		return badRequest(err)
+	}
+
+	if err := h.Repo.VotePoll(p.ID, userID, req.OptionIDs); err != nil {
+		switch {
+		case err == repository.ErrNotFound:
+			return notFound()
+		case err == repository.ErrForbidden:
+			return forbidden("the poll is closed")
+		case err == repository.ErrChannelArchived:
+			return forbidden("the channel is archived")
+		case repository.IsArgError(err):
+			return badRequest(err)
+		default:
+			return internalServerError(err, h.requestContextLogger(c))
+		}
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// DeletePollVotes DELETE /polls/:pollID/votes
+func (h *Handlers) DeletePollVotes(c echo.Context) error {
+	userID := getRequestUserID(c)
+	p := getPollFromContext(c)
+
+	if err := h.Repo.RetractPollVote(p.ID, userID); err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return notFound()
+		case repository.ErrForbidden:
+			return forbidden("the poll is closed")
+		case repository.ErrChannelArchived:
+			return forbidden("the channel is archived")
+		default:
+			return internalServerError(err, h.requestContextLogger(c))
+		}
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *Handlers) formatPoll(p *model.Poll, userID uuid.UUID) (*pollResponse, error) {
+	votes, err := h.Repo.GetPollVotes(p.ID)
+	if err != nil {
+		return nil, err
+	}
+	return formatPoll(p, votes, userID), nil
+}
@@ -0,0 +1,360 @@
+package router
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/sessions"
+	"github.com/traPtitech/traQ/utils/message"
+	"gopkg.in/guregu/null.v3"
+)
+
+func mustMakePoll(t *testing.T, repo repository.Repository, userID, channelID uuid.UUID, multipleChoice, anonymous bool) *model.Poll {
+	t.Helper()
+	p, err := repo.CreatePoll(repository.CreatePollArgs{
+		ChannelID:      channelID,
+		CreatorID:      userID,
+		Question:       "question",
+		Options:        []string{"a", "b", "c"},
+		MultipleChoice: multipleChoice,
+		Anonymous:      anonymous,
+	})
+	require.NoError(t, err)
+	m := mustMakeMessage(t, repo, userID, channelID)
+	require.NoError(t, repo.AttachPollToMessage(p.ID, m.ID))
+	p.MessageID = m.ID
+	return p
+}
+
+func TestHandlers_PostPoll(t *testing.T) {
+	t.Parallel()
+	repo, server, _, _, session, _, testUser, _ := setupWithUsers(t, common3)
+
+	channel := mustMakeChannel(t, repo, random)
+
+	t.Run("NotLoggedIn", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.POST("/api/1.0/channels/{channelID}/polls", channel.ID.String()).
+			WithJSON(map[string]interface{}{"question": "q", "options": []string{"a", "b"}}).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("BadRequest", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.POST("/api/1.0/channels/{channelID}/polls", channel.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]interface{}{"question": "", "options": []string{"a", "b"}}).
+			Expect().
+			Status(http.StatusBadRequest)
+		e.POST("/api/1.0/channels/{channelID}/polls", channel.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]interface{}{"question": "q", "options": []string{"a"}}).
+			Expect().
+			Status(http.StatusBadRequest)
+		e.POST("/api/1.0/channels/{channelID}/polls", channel.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]interface{}{"question": "q", "options": []string{"a", "b"}, "deadline": time.Now().Add(-time.Hour)}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("ContentFilter", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		ch := mustMakeChannel(t, repo, random)
+		mustMakeContentFilterRule(t, repo, testUser.ID, ch.ID, "spam", model.ContentFilterActionReject)
+		mustMakeContentFilterRule(t, repo, testUser.ID, ch.ID, "secret", model.ContentFilterActionMask)
+
+		// メッセージが拒否された場合は投票も残らない
+		e.POST("/api/1.0/channels/{channelID}/polls", ch.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]interface{}{"question": "q", "options": []string{"a", "b"}, "text": "spam"}).
+			Expect().
+			Status(http.StatusBadRequest)
+		r := repo.(*TestRepository)
+		r.PollsLock.RLock()
+		for _, p := range r.Polls {
+			assert.NotEqual(t, ch.ID, p.ChannelID)
+		}
+		r.PollsLock.RUnlock()
+
+		e.POST("/api/1.0/channels/{channelID}/polls", ch.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]interface{}{"question": "spam?", "options": []string{"a", "b"}}).
+			Expect().
+			Status(http.StatusBadRequest)
+
+		obj := e.POST("/api/1.0/channels/{channelID}/polls", ch.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]interface{}{"question": "secret?", "options": []string{"secret", "b"}}).
+			Expect().
+			Status(http.StatusCreated).
+			JSON().
+			Object()
+		obj.Value("question").String().NotContains("secret")
+		obj.Value("options").Array().Element(0).Object().Value("label").String().NotContains("secret")
+	})
+
+	t.Run("Successful1", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		obj := e.POST("/api/1.0/channels/{channelID}/polls", channel.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]interface{}{"question": "q", "options": []string{"a", "b"}, "anonymous": true, "text": "vote please"}).
+			Expect().
+			Status(http.StatusCreated).
+			JSON().
+			Object()
+
+		obj.Value("channelId").String().Equal(channel.ID.String())
+		obj.Value("creatorId").String().Equal(testUser.ID.String())
+		obj.Value("question").String().Equal("q")
+		obj.Value("multipleChoice").Boolean().False()
+		obj.Value("anonymous").Boolean().True()
+		obj.Value("closed").Boolean().False()
+		obj.Value("options").Array().Length().Equal(2)
+		obj.Value("voterCount").Number().Equal(0)
+
+		pollID := uuid.FromStringOrNil(obj.Value("pollId").String().Raw())
+		messageID := uuid.FromStringOrNil(obj.Value("messageId").String().Raw())
+		m, err := repo.GetMessageByID(messageID)
+		require.NoError(t, err)
+		if embedded := message.ParseAST(m.Text).Embedded(); assert.Len(t, embedded, 1) {
+			assert.Equal(t, "poll", embedded[0].Type)
+			assert.Equal(t, pollID.String(), embedded[0].ID)
+		}
+		p, err := repo.GetPoll(pollID)
+		require.NoError(t, err)
+		assert.Equal(t, messageID, p.MessageID)
+	})
+}
+
+func TestHandlers_GetPoll(t *testing.T) {
+	t.Parallel()
+	repo, server, _, _, session, adminSession, testUser, adminUser := setupWithUsers(t, common3)
+
+	channel := mustMakeChannel(t, repo, random)
+	named := mustMakePoll(t, repo, testUser.ID, channel.ID, false, false)
+	anonymous := mustMakePoll(t, repo, testUser.ID, channel.ID, true, true)
+	require.NoError(t, repo.VotePoll(named.ID, testUser.ID, []uuid.UUID{named.Options[0].ID}))
+	require.NoError(t, repo.VotePoll(named.ID, adminUser.ID, []uuid.UUID{named.Options[0].ID}))
+	require.NoError(t, repo.VotePoll(anonymous.ID, adminUser.ID, []uuid.UUID{anonymous.Options[0].ID, anonymous.Options[1].ID}))
+
+	private := mustMakePrivateChannel(t, repo, random, []uuid.UUID{adminUser.ID})
+	privatePoll := mustMakePoll(t, repo, adminUser.ID, private.ID, false, false)
+
+	t.Run("NotLoggedIn", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.GET("/api/1.0/polls/{pollID}", named.ID.String()).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.GET("/api/1.0/polls/{pollID}", uuid.Must(uuid.NewV4()).String()).
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusNotFound)
+		e.GET("/api/1.0/polls/{pollID}", privatePoll.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusNotFound)
+	})
+
+	t.Run("Successful1", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		obj := e.GET("/api/1.0/polls/{pollID}", named.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object()
+
+		obj.Value("pollId").String().Equal(named.ID.String())
+		obj.Value("voterCount").Number().Equal(2)
+		obj.Value("myVotes").Array().Elements(named.Options[0].ID.String())
+		options := obj.Value("options").Array()
+		options.Length().Equal(3)
+		options.Element(0).Object().Value("count").Number().Equal(2)
+		options.Element(0).Object().Value("voters").Array().Length().Equal(2)
+		options.Element(1).Object().Value("count").Number().Equal(0)
+		options.Element(1).Object().Value("voters").Array().Empty()
+	})
+
+	t.Run("Successful2", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		obj := e.GET("/api/1.0/polls/{pollID}", anonymous.ID.String()).
+			WithCookie(sessions.CookieName, adminSession).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object()
+
+		// 匿名投票では投票者は公開されない
+		obj.Value("voterCount").Number().Equal(1)
+		obj.Value("myVotes").Array().Length().Equal(2)
+		options := obj.Value("options").Array()
+		options.Element(0).Object().Value("count").Number().Equal(1)
+		options.Element(0).Object().Value("voters").Null()
+	})
+}
+
+func TestHandlers_PutPollVotes(t *testing.T) {
+	t.Parallel()
+	repo, server, _, _, session, _, testUser, _ := setupWithUsers(t, common3)
+
+	channel := mustMakeChannel(t, repo, random)
+
+	t.Run("NotLoggedIn", func(t *testing.T) {
+		t.Parallel()
+		p := mustMakePoll(t, repo, testUser.ID, channel.ID, false, false)
+		e := makeExp(t, server)
+		e.PUT("/api/1.0/polls/{pollID}/votes", p.ID.String()).
+			WithJSON(map[string]interface{}{"optionIds": []uuid.UUID{p.Options[0].ID}}).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("BadRequest", func(t *testing.T) {
+		t.Parallel()
+		p := mustMakePoll(t, repo, testUser.ID, channel.ID, false, false)
+		e := makeExp(t, server)
+		e.PUT("/api/1.0/polls/{pollID}/votes", p.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]interface{}{"optionIds": []uuid.UUID{}}).
+			Expect().
+			Status(http.StatusBadRequest)
+		e.PUT("/api/1.0/polls/{pollID}/votes", p.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]interface{}{"optionIds": []uuid.UUID{uuid.Must(uuid.NewV4())}}).
+			Expect().
+			Status(http.StatusBadRequest)
+		// 単一選択の投票に複数の選択肢
+		e.PUT("/api/1.0/polls/{pollID}/votes", p.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]interface{}{"optionIds": []uuid.UUID{p.Options[0].ID, p.Options[1].ID}}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("Closed", func(t *testing.T) {
+		t.Parallel()
+		p, err := repo.CreatePoll(repository.CreatePollArgs{
+			ChannelID: channel.ID,
+			CreatorID: testUser.ID,
+			Question:  "q",
+			Options:   []string{"a", "b"},
+			Deadline:  null.TimeFrom(time.Now().Add(time.Millisecond)),
+		})
+		require.NoError(t, err)
+		require.NoError(t, repo.AttachPollToMessage(p.ID, mustMakeMessage(t, repo, testUser.ID, channel.ID).ID))
+		time.Sleep(10 * time.Millisecond)
+
+		e := makeExp(t, server)
+		e.PUT("/api/1.0/polls/{pollID}/votes", p.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]interface{}{"optionIds": []uuid.UUID{p.Options[0].ID}}).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
+	t.Run("Archived", func(t *testing.T) {
+		t.Parallel()
+		ch := mustMakeChannel(t, repo, random)
+		p := mustMakePoll(t, repo, testUser.ID, ch.ID, false, false)
+		require.NoError(t, repo.UpdateChannelArchived(ch.ID, true, testUser.ID))
+
+		e := makeExp(t, server)
+		e.PUT("/api/1.0/polls/{pollID}/votes", p.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]interface{}{"optionIds": []uuid.UUID{p.Options[0].ID}}).
+			Expect().
+			Status(http.StatusForbidden)
+		e.DELETE("/api/1.0/polls/{pollID}/votes", p.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
+	t.Run("Successful1", func(t *testing.T) {
+		t.Parallel()
+		p := mustMakePoll(t, repo, testUser.ID, channel.ID, false, false)
+		e := makeExp(t, server)
+		e.PUT("/api/1.0/polls/{pollID}/votes", p.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]interface{}{"optionIds": []uuid.UUID{p.Options[0].ID}}).
+			Expect().
+			Status(http.StatusNoContent)
+		// 投票し直すと置き換えられる
+		e.PUT("/api/1.0/polls/{pollID}/votes", p.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]interface{}{"optionIds": []uuid.UUID{p.Options[1].ID}}).
+			Expect().
+			Status(http.StatusNoContent)
+
+		votes, err := repo.GetPollVotes(p.ID)
+		require.NoError(t, err)
+		if assert.Len(t, votes, 1) {
+			assert.Equal(t, p.Options[1].ID, votes[0].OptionID)
+		}
+	})
+
+	t.Run("Successful2", func(t *testing.T) {
+		t.Parallel()
+		p := mustMakePoll(t, repo, testUser.ID, channel.ID, true, false)
+		e := makeExp(t, server)
+		e.PUT("/api/1.0/polls/{pollID}/votes", p.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]interface{}{"optionIds": []uuid.UUID{p.Options[0].ID, p.Options[2].ID}}).
+			Expect().
+			Status(http.StatusNoContent)
+
+		votes, err := repo.GetPollVotes(p.ID)
+		require.NoError(t, err)
+		assert.Len(t, votes, 2)
+	})
+}
+
+func TestHandlers_DeletePollVotes(t *testing.T) {
+	t.Parallel()
+	repo, server, _, _, session, _, testUser, _ := setupWithUsers(t, common3)
+
+	channel := mustMakeChannel(t, repo, random)
+	p := mustMakePoll(t, repo, testUser.ID, channel.ID, false, false)
+	require.NoError(t, repo.VotePoll(p.ID, testUser.ID, []uuid.UUID{p.Options[0].ID}))
+
+	t.Run("NotLoggedIn", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.DELETE("/api/1.0/polls/{pollID}/votes", p.ID.String()).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("Successful1", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.DELETE("/api/1.0/polls/{pollID}/votes", p.ID.String()).
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusNoContent)
+
+		votes, err := repo.GetPollVotes(p.ID)
+		require.NoError(t, err)
+		assert.Len(t, votes, 0)
+	})
+}
@@ -0,0 +1,116 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/traPtitech/traQ/repository"
+	"gopkg.in/guregu/null.v3"
+)
+
+// reminderTimeRequest リマインダーの通知日時の指定
+//
+// 絶対日時(remindAt)か現在からの秒数(duration)のどちらか一方を指定します。
+type reminderTimeRequest struct {
+	RemindAt null.Time `json:"remindAt"`
+	Duration null.Int  `json:"duration"`
+}
+
+// remindAt 通知日時を計算します
+func (r *reminderTimeRequest) remindAt() (time.Time, error) {
+	switch {
+	case r.RemindAt.Valid && r.Duration.Valid:
+		return time.Time{}, errors.New("only one of remindAt and duration can be specified")
+	case r.RemindAt.Valid:
+		if !r.RemindAt.Time.After(time.Now()) {
+			return time.Time{}, errors.New("remindAt must be in the future")
+		}
+		return r.RemindAt.Time, nil
+	case r.Duration.Valid:
+		if r.Duration.Int64 <= 0 {
+			return time.Time{}, errors.New("duration must be positive")
+		}
+		return time.Now().Add(time.Duration(r.Duration.Int64) * time.Second), nil
+	default:
+		return time.Time{}, errors.New("remindAt or duration is required")
+	}
+}
+
+// PostMessageReminder POST /messages/:messageID/reminders
+func (h *Handlers) PostMessageReminder(c echo.Context) error {
+	userID := getRequestUserID(c)
+	m := getMessageFromContext(c)
+
+	var req reminderTimeRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return badRequest(err)
+	}
+	at, err := req.remindAt()
+	if err != nil {
+		return badRequest(err)
+	}
+
+	r, err := h.Repo.CreateReminder(userID, m.ID, at)
+	if err != nil {
+		return internalServerError(err, h.requestContextLogger(c))
+	}
+	return c.JSON(http.StatusCreated, r)
+}
+
+// GetMyReminders GET /users/me/reminders
+func (h *Handlers) GetMyReminders(c echo.Context) error {
+	userID := getRequestUserID(c)
+
+	reminders, err := h.Repo.GetRemindersByUserID(userID)
+	if err != nil {
+		return internalServerError(err, h.requestContextLogger(c))
+	}
+	return c.JSON(http.StatusOK, reminders)
+}
+
+// PostSnoozeReminder POST /users/me/reminders/:reminderID/snooze
+func (h *Handlers) PostSnoozeReminder(c echo.Context) error {
+	r := getReminderFromContext(c)
+
+	var req reminderTimeRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return badRequest(err)
+	}
+	at, err := req.remindAt()
+	if err != nil {
+		return badRequest(err)
+	}
+
+	if err := h.Repo.SnoozeReminder(r.ID, at); err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return notFound()
+		case repository.ErrForbidden:
+			return conflict("the reminder is being sent")
+		default:
+			return internalServerError(err, h.requestContextLogger(c))
+		}
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// DeleteReminder DELETE /users/me/reminders/:reminderID
+func (h *Handlers) DeleteReminder(c echo.Context) error {
+	r := getReminderFromContext(c)
+
+	if err := h.Repo.DeleteReminder(r.ID); err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return notFound()
+		case repository.ErrForbidden:
+			return conflict("the reminder is being sent")
+		default:
+			return internalServerError(err, h.requestContextLogger(c))
+		}
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,204 @@
+package router
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/sessions"
+)
+
+func mustMakeReminder(t *testing.T, repo repository.Repository, userID, messageID uuid.UUID, remindAt time.Time) *model.Reminder {
+	t.Helper()
+	r, err := repo.CreateReminder(userID, messageID, remindAt)
+	require.NoError(t, err)
+	return r
+}
+
+func TestHandlers_PostMessageReminder(t *testing.T) {
+	t.Parallel()
+	repo, server, _, _, session, _, testUser, _ := setupWithUsers(t, common7)
+
+	channel := mustMakeChannel(t, repo, random)
+	message := mustMakeMessage(t, repo, testUser.ID, channel.ID)
+
+	t.Run("NotLoggedIn", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.POST("/api/1.0/messages/{messageID}/reminders", message.ID).
+			WithJSON(map[string]interface{}{"duration": 60}).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("BadRequest", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		for _, body := range []map[string]interface{}{
+			{},
+			{"remindAt": time.Now().Add(-time.Hour)},
+			{"duration": 0},
+			{"remindAt": time.Now().Add(time.Hour), "duration": 60},
+		} {
+			e.POST("/api/1.0/messages/{messageID}/reminders", message.ID).
+				WithCookie(sessions.CookieName, session).
+				WithJSON(body).
+				Expect().
+				Status(http.StatusBadRequest)
+		}
+	})
+
+	t.Run("Successful1", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		at := time.Now().Add(time.Hour)
+		obj := e.POST("/api/1.0/messages/{messageID}/reminders", message.ID).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]interface{}{"remindAt": at}).
+			Expect().
+			Status(http.StatusCreated).
+			JSON().
+			Object()
+		obj.Value("messageId").String().Equal(message.ID.String())
+		obj.Value("userId").String().Equal(testUser.ID.String())
+		obj.Value("state").String().Equal("pending")
+
+		r, err := repo.GetReminder(uuid.FromStringOrNil(obj.Value("id").String().Raw()))
+		require.NoError(t, err)
+		assert.True(t, at.Equal(r.RemindAt))
+	})
+
+	t.Run("Successful2", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		obj := e.POST("/api/1.0/messages/{messageID}/reminders", message.ID).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]interface{}{"duration": 600}).
+			Expect().
+			Status(http.StatusCreated).
+			JSON().
+			Object()
+
+		r, err := repo.GetReminder(uuid.FromStringOrNil(obj.Value("id").String().Raw()))
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(10*time.Minute), r.RemindAt, time.Minute)
+	})
+}
+
+func TestHandlers_GetMyReminders(t *testing.T) {
+	t.Parallel()
+	repo, server, _, _, _, _, testUser, _ := setupWithUsers(t, common7)
+
+	user := mustMakeUser(t, repo, random)
+	session := generateSession(t, user.ID)
+	channel := mustMakeChannel(t, repo, random)
+	message := mustMakeMessage(t, repo, testUser.ID, channel.ID)
+	r1 := mustMakeReminder(t, repo, user.ID, message.ID, time.Now().Add(2*time.Hour))
+	r2 := mustMakeReminder(t, repo, user.ID, message.ID, time.Now().Add(time.Hour))
+
+	t.Run("NotLoggedIn", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.GET("/api/1.0/users/me/reminders").
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("Successful1", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		arr := e.GET("/api/1.0/users/me/reminders").
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Array()
+		arr.Length().Equal(2)
+		arr.Element(0).Object().Value("id").String().Equal(r2.ID.String())
+		arr.Element(1).Object().Value("id").String().Equal(r1.ID.String())
+	})
+}
+
+func TestHandlers_PostSnoozeReminder(t *testing.T) {
+	t.Parallel()
+	repo, server, _, _, session, adminSession, testUser, _ := setupWithUsers(t, common7)
+
+	channel := mustMakeChannel(t, repo, random)
+	message := mustMakeMessage(t, repo, testUser.ID, channel.ID)
+
+	t.Run("NotFound", func(t *testing.T) {
+		t.Parallel()
+		r := mustMakeReminder(t, repo, testUser.ID, message.ID, time.Now())
+		e := makeExp(t, server)
+		e.POST("/api/1.0/users/me/reminders/{reminderID}/snooze", r.ID).
+			WithCookie(sessions.CookieName, adminSession).
+			WithJSON(map[string]interface{}{"duration": 600}).
+			Expect().
+			Status(http.StatusNotFound)
+	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		t.Parallel()
+		r := mustMakeReminder(t, repo, testUser.ID, message.ID, time.Now())
+		ok, err := repo.ClaimReminder(r.ID)
+		require.NoError(t, err)
+		require.True(t, ok)
+		e := makeExp(t, server)
+		e.POST("/api/1.0/users/me/reminders/{reminderID}/snooze", r.ID).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]interface{}{"duration": 600}).
+			Expect().
+			Status(http.StatusConflict)
+	})
+
+	t.Run("Successful1", func(t *testing.T) {
+		t.Parallel()
+		r := mustMakeReminder(t, repo, testUser.ID, message.ID, time.Now())
+		e := makeExp(t, server)
+		e.POST("/api/1.0/users/me/reminders/{reminderID}/snooze", r.ID).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]interface{}{"duration": 600}).
+			Expect().
+			Status(http.StatusNoContent)
+
+		r, err := repo.GetReminder(r.ID)
+		require.NoError(t, err)
+		assert.Equal(t, model.ReminderPending, r.State)
+		assert.WithinDuration(t, time.Now().Add(10*time.Minute), r.RemindAt, time.Minute)
+	})
+}
+
+func TestHandlers_DeleteReminder(t *testing.T) {
+	t.Parallel()
+	repo, server, _, _, session, _, testUser, _ := setupWithUsers(t, common7)
+
+	channel := mustMakeChannel(t, repo, random)
+	message := mustMakeMessage(t, repo, testUser.ID, channel.ID)
+
+	t.Run("NotLoggedIn", func(t *testing.T) {
+		t.Parallel()
+		r := mustMakeReminder(t, repo, testUser.ID, message.ID, time.Now().Add(time.Hour))
+		e := makeExp(t, server)
+		e.DELETE("/api/1.0/users/me/reminders/{reminderID}", r.ID).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("Successful1", func(t *testing.T) {
+		t.Parallel()
+		r := mustMakeReminder(t, repo, testUser.ID, message.ID, time.Now().Add(time.Hour))
+		e := makeExp(t, server)
+		e.DELETE("/api/1.0/users/me/reminders/{reminderID}", r.ID).
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusNoContent)
+
+		_, err := repo.GetReminder(r.ID)
+		assert.Equal(t, repository.ErrNotFound, err)
+	})
+}
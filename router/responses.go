@@ -3,6 +3,7 @@ package router
 import (
 	"github.com/gofrs/uuid"
 	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
 	"time"
 )
 
@@ -85,28 +86,176 @@ func (h *Handlers) formatUserDetail(user *model.User, tagList []*model.UsersTag)
 }
 
 type messageResponse struct {
-	MessageID       uuid.UUID            `json:"messageId"`
-	UserID          uuid.UUID            `json:"userId"`
-	ParentChannelID uuid.UUID            `json:"parentChannelId"`
-	Content         string               `json:"content"`
-	CreatedAt       time.Time            `json:"createdAt"`
-	UpdatedAt       time.Time            `json:"updatedAt"`
-	Pin             bool                 `json:"pin"`
-	Reported        bool                 `json:"reported"`
-	StampList       []model.MessageStamp `json:"stampList"`
+	MessageID         uuid.UUID                  `json:"messageId"`
+	UserID            uuid.UUID                  `json:"userId"`
+	ParentChannelID   uuid.UUID                  `json:"parentChannelId"`
+	ParentMessageID   uuid.UUID                  `json:"parentMessageId"`
+	Content           string                     `json:"content"`
+	CreatedAt         time.Time                  `json:"createdAt"`
+	UpdatedAt         time.Time                  `json:"updatedAt"`
+	Edited            bool                       `json:"edited"`
+	EditCount         int                        `json:"editCount"`
+	Pin               bool                       `json:"pin"`
+	Reported          bool                       `json:"reported"`
+	StampList         []model.MessageStamp       `json:"stampList"`
+	ThreadReplyCount  int                        `json:"threadReplyCount"`
+	ThreadLatestReply *threadLatestReplyResponse `json:"threadLatestReply"`
+	Citations         []*citationResponse        `json:"citations"`
+}
+
+type ephemeralMessageResponse struct {
+	MessageID       uuid.UUID `json:"messageId"`
+	UserID          uuid.UUID `json:"userId"`
+	ParentChannelID uuid.UUID `json:"parentChannelId"`
+	Content         string    `json:"content"`
+	CreatedAt       time.Time `json:"createdAt"`
+}
+
+type pollResponse struct {
+	PollID         uuid.UUID             `json:"pollId"`
+	ChannelID      uuid.UUID             `json:"channelId"`
+	MessageID      uuid.UUID             `json:"messageId"`
+	CreatorID      uuid.UUID             `json:"creatorId"`
+	Question       string                `json:"question"`
+	MultipleChoice bool                  `json:"multipleChoice"`
+	Anonymous      bool                  `json:"anonymous"`
+	Deadline       *time.Time            `json:"deadline"`
+	Closed         bool                  `json:"closed"`
+	Options        []*pollOptionResponse `json:"options"`
+	VoterCount     int                   `json:"voterCount"`
+	MyVotes        []uuid.UUID           `json:"myVotes"`
+	CreatedAt      time.Time             `json:"createdAt"`
+}
+
+type pollOptionResponse struct {
+	OptionID uuid.UUID   `json:"optionId"`
+	Label    string      `json:"label"`
+	Count    int         `json:"count"`
+	Voters   []uuid.UUID `json:"voters"`
+}
+
+func formatPoll(p *model.Poll, votes []*model.PollVote, userID uuid.UUID) *pollResponse {
+	res := &pollResponse{
+		PollID:         p.ID,
+		ChannelID:      p.ChannelID,
+		MessageID:      p.MessageID,
+		CreatorID:      p.CreatorID,
+		Question:       p.Question,
+		MultipleChoice: p.MultipleChoice,
+		Anonymous:      p.Anonymous,
+		Deadline:       p.Deadline,
+		Closed:         p.IsClosed(time.Now()),
+		Options:        make([]*pollOptionResponse, len(p.Options)),
+		MyVotes:        make([]uuid.UUID, 0),
+		CreatedAt:      p.CreatedAt,
+	}
+
+	options := make(map[uuid.UUID]*pollOptionResponse, len(p.Options))
+	for i, o := range p.Options {
+		res.Options[i] = &pollOptionResponse{OptionID: o.ID, Label: o.Label}
+		// 匿名投票の場合は投票者を公開しない
+		if !p.Anonymous {
+			res.Options[i].Voters = make([]uuid.UUID, 0)
+		}
+		options[o.ID] = res.Options[i]
+	}
+
+	voters := make(map[uuid.UUID]bool)
+	for _, v := range votes {
+		o, ok := options[v.OptionID]
+		if !ok {
+			continue
+		}
+		o.Count++
+		if !p.Anonymous {
+			o.Voters = append(o.Voters, v.UserID)
+		}
+		if v.UserID == userID {
+			res.MyVotes = append(res.MyVotes, v.OptionID)
+		}
+		voters[v.UserID] = true
+	}
+	res.VoterCount = len(voters)
+	return res
+}
+
+type contentFilterErrorResponse struct {
+	Message string    `json:"message"`
+	RuleID  uuid.UUID `json:"ruleId"`
+}
+
+type moderationCaseResponse struct {
+	CaseID     uuid.UUID              `json:"caseId"`
+	MessageID  uuid.UUID              `json:"messageId"`
+	State      string                 `json:"state"`
+	AssigneeID uuid.UUID              `json:"assigneeId"`
+	Reports    []*model.MessageReport `json:"reports"`
+	CreatedAt  time.Time              `json:"createdAt"`
+	UpdatedAt  time.Time              `json:"updatedAt"`
+}
+
+func formatModerationCase(mc *model.ModerationCase, reports []*model.MessageReport) *moderationCaseResponse {
+	return &moderationCaseResponse{
+		CaseID:     mc.ID,
+		MessageID:  mc.MessageID,
+		State:      string(mc.State),
+		AssigneeID: mc.AssigneeID,
+		Reports:    reports,
+		CreatedAt:  mc.CreatedAt,
+		UpdatedAt:  mc.UpdatedAt,
+	}
+}
+
+type citationResponse struct {
+	MessageID   uuid.UUID `json:"messageId"`
+	UserID      uuid.UUID `json:"userId"`
+	ChannelID   uuid.UUID `json:"channelId"`
+	ChannelPath string    `json:"channelPath"`
+	Excerpt     string    `json:"excerpt"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+func formatCitation(c *repository.MessageCitation) *citationResponse {
+	return &citationResponse{
+		MessageID:   c.MessageID,
+		UserID:      c.UserID,
+		ChannelID:   c.ChannelID,
+		ChannelPath: c.ChannelPath,
+		Excerpt:     c.Excerpt,
+		CreatedAt:   c.CreatedAt,
+	}
+}
+
+type threadLatestReplyResponse struct {
+	MessageID uuid.UUID `json:"messageId"`
+	UserID    uuid.UUID `json:"userId"`
+	CreatedAt time.Time `json:"createdAt"`
 }
 
 func formatMessage(m *model.Message) *messageResponse {
-	return &messageResponse{
+	res := &messageResponse{
 		MessageID:       m.ID,
 		UserID:          m.UserID,
 		ParentChannelID: m.ChannelID,
+		ParentMessageID: m.ParentID,
 		Pin:             m.Pin != nil,
 		Content:         m.Text,
 		CreatedAt:       m.CreatedAt,
 		UpdatedAt:       m.UpdatedAt,
+		Edited:          m.IsEdited(),
+		EditCount:       m.EditCount,
 		StampList:       m.Stamps,
+		Citations:       []*citationResponse{},
 	}
+	if m.Thread != nil {
+		res.ThreadReplyCount = m.Thread.ReplyCount
+		res.ThreadLatestReply = &threadLatestReplyResponse{
+			MessageID: m.Thread.LatestReplyID,
+			UserID:    m.Thread.LatestReplyUserID,
+			CreatedAt: m.Thread.LatestReplyAt,
+		}
+	}
+	return res
 }
 
 func formatMessages(ms []*model.Message) []*messageResponse {
@@ -117,6 +266,31 @@ func formatMessages(ms []*model.Message) []*messageResponse {
 	return res
 }
 
+type deletedMessageResponse struct {
+	*messageResponse
+	DeletedAt time.Time `json:"deletedAt"`
+}
+
+type mentionResponse struct {
+	MessageID uuid.UUID        `json:"messageId"`
+	ChannelID uuid.UUID        `json:"channelId"`
+	GroupID   uuid.NullUUID    `json:"groupId"`
+	CreatedAt time.Time        `json:"createdAt"`
+	Read      bool             `json:"read"`
+	Message   *messageResponse `json:"message"`
+}
+
+func formatMention(mention *repository.UserMention) *mentionResponse {
+	return &mentionResponse{
+		MessageID: mention.MessageID,
+		ChannelID: mention.ChannelID,
+		GroupID:   uuid.NullUUID{UUID: mention.GroupID, Valid: mention.GroupID != uuid.Nil},
+		CreatedAt: mention.CreatedAt,
+		Read:      mention.Read,
+		Message:   formatMessage(mention.Message),
+	}
+}
+
 type pinResponse struct {
 	PinID     uuid.UUID        `json:"pinId"`
 	ChannelID uuid.UUID        `json:"channelId"`
@@ -180,28 +354,32 @@ func formatWebhooks(ws []model.Webhook) []*webhookResponse {
 }
 
 type channelResponse struct {
-	ChannelID  string      `json:"channelId"`
-	Name       string      `json:"name"`
-	Parent     string      `json:"parent"`
-	Topic      string      `json:"topic"`
-	Children   []uuid.UUID `json:"children"`
-	Member     []uuid.UUID `json:"member"`
-	Visibility bool        `json:"visibility"`
-	Force      bool        `json:"force"`
-	Private    bool        `json:"private"`
-	DM         bool        `json:"dm"`
+	ChannelID        string      `json:"channelId"`
+	Name             string      `json:"name"`
+	Parent           string      `json:"parent"`
+	Topic            string      `json:"topic"`
+	Children         []uuid.UUID `json:"children"`
+	Member           []uuid.UUID `json:"member"`
+	Visibility       bool        `json:"visibility"`
+	Force            bool        `json:"force"`
+	Private          bool        `json:"private"`
+	DM               bool        `json:"dm"`
+	SlowModeInterval int         `json:"slowModeInterval"`
+	Archived         bool        `json:"archived"`
 }
 
 func (h *Handlers) formatChannel(channel *model.Channel) (response *channelResponse, err error) {
 	response = &channelResponse{
-		ChannelID:  channel.ID.String(),
-		Name:       channel.Name,
-		Topic:      channel.Topic,
-		Visibility: channel.IsVisible,
-		Force:      channel.IsForced,
-		Private:    !channel.IsPublic,
-		DM:         channel.IsDMChannel(),
-		Member:     make([]uuid.UUID, 0),
+		ChannelID:        channel.ID.String(),
+		Name:             channel.Name,
+		Topic:            channel.Topic,
+		Visibility:       channel.IsVisible,
+		Force:            channel.IsForced,
+		Private:          !channel.IsPublic,
+		DM:               channel.IsDMChannel(),
+		SlowModeInterval: channel.SlowModeInterval,
+		Archived:         channel.IsArchived,
+		Member:           make([]uuid.UUID, 0),
 	}
 	if channel.ParentID != uuid.Nil {
 		response.Parent = channel.ParentID.String()
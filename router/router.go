@@ -14,7 +14,7 @@ func SetupRouting(e *echo.Echo, h *Handlers) {
 	e.Validator = validator.New()
 	e.Use(RequestCounterMiddleware())
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		ExposeHeaders: []string{"X-TRAQ-VERSION", headerCacheFile, headerFileMetaType},
+		ExposeHeaders: []string{"X-TRAQ-VERSION", headerCacheFile, headerFileMetaType, headerMore},
 		AllowHeaders:  []string{echo.HeaderContentType, echo.HeaderAuthorization, headerSignature},
 		MaxAge:        3600,
 	}))
@@ -40,6 +40,7 @@ func SetupRouting(e *echo.Echo, h *Handlers) {
 				apiUsersMe.PUT("/icon", h.PutMyIcon, requires(permission.ChangeMyIcon))
 				apiUsersMe.GET("/stamp-history", h.GetMyStampHistory, requires(permission.GetMyStampHistory))
 				apiUsersMe.GET("/groups", h.GetMyBelongingGroup)
+				apiUsersMe.GET("/mentions", h.GetMyMentions, requires(permission.GetMessage), botGuard(blockAlways))
 				apiUsersMe.GET("/notification", h.GetMyNotificationChannels, requires(permission.GetNotificationStatus), botGuard(blockAlways))
 				apiUsersMe.GET("/tokens", h.GetMyTokens, requires(permission.GetMyTokens), botGuard(blockAlways))
 				apiUsersMe.DELETE("/tokens/:tokenID", h.DeleteMyToken, requires(permission.RevokeMyToken), botGuard(blockAlways))
@@ -72,6 +73,26 @@ func SetupRouting(e *echo.Echo, h *Handlers) {
 						}
 					}
 				}
+				apiUsersMeScheduledMessages := apiUsersMe.Group("/scheduled-messages", botGuard(blockAlways))
+				{
+					apiUsersMeScheduledMessages.GET("", h.GetScheduledMessages, requires(permission.GetScheduledMessage))
+					apiUsersMeScheduledMessages.POST("", h.PostScheduledMessage, bodyLimit(100), requires(permission.CreateScheduledMessage))
+					apiUsersMeScheduledMessagesMid := apiUsersMeScheduledMessages.Group("/:scheduledMessageID", h.ValidateScheduledMessageID())
+					{
+						apiUsersMeScheduledMessagesMid.GET("", h.GetScheduledMessage, requires(permission.GetScheduledMessage))
+						apiUsersMeScheduledMessagesMid.PATCH("", h.PatchScheduledMessage, bodyLimit(100), requires(permission.EditScheduledMessage))
+						apiUsersMeScheduledMessagesMid.DELETE("", h.DeleteScheduledMessage, requires(permission.DeleteScheduledMessage))
+					}
+				}
+				apiUsersMeReminders := apiUsersMe.Group("/reminders", botGuard(blockAlways))
+				{
+					apiUsersMeReminders.GET("", h.GetMyReminders, requires(permission.GetReminder))
+					apiUsersMeRemindersRid := apiUsersMeReminders.Group("/:reminderID", h.ValidateReminderID())
+					{
+						apiUsersMeRemindersRid.POST("/snooze", h.PostSnoozeReminder, requires(permission.EditReminder))
+						apiUsersMeRemindersRid.DELETE("", h.DeleteReminder, requires(permission.DeleteReminder))
+					}
+				}
 				apiUsersMeStars := apiUsersMe.Group("/stars", botGuard(blockAlways))
 				{
 					apiUsersMeStars.GET("", h.GetStars, requires(permission.GetStar))
@@ -81,6 +102,15 @@ func SetupRouting(e *echo.Echo, h *Handlers) {
 						apiUsersMeStarsCid.DELETE("", h.DeleteStars, requires(permission.DeleteStar))
 					}
 				}
+				apiUsersMeChannels := apiUsersMe.Group("/channels", botGuard(blockAlways))
+				{
+					apiUsersMeChannels.GET("", h.GetJoinedChannels, requires(permission.GetChannel))
+					apiUsersMeChannelsCid := apiUsersMeChannels.Group("/:channelID", h.ValidateChannelID(false))
+					{
+						apiUsersMeChannelsCid.PUT("", h.PutJoinedChannel, requires(permission.JoinChannel))
+						apiUsersMeChannelsCid.DELETE("", h.DeleteJoinedChannel, requires(permission.LeaveChannel))
+					}
+				}
 				apiUsersMeUnread := apiUsersMe.Group("/unread", botGuard(blockAlways))
 				{
 					apiUsersMeUnread.GET("/channels", h.GetUnreadChannels, requires(permission.GetUnread))
@@ -104,7 +134,7 @@ func SetupRouting(e *echo.Echo, h *Handlers) {
 				apiUsersUID.PUT("/status", h.PutUserStatus, requires(permission.EditOtherUsers))
 				apiUsersUID.PUT("/password", h.PutUserPassword, requires(permission.EditOtherUsers))
 				apiUsersUID.GET("/messages", h.GetDirectMessages, requires(permission.GetMessage), botGuard(blockUnlessSubscribingEvent(bot.DirectMessageCreated)))
-				apiUsersUID.POST("/messages", h.PostDirectMessage, bodyLimit(100), requires(permission.PostMessage), botGuard(blockUnlessSubscribingEvent(bot.DirectMessageCreated)))
+				apiUsersUID.POST("/messages", h.PostDirectMessage, bodyLimit(100), requires(permission.PostMessage), botGuard(blockUnlessSubscribingEvent(bot.DirectMessageCreated)), h.PostRateLimit())
 				apiUsersUID.GET("/icon", h.GetUserIcon, requires(permission.DownloadFile))
 				apiUsersUID.PUT("/icon", h.PutUserIcon, requires(permission.EditOtherUsers))
 				apiUsersUID.GET("/notification", h.GetNotificationChannels, requires(permission.GetNotificationStatus))
@@ -138,6 +168,21 @@ func SetupRouting(e *echo.Echo, h *Handlers) {
 				apiChannelsCid.PUT("/parent", h.PutChannelParent, requires(permission.ChangeParentChannel), botGuard(blockAlways))
 				apiChannelsCid.POST("/children", h.PostChannelChildren, requires(permission.CreateChannel), botGuard(blockAlways))
 				apiChannelsCid.GET("/pins", h.GetChannelPin, requires(permission.GetPin))
+				apiChannelsCid.GET("/members", h.GetChannelMembers, requires(permission.GetChannel))
+				apiChannelsCid.GET("/retention", h.GetChannelRetentionPolicy, requires(permission.GetChannel))
+				apiChannelsCid.PUT("/retention", h.PutChannelRetentionPolicy, requires(permission.EditChannel), botGuard(blockAlways))
+				apiChannelsCid.DELETE("/retention", h.DeleteChannelRetentionPolicy, requires(permission.EditChannel), botGuard(blockAlways))
+				apiChannelsCidExports := apiChannelsCid.Group("/exports", requires(permission.ExportChannel), botGuard(blockAlways))
+				{
+					apiChannelsCidExports.POST("", h.PostChannelExport)
+					apiChannelsCidExportsEid := apiChannelsCidExports.Group("/:exportID", h.ValidateChannelExportID())
+					{
+						apiChannelsCidExportsEid.GET("", h.GetChannelExport)
+						apiChannelsCidExportsEid.GET("/download", h.GetChannelExportArchive)
+					}
+				}
+				apiChannelsCid.PUT("/archive", h.PutChannelArchive, requires(permission.ArchiveChannel), botGuard(blockAlways))
+				apiChannelsCid.DELETE("/archive", h.DeleteChannelArchive, requires(permission.ArchiveChannel), botGuard(blockAlways))
 				apiChannelsCidTopic := apiChannelsCid.Group("/topic")
 				{
 					apiChannelsCidTopic.GET("", h.GetTopic, requires(permission.GetTopic))
@@ -146,8 +191,10 @@ func SetupRouting(e *echo.Echo, h *Handlers) {
 				apiChannelsCidMessages := apiChannelsCid.Group("/messages")
 				{
 					apiChannelsCidMessages.GET("", h.GetMessagesByChannelID, requires(permission.GetMessage))
-					apiChannelsCidMessages.POST("", h.PostMessage, bodyLimit(100), requires(permission.PostMessage))
+					apiChannelsCidMessages.POST("", h.PostMessage, bodyLimit(100), requires(permission.PostMessage), h.PostRateLimit())
 				}
+				apiChannelsCid.POST("/ephemeral", h.PostEphemeralMessage, bodyLimit(100), requires(permission.PostEphemeralMessage), h.PostRateLimit())
+				apiChannelsCid.POST("/polls", h.PostPoll, bodyLimit(100), requires(permission.CreatePoll), h.PostRateLimit())
 				apiChannelsCidNotification := apiChannelsCid.Group("/notification")
 				{
 					apiChannelsCidNotification.GET("", h.GetNotificationStatus, requires(permission.GetNotificationStatus))
@@ -169,12 +216,21 @@ func SetupRouting(e *echo.Echo, h *Handlers) {
 		apiMessages := api.Group("/messages")
 		{
 			apiMessages.GET("/reports", h.GetMessageReports, requires(permission.GetMessageReports))
+			apiMessages.GET("/deleted", h.GetDeletedMessages, requires(permission.RestoreMessage), botGuard(blockAlways))
+			apiMessages.POST("/deleted/:messageID/restore", h.PostRestoreMessage, requires(permission.RestoreMessage), botGuard(blockAlways))
+			apiMessages.GET("/search", h.SearchMessages, requires(permission.GetMessage), botGuard(blockAlways))
 			apiMessagesMid := apiMessages.Group("/:messageID", h.ValidateMessageID(), botGuard(blockByMessageChannel))
 			{
 				apiMessagesMid.GET("", h.GetMessageByID, requires(permission.GetMessage))
 				apiMessagesMid.PUT("", h.PutMessageByID, bodyLimit(100), requires(permission.EditMessage))
 				apiMessagesMid.DELETE("", h.DeleteMessageByID, requires(permission.DeleteMessage))
+				apiMessagesMid.GET("/history", h.GetMessageHistory, requires(permission.GetMessage))
+				apiMessagesMid.GET("/previews", h.GetMessagePreviews, requires(permission.GetMessage))
+				apiMessagesMid.POST("/move", h.PostMoveMessages, requires(permission.MoveMessage), botGuard(blockAlways))
 				apiMessagesMid.POST("/report", h.PostMessageReport, requires(permission.ReportMessage), botGuard(blockAlways))
+				apiMessagesMid.POST("/reminders", h.PostMessageReminder, requires(permission.CreateReminder), botGuard(blockAlways))
+				apiMessagesMid.GET("/thread", h.GetThreadMessages, requires(permission.GetMessage))
+				apiMessagesMid.POST("/thread", h.PostThreadMessage, bodyLimit(100), requires(permission.PostMessage), h.PostRateLimit())
 				apiMessagesMid.GET("/stamps", h.GetMessageStamps, requires(permission.GetMessageStamp))
 				apiMessagesMidStampsSid := apiMessagesMid.Group("/stamps/:stampID", h.ValidateStampID(true))
 				{
@@ -210,6 +266,38 @@ func SetupRouting(e *echo.Echo, h *Handlers) {
 				apiPinsPid.DELETE("", h.DeletePin, requires(permission.DeletePin))
 			}
 		}
+		apiContentFilters := api.Group("/content-filters", botGuard(blockAlways))
+		{
+			apiContentFilters.GET("", h.GetContentFilterRules, requires(permission.ManageContentFilter))
+			apiContentFilters.POST("", h.PostContentFilterRule, requires(permission.ManageContentFilter))
+			apiContentFiltersRid := apiContentFilters.Group("/:ruleID", h.ValidateContentFilterRuleID())
+			{
+				apiContentFiltersRid.GET("", h.GetContentFilterRule, requires(permission.ManageContentFilter))
+				apiContentFiltersRid.PATCH("", h.PatchContentFilterRule, requires(permission.ManageContentFilter))
+				apiContentFiltersRid.DELETE("", h.DeleteContentFilterRule, requires(permission.ManageContentFilter))
+			}
+		}
+		apiModeration := api.Group("/moderation", botGuard(blockAlways))
+		{
+			apiModeration.GET("/cases", h.GetModerationCases, requires(permission.ManageModeration))
+			apiModerationCid := apiModeration.Group("/cases/:caseID", h.ValidateModerationCaseID())
+			{
+				apiModerationCid.GET("", h.GetModerationCase, requires(permission.ManageModeration))
+				apiModerationCid.PATCH("", h.PatchModerationCase, requires(permission.ManageModeration))
+				apiModerationCid.GET("/logs", h.GetModerationLogs, requires(permission.ManageModeration))
+				apiModerationCid.POST("/notes", h.PostModerationNote, requires(permission.ManageModeration))
+				apiModerationCid.POST("/actions", h.PostModerationAction, requires(permission.ManageModeration))
+			}
+		}
+		apiPolls := api.Group("/polls")
+		{
+			apiPollsPid := apiPolls.Group("/:pollID", h.ValidatePollID(), botGuard(blockByPollChannel))
+			{
+				apiPollsPid.GET("", h.GetPoll, requires(permission.GetPoll))
+				apiPollsPid.PUT("/votes", h.PutPollVotes, requires(permission.VotePoll))
+				apiPollsPid.DELETE("/votes", h.DeletePollVotes, requires(permission.VotePoll))
+			}
+		}
 		apiStamps := api.Group("/stamps")
 		{
 			apiStamps.GET("", h.GetStamps, requires(permission.GetStamp))
@@ -304,8 +392,8 @@ func SetupRouting(e *echo.Echo, h *Handlers) {
 			apiPublic.GET("/emoji.css", h.GetPublicEmojiCSS)
 			apiPublic.GET("/emoji/:stampID", h.GetPublicEmojiImage, h.ValidateStampID(false))
 		}
-		apiNoAuth.POST("/webhooks/:webhookID", h.PostWebhook, h.ValidateWebhookID(false))
-		apiNoAuth.POST("/webhooks/:webhookID/github", h.PostWebhookByGithub, h.ValidateWebhookID(false))
+		apiNoAuth.POST("/webhooks/:webhookID", h.PostWebhook, h.ValidateWebhookID(false), h.VerifyWebhookSignature(headerSignature, ""), h.PostRateLimit())
+		apiNoAuth.POST("/webhooks/:webhookID/github", h.PostWebhookByGithub, h.ValidateWebhookID(false), h.VerifyWebhookSignature(headerGitHubSignature, "sha1="), h.PostRateLimit())
 		apiOAuth := apiNoAuth.Group("/oauth2")
 		{
 			apiOAuth.GET("/authorize", h.AuthorizationEndpointHandler)
@@ -15,6 +15,8 @@ import (
 	"github.com/traPtitech/traQ/rbac/role"
 	"github.com/traPtitech/traQ/repository"
 	"github.com/traPtitech/traQ/utils"
+	"github.com/traPtitech/traQ/utils/contentfilter"
+	"github.com/traPtitech/traQ/utils/message"
 	"github.com/traPtitech/traQ/utils/storage"
 	"github.com/traPtitech/traQ/utils/validator"
 	"golang.org/x/sync/errgroup"
@@ -24,6 +26,7 @@ import (
 	"mime"
 	"path/filepath"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 	"unicode/utf8"
@@ -52,10 +55,14 @@ type TestRepository struct {
 	ChannelSubscribesLock     sync.RWMutex
 	PrivateChannelMembers     map[uuid.UUID]map[uuid.UUID]bool
 	PrivateChannelMembersLock sync.RWMutex
+	ChannelMembers            map[uuid.UUID]map[uuid.UUID]bool
+	ChannelMembersLock        sync.RWMutex
 	Messages                  map[uuid.UUID]model.Message
+	DeletedMessages           map[uuid.UUID]model.Message
 	MessagesLock              sync.RWMutex
-	MessageUnreads            map[uuid.UUID]map[uuid.UUID]bool
-	MessageUnreadsLock        sync.RWMutex
+	ArchivedMessages          map[uuid.UUID][]model.ArchivedMessage
+	ReadPointers              map[uuid.UUID]map[uuid.UUID]model.ChannelReadPointer
+	ReadPointersLock          sync.RWMutex
 	MessageReports            []model.MessageReport
 	MessageReportsLock        sync.RWMutex
 	Pins                      map[uuid.UUID]model.Pin
@@ -78,10 +85,26 @@ type TestRepository struct {
 	OAuth2AuthorizesLock      sync.RWMutex
 	OAuth2Tokens              map[uuid.UUID]model.OAuth2Token
 	OAuth2TokensLock          sync.RWMutex
-}
-
-func (repo *TestRepository) GetUserUnreadChannels(userID uuid.UUID) ([]*repository.UserUnreadChannel, error) {
-	panic("implement me")
+	ScheduledMessages         map[uuid.UUID]model.ScheduledMessage
+	ScheduledMessagesLock     sync.RWMutex
+	ChannelExports            map[uuid.UUID]model.ChannelExport
+	ChannelExportsLock        sync.RWMutex
+	RetentionPolicies         map[uuid.UUID]model.ChannelRetentionPolicy
+	RetentionPoliciesLock     sync.RWMutex
+	LinkPreviews              map[string]model.LinkPreview
+	LinkPreviewsLock          sync.RWMutex
+	Polls                     map[uuid.UUID]model.Poll
+	PollVotes                 map[uuid.UUID][]model.PollVote
+	PollsLock                 sync.RWMutex
+	ContentFilterRules        map[uuid.UUID]model.ContentFilterRule
+	ContentFilterRulesLock    sync.RWMutex
+	ModerationCases           map[uuid.UUID]model.ModerationCase
+	ModerationLogs            []model.ModerationLog
+	ModerationLock            sync.RWMutex
+	Mentions                  []model.Mention
+	MentionsLock              sync.RWMutex
+	Reminders                 map[uuid.UUID]model.Reminder
+	RemindersLock             sync.RWMutex
 }
 
 func (repo *TestRepository) GetBotByBotUserID(id uuid.UUID) (*model.Bot, error) {
@@ -119,8 +142,11 @@ func NewTestRepository() *TestRepository {
 		Channels:              map[uuid.UUID]model.Channel{},
 		ChannelSubscribes:     map[uuid.UUID]map[uuid.UUID]bool{},
 		PrivateChannelMembers: map[uuid.UUID]map[uuid.UUID]bool{},
+		ChannelMembers:        map[uuid.UUID]map[uuid.UUID]bool{},
 		Messages:              map[uuid.UUID]model.Message{},
-		MessageUnreads:        map[uuid.UUID]map[uuid.UUID]bool{},
+		DeletedMessages:       map[uuid.UUID]model.Message{},
+		ArchivedMessages:      map[uuid.UUID][]model.ArchivedMessage{},
+		ReadPointers:          map[uuid.UUID]map[uuid.UUID]model.ChannelReadPointer{},
 		MessageReports:        []model.MessageReport{},
 		Pins:                  map[uuid.UUID]model.Pin{},
 		Stars:                 map[uuid.UUID]map[uuid.UUID]bool{},
@@ -132,6 +158,17 @@ func NewTestRepository() *TestRepository {
 		OAuth2Clients:         map[string]model.OAuth2Client{},
 		OAuth2Authorizes:      map[string]model.OAuth2Authorize{},
 		OAuth2Tokens:          map[uuid.UUID]model.OAuth2Token{},
+		ScheduledMessages:     map[uuid.UUID]model.ScheduledMessage{},
+		ChannelExports:        map[uuid.UUID]model.ChannelExport{},
+		RetentionPolicies:     map[uuid.UUID]model.ChannelRetentionPolicy{},
+		LinkPreviews:          map[string]model.LinkPreview{},
+		Polls:                 map[uuid.UUID]model.Poll{},
+		PollVotes:             map[uuid.UUID][]model.PollVote{},
+		ContentFilterRules:    map[uuid.UUID]model.ContentFilterRule{},
+		ModerationCases:       map[uuid.UUID]model.ModerationCase{},
+		ModerationLogs:        []model.ModerationLog{},
+		Mentions:              []model.Mention{},
+		Reminders:             map[uuid.UUID]model.Reminder{},
 	}
 	_, _ = r.CreateUser("traq", "traq", role.Admin)
 	return r
@@ -896,8 +933,8 @@ func (repo *TestRepository) CreateChildChannel(name string, parentID, creatorID
 		return nil, err
 	}
 
-	// ダイレクトメッセージの子チャンネルは作れない
-	if pCh.IsDMChannel() {
+	// ダイレクトメッセージ・アーカイブされたチャンネルの子チャンネルは作れない
+	if pCh.IsDMChannel() || pCh.IsArchived {
 		return nil, repository.ErrForbidden
 	}
 
@@ -989,6 +1026,65 @@ func (repo *TestRepository) UpdateChannelAttributes(channelID uuid.UUID, visibil
 	return nil
 }
 
+func (repo *TestRepository) UpdateChannelSlowModeInterval(channelID uuid.UUID, interval int) error {
+	if channelID == uuid.Nil {
+		return repository.ErrNilID
+	}
+	if interval < 0 {
+		return repository.ArgError("interval", "Interval must be non-negative")
+	}
+	repo.ChannelsLock.Lock()
+	defer repo.ChannelsLock.Unlock()
+	ch, ok := repo.Channels[channelID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	ch.SlowModeInterval = interval
+	ch.UpdatedAt = time.Now()
+	repo.Channels[channelID] = ch
+	return nil
+}
+
+func (repo *TestRepository) UpdateChannelArchived(channelID uuid.UUID, archived bool, updaterID uuid.UUID) error {
+	if channelID == uuid.Nil {
+		return repository.ErrNilID
+	}
+	ch, err := repo.GetChannel(channelID)
+	if err != nil {
+		return err
+	}
+	if ch.IsDMChannel() {
+		return repository.ErrForbidden
+	}
+	if !archived && ch.ParentID != uuid.Nil {
+		parent, err := repo.GetChannel(ch.ParentID)
+		if err != nil {
+			return err
+		}
+		if parent.IsArchived {
+			return repository.ErrForbidden
+		}
+	}
+
+	desc, err := repo.GetDescendantChannelIDs(channelID)
+	if err != nil {
+		return err
+	}
+	repo.ChannelsLock.Lock()
+	defer repo.ChannelsLock.Unlock()
+	for _, id := range append(desc, channelID) {
+		ch, ok := repo.Channels[id]
+		if !ok {
+			continue
+		}
+		ch.IsArchived = archived
+		ch.UpdaterID = updaterID
+		ch.UpdatedAt = time.Now()
+		repo.Channels[id] = ch
+	}
+	return nil
+}
+
 func (repo *TestRepository) UpdateChannelTopic(channelID uuid.UUID, topic string, updaterID uuid.UUID) error {
 	if channelID == uuid.Nil {
 		return repository.ErrNilID
@@ -1163,6 +1259,28 @@ func (repo *TestRepository) GetChannel(channelID uuid.UUID) (*model.Channel, err
 	return &ch, nil
 }
 
+// checkChannelNotArchived ChannelsLockを取得していない状態で呼び出す必要があります
+func (repo *TestRepository) checkChannelNotArchived(channelID uuid.UUID) error {
+	repo.ChannelsLock.RLock()
+	ch, ok := repo.Channels[channelID]
+	repo.ChannelsLock.RUnlock()
+	if ok && ch.IsArchived {
+		return repository.ErrChannelArchived
+	}
+	return nil
+}
+
+// checkMessageChannelNotArchived MessagesLockを取得していない状態で呼び出す必要があります
+func (repo *TestRepository) checkMessageChannelNotArchived(messageID uuid.UUID) error {
+	repo.MessagesLock.RLock()
+	m, ok := repo.Messages[messageID]
+	repo.MessagesLock.RUnlock()
+	if !ok {
+		return nil
+	}
+	return repo.checkChannelNotArchived(m.ChannelID)
+}
+
 func (repo *TestRepository) GetChannelByMessageID(messageID uuid.UUID) (*model.Channel, error) {
 	repo.MessagesLock.RLock()
 	m, ok := repo.Messages[messageID]
@@ -1198,7 +1316,41 @@ func (repo *TestRepository) GetChannelsByUserID(userID uuid.UUID) ([]*model.Chan
 }
 
 func (repo *TestRepository) GetDirectMessageChannel(user1, user2 uuid.UUID) (*model.Channel, error) {
-	panic("implement me")
+	if user1 == uuid.Nil || user2 == uuid.Nil {
+		return nil, repository.ErrNilID
+	}
+	members := map[uuid.UUID]bool{user1: true, user2: true}
+
+	repo.ChannelsLock.Lock()
+	defer repo.ChannelsLock.Unlock()
+	repo.PrivateChannelMembersLock.Lock()
+	defer repo.PrivateChannelMembersLock.Unlock()
+	for _, ch := range repo.Channels {
+		if ch.ParentID != dmChannelRootUUID || len(repo.PrivateChannelMembers[ch.ID]) != len(members) {
+			continue
+		}
+		ok := true
+		for uid := range repo.PrivateChannelMembers[ch.ID] {
+			ok = ok && members[uid]
+		}
+		if ok {
+			ch := ch
+			return &ch, nil
+		}
+	}
+
+	ch := model.Channel{
+		ID:        uuid.Must(uuid.NewV4()),
+		Name:      "dm_" + utils.RandAlphabetAndNumberString(17),
+		ParentID:  dmChannelRootUUID,
+		IsPublic:  false,
+		IsVisible: true,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	repo.Channels[ch.ID] = ch
+	repo.PrivateChannelMembers[ch.ID] = members
+	return &ch, nil
 }
 
 func (repo *TestRepository) IsChannelPresent(name string, parent uuid.UUID) (bool, error) {
@@ -1295,7 +1447,21 @@ func (repo *TestRepository) GetAscendantChannelIDs(channelID uuid.UUID) ([]uuid.
 }
 
 func (repo *TestRepository) GetChannelPath(id uuid.UUID) (string, error) {
-	panic("implement me")
+	repo.ChannelsLock.RLock()
+	defer repo.ChannelsLock.RUnlock()
+	ch, ok := repo.Channels[id]
+	if !ok {
+		return "", repository.ErrNotFound
+	}
+	path := ch.Name
+	for ch.ParentID != uuid.Nil {
+		ch, ok = repo.Channels[ch.ParentID]
+		if !ok {
+			break
+		}
+		path = ch.Name + "/" + path
+	}
+	return path, nil
 }
 
 func (repo *TestRepository) GetChannelDepth(id uuid.UUID) (int, error) {
@@ -1412,6 +1578,60 @@ func (repo *TestRepository) GetSubscribedChannelIDs(userID uuid.UUID) ([]uuid.UU
 	return result, nil
 }
 
+func (repo *TestRepository) JoinChannel(userID, channelID uuid.UUID) error {
+	if userID == uuid.Nil || channelID == uuid.Nil {
+		return repository.ErrNilID
+	}
+	ch, err := repo.GetChannel(channelID)
+	if err != nil {
+		return err
+	}
+	if !ch.IsPublic || ch.IsArchived {
+		return repository.ErrForbidden
+	}
+	repo.ChannelMembersLock.Lock()
+	uids, ok := repo.ChannelMembers[channelID]
+	if !ok {
+		uids = make(map[uuid.UUID]bool)
+	}
+	uids[userID] = true
+	repo.ChannelMembers[channelID] = uids
+	repo.ChannelMembersLock.Unlock()
+	return repo.SubscribeChannel(userID, channelID)
+}
+
+func (repo *TestRepository) LeaveChannel(userID, channelID uuid.UUID) error {
+	if userID == uuid.Nil || channelID == uuid.Nil {
+		return repository.ErrNilID
+	}
+	repo.ChannelMembersLock.Lock()
+	delete(repo.ChannelMembers[channelID], userID)
+	repo.ChannelMembersLock.Unlock()
+	return repo.UnsubscribeChannel(userID, channelID)
+}
+
+func (repo *TestRepository) GetJoinedUserIDs(channelID uuid.UUID) ([]uuid.UUID, error) {
+	result := make([]uuid.UUID, 0)
+	repo.ChannelMembersLock.RLock()
+	for uid := range repo.ChannelMembers[channelID] {
+		result = append(result, uid)
+	}
+	repo.ChannelMembersLock.RUnlock()
+	return result, nil
+}
+
+func (repo *TestRepository) GetJoinedChannelIDs(userID uuid.UUID) ([]uuid.UUID, error) {
+	result := make([]uuid.UUID, 0)
+	repo.ChannelMembersLock.RLock()
+	for cid, uids := range repo.ChannelMembers {
+		if uids[userID] {
+			result = append(result, cid)
+		}
+	}
+	repo.ChannelMembersLock.RUnlock()
+	return result, nil
+}
+
 func (repo *TestRepository) CreateMessage(userID, channelID uuid.UUID, text string) (*model.Message, error) {
 	if userID == uuid.Nil || channelID == uuid.Nil {
 		return nil, repository.ErrNilID
@@ -1419,12 +1639,19 @@ func (repo *TestRepository) CreateMessage(userID, channelID uuid.UUID, text stri
 	if len(text) == 0 {
 		return nil, repository.ArgError("text", "Text is required")
 	}
+	if err := repo.checkChannelNotArchived(channelID); err != nil {
+		return nil, err
+	}
+	filtered, err := repo.applyContentFilter(channelID, text)
+	if err != nil {
+		return nil, err
+	}
 
 	m := &model.Message{
 		ID:        uuid.Must(uuid.NewV4()),
 		UserID:    userID,
 		ChannelID: channelID,
-		Text:      text,
+		Text:      filtered.Text,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 		Stamps:    make([]model.MessageStamp, 0),
@@ -1433,9 +1660,84 @@ func (repo *TestRepository) CreateMessage(userID, channelID uuid.UUID, text stri
 	repo.MessagesLock.Lock()
 	repo.Messages[m.ID] = *m
 	repo.MessagesLock.Unlock()
+	repo.reportFlaggedMessage(m.ID, filtered.FlaggedBy)
+	return m, nil
+}
+
+func (repo *TestRepository) CreateThreadReply(userID, parentID uuid.UUID, text string) (*model.Message, error) {
+	if userID == uuid.Nil || parentID == uuid.Nil {
+		return nil, repository.ErrNilID
+	}
+	if len(text) == 0 {
+		return nil, repository.ArgError("text", "Text is required")
+	}
+
+	repo.MessagesLock.Lock()
+	defer repo.MessagesLock.Unlock()
+	parent, ok := repo.Messages[parentID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	if parent.IsThreadReply() {
+		return nil, repository.ArgError("parentID", "thread replies cannot have replies")
+	}
+	if err := repo.checkChannelNotArchived(parent.ChannelID); err != nil {
+		return nil, err
+	}
+	filtered, err := repo.applyContentFilter(parent.ChannelID, text)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &model.Message{
+		ID:        uuid.Must(uuid.NewV4()),
+		UserID:    userID,
+		ChannelID: parent.ChannelID,
+		ParentID:  parentID,
+		Text:      filtered.Text,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		Stamps:    make([]model.MessageStamp, 0),
+	}
+	repo.Messages[m.ID] = *m
+	repo.reportFlaggedMessage(m.ID, filtered.FlaggedBy)
 	return m, nil
 }
 
+func (repo *TestRepository) GetThreadParticipantIDs(parentID uuid.UUID) ([]uuid.UUID, error) {
+	result := make([]uuid.UUID, 0)
+	users := map[uuid.UUID]bool{}
+	repo.MessagesLock.RLock()
+	for _, v := range repo.Messages {
+		if (v.ID == parentID || v.ParentID == parentID) && !users[v.UserID] {
+			users[v.UserID] = true
+			result = append(result, v.UserID)
+		}
+	}
+	repo.MessagesLock.RUnlock()
+	return result, nil
+}
+
+// getMessageThread MessagesLockを取得した状態で呼び出す必要があります
+func (repo *TestRepository) getMessageThread(parentID uuid.UUID) *model.MessageThread {
+	var t *model.MessageThread
+	for _, v := range repo.Messages {
+		if v.ParentID != parentID {
+			continue
+		}
+		if t == nil {
+			t = &model.MessageThread{ParentID: parentID}
+		}
+		t.ReplyCount++
+		if v.CreatedAt.After(t.LatestReplyAt) {
+			t.LatestReplyID = v.ID
+			t.LatestReplyUserID = v.UserID
+			t.LatestReplyAt = v.CreatedAt
+		}
+	}
+	return t
+}
+
 func (repo *TestRepository) UpdateMessage(messageID uuid.UUID, text string) error {
 	if messageID == uuid.Nil {
 		return repository.ErrNilID
@@ -1450,178 +1752,553 @@ func (repo *TestRepository) UpdateMessage(messageID uuid.UUID, text string) erro
 	if !ok {
 		return repository.ErrNotFound
 	}
-	m.Text = text
+	if err := repo.checkChannelNotArchived(m.ChannelID); err != nil {
+		return err
+	}
+	filtered, err := repo.applyContentFilter(m.ChannelID, text)
+	if err != nil {
+		return err
+	}
+	repo.ArchivedMessages[messageID] = append(repo.ArchivedMessages[messageID], model.ArchivedMessage{
+		ID:        uuid.Must(uuid.NewV4()),
+		MessageID: m.ID,
+		UserID:    m.UserID,
+		Text:      m.Text,
+		DateTime:  m.UpdatedAt,
+	})
+	m.Text = filtered.Text
+	m.EditCount++
 	m.UpdatedAt = time.Now()
 	repo.Messages[messageID] = m
+	repo.reportFlaggedMessage(messageID, filtered.FlaggedBy)
 	return nil
 }
 
 func (repo *TestRepository) DeleteMessage(messageID uuid.UUID) error {
+	return repo.deleteMessage(messageID, false)
+}
+
+func (repo *TestRepository) DeleteExpiredMessage(messageID uuid.UUID) error {
+	return repo.deleteMessage(messageID, true)
+}
+
+func (repo *TestRepository) deleteMessage(messageID uuid.UUID, ignoreArchived bool) error {
 	if messageID == uuid.Nil {
 		return repository.ErrNilID
 	}
 
 	repo.MessagesLock.Lock()
 	defer repo.MessagesLock.Unlock()
-	if _, ok := repo.Messages[messageID]; !ok {
+	m, ok := repo.Messages[messageID]
+	if !ok {
 		return repository.ErrNotFound
 	}
+	if !ignoreArchived {
+		if err := repo.checkChannelNotArchived(m.ChannelID); err != nil {
+			return err
+		}
+	}
+	now := time.Now()
+	m.DeletedAt = &now
+	repo.DeletedMessages[messageID] = m
 	delete(repo.Messages, messageID)
 	return nil
 }
 
-func (repo *TestRepository) GetMessageByID(messageID uuid.UUID) (*model.Message, error) {
-	repo.MessagesLock.RLock()
-	m, ok := repo.Messages[messageID]
-	repo.MessagesLock.RUnlock()
-	if !ok {
-		return nil, repository.ErrNotFound
-	}
-	m.Stamps = make([]model.MessageStamp, 0)
-	return &m, nil
-}
-
-func (repo *TestRepository) GetMessagesByChannelID(channelID uuid.UUID, limit, offset int) ([]*model.Message, error) {
-	tmp := make([]*model.Message, 0)
+func (repo *TestRepository) GetDeletedMessages(channelID, userID uuid.UUID, limit, offset int) ([]*model.Message, error) {
+	result := make([]*model.Message, 0)
 	repo.MessagesLock.RLock()
-	for _, v := range repo.Messages {
-		if v.ChannelID == channelID {
-			v := v
-			v.Stamps = make([]model.MessageStamp, 0)
-			tmp = append(tmp, &v)
+	for _, m := range repo.DeletedMessages {
+		m := m
+		if (channelID == uuid.Nil || m.ChannelID == channelID) && (userID == uuid.Nil || m.UserID == userID) {
+			result = append(result, &m)
 		}
 	}
 	repo.MessagesLock.RUnlock()
-	sort.Slice(tmp, func(i, j int) bool {
-		return tmp[i].CreatedAt.After(tmp[j].CreatedAt)
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].DeletedAt.After(*result[j].DeletedAt)
 	})
-	if offset < 0 {
-		offset = 0
-	}
-	if limit <= 0 {
-		limit = math.MaxInt32
+	if offset > 0 {
+		if offset >= len(result) {
+			return []*model.Message{}, nil
+		}
+		result = result[offset:]
 	}
-	result := make([]*model.Message, 0)
-	for i := offset; i < len(tmp) && i < offset+limit; i++ {
-		result = append(result, tmp[i])
+	if limit > 0 && limit < len(result) {
+		result = result[:limit]
 	}
 	return result, nil
 }
 
-func (repo *TestRepository) GetMessagesByUserID(userID uuid.UUID, limit, offset int) ([]*model.Message, error) {
-	tmp := make([]*model.Message, 0)
-	repo.MessagesLock.RLock()
-	for _, v := range repo.Messages {
-		if v.UserID == userID {
-			v := v
-			v.Stamps = make([]model.MessageStamp, 0)
-			tmp = append(tmp, &v)
-		}
+func (repo *TestRepository) RestoreMessage(messageID uuid.UUID) error {
+	if messageID == uuid.Nil {
+		return repository.ErrNilID
 	}
-	repo.MessagesLock.RUnlock()
-	sort.Slice(tmp, func(i, j int) bool {
-		return tmp[i].CreatedAt.After(tmp[j].CreatedAt)
-	})
-	if offset < 0 {
-		offset = 0
+	repo.MessagesLock.Lock()
+	defer repo.MessagesLock.Unlock()
+	m, ok := repo.DeletedMessages[messageID]
+	if !ok {
+		if _, ok := repo.Messages[messageID]; ok {
+			return repository.ArgError("messageID", "the message is not deleted")
+		}
+		return repository.ErrNotFound
 	}
-	if limit <= 0 {
-		limit = math.MaxInt32
+	if m.IsThreadReply() {
+		if _, ok := repo.Messages[m.ParentID]; !ok {
+			return repository.ArgError("messageID", "the parent message has been deleted")
+		}
 	}
-	result := make([]*model.Message, 0)
-	for i := offset; i < len(tmp) && i < offset+limit; i++ {
-		result = append(result, tmp[i])
+	if err := repo.checkChannelNotArchived(m.ChannelID); err != nil {
+		return err
 	}
-	return result, nil
-}
+	m.DeletedAt = nil
+	repo.Messages[messageID] = m
+	delete(repo.DeletedMessages, messageID)
+	return nil
+}
 
-func (repo *TestRepository) GetArchivedMessagesByID(messageID uuid.UUID) ([]*model.ArchivedMessage, error) {
-	panic("implement me")
+func (repo *TestRepository) GetMessageCitations(userID uuid.UUID, messageIDs []uuid.UUID) ([]*repository.MessageCitation, error) {
+	res := make([]*repository.MessageCitation, 0, len(messageIDs))
+	for _, id := range messageIDs {
+		m, err := repo.GetMessageByID(id)
+		if err != nil {
+			if err == repository.ErrNotFound {
+				continue
+			}
+			return nil, err
+		}
+		if ok, err := repo.IsChannelAccessibleToUser(userID, m.ChannelID); err != nil {
+			return nil, err
+		} else if !ok {
+			continue
+		}
+		path, err := repo.GetChannelPath(m.ChannelID)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, &repository.MessageCitation{
+			MessageID:   m.ID,
+			UserID:      m.UserID,
+			ChannelID:   m.ChannelID,
+			ChannelPath: path,
+			Excerpt:     m.Text,
+			CreatedAt:   m.CreatedAt,
+		})
+	}
+	return res, nil
 }
 
-func (repo *TestRepository) SetMessageUnread(userID, messageID uuid.UUID, noticeable bool) error {
-	if userID == uuid.Nil || messageID == uuid.Nil {
-		return repository.ErrNilID
+func (repo *TestRepository) CanonicalizeMessageEmbeds(userID uuid.UUID, text string) (string, *message.EmbeddedInfo, error) {
+	var (
+		invalid *message.EmbeddedInfo
+		dbErr   error
+	)
+	res := message.ReplaceEmbeds(text, func(token string, info *message.EmbeddedInfo) string {
+		if dbErr != nil {
+			return token
+		}
+		raw, ok, err := repo.resolveEmbedRaw(userID, info)
+		switch {
+		case err != nil:
+			dbErr = err
+			return token
+		case !ok:
+			if invalid == nil {
+				invalid = info
+			}
+			return info.Raw
+		case raw == info.Raw:
+			return token
+		default:
+			info.Raw = raw
+			return info.String()
+		}
+	})
+	if dbErr != nil {
+		return "", nil, dbErr
+	}
+	return res, invalid, nil
+}
+
+func (repo *TestRepository) resolveEmbedRaw(userID uuid.UUID, info *message.EmbeddedInfo) (string, bool, error) {
+	switch info.Type {
+	case "user", "group", "channel", "file", "stamp":
+	default:
+		return info.Raw, true, nil
+	}
+	id, err := uuid.FromString(info.ID)
+	if err != nil || id == uuid.Nil {
+		return "", false, nil
+	}
+	notFound := func(err error) (string, bool, error) {
+		if err == repository.ErrNotFound {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+
+	switch info.Type {
+	case "user":
+		u, err := repo.GetUser(id)
+		if err != nil {
+			return notFound(err)
+		}
+		if u.Status == model.UserAccountStatusDeactivated {
+			return "", false, nil
+		}
+		return "@" + u.Name, true, nil
+	case "group":
+		g, err := repo.GetUserGroup(id)
+		if err != nil {
+			return notFound(err)
+		}
+		return "@" + g.Name, true, nil
+	case "channel":
+		ch, err := repo.GetChannel(id)
+		if err != nil {
+			return notFound(err)
+		}
+		if ch.IsDMChannel() {
+			return "", false, nil
+		}
+		if ok, err := repo.IsChannelAccessibleToUser(userID, id); err != nil || !ok {
+			return "", false, err
+		}
+		path, err := repo.GetChannelPath(id)
+		if err != nil {
+			return notFound(err)
+		}
+		return "#" + path, true, nil
+	case "file":
+		f, err := repo.GetFileMeta(id)
+		if err != nil {
+			return notFound(err)
+		}
+		if ok, err := repo.IsFileAccessible(id, userID); err != nil {
+			return notFound(err)
+		} else if !ok {
+			return "", false, nil
+		}
+		return f.Name, true, nil
+	case "stamp":
+		s, err := repo.GetStamp(id)
+		if err != nil {
+			return notFound(err)
+		}
+		return ":" + s.Name + ":", true, nil
+	}
+	return "", false, nil
+}
+
+func (repo *TestRepository) MoveMessages(firstID, lastID, channelID uuid.UUID) ([]uuid.UUID, error) {
+	if firstID == uuid.Nil || lastID == uuid.Nil || channelID == uuid.Nil {
+		return nil, repository.ErrNilID
 	}
-	repo.MessageUnreadsLock.Lock()
-	mMap, ok := repo.MessageUnreads[userID]
+	repo.ChannelsLock.RLock()
+	to, ok := repo.Channels[channelID]
+	repo.ChannelsLock.RUnlock()
 	if !ok {
-		mMap = make(map[uuid.UUID]bool)
+		return nil, repository.ArgError("channelID", "the Channel is not found")
 	}
-	mMap[messageID] = true
-	repo.MessageUnreads[userID] = mMap
-	repo.MessageUnreadsLock.Unlock()
-	return nil
+	if to.IsDMChannel() {
+		return nil, repository.ArgError("channelID", "direct message channels are not allowed")
+	}
+	if to.IsArchived {
+		return nil, repository.ErrChannelArchived
+	}
+
+	repo.MessagesLock.Lock()
+	defer repo.MessagesLock.Unlock()
+	first, ok := repo.Messages[firstID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	last, ok := repo.Messages[lastID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	if first.IsThreadReply() || last.IsThreadReply() {
+		return nil, repository.ArgError("firstID", "thread replies cannot be moved individually")
+	}
+	if first.ChannelID != last.ChannelID {
+		return nil, repository.ArgError("lastID", "the messages must be in the same channel")
+	}
+	if last.CreatedAt.Before(first.CreatedAt) {
+		return nil, repository.ArgError("lastID", "the last message must not be older than the first message")
+	}
+	if first.ChannelID == channelID {
+		return nil, repository.ArgError("channelID", "the messages are already in the channel")
+	}
+	if err := repo.checkChannelNotArchived(first.ChannelID); err != nil {
+		return nil, err
+	}
+
+	from := first.ChannelID
+	parents := map[uuid.UUID]bool{}
+	for _, m := range repo.Messages {
+		if m.ChannelID == from && !m.IsThreadReply() && !m.CreatedAt.Before(first.CreatedAt) && !m.CreatedAt.After(last.CreatedAt) {
+			parents[m.ID] = true
+		}
+	}
+	ids := make([]uuid.UUID, 0)
+	for id, m := range repo.Messages {
+		if parents[id] || parents[m.ParentID] {
+			m.ChannelID = channelID
+			repo.Messages[id] = m
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
 }
 
-func (repo *TestRepository) GetUnreadMessagesByUserID(userID uuid.UUID) ([]*model.Message, error) {
-	result := make([]*model.Message, 0)
-	repo.MessageUnreadsLock.RLock()
+func (repo *TestRepository) GetMessageByID(messageID uuid.UUID) (*model.Message, error) {
 	repo.MessagesLock.RLock()
-	for uid, mMap := range repo.MessageUnreads {
-		if uid != userID {
-			continue
+	m, ok := repo.Messages[messageID]
+	if ok {
+		m.Thread = repo.getMessageThread(messageID)
+	}
+	repo.MessagesLock.RUnlock()
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	m.Stamps = make([]model.MessageStamp, 0)
+	return &m, nil
+}
+
+func (repo *TestRepository) GetMessagesByChannelID(channelID uuid.UUID, limit, offset int) ([]*model.Message, error) {
+	tmp := make([]*model.Message, 0)
+	repo.MessagesLock.RLock()
+	for _, v := range repo.Messages {
+		if v.ChannelID == channelID {
+			v := v
+			v.Stamps = make([]model.MessageStamp, 0)
+			tmp = append(tmp, &v)
 		}
-		for mid := range mMap {
-			m, ok := repo.Messages[mid]
-			if ok {
-				result = append(result, &m)
-			}
+	}
+	repo.MessagesLock.RUnlock()
+	sort.Slice(tmp, func(i, j int) bool {
+		return tmp[i].CreatedAt.After(tmp[j].CreatedAt)
+	})
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 {
+		limit = math.MaxInt32
+	}
+	result := make([]*model.Message, 0)
+	for i := offset; i < len(tmp) && i < offset+limit; i++ {
+		result = append(result, tmp[i])
+	}
+	return result, nil
+}
+
+func (repo *TestRepository) GetMessagesByUserID(userID uuid.UUID, limit, offset int) ([]*model.Message, error) {
+	tmp := make([]*model.Message, 0)
+	repo.MessagesLock.RLock()
+	for _, v := range repo.Messages {
+		if v.UserID == userID {
+			v := v
+			v.Stamps = make([]model.MessageStamp, 0)
+			tmp = append(tmp, &v)
 		}
 	}
 	repo.MessagesLock.RUnlock()
-	repo.MessageUnreadsLock.RUnlock()
-	sort.Slice(result, func(i, j int) bool {
-		return result[j].CreatedAt.After(result[i].CreatedAt)
+	sort.Slice(tmp, func(i, j int) bool {
+		return tmp[i].CreatedAt.After(tmp[j].CreatedAt)
 	})
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 {
+		limit = math.MaxInt32
+	}
+	result := make([]*model.Message, 0)
+	for i := offset; i < len(tmp) && i < offset+limit; i++ {
+		result = append(result, tmp[i])
+	}
 	return result, nil
 }
 
-func (repo *TestRepository) DeleteUnreadsByMessageID(messageID uuid.UUID) error {
-	if messageID == uuid.Nil {
-		return repository.ErrNilID
+func (repo *TestRepository) GetMessages(query repository.MessagesQuery) ([]*model.Message, bool, error) {
+	repo.MessagesLock.RLock()
+	defer repo.MessagesLock.RUnlock()
+
+	less := func(a, b *model.Message) bool {
+		if a.CreatedAt.Equal(b.CreatedAt) {
+			return a.ID.String() < b.ID.String()
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
 	}
-	repo.MessageUnreadsLock.Lock()
-	for _, mMap := range repo.MessageUnreads {
-		var deleted []uuid.UUID
-		for mid := range mMap {
-			if mid == messageID {
-				deleted = append(deleted, mid)
-			}
+	var before, after *model.Message
+	if query.Before != uuid.Nil {
+		m, ok := repo.Messages[query.Before]
+		if !ok {
+			return nil, false, repository.ArgError("cursor", "the message doesn't exist")
 		}
-		for _, v := range deleted {
-			delete(mMap, v)
+		if query.Channel != uuid.Nil && m.ChannelID != query.Channel {
+			return nil, false, repository.ArgError("cursor", "the message doesn't belong to the channel")
 		}
+		before = &m
 	}
-	repo.MessageUnreadsLock.Unlock()
-	return nil
+	if query.After != uuid.Nil {
+		m, ok := repo.Messages[query.After]
+		if !ok {
+			return nil, false, repository.ArgError("cursor", "the message doesn't exist")
+		}
+		if query.Channel != uuid.Nil && m.ChannelID != query.Channel {
+			return nil, false, repository.ArgError("cursor", "the message doesn't belong to the channel")
+		}
+		after = &m
+	}
+
+	tmp := make([]*model.Message, 0)
+	for _, v := range repo.Messages {
+		v := v
+		if query.Channel != uuid.Nil && v.ChannelID != query.Channel {
+			continue
+		}
+		if query.User != uuid.Nil && v.UserID != query.User {
+			continue
+		}
+		if query.Parent != uuid.Nil && v.ParentID != query.Parent {
+			continue
+		}
+		if query.Parent == uuid.Nil && query.ExcludeReplies && v.IsThreadReply() {
+			continue
+		}
+		if query.Since.Valid && v.CreatedAt.Before(query.Since.Time) {
+			continue
+		}
+		if query.Until.Valid && v.CreatedAt.After(query.Until.Time) {
+			continue
+		}
+		if before != nil && !less(&v, before) {
+			continue
+		}
+		if after != nil && !less(after, &v) {
+			continue
+		}
+		v.Stamps = make([]model.MessageStamp, 0)
+		tmp = append(tmp, &v)
+	}
+	sort.Slice(tmp, func(i, j int) bool {
+		if query.Asc {
+			return less(tmp[i], tmp[j])
+		}
+		return less(tmp[j], tmp[i])
+	})
+
+	offset := query.Offset
+	if offset < 0 {
+		offset = 0
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = math.MaxInt32
+	}
+	result := make([]*model.Message, 0)
+	for i := offset; i < len(tmp) && i < offset+limit; i++ {
+		result = append(result, tmp[i])
+	}
+	return result, offset+limit < len(tmp), nil
 }
 
-func (repo *TestRepository) DeleteUnreadsByChannelID(channelID, userID uuid.UUID) error {
-	if channelID == uuid.Nil || userID == uuid.Nil {
-		return repository.ErrNilID
+func (repo *TestRepository) SearchMessages(query repository.MessageSearchQuery) ([]*model.Message, bool, error) {
+	if query.Searcher == uuid.Nil {
+		return nil, false, repository.ErrNilID
+	}
+	contains := func(ids []uuid.UUID, id uuid.UUID) bool {
+		for _, v := range ids {
+			if v == id {
+				return true
+			}
+		}
+		return false
 	}
-	repo.MessageUnreadsLock.Lock()
+
+	tmp := make([]*model.Message, 0)
 	repo.MessagesLock.RLock()
-	for uid, mMap := range repo.MessageUnreads {
-		if uid != userID {
+	for _, v := range repo.Messages {
+		v := v
+		tmp = append(tmp, &v)
+	}
+	repo.MessagesLock.RUnlock()
+
+	result := make([]*model.Message, 0)
+	for _, v := range tmp {
+		if ok, _ := repo.IsChannelAccessibleToUser(query.Searcher, v.ChannelID); !ok {
 			continue
 		}
-		var deleted []uuid.UUID
-		for mid := range mMap {
-			m, ok := repo.Messages[mid]
-			if ok {
-				if m.ChannelID == channelID {
-					deleted = append(deleted, mid)
-				}
-			}
+		if len(query.Channels) > 0 && !contains(query.Channels, v.ChannelID) {
+			continue
+		}
+		if len(query.Users) > 0 && !contains(query.Users, v.UserID) {
+			continue
+		}
+		if query.Since.Valid && v.CreatedAt.Before(query.Since.Time) {
+			continue
 		}
-		for _, v := range deleted {
-			delete(mMap, v)
+		if query.Until.Valid && v.CreatedAt.After(query.Until.Time) {
+			continue
+		}
+		ok := true
+		for _, w := range query.Words {
+			ok = ok && strings.Contains(v.Text, w)
+		}
+		for _, id := range query.Mentions {
+			ok = ok && strings.Contains(v.Text, id.String())
+		}
+		if query.HasFile {
+			ok = ok && strings.Contains(v.Text, `"type":"file"`)
 		}
+		if !ok {
+			continue
+		}
+		v.Stamps = make([]model.MessageStamp, 0)
+		result = append(result, v)
 	}
-	repo.MessagesLock.RUnlock()
-	repo.MessageUnreadsLock.Unlock()
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+
+	offset := query.Offset
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > len(result) {
+		offset = len(result)
+	}
+	result = result[offset:]
+	if query.Limit > 0 && len(result) > query.Limit {
+		return result[:query.Limit], true, nil
+	}
+	return result, false, nil
+}
+
+func (repo *TestRepository) GetArchivedMessagesByID(messageID uuid.UUID) ([]*model.ArchivedMessage, error) {
+	repo.MessagesLock.RLock()
+	defer repo.MessagesLock.RUnlock()
+	result := make([]*model.ArchivedMessage, 0)
+	for _, v := range repo.ArchivedMessages[messageID] {
+		v := v
+		result = append(result, &v)
+	}
+	return result, nil
+}
+
+func (repo *TestRepository) SetMessageCreatedAt(messageID uuid.UUID, createdAt time.Time) error {
+	if messageID == uuid.Nil {
+		return repository.ErrNilID
+	}
+	repo.MessagesLock.Lock()
+	defer repo.MessagesLock.Unlock()
+	m, ok := repo.Messages[messageID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	m.CreatedAt = createdAt
+	m.UpdatedAt = createdAt
+	repo.Messages[messageID] = m
 	return nil
 }
 
@@ -1643,13 +2320,15 @@ func (repo *TestRepository) CreateMessageReport(messageID, reporterID uuid.UUID,
 		CreatedAt: time.Now(),
 	}
 	repo.MessageReportsLock.Lock()
-	defer repo.MessageReportsLock.Unlock()
 	for _, v := range repo.MessageReports {
 		if v.MessageID == messageID && v.Reporter == reporterID {
+			repo.MessageReportsLock.Unlock()
 			return repository.ErrAlreadyExists
 		}
 	}
 	repo.MessageReports = append(repo.MessageReports, report)
+	repo.MessageReportsLock.Unlock()
+	repo.openModerationCase(messageID, reporterID, reason)
 	return nil
 }
 
@@ -1698,11 +2377,20 @@ func (repo *TestRepository) GetMessageReportsByReporterID(reporterID uuid.UUID)
 }
 
 func (repo *TestRepository) AddStampToMessage(messageID, stampID, userID uuid.UUID) (ms *model.MessageStamp, err error) {
-	panic("implement me")
+	if messageID == uuid.Nil || stampID == uuid.Nil || userID == uuid.Nil {
+		return nil, repository.ErrNilID
+	}
+	if err := repo.checkMessageChannelNotArchived(messageID); err != nil {
+		return nil, err
+	}
+	return &model.MessageStamp{MessageID: messageID, StampID: stampID, UserID: userID, Count: 1, CreatedAt: time.Now(), UpdatedAt: time.Now()}, nil
 }
 
 func (repo *TestRepository) RemoveStampFromMessage(messageID, stampID, userID uuid.UUID) (err error) {
-	panic("implement me")
+	if messageID == uuid.Nil || stampID == uuid.Nil || userID == uuid.Nil {
+		return repository.ErrNilID
+	}
+	return repo.checkMessageChannelNotArchived(messageID)
 }
 
 func (repo *TestRepository) GetMessageStamps(messageID uuid.UUID) (stamps []*model.MessageStamp, err error) {
@@ -2014,6 +2702,9 @@ func (repo *TestRepository) CreatePin(messageID, userID uuid.UUID) (uuid.UUID, e
 	if messageID == uuid.Nil || userID == uuid.Nil {
 		return uuid.Nil, repository.ErrNilID
 	}
+	if err := repo.checkMessageChannelNotArchived(messageID); err != nil {
+		return uuid.Nil, err
+	}
 	repo.PinsLock.Lock()
 	defer repo.PinsLock.Unlock()
 	for _, pin := range repo.Pins {
@@ -2060,8 +2751,13 @@ func (repo *TestRepository) DeletePin(id uuid.UUID) error {
 		return repository.ErrNilID
 	}
 	repo.PinsLock.Lock()
+	defer repo.PinsLock.Unlock()
+	if pin, ok := repo.Pins[id]; ok {
+		if err := repo.checkMessageChannelNotArchived(pin.MessageID); err != nil {
+			return err
+		}
+	}
 	delete(repo.Pins, id)
-	repo.PinsLock.Unlock()
 	return nil
 }
 
@@ -2238,6 +2934,25 @@ func (repo *TestRepository) SaveFileWithACL(name string, src io.Reader, size int
 	return f, nil
 }
 
+func (repo *TestRepository) SaveChannelFile(name string, src io.Reader, size int64, mimeType string, fType string, creatorID, channelID uuid.UUID) (*model.File, error) {
+	repo.ChannelsLock.RLock()
+	_, ok := repo.Channels[channelID]
+	repo.ChannelsLock.RUnlock()
+	if !ok {
+		return nil, repository.ArgError("channelID", "the Channel is not found")
+	}
+
+	f, err := repo.SaveFileWithACL(name, src, size, mimeType, fType, creatorID, repository.ACL{})
+	if err != nil {
+		return nil, err
+	}
+	f.ChannelID = channelID
+	repo.FilesLock.Lock()
+	repo.Files[f.ID] = *f
+	repo.FilesLock.Unlock()
+	return f, nil
+}
+
 func (repo *TestRepository) RegenerateThumbnail(fileID uuid.UUID) (bool, error) {
 	return false, nil
 }
@@ -2247,12 +2962,25 @@ func (repo *TestRepository) IsFileAccessible(fileID, userID uuid.UUID) (bool, er
 		return false, repository.ErrNilID
 	}
 	repo.FilesLock.RLock()
-	_, ok := repo.Files[fileID]
+	f, ok := repo.Files[fileID]
 	repo.FilesLock.RUnlock()
 	if !ok {
 		return false, repository.ErrNotFound
 	}
 
+	if f.ChannelID != uuid.Nil {
+		if userID != uuid.Nil && userID == f.CreatorID {
+			return true, nil
+		}
+		if userID == uuid.Nil {
+			repo.ChannelsLock.RLock()
+			ch, ok := repo.Channels[f.ChannelID]
+			repo.ChannelsLock.RUnlock()
+			return ok && ch.IsPublic, nil
+		}
+		return repo.IsChannelAccessibleToUser(userID, f.ChannelID)
+	}
+
 	var allow bool
 	repo.FilesACLLock.RLock()
 	defer repo.FilesACLLock.RUnlock()
@@ -2809,3 +3537,1152 @@ func (repo *TestRepository) RemoveBotFromChannel(botID, channelID uuid.UUID) err
 func (repo *TestRepository) GetParticipatingChannelIDsByBot(botID uuid.UUID) ([]uuid.UUID, error) {
 	panic("implement me")
 }
+
+func (repo *TestRepository) CreateScheduledMessage(userID, channelID uuid.UUID, text string, scheduledAt time.Time) (*model.ScheduledMessage, error) {
+	if userID == uuid.Nil || channelID == uuid.Nil {
+		return nil, repository.ErrNilID
+	}
+	m := model.ScheduledMessage{
+		ID:          uuid.Must(uuid.NewV4()),
+		UserID:      userID,
+		ChannelID:   channelID,
+		Text:        text,
+		ScheduledAt: scheduledAt,
+		State:       model.ScheduledMessagePending,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	repo.ScheduledMessagesLock.Lock()
+	repo.ScheduledMessages[m.ID] = m
+	repo.ScheduledMessagesLock.Unlock()
+	return &m, nil
+}
+
+func (repo *TestRepository) UpdateScheduledMessage(id uuid.UUID, args repository.UpdateScheduledMessageArgs) error {
+	if id == uuid.Nil {
+		return repository.ErrNilID
+	}
+	repo.ScheduledMessagesLock.Lock()
+	defer repo.ScheduledMessagesLock.Unlock()
+	m, ok := repo.ScheduledMessages[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	if m.State != model.ScheduledMessagePending {
+		return repository.ErrForbidden
+	}
+	if args.Text.Valid {
+		m.Text = args.Text.String
+	}
+	if args.ScheduledAt.Valid {
+		m.ScheduledAt = args.ScheduledAt.Time
+	}
+	m.UpdatedAt = time.Now()
+	repo.ScheduledMessages[id] = m
+	return nil
+}
+
+func (repo *TestRepository) DeleteScheduledMessage(id uuid.UUID) error {
+	if id == uuid.Nil {
+		return repository.ErrNilID
+	}
+	repo.ScheduledMessagesLock.Lock()
+	defer repo.ScheduledMessagesLock.Unlock()
+	m, ok := repo.ScheduledMessages[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	if m.State == model.ScheduledMessageSending {
+		return repository.ErrForbidden
+	}
+	delete(repo.ScheduledMessages, id)
+	return nil
+}
+
+func (repo *TestRepository) GetScheduledMessage(id uuid.UUID) (*model.ScheduledMessage, error) {
+	repo.ScheduledMessagesLock.RLock()
+	defer repo.ScheduledMessagesLock.RUnlock()
+	m, ok := repo.ScheduledMessages[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &m, nil
+}
+
+func (repo *TestRepository) GetScheduledMessagesByUserID(userID uuid.UUID) ([]*model.ScheduledMessage, error) {
+	result := make([]*model.ScheduledMessage, 0)
+	repo.ScheduledMessagesLock.RLock()
+	for _, v := range repo.ScheduledMessages {
+		if v.UserID == userID {
+			v := v
+			result = append(result, &v)
+		}
+	}
+	repo.ScheduledMessagesLock.RUnlock()
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ScheduledAt.Before(result[j].ScheduledAt)
+	})
+	return result, nil
+}
+
+func (repo *TestRepository) GetDueScheduledMessages(now time.Time, limit int) ([]*model.ScheduledMessage, error) {
+	panic("implement me")
+}
+
+func (repo *TestRepository) ClaimScheduledMessage(id uuid.UUID) (bool, error) {
+	panic("implement me")
+}
+
+func (repo *TestRepository) MarkScheduledMessageSent(id, messageID uuid.UUID) error {
+	panic("implement me")
+}
+
+func (repo *TestRepository) MarkScheduledMessageFailed(id uuid.UUID, reason string) error {
+	panic("implement me")
+}
+
+func (repo *TestRepository) FailStaleScheduledMessages(before time.Time) (int, error) {
+	panic("implement me")
+}
+
+func (repo *TestRepository) CreateChannelExport(channelID, userID uuid.UUID, withDescendants bool) (*model.ChannelExport, error) {
+	if channelID == uuid.Nil || userID == uuid.Nil {
+		return nil, repository.ErrNilID
+	}
+	e := model.ChannelExport{
+		ID:              uuid.Must(uuid.NewV4()),
+		ChannelID:       channelID,
+		UserID:          userID,
+		WithDescendants: withDescendants,
+		State:           model.ChannelExportPending,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	}
+	repo.ChannelExportsLock.Lock()
+	repo.ChannelExports[e.ID] = e
+	repo.ChannelExportsLock.Unlock()
+	return &e, nil
+}
+
+func (repo *TestRepository) GetChannelExport(id uuid.UUID) (*model.ChannelExport, error) {
+	repo.ChannelExportsLock.RLock()
+	defer repo.ChannelExportsLock.RUnlock()
+	e, ok := repo.ChannelExports[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &e, nil
+}
+
+func (repo *TestRepository) GetPendingChannelExports(limit int) ([]*model.ChannelExport, error) {
+	panic("implement me")
+}
+
+func (repo *TestRepository) ClaimChannelExport(id uuid.UUID) (bool, error) {
+	if id == uuid.Nil {
+		return false, repository.ErrNilID
+	}
+	repo.ChannelExportsLock.Lock()
+	defer repo.ChannelExportsLock.Unlock()
+	e, ok := repo.ChannelExports[id]
+	if !ok || e.State != model.ChannelExportPending {
+		return false, nil
+	}
+	e.State = model.ChannelExportRunning
+	e.UpdatedAt = time.Now()
+	repo.ChannelExports[id] = e
+	return true, nil
+}
+
+func (repo *TestRepository) MarkChannelExportCompleted(id, fileID uuid.UUID) error {
+	if id == uuid.Nil || fileID == uuid.Nil {
+		return repository.ErrNilID
+	}
+	repo.ChannelExportsLock.Lock()
+	defer repo.ChannelExportsLock.Unlock()
+	e, ok := repo.ChannelExports[id]
+	if !ok || e.State != model.ChannelExportRunning {
+		return nil
+	}
+	e.State = model.ChannelExportCompleted
+	e.FileID = fileID
+	e.UpdatedAt = time.Now()
+	repo.ChannelExports[id] = e
+	return nil
+}
+
+func (repo *TestRepository) MarkChannelExportFailed(id uuid.UUID, reason string) error {
+	if id == uuid.Nil {
+		return repository.ErrNilID
+	}
+	repo.ChannelExportsLock.Lock()
+	defer repo.ChannelExportsLock.Unlock()
+	e, ok := repo.ChannelExports[id]
+	if !ok || e.State != model.ChannelExportRunning {
+		return nil
+	}
+	e.State = model.ChannelExportFailed
+	e.Error = reason
+	e.UpdatedAt = time.Now()
+	repo.ChannelExports[id] = e
+	return nil
+}
+
+func (repo *TestRepository) FailStaleChannelExports(before time.Time) (int, error) {
+	panic("implement me")
+}
+
+func (repo *TestRepository) SetChannelRetentionPolicy(channelID uuid.UUID, days int, exemptPinned, exemptClipped bool) error {
+	if channelID == uuid.Nil {
+		return repository.ErrNilID
+	}
+	if days < 0 {
+		return repository.ArgError("days", "Days must be non-negative")
+	}
+	repo.ChannelsLock.RLock()
+	_, ok := repo.Channels[channelID]
+	repo.ChannelsLock.RUnlock()
+	if !ok {
+		return repository.ErrNotFound
+	}
+	repo.RetentionPoliciesLock.Lock()
+	repo.RetentionPolicies[channelID] = model.ChannelRetentionPolicy{
+		ChannelID:     channelID,
+		Days:          days,
+		ExemptPinned:  exemptPinned,
+		ExemptClipped: exemptClipped,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	repo.RetentionPoliciesLock.Unlock()
+	return nil
+}
+
+func (repo *TestRepository) DeleteChannelRetentionPolicy(channelID uuid.UUID) error {
+	if channelID == uuid.Nil {
+		return repository.ErrNilID
+	}
+	repo.RetentionPoliciesLock.Lock()
+	delete(repo.RetentionPolicies, channelID)
+	repo.RetentionPoliciesLock.Unlock()
+	return nil
+}
+
+func (repo *TestRepository) GetChannelRetentionPolicy(channelID uuid.UUID) (*model.ChannelRetentionPolicy, error) {
+	repo.RetentionPoliciesLock.RLock()
+	defer repo.RetentionPoliciesLock.RUnlock()
+	p, ok := repo.RetentionPolicies[channelID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &p, nil
+}
+
+func (repo *TestRepository) GetEffectiveChannelRetentionPolicy(channelID uuid.UUID) (*model.ChannelRetentionPolicy, error) {
+	for id := channelID; id != uuid.Nil; {
+		if p, err := repo.GetChannelRetentionPolicy(id); err == nil {
+			return p, nil
+		}
+		repo.ChannelsLock.RLock()
+		ch, ok := repo.Channels[id]
+		repo.ChannelsLock.RUnlock()
+		if !ok {
+			break
+		}
+		id = ch.ParentID
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (repo *TestRepository) GetRetentionTargetChannels() (map[uuid.UUID]*model.ChannelRetentionPolicy, error) {
+	panic("implement me")
+}
+
+func (repo *TestRepository) GetExpiredMessageIDs(channelID uuid.UUID, before time.Time, exemptPinned, exemptClipped bool, limit int) ([]uuid.UUID, error) {
+	panic("implement me")
+}
+
+func (repo *TestRepository) SaveLinkPreview(preview *model.LinkPreview) error {
+	if preview == nil || len(preview.URL) == 0 {
+		return repository.ArgError("preview", "URL is required")
+	}
+	preview.URLHash = model.LinkPreviewURLHash(preview.URL)
+	repo.LinkPreviewsLock.Lock()
+	repo.LinkPreviews[preview.URLHash] = *preview
+	repo.LinkPreviewsLock.Unlock()
+	return nil
+}
+
+func (repo *TestRepository) GetLinkPreview(url string) (*model.LinkPreview, error) {
+	repo.LinkPreviewsLock.RLock()
+	defer repo.LinkPreviewsLock.RUnlock()
+	p, ok := repo.LinkPreviews[model.LinkPreviewURLHash(url)]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &p, nil
+}
+
+func (repo *TestRepository) GetLinkPreviews(urls []string) ([]*model.LinkPreview, error) {
+	result := make([]*model.LinkPreview, 0, len(urls))
+	repo.LinkPreviewsLock.RLock()
+	defer repo.LinkPreviewsLock.RUnlock()
+	for _, u := range urls {
+		if p, ok := repo.LinkPreviews[model.LinkPreviewURLHash(u)]; ok {
+			result = append(result, &p)
+		}
+	}
+	return result, nil
+}
+
+func (repo *TestRepository) ReadChannel(userID, channelID uuid.UUID) error {
+	if userID == uuid.Nil || channelID == uuid.Nil {
+		return repository.ErrNilID
+	}
+	now := time.Now()
+	repo.ReadPointersLock.Lock()
+	pMap, ok := repo.ReadPointers[userID]
+	if !ok {
+		pMap = make(map[uuid.UUID]model.ChannelReadPointer)
+		repo.ReadPointers[userID] = pMap
+	}
+	pMap[channelID] = model.ChannelReadPointer{
+		UserID:     userID,
+		ChannelID:  channelID,
+		LastReadAt: &now,
+		UpdatedAt:  now,
+	}
+	repo.ReadPointersLock.Unlock()
+	return nil
+}
+
+func (repo *TestRepository) UpdateReadPointers(channelID, messageID uuid.UUID, readAt time.Time, userIDs []uuid.UUID) error {
+	if channelID == uuid.Nil {
+		return repository.ErrNilID
+	}
+	repo.ReadPointersLock.Lock()
+	defer repo.ReadPointersLock.Unlock()
+	for _, id := range userIDs {
+		if id == uuid.Nil {
+			return repository.ErrNilID
+		}
+		pMap, ok := repo.ReadPointers[id]
+		if !ok {
+			pMap = make(map[uuid.UUID]model.ChannelReadPointer)
+			repo.ReadPointers[id] = pMap
+		}
+		p := pMap[channelID]
+		p.UserID = id
+		p.ChannelID = channelID
+		if p.LastReadAt == nil || p.LastReadAt.Before(readAt) {
+			t := readAt
+			p.LastReadAt = &t
+			p.LastReadMessageID = messageID
+		}
+		p.UpdatedAt = time.Now()
+		pMap[channelID] = p
+	}
+	return nil
+}
+
+func (repo *TestRepository) IncrementUnreadCounters(channelID uuid.UUID, notifiedAt time.Time, users map[uuid.UUID]bool) error {
+	if channelID == uuid.Nil {
+		return repository.ErrNilID
+	}
+	repo.ReadPointersLock.Lock()
+	defer repo.ReadPointersLock.Unlock()
+	for id, mentioned := range users {
+		if id == uuid.Nil {
+			return repository.ErrNilID
+		}
+		pMap, ok := repo.ReadPointers[id]
+		if !ok {
+			pMap = make(map[uuid.UUID]model.ChannelReadPointer)
+			repo.ReadPointers[id] = pMap
+		}
+		p := pMap[channelID]
+		p.UserID = id
+		p.ChannelID = channelID
+		if mentioned {
+			p.MentionCount++
+		}
+		t := notifiedAt
+		if p.NotifiedCount == 0 {
+			p.FirstNotifiedAt = &t
+		}
+		p.NotifiedCount++
+		p.LastNotifiedAt = &t
+		p.UpdatedAt = time.Now()
+		pMap[channelID] = p
+	}
+	return nil
+}
+
+func (repo *TestRepository) GetReadPointer(userID, channelID uuid.UUID) (*model.ChannelReadPointer, error) {
+	repo.ReadPointersLock.RLock()
+	defer repo.ReadPointersLock.RUnlock()
+	p, ok := repo.ReadPointers[userID][channelID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &p, nil
+}
+
+func (repo *TestRepository) GetUserUnreadChannels(userID uuid.UUID) ([]*repository.UserUnreadChannel, error) {
+	result := make([]*repository.UserUnreadChannel, 0)
+	repo.ReadPointersLock.RLock()
+	for _, p := range repo.ReadPointers[userID] {
+		if p.NotifiedCount == 0 {
+			continue
+		}
+		result = append(result, &repository.UserUnreadChannel{
+			ChannelID:  p.ChannelID,
+			Count:      p.NotifiedCount,
+			Noticeable: p.MentionCount > 0,
+			Since:      *p.FirstNotifiedAt,
+			UpdatedAt:  *p.LastNotifiedAt,
+		})
+	}
+	repo.ReadPointersLock.RUnlock()
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].UpdatedAt.After(result[j].UpdatedAt)
+	})
+	return result, nil
+}
+
+func (repo *TestRepository) CreatePoll(args repository.CreatePollArgs) (*model.Poll, error) {
+	if args.ChannelID == uuid.Nil || args.CreatorID == uuid.Nil {
+		return nil, repository.ErrNilID
+	}
+	if err := repo.checkChannelNotArchived(args.ChannelID); err != nil {
+		return nil, err
+	}
+	filtered, err := repo.applyContentFilter(args.ChannelID, args.Question)
+	if err != nil {
+		return nil, err
+	}
+	args.Question = filtered.Text
+	options := make([]string, len(args.Options))
+	for i, label := range args.Options {
+		filtered, err := repo.applyContentFilter(args.ChannelID, label)
+		if err != nil {
+			return nil, err
+		}
+		options[i] = filtered.Text
+	}
+	args.Options = options
+	if len(args.Question) == 0 {
+		return nil, repository.ArgError("args.Question", "Question must be non-empty and shorter than 1001 characters")
+	}
+	if len(args.Options) < 2 || len(args.Options) > 20 {
+		return nil, repository.ArgError("args.Options", "the number of Options must be between 2 and 20")
+	}
+	if args.Deadline.Valid && !args.Deadline.Time.After(time.Now()) {
+		return nil, repository.ArgError("args.Deadline", "Deadline must be in the future")
+	}
+	p := model.Poll{
+		ID:             uuid.Must(uuid.NewV4()),
+		ChannelID:      args.ChannelID,
+		CreatorID:      args.CreatorID,
+		Question:       args.Question,
+		MultipleChoice: args.MultipleChoice,
+		Anonymous:      args.Anonymous,
+		Deadline:       args.Deadline.Ptr(),
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+	labels := map[string]bool{}
+	for i, label := range args.Options {
+		if len(label) == 0 || labels[label] {
+			return nil, repository.ArgError("args.Options", "invalid Options")
+		}
+		labels[label] = true
+		p.Options = append(p.Options, &model.PollOption{ID: uuid.Must(uuid.NewV4()), PollID: p.ID, Label: label, Position: i})
+	}
+	repo.PollsLock.Lock()
+	repo.Polls[p.ID] = p
+	repo.PollsLock.Unlock()
+	return &p, nil
+}
+
+func (repo *TestRepository) DeletePoll(id uuid.UUID) error {
+	if id == uuid.Nil {
+		return repository.ErrNilID
+	}
+	repo.PollsLock.Lock()
+	defer repo.PollsLock.Unlock()
+	if _, ok := repo.Polls[id]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(repo.Polls, id)
+	delete(repo.PollVotes, id)
+	return nil
+}
+
+func (repo *TestRepository) AttachPollToMessage(pollID, messageID uuid.UUID) error {
+	if pollID == uuid.Nil || messageID == uuid.Nil {
+		return repository.ErrNilID
+	}
+	repo.PollsLock.Lock()
+	defer repo.PollsLock.Unlock()
+	p, ok := repo.Polls[pollID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	p.MessageID = messageID
+	repo.Polls[pollID] = p
+	return nil
+}
+
+func (repo *TestRepository) GetPoll(id uuid.UUID) (*model.Poll, error) {
+	repo.PollsLock.RLock()
+	defer repo.PollsLock.RUnlock()
+	p, ok := repo.Polls[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &p, nil
+}
+
+func (repo *TestRepository) GetPollVotes(pollID uuid.UUID) ([]*model.PollVote, error) {
+	result := make([]*model.PollVote, 0)
+	repo.PollsLock.RLock()
+	for _, v := range repo.PollVotes[pollID] {
+		v := v
+		result = append(result, &v)
+	}
+	repo.PollsLock.RUnlock()
+	return result, nil
+}
+
+func (repo *TestRepository) VotePoll(pollID, userID uuid.UUID, optionIDs []uuid.UUID) error {
+	if pollID == uuid.Nil || userID == uuid.Nil {
+		return repository.ErrNilID
+	}
+	if len(optionIDs) == 0 {
+		return repository.ArgError("optionIDs", "at least one option is required")
+	}
+	repo.PollsLock.Lock()
+	defer repo.PollsLock.Unlock()
+	p, ok := repo.Polls[pollID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	if p.IsClosed(time.Now()) {
+		return repository.ErrForbidden
+	}
+	if err := repo.checkChannelNotArchived(p.ChannelID); err != nil {
+		return err
+	}
+	valid := map[uuid.UUID]bool{}
+	for _, o := range p.Options {
+		valid[o.ID] = true
+	}
+	selected := map[uuid.UUID]bool{}
+	for _, id := range optionIDs {
+		if !valid[id] {
+			return repository.ArgError("optionIDs", "invalid option")
+		}
+		selected[id] = true
+	}
+	if !p.MultipleChoice && len(selected) > 1 {
+		return repository.ArgError("optionIDs", "the poll accepts only one option")
+	}
+	votes := make([]model.PollVote, 0)
+	for _, v := range repo.PollVotes[pollID] {
+		if v.UserID != userID {
+			votes = append(votes, v)
+		}
+	}
+	for _, o := range p.Options {
+		if selected[o.ID] {
+			votes = append(votes, model.PollVote{PollID: pollID, UserID: userID, OptionID: o.ID, CreatedAt: time.Now()})
+		}
+	}
+	repo.PollVotes[pollID] = votes
+	return nil
+}
+
+func (repo *TestRepository) RetractPollVote(pollID, userID uuid.UUID) error {
+	if pollID == uuid.Nil || userID == uuid.Nil {
+		return repository.ErrNilID
+	}
+	repo.PollsLock.Lock()
+	defer repo.PollsLock.Unlock()
+	p, ok := repo.Polls[pollID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	if p.IsClosed(time.Now()) {
+		return repository.ErrForbidden
+	}
+	if err := repo.checkChannelNotArchived(p.ChannelID); err != nil {
+		return err
+	}
+	votes := make([]model.PollVote, 0)
+	for _, v := range repo.PollVotes[pollID] {
+		if v.UserID != userID {
+			votes = append(votes, v)
+		}
+	}
+	repo.PollVotes[pollID] = votes
+	return nil
+}
+
+func (repo *TestRepository) CreateContentFilterRule(args repository.CreateContentFilterRuleArgs) (*model.ContentFilterRule, error) {
+	if args.CreatorID == uuid.Nil {
+		return nil, repository.ErrNilID
+	}
+	r := model.ContentFilterRule{
+		ID:          uuid.Must(uuid.NewV4()),
+		Pattern:     args.Pattern,
+		IsRegex:     args.IsRegex,
+		ChannelID:   args.ChannelID,
+		Action:      args.Action,
+		Description: args.Description,
+		CreatorID:   args.CreatorID,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if _, err := contentfilter.Compile(&r); err != nil || len(r.Pattern) == 0 {
+		return nil, repository.ArgError("args.Pattern", "invalid Pattern")
+	}
+	if !r.Action.Valid() {
+		return nil, repository.ArgError("args.Action", "invalid Action")
+	}
+	if !r.IsGlobal() {
+		if _, err := repo.GetChannel(r.ChannelID); err != nil {
+			return nil, repository.ArgError("args.ChannelID", "the Channel is not found")
+		}
+	}
+	repo.ContentFilterRulesLock.Lock()
+	repo.ContentFilterRules[r.ID] = r
+	repo.ContentFilterRulesLock.Unlock()
+	return &r, nil
+}
+
+func (repo *TestRepository) UpdateContentFilterRule(id uuid.UUID, args repository.UpdateContentFilterRuleArgs) error {
+	if id == uuid.Nil {
+		return repository.ErrNilID
+	}
+	repo.ContentFilterRulesLock.Lock()
+	defer repo.ContentFilterRulesLock.Unlock()
+	r, ok := repo.ContentFilterRules[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	if args.Pattern.Valid {
+		r.Pattern = args.Pattern.String
+	}
+	if args.IsRegex.Valid {
+		r.IsRegex = args.IsRegex.Bool
+	}
+	if args.Action.Valid {
+		r.Action = model.ContentFilterAction(args.Action.String)
+	}
+	if args.Description.Valid {
+		r.Description = args.Description.String
+	}
+	if _, err := contentfilter.Compile(&r); err != nil || len(r.Pattern) == 0 {
+		return repository.ArgError("args.Pattern", "invalid Pattern")
+	}
+	if !r.Action.Valid() {
+		return repository.ArgError("args.Action", "invalid Action")
+	}
+	r.UpdatedAt = time.Now()
+	repo.ContentFilterRules[id] = r
+	return nil
+}
+
+func (repo *TestRepository) DeleteContentFilterRule(id uuid.UUID) error {
+	if id == uuid.Nil {
+		return repository.ErrNilID
+	}
+	repo.ContentFilterRulesLock.Lock()
+	defer repo.ContentFilterRulesLock.Unlock()
+	if _, ok := repo.ContentFilterRules[id]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(repo.ContentFilterRules, id)
+	return nil
+}
+
+func (repo *TestRepository) GetContentFilterRule(id uuid.UUID) (*model.ContentFilterRule, error) {
+	repo.ContentFilterRulesLock.RLock()
+	defer repo.ContentFilterRulesLock.RUnlock()
+	r, ok := repo.ContentFilterRules[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &r, nil
+}
+
+func (repo *TestRepository) GetContentFilterRules() ([]*model.ContentFilterRule, error) {
+	result := make([]*model.ContentFilterRule, 0)
+	repo.ContentFilterRulesLock.RLock()
+	for _, r := range repo.ContentFilterRules {
+		r := r
+		result = append(result, &r)
+	}
+	repo.ContentFilterRulesLock.RUnlock()
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result, nil
+}
+
+func (repo *TestRepository) ApplyContentFilter(channelID uuid.UUID, text string) (string, error) {
+	if channelID == uuid.Nil {
+		return "", repository.ErrNilID
+	}
+	res, err := repo.applyContentFilter(channelID, text)
+	if err != nil {
+		return "", err
+	}
+	return res.Text, nil
+}
+
+func (repo *TestRepository) applyContentFilter(channelID uuid.UUID, text string) (*contentfilter.Result, error) {
+	rules, _ := repo.GetContentFilterRules()
+	channels := []uuid.UUID{channelID}
+	repo.ChannelsLock.RLock()
+	for ch, ok := repo.Channels[channelID]; ok && ch.ParentID != uuid.Nil; ch, ok = repo.Channels[ch.ParentID] {
+		channels = append(channels, ch.ParentID)
+	}
+	repo.ChannelsLock.RUnlock()
+
+	res := contentfilter.New(rules).Apply(text, channels)
+	if res.RejectedBy != nil {
+		return nil, &repository.ContentFilterError{RuleID: res.RejectedBy.ID}
+	}
+	return res, nil
+}
+
+func (repo *TestRepository) reportFlaggedMessage(messageID uuid.UUID, rules []*model.ContentFilterRule) {
+	if len(rules) == 0 {
+		return
+	}
+	reasons := make([]string, len(rules))
+	for i, r := range rules {
+		reasons[i] = "content filter rule " + r.ID.String()
+	}
+	repo.MessageReportsLock.Lock()
+	repo.MessageReports = append(repo.MessageReports, model.MessageReport{
+		ID:        uuid.Must(uuid.NewV4()),
+		MessageID: messageID,
+		Reporter:  uuid.Nil,
+		Reason:    strings.Join(reasons, "\n"),
+		CreatedAt: time.Now(),
+	})
+	repo.MessageReportsLock.Unlock()
+	repo.openModerationCase(messageID, uuid.Nil, strings.Join(reasons, "\n"))
+}
+
+func (repo *TestRepository) GetModerationCases(state model.ModerationCaseState, offset, limit int) ([]*model.ModerationCase, error) {
+	result := make([]*model.ModerationCase, 0)
+	repo.ModerationLock.RLock()
+	for _, c := range repo.ModerationCases {
+		c := c
+		if len(state) == 0 || c.State == state {
+			result = append(result, &c)
+		}
+	}
+	repo.ModerationLock.RUnlock()
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].UpdatedAt.After(result[j].UpdatedAt)
+	})
+	if offset >= len(result) {
+		return []*model.ModerationCase{}, nil
+	}
+	result = result[offset:]
+	if limit > 0 && limit < len(result) {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (repo *TestRepository) GetModerationCase(id uuid.UUID) (*model.ModerationCase, error) {
+	repo.ModerationLock.RLock()
+	defer repo.ModerationLock.RUnlock()
+	c, ok := repo.ModerationCases[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &c, nil
+}
+
+func (repo *TestRepository) GetModerationCaseByMessageID(messageID uuid.UUID) (*model.ModerationCase, error) {
+	repo.ModerationLock.RLock()
+	defer repo.ModerationLock.RUnlock()
+	for _, c := range repo.ModerationCases {
+		if c.MessageID == messageID {
+			return &c, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (repo *TestRepository) UpdateModerationCase(id, actorID uuid.UUID, args repository.UpdateModerationCaseArgs) error {
+	if id == uuid.Nil || actorID == uuid.Nil {
+		return repository.ErrNilID
+	}
+	if args.State.Valid && !model.ModerationCaseState(args.State.String).Valid() {
+		return repository.ArgError("args.State", "invalid State")
+	}
+	if args.AssigneeID.Valid && args.AssigneeID.UUID != uuid.Nil {
+		if _, err := repo.GetUser(args.AssigneeID.UUID); err != nil {
+			return repository.ArgError("args.AssigneeID", "the User is not found")
+		}
+	}
+	repo.ModerationLock.Lock()
+	defer repo.ModerationLock.Unlock()
+	c, ok := repo.ModerationCases[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	if args.State.Valid && model.ModerationCaseState(args.State.String) != c.State {
+		c.State = model.ModerationCaseState(args.State.String)
+		repo.appendModerationLog(id, actorID, model.ModerationLogStateChanged, args.State.String)
+	}
+	if args.AssigneeID.Valid && args.AssigneeID.UUID != c.AssigneeID {
+		c.AssigneeID = args.AssigneeID.UUID
+		repo.appendModerationLog(id, actorID, model.ModerationLogAssigned, args.AssigneeID.UUID.String())
+	}
+	c.UpdatedAt = time.Now()
+	repo.ModerationCases[id] = c
+	return nil
+}
+
+func (repo *TestRepository) AddModerationNote(caseID, actorID uuid.UUID, note string) (*model.ModerationLog, error) {
+	if caseID == uuid.Nil || actorID == uuid.Nil {
+		return nil, repository.ErrNilID
+	}
+	if len(note) == 0 {
+		return nil, repository.ArgError("note", "note is required")
+	}
+	repo.ModerationLock.Lock()
+	defer repo.ModerationLock.Unlock()
+	c, ok := repo.ModerationCases[caseID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	l := repo.appendModerationLog(caseID, actorID, model.ModerationLogNote, note)
+	c.UpdatedAt = time.Now()
+	repo.ModerationCases[caseID] = c
+	return &l, nil
+}
+
+func (repo *TestRepository) TakeModerationAction(caseID, actorID uuid.UUID, action model.ModerationActionType, comment string) error {
+	if caseID == uuid.Nil || actorID == uuid.Nil {
+		return repository.ErrNilID
+	}
+	if !action.Valid() {
+		return repository.ArgError("action", "invalid action")
+	}
+	c, err := repo.GetModerationCase(caseID)
+	if err != nil {
+		return err
+	}
+	m, err := repo.GetMessageByID(c.MessageID)
+	if err != nil {
+		if action == model.ModerationActionDeleteMessage {
+			return repository.ArgError("action", "the message has already been deleted")
+		}
+		return err
+	}
+
+	switch action {
+	case model.ModerationActionDeleteMessage:
+		if err := repo.DeleteMessage(m.ID); err != nil {
+			return err
+		}
+	case model.ModerationActionSuspendUser:
+		if err := repo.ChangeUserAccountStatus(m.UserID, model.UserAccountStatusSuspended); err != nil {
+			return err
+		}
+	}
+
+	detail := string(action)
+	if len(comment) > 0 {
+		detail += ": " + comment
+	}
+	repo.ModerationLock.Lock()
+	defer repo.ModerationLock.Unlock()
+	repo.appendModerationLog(caseID, actorID, model.ModerationLogAction, detail)
+	if c.State != model.ModerationCaseStateResolved {
+		repo.appendModerationLog(caseID, actorID, model.ModerationLogStateChanged, string(model.ModerationCaseStateResolved))
+	}
+	c.State = model.ModerationCaseStateResolved
+	c.UpdatedAt = time.Now()
+	repo.ModerationCases[caseID] = *c
+	return nil
+}
+
+func (repo *TestRepository) GetModerationLogs(caseID uuid.UUID) ([]*model.ModerationLog, error) {
+	result := make([]*model.ModerationLog, 0)
+	repo.ModerationLock.RLock()
+	defer repo.ModerationLock.RUnlock()
+	for _, l := range repo.ModerationLogs {
+		l := l
+		if l.CaseID == caseID {
+			result = append(result, &l)
+		}
+	}
+	return result, nil
+}
+
+func (repo *TestRepository) openModerationCase(messageID, reporterID uuid.UUID, reason string) {
+	repo.ModerationLock.Lock()
+	defer repo.ModerationLock.Unlock()
+	var c model.ModerationCase
+	found := false
+	for _, v := range repo.ModerationCases {
+		if v.MessageID == messageID {
+			c, found = v, true
+			break
+		}
+	}
+	if !found {
+		c = model.ModerationCase{
+			ID:        uuid.Must(uuid.NewV4()),
+			MessageID: messageID,
+			State:     model.ModerationCaseStateOpen,
+			CreatedAt: time.Now(),
+		}
+	} else if c.State.IsClosed() {
+		c.State = model.ModerationCaseStateOpen
+		repo.appendModerationLog(c.ID, uuid.Nil, model.ModerationLogStateChanged, string(model.ModerationCaseStateOpen))
+	}
+	c.UpdatedAt = time.Now()
+	repo.ModerationCases[c.ID] = c
+	repo.appendModerationLog(c.ID, reporterID, model.ModerationLogReported, reason)
+}
+
+func (repo *TestRepository) appendModerationLog(caseID, actorID uuid.UUID, logType model.ModerationLogType, detail string) model.ModerationLog {
+	l := model.ModerationLog{
+		ID:        uuid.Must(uuid.NewV4()),
+		CaseID:    caseID,
+		ActorID:   actorID,
+		Type:      logType,
+		Detail:    detail,
+		CreatedAt: time.Now(),
+	}
+	repo.ModerationLogs = append(repo.ModerationLogs, l)
+	return l
+}
+
+func (repo *TestRepository) CreateMentions(message *model.Message, mentions map[uuid.UUID]uuid.UUID) error {
+	repo.MentionsLock.Lock()
+	defer repo.MentionsLock.Unlock()
+	for userID, groupID := range mentions {
+		if _, err := repo.GetUser(userID); err != nil {
+			continue
+		}
+		exists := false
+		for _, m := range repo.Mentions {
+			if m.UserID == userID && m.MessageID == message.ID {
+				exists = true
+				break
+			}
+		}
+		if exists {
+			continue
+		}
+		repo.Mentions = append(repo.Mentions, model.Mention{
+			UserID:    userID,
+			MessageID: message.ID,
+			ChannelID: message.ChannelID,
+			GroupID:   groupID,
+			CreatedAt: message.CreatedAt,
+		})
+	}
+	return nil
+}
+
+func (repo *TestRepository) GetMentions(query repository.MentionsQuery) ([]*repository.UserMention, bool, error) {
+	if query.User == uuid.Nil {
+		return nil, false, repository.ErrNilID
+	}
+	repo.MentionsLock.RLock()
+	defer repo.MentionsLock.RUnlock()
+
+	var cursor *model.Mention
+	if query.Before != uuid.Nil {
+		for _, m := range repo.Mentions {
+			if m.UserID == query.User && m.MessageID == query.Before {
+				m := m
+				cursor = &m
+				break
+			}
+		}
+		if cursor == nil {
+			return nil, false, repository.ArgError("query.Before", "the Mention is not found")
+		}
+	}
+
+	result := make([]*repository.UserMention, 0)
+	for _, m := range repo.Mentions {
+		if m.UserID != query.User {
+			continue
+		}
+		if query.Channel != uuid.Nil && m.ChannelID != query.Channel {
+			continue
+		}
+		message, err := repo.GetMessageByID(m.MessageID)
+		if err != nil {
+			continue
+		}
+		if ok, _ := repo.IsChannelAccessibleToUser(query.User, m.ChannelID); !ok {
+			continue
+		}
+		if cursor != nil && !(m.CreatedAt.Before(cursor.CreatedAt) || (m.CreatedAt.Equal(cursor.CreatedAt) && m.MessageID.String() < cursor.MessageID.String())) {
+			continue
+		}
+		read := false
+		repo.ReadPointersLock.RLock()
+		if p, ok := repo.ReadPointers[query.User][m.ChannelID]; ok && p.LastReadAt != nil {
+			read = !m.CreatedAt.After(*p.LastReadAt)
+		}
+		repo.ReadPointersLock.RUnlock()
+		if query.UnreadOnly && read {
+			continue
+		}
+		result = append(result, &repository.UserMention{
+			MessageID: m.MessageID,
+			ChannelID: m.ChannelID,
+			GroupID:   m.GroupID,
+			CreatedAt: m.CreatedAt,
+			Read:      read,
+			Message:   message,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].MessageID.String() > result[j].MessageID.String()
+		}
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+	if query.Limit > 0 && len(result) > query.Limit {
+		return result[:query.Limit], true, nil
+	}
+	return result, false, nil
+}
+
+func (repo *TestRepository) CreateReminder(userID, messageID uuid.UUID, remindAt time.Time) (*model.Reminder, error) {
+	if userID == uuid.Nil || messageID == uuid.Nil {
+		return nil, repository.ErrNilID
+	}
+	r := model.Reminder{
+		ID:        uuid.Must(uuid.NewV4()),
+		UserID:    userID,
+		MessageID: messageID,
+		RemindAt:  remindAt,
+		State:     model.ReminderPending,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	repo.RemindersLock.Lock()
+	repo.Reminders[r.ID] = r
+	repo.RemindersLock.Unlock()
+	return &r, nil
+}
+
+func (repo *TestRepository) SnoozeReminder(id uuid.UUID, remindAt time.Time) error {
+	if id == uuid.Nil {
+		return repository.ErrNilID
+	}
+	repo.RemindersLock.Lock()
+	defer repo.RemindersLock.Unlock()
+	r, ok := repo.Reminders[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	if r.State == model.ReminderSending {
+		return repository.ErrForbidden
+	}
+	r.RemindAt = remindAt
+	r.State = model.ReminderPending
+	r.Error = ""
+	r.UpdatedAt = time.Now()
+	repo.Reminders[id] = r
+	return nil
+}
+
+func (repo *TestRepository) DeleteReminder(id uuid.UUID) error {
+	if id == uuid.Nil {
+		return repository.ErrNilID
+	}
+	repo.RemindersLock.Lock()
+	defer repo.RemindersLock.Unlock()
+	r, ok := repo.Reminders[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	if r.State == model.ReminderSending {
+		return repository.ErrForbidden
+	}
+	delete(repo.Reminders, id)
+	return nil
+}
+
+func (repo *TestRepository) GetReminder(id uuid.UUID) (*model.Reminder, error) {
+	repo.RemindersLock.RLock()
+	defer repo.RemindersLock.RUnlock()
+	r, ok := repo.Reminders[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &r, nil
+}
+
+func (repo *TestRepository) GetRemindersByUserID(userID uuid.UUID) ([]*model.Reminder, error) {
+	result := make([]*model.Reminder, 0)
+	repo.RemindersLock.RLock()
+	for _, v := range repo.Reminders {
+		if v.UserID == userID {
+			v := v
+			result = append(result, &v)
+		}
+	}
+	repo.RemindersLock.RUnlock()
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].RemindAt.Before(result[j].RemindAt)
+	})
+	return result, nil
+}
+
+func (repo *TestRepository) GetDueReminders(now time.Time, limit int) ([]*model.Reminder, error) {
+	panic("implement me")
+}
+
+func (repo *TestRepository) ClaimReminder(id uuid.UUID) (bool, error) {
+	if id == uuid.Nil {
+		return false, repository.ErrNilID
+	}
+	repo.RemindersLock.Lock()
+	defer repo.RemindersLock.Unlock()
+	r, ok := repo.Reminders[id]
+	if !ok || r.State != model.ReminderPending {
+		return false, nil
+	}
+	r.State = model.ReminderSending
+	r.UpdatedAt = time.Now()
+	repo.Reminders[id] = r
+	return true, nil
+}
+
+func (repo *TestRepository) MarkReminderSent(id uuid.UUID) error {
+	panic("implement me")
+}
+
+func (repo *TestRepository) MarkReminderFailed(id uuid.UUID, reason string) error {
+	panic("implement me")
+}
+
+func (repo *TestRepository) FailStaleReminders(before time.Time) (int, error) {
+	panic("implement me")
+}
+
+func (repo *TestRepository) GetReminderBotUser() (*model.User, error) {
+	panic("implement me")
+}
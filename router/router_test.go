@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"github.com/gavv/httpexpect"
 	"github.com/gofrs/uuid"
+	"github.com/leandro-lugaresi/hub"
 	"github.com/traPtitech/traQ/rbac"
 	"github.com/traPtitech/traQ/repository"
 	"github.com/traPtitech/traQ/sessions"
@@ -42,6 +43,7 @@ const (
 var (
 	servers      = map[string]*httptest.Server{}
 	repositories = map[string]*TestRepository{}
+	streamers    = map[string]*SSEStreamer{}
 )
 
 func TestMain(m *testing.M) {
@@ -68,9 +70,11 @@ func TestMain(m *testing.M) {
 
 		e := echo.New()
 		repo := NewTestRepository()
+		sse := NewSSEStreamer(hub.New(), repo, r)
 		SetupRouting(e, &Handlers{
 			RBAC:   r,
 			Repo:   repo,
+			SSE:    sse,
 			Logger: zap.NewNop(),
 			HandlerConfig: HandlerConfig{
 				AccessTokenExp:   1000,
@@ -79,6 +83,7 @@ func TestMain(m *testing.M) {
 		})
 		servers[key] = httptest.NewServer(e)
 		repositories[key] = repo
+		streamers[key] = sse
 	}
 
 	code := m.Run()
@@ -192,7 +197,9 @@ func mustMakeMessage(t *testing.T, repo repository.Repository, userID, channelID
 
 func mustMakeMessageUnread(t *testing.T, repo repository.Repository, userID, messageID uuid.UUID) {
 	t.Helper()
-	require.NoError(t, repo.SetMessageUnread(userID, messageID, false))
+	m, err := repo.GetMessageByID(messageID)
+	require.NoError(t, err)
+	require.NoError(t, repo.IncrementUnreadCounters(m.ChannelID, m.CreatedAt, map[uuid.UUID]bool{userID: false}))
 }
 
 func mustMakeUser(t *testing.T, repo repository.Repository, userName string) *model.User {
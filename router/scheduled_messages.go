@@ -0,0 +1,122 @@
+package router
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/labstack/echo"
+	"github.com/traPtitech/traQ/repository"
+	"gopkg.in/guregu/null.v3"
+)
+
+// GetScheduledMessages GET /users/me/scheduled-messages
+func (h *Handlers) GetScheduledMessages(c echo.Context) error {
+	userID := getRequestUserID(c)
+
+	messages, err := h.Repo.GetScheduledMessagesByUserID(userID)
+	if err != nil {
+		return internalServerError(err, h.requestContextLogger(c))
+	}
+	return c.JSON(http.StatusOK, messages)
+}
+
+// PostScheduledMessage POST /users/me/scheduled-messages
+func (h *Handlers) PostScheduledMessage(c echo.Context) error {
+	userID := getRequestUserID(c)
+
+	var req struct {
+		ChannelID   uuid.UUID `json:"channelId"`
+		Text        string    `json:"text"        validate:"required"`
+		ScheduledAt time.Time `json:"scheduledAt"`
+	}
+	if err := bindAndValidate(c, &req); err != nil {
+		return badRequest(err)
+	}
+	if !req.ScheduledAt.After(time.Now()) {
+		return badRequest("scheduledAt must be in the future")
+	}
+
+	// チャンネルの可用性を確認
+	if ok, err := h.Repo.IsChannelAccessibleToUser(userID, req.ChannelID); err != nil {
+		return internalServerError(err, h.requestContextLogger(c))
+	} else if !ok {
+		return badRequest("the channel is not found")
+	}
+
+	text, err := h.canonicalizeEmbeds(c, req.Text, false)
+	if err != nil {
+		return err
+	}
+
+	m, err := h.Repo.CreateScheduledMessage(userID, req.ChannelID, text, req.ScheduledAt)
+	if err != nil {
+		return internalServerError(err, h.requestContextLogger(c))
+	}
+	return c.JSON(http.StatusCreated, m)
+}
+
+// GetScheduledMessage GET /users/me/scheduled-messages/:scheduledMessageID
+func (h *Handlers) GetScheduledMessage(c echo.Context) error {
+	return c.JSON(http.StatusOK, getScheduledMessageFromContext(c))
+}
+
+// PatchScheduledMessage PATCH /users/me/scheduled-messages/:scheduledMessageID
+func (h *Handlers) PatchScheduledMessage(c echo.Context) error {
+	m := getScheduledMessageFromContext(c)
+
+	var req struct {
+		Text        null.String `json:"text"`
+		ScheduledAt null.Time   `json:"scheduledAt"`
+	}
+	if err := bindAndValidate(c, &req); err != nil {
+		return badRequest(err)
+	}
+	if req.Text.Valid && len(req.Text.String) == 0 {
+		return badRequest("text must not be empty")
+	}
+	if req.ScheduledAt.Valid && !req.ScheduledAt.Time.After(time.Now()) {
+		return badRequest("scheduledAt must be in the future")
+	}
+	if req.Text.Valid {
+		text, err := h.canonicalizeEmbeds(c, req.Text.String, false)
+		if err != nil {
+			return err
+		}
+		req.Text.String = text
+	}
+
+	if err := h.Repo.UpdateScheduledMessage(m.ID, repository.UpdateScheduledMessageArgs{
+		Text:        req.Text,
+		ScheduledAt: req.ScheduledAt,
+	}); err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return notFound()
+		case repository.ErrForbidden:
+			return conflict("the scheduled message is no longer pending")
+		default:
+			return internalServerError(err, h.requestContextLogger(c))
+		}
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// DeleteScheduledMessage DELETE /users/me/scheduled-messages/:scheduledMessageID
+func (h *Handlers) DeleteScheduledMessage(c echo.Context) error {
+	m := getScheduledMessageFromContext(c)
+
+	if err := h.Repo.DeleteScheduledMessage(m.ID); err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return notFound()
+		case repository.ErrForbidden:
+			return conflict("the scheduled message is being sent")
+		default:
+			return internalServerError(err, h.requestContextLogger(c))
+		}
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,254 @@
+package router
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/sessions"
+)
+
+func mustMakeScheduledMessage(t *testing.T, repo repository.Repository, userID, channelID uuid.UUID, scheduledAt time.Time) *model.ScheduledMessage {
+	t.Helper()
+	m, err := repo.CreateScheduledMessage(userID, channelID, "scheduled", scheduledAt)
+	require.NoError(t, err)
+	return m
+}
+
+func TestHandlers_GetScheduledMessages(t *testing.T) {
+	t.Parallel()
+	repo, server, _, _, session, _, testUser, _ := setupWithUsers(t, common7)
+
+	channel := mustMakeChannel(t, repo, random)
+	m1 := mustMakeScheduledMessage(t, repo, testUser.ID, channel.ID, time.Now().Add(2*time.Hour))
+	m2 := mustMakeScheduledMessage(t, repo, testUser.ID, channel.ID, time.Now().Add(time.Hour))
+
+	t.Run("NotLoggedIn", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.GET("/api/1.0/users/me/scheduled-messages").
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("Successful1", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		arr := e.GET("/api/1.0/users/me/scheduled-messages").
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Array()
+		arr.Length().Equal(2)
+		arr.Element(0).Object().Value("id").String().Equal(m2.ID.String())
+		arr.Element(0).Object().Value("state").String().Equal("pending")
+		arr.Element(1).Object().Value("id").String().Equal(m1.ID.String())
+	})
+}
+
+func TestHandlers_PostScheduledMessage(t *testing.T) {
+	t.Parallel()
+	repo, server, _, _, session, _, testUser, _ := setupWithUsers(t, common7)
+
+	channel := mustMakeChannel(t, repo, random)
+	other := mustMakeUser(t, repo, random)
+	private := mustMakePrivateChannel(t, repo, random, []uuid.UUID{other.ID})
+
+	t.Run("NotLoggedIn", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.POST("/api/1.0/users/me/scheduled-messages").
+			WithJSON(map[string]interface{}{"channelId": channel.ID, "text": "test", "scheduledAt": time.Now().Add(time.Hour)}).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("Past", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.POST("/api/1.0/users/me/scheduled-messages").
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]interface{}{"channelId": channel.ID, "text": "test", "scheduledAt": time.Now().Add(-time.Hour)}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("EmptyText", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.POST("/api/1.0/users/me/scheduled-messages").
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]interface{}{"channelId": channel.ID, "text": "", "scheduledAt": time.Now().Add(time.Hour)}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("InaccessibleChannel", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.POST("/api/1.0/users/me/scheduled-messages").
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]interface{}{"channelId": private.ID, "text": "test", "scheduledAt": time.Now().Add(time.Hour)}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("Successful1", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		obj := e.POST("/api/1.0/users/me/scheduled-messages").
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]interface{}{"channelId": channel.ID, "text": "test", "scheduledAt": time.Now().Add(time.Hour)}).
+			Expect().
+			Status(http.StatusCreated).
+			JSON().
+			Object()
+		obj.Value("channelId").String().Equal(channel.ID.String())
+		obj.Value("userId").String().Equal(testUser.ID.String())
+		obj.Value("text").String().Equal("test")
+		obj.Value("state").String().Equal("pending")
+
+		m, err := repo.GetScheduledMessage(uuid.FromStringOrNil(obj.Value("id").String().Raw()))
+		require.NoError(t, err)
+		assert.Equal(t, "test", m.Text)
+	})
+}
+
+func TestHandlers_GetScheduledMessage(t *testing.T) {
+	t.Parallel()
+	repo, server, _, _, session, _, testUser, _ := setupWithUsers(t, common7)
+
+	channel := mustMakeChannel(t, repo, random)
+	m := mustMakeScheduledMessage(t, repo, testUser.ID, channel.ID, time.Now().Add(time.Hour))
+	other := mustMakeUser(t, repo, random)
+	otherMessage := mustMakeScheduledMessage(t, repo, other.ID, channel.ID, time.Now().Add(time.Hour))
+
+	t.Run("NotLoggedIn", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.GET("/api/1.0/users/me/scheduled-messages/{id}", m.ID).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("OthersMessage", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.GET("/api/1.0/users/me/scheduled-messages/{id}", otherMessage.ID).
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusNotFound)
+	})
+
+	t.Run("Successful1", func(t *testing.T) {
+		t.Parallel()
+		e := makeExp(t, server)
+		e.GET("/api/1.0/users/me/scheduled-messages/{id}", m.ID).
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object().
+			Value("id").
+			String().
+			Equal(m.ID.String())
+	})
+}
+
+func TestHandlers_PatchScheduledMessage(t *testing.T) {
+	t.Parallel()
+	repo, server, _, _, session, _, testUser, _ := setupWithUsers(t, common7)
+
+	channel := mustMakeChannel(t, repo, random)
+
+	t.Run("NotLoggedIn", func(t *testing.T) {
+		t.Parallel()
+		m := mustMakeScheduledMessage(t, repo, testUser.ID, channel.ID, time.Now().Add(time.Hour))
+		e := makeExp(t, server)
+		e.PATCH("/api/1.0/users/me/scheduled-messages/{id}", m.ID).
+			WithJSON(map[string]interface{}{"text": "updated"}).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("Past", func(t *testing.T) {
+		t.Parallel()
+		m := mustMakeScheduledMessage(t, repo, testUser.ID, channel.ID, time.Now().Add(time.Hour))
+		e := makeExp(t, server)
+		e.PATCH("/api/1.0/users/me/scheduled-messages/{id}", m.ID).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]interface{}{"scheduledAt": time.Now().Add(-time.Hour)}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("NotPending", func(t *testing.T) {
+		t.Parallel()
+		m := mustMakeScheduledMessage(t, repo, testUser.ID, channel.ID, time.Now().Add(time.Hour))
+		r := repo.(*TestRepository)
+		r.ScheduledMessagesLock.Lock()
+		sm := r.ScheduledMessages[m.ID]
+		sm.State = model.ScheduledMessageSent
+		r.ScheduledMessages[m.ID] = sm
+		r.ScheduledMessagesLock.Unlock()
+
+		e := makeExp(t, server)
+		e.PATCH("/api/1.0/users/me/scheduled-messages/{id}", m.ID).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]interface{}{"text": "updated"}).
+			Expect().
+			Status(http.StatusConflict)
+	})
+
+	t.Run("Successful1", func(t *testing.T) {
+		t.Parallel()
+		m := mustMakeScheduledMessage(t, repo, testUser.ID, channel.ID, time.Now().Add(time.Hour))
+		at := time.Now().Add(3 * time.Hour)
+		e := makeExp(t, server)
+		e.PATCH("/api/1.0/users/me/scheduled-messages/{id}", m.ID).
+			WithCookie(sessions.CookieName, session).
+			WithJSON(map[string]interface{}{"text": "updated", "scheduledAt": at}).
+			Expect().
+			Status(http.StatusNoContent)
+
+		m, err := repo.GetScheduledMessage(m.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "updated", m.Text)
+		assert.True(t, at.Equal(m.ScheduledAt))
+	})
+}
+
+func TestHandlers_DeleteScheduledMessage(t *testing.T) {
+	t.Parallel()
+	repo, server, _, _, session, _, testUser, _ := setupWithUsers(t, common7)
+
+	channel := mustMakeChannel(t, repo, random)
+
+	t.Run("NotLoggedIn", func(t *testing.T) {
+		t.Parallel()
+		m := mustMakeScheduledMessage(t, repo, testUser.ID, channel.ID, time.Now().Add(time.Hour))
+		e := makeExp(t, server)
+		e.DELETE("/api/1.0/users/me/scheduled-messages/{id}", m.ID).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("Successful1", func(t *testing.T) {
+		t.Parallel()
+		m := mustMakeScheduledMessage(t, repo, testUser.ID, channel.ID, time.Now().Add(time.Hour))
+		e := makeExp(t, server)
+		e.DELETE("/api/1.0/users/me/scheduled-messages/{id}", m.ID).
+			WithCookie(sessions.CookieName, session).
+			Expect().
+			Status(http.StatusNoContent)
+
+		_, err := repo.GetScheduledMessage(m.ID)
+		assert.Equal(t, repository.ErrNotFound, err)
+	})
+}
@@ -9,6 +9,8 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/traPtitech/traQ/event"
 	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/rbac"
+	"github.com/traPtitech/traQ/rbac/permission"
 	"github.com/traPtitech/traQ/repository"
 	"github.com/traPtitech/traQ/utils/message"
 	"net/http"
@@ -33,6 +35,7 @@ type eventData struct {
 type SSEStreamer struct {
 	sseClientMap
 	repo       repository.Repository
+	rbac       *rbac.RBAC
 	connect    chan *sseClient
 	disconnect chan *sseClient
 	stop       chan struct{}
@@ -110,9 +113,10 @@ func (c *sseClient) dispose() {
 }
 
 // NewSSEStreamer SSEストリーマーを作成します
-func NewSSEStreamer(hub *hub.Hub, repo repository.Repository) *SSEStreamer {
+func NewSSEStreamer(hub *hub.Hub, repo repository.Repository, rbac *rbac.RBAC) *SSEStreamer {
 	s := &SSEStreamer{
 		repo:       repo,
+		rbac:       rbac,
 		connect:    make(chan *sseClient),
 		disconnect: make(chan *sseClient, 10),
 		stop:       make(chan struct{}),
@@ -160,6 +164,17 @@ func (s *SSEStreamer) setupSubscriber(h *hub.Hub) {
 		event.MessageCreated,
 	))
 
+	go func(sub hub.Subscription) {
+		for ev := range sub.Receiver {
+			m := ev.Fields["message"].(*model.Message)
+			p := ev.Fields["plain"].(string)
+			e := ev.Fields["embedded"].([]*message.EmbeddedInfo)
+			go s.processThreadReplyCreated(m, p, e)
+		}
+	}(h.Subscribe(10,
+		event.ThreadReplyCreated,
+	))
+
 	go func(sub hub.Subscription) {
 		for ev := range sub.Receiver {
 			private := ev.Fields["private"].(bool)
@@ -182,10 +197,14 @@ func (s *SSEStreamer) setupSubscriber(h *hub.Hub) {
 	}(h.Subscribe(10,
 		event.MessageUpdated,
 		event.MessageDeleted,
+		event.MessageRestored,
+		event.MessageMoved,
 		event.MessagePinned,
 		event.MessageUnpinned,
 		event.MessageStamped,
 		event.MessageUnstamped,
+		event.MessagePreviewsReady,
+		event.PollUpdated,
 	))
 
 	go func(sub hub.Subscription) {
@@ -204,6 +223,16 @@ func (s *SSEStreamer) setupSubscriber(h *hub.Hub) {
 		event.ClipFolderUpdated,
 		event.ClipFolderDeleted,
 		event.ChannelRead,
+		event.ModerationWarned,
+		event.ReminderFired,
+	))
+
+	go func(sub hub.Subscription) {
+		for ev := range sub.Receiver {
+			go s.processModeratorMulticastEvent(ev)
+		}
+	}(h.Subscribe(10,
+		event.ModerationCaseUpdated,
 	))
 
 	go func(sub hub.Subscription) {
@@ -211,6 +240,8 @@ func (s *SSEStreamer) setupSubscriber(h *hub.Hub) {
 			go s.processBroadcastEvent(ev)
 		}
 	}(h.Subscribe(10,
+		event.ChannelMemberJoined,
+		event.ChannelMemberLeft,
 		event.UserCreated,
 		event.UserUpdated,
 		event.UserIconUpdated,
@@ -236,10 +267,8 @@ func (s *SSEStreamer) processMessageCreated(message *model.Message, plain string
 			"id": message.ID,
 		},
 	}
-	viewers := map[uuid.UUID]bool{}
-	connector := map[uuid.UUID]bool{}
 	subscribers := map[uuid.UUID]bool{}
-	noticeable := map[uuid.UUID]bool{}
+	targets := map[uuid.UUID]bool{}
 	ch, _ := s.repo.GetChannel(message.ChannelID)
 	switch {
 	case ch.IsForced: // 強制通知チャンネル
@@ -249,7 +278,6 @@ func (s *SSEStreamer) processMessageCreated(message *model.Message, plain string
 				continue
 			}
 			subscribers[v.ID] = true
-			noticeable[v.ID] = true
 		}
 
 	case !ch.IsPublic: // プライベートチャンネル
@@ -266,22 +294,64 @@ func (s *SSEStreamer) processMessageCreated(message *model.Message, plain string
 		}
 
 		// グループユーザー・メンションユーザー取得
-		for _, v := range embedded {
-			switch v.Type {
-			case "user":
-				if uid, err := uuid.FromString(v.ID); err == nil {
-					subscribers[uid] = true
-					noticeable[uid] = true
-				}
-			case "group":
-				gs, _ := s.repo.GetUserGroupMemberIDs(uuid.FromStringOrNil(v.ID))
-				for _, v := range gs {
-					subscribers[v] = true
-					noticeable[v] = true
-				}
+		s.addMentionedUsers(embedded, subscribers, targets)
+	}
+
+	s.sendMessageEvent(message, ed, subscribers, targets)
+}
+
+func (s *SSEStreamer) processThreadReplyCreated(message *model.Message, plain string, embedded []*message.EmbeddedInfo) {
+	ed := &eventData{
+		EventType: "THREAD_REPLY_CREATED",
+		Payload: Payload{
+			"id":       message.ID,
+			"parentId": message.ParentID,
+		},
+	}
+	subscribers := map[uuid.UUID]bool{}
+	targets := map[uuid.UUID]bool{}
+	ch, _ := s.repo.GetChannel(message.ChannelID)
+
+	// スレッド参加者取得
+	users, _ := s.repo.GetThreadParticipantIDs(message.ParentID)
+	for _, v := range users {
+		subscribers[v] = true
+		targets[v] = false
+	}
+
+	// グループユーザー・メンションユーザー取得
+	if ch.IsPublic {
+		s.addMentionedUsers(embedded, subscribers, targets)
+	}
+
+	s.sendMessageEvent(message, ed, subscribers, targets)
+}
+
+// addMentionedUsers メンションされたユーザーを通知対象・未読の通知対象に追加します
+func (s *SSEStreamer) addMentionedUsers(embedded []*message.EmbeddedInfo, subscribers, targets map[uuid.UUID]bool) {
+	for _, v := range embedded {
+		switch v.Type {
+		case "user":
+			if uid, err := uuid.FromString(v.ID); err == nil {
+				subscribers[uid] = true
+				targets[uid] = true
+			}
+		case "group":
+			gs, _ := s.repo.GetUserGroupMemberIDs(uuid.FromStringOrNil(v.ID))
+			for _, v := range gs {
+				subscribers[v] = true
+				targets[v] = true
 			}
 		}
 	}
+}
+
+// sendMessageEvent 未読の通知数と閲覧者の既読位置を更新し、通知対象ユーザーとチャンネルの閲覧者にイベントを送信します
+//
+// targetsのキーは未読の通知数を増やすユーザー、値はメンションされたかどうかです。
+func (s *SSEStreamer) sendMessageEvent(message *model.Message, ed *eventData, subscribers, targets map[uuid.UUID]bool) {
+	viewers := map[uuid.UUID]bool{}
+	connector := map[uuid.UUID]bool{}
 
 	// ハートビートユーザー取得
 	if s, ok := s.repo.GetHeartbeatStatus(message.ChannelID); ok {
@@ -293,11 +363,22 @@ func (s *SSEStreamer) processMessageCreated(message *model.Message, plain string
 		}
 	}
 
-	// 送信
-	for id := range subscribers {
+	// 未読更新
+	unread := map[uuid.UUID]bool{}
+	for id, mentioned := range targets {
 		if !(id == message.UserID || viewers[id]) {
-			_ = s.repo.SetMessageUnread(id, message.ID, noticeable[id])
+			unread[id] = mentioned
 		}
+	}
+	_ = s.repo.IncrementUnreadCounters(message.ChannelID, message.CreatedAt, unread)
+	readers := make([]uuid.UUID, 0, len(viewers))
+	for id := range viewers {
+		readers = append(readers, id)
+	}
+	_ = s.repo.UpdateReadPointers(message.ChannelID, message.ID, message.CreatedAt, readers)
+
+	// 送信
+	for id := range subscribers {
 		go s.multicast(id, ed)
 	}
 	for id := range connector {
@@ -437,6 +518,24 @@ func (s *SSEStreamer) processUserMulticastEvent(ev hub.Message) {
 			},
 		}
 		targets[ev.Fields["user_id"].(uuid.UUID)] = true
+	case event.ModerationWarned:
+		ed = &eventData{
+			EventType: "MODERATION_WARNING",
+			Payload: Payload{
+				"id":      ev.Fields["case_id"].(uuid.UUID),
+				"message": ev.Fields["message"].(string),
+			},
+		}
+		targets[ev.Fields["user_id"].(uuid.UUID)] = true
+	case event.ReminderFired:
+		ed = &eventData{
+			EventType: "REMINDER_FIRED",
+			Payload: Payload{
+				"id":        ev.Fields["reminder_id"].(uuid.UUID),
+				"messageId": ev.Fields["message_id"].(uuid.UUID),
+			},
+		}
+		targets[ev.Fields["user_id"].(uuid.UUID)] = true
 	}
 	for u := range targets {
 		go s.multicast(u, ed)
@@ -445,8 +544,9 @@ func (s *SSEStreamer) processUserMulticastEvent(ev hub.Message) {
 
 func (s *SSEStreamer) processChannelUserMulticastEvent(ev hub.Message) {
 	var (
-		ed  *eventData
-		cid uuid.UUID
+		ed   *eventData
+		cid  uuid.UUID
+		cids []uuid.UUID
 	)
 	switch ev.Topic() {
 	case event.MessageUpdated:
@@ -465,6 +565,25 @@ func (s *SSEStreamer) processChannelUserMulticastEvent(ev hub.Message) {
 			},
 		}
 		cid = ev.Fields["message"].(*model.Message).ChannelID
+	case event.MessageRestored:
+		ed = &eventData{
+			EventType: "MESSAGE_RESTORED",
+			Payload: Payload{
+				"id": ev.Fields["message_id"].(uuid.UUID),
+			},
+		}
+		cid = ev.Fields["message"].(*model.Message).ChannelID
+	case event.MessageMoved:
+		ed = &eventData{
+			EventType: "MESSAGE_MOVED",
+			Payload: Payload{
+				"ids":             ev.Fields["message_ids"].([]uuid.UUID),
+				"from_channel_id": ev.Fields["from_channel_id"].(uuid.UUID),
+				"to_channel_id":   ev.Fields["to_channel_id"].(uuid.UUID),
+			},
+		}
+		cid = ev.Fields["from_channel_id"].(uuid.UUID)
+		cids = append(cids, ev.Fields["to_channel_id"].(uuid.UUID))
 	case event.MessagePinned:
 		ed = &eventData{
 			EventType: "MESSAGE_PINNED",
@@ -519,10 +638,63 @@ func (s *SSEStreamer) processChannelUserMulticastEvent(ev hub.Message) {
 			return
 		}
 		cid = ch.ID
+	case event.MessagePreviewsReady:
+		ed = &eventData{
+			EventType: "MESSAGE_PREVIEWS_READY",
+			Payload: Payload{
+				"id": ev.Fields["message_id"].(uuid.UUID),
+			},
+		}
+		cid = ev.Fields["message"].(*model.Message).ChannelID
+	case event.PollUpdated:
+		ed = &eventData{
+			EventType: "POLL_UPDATED",
+			Payload: Payload{
+				"id":         ev.Fields["poll_id"].(uuid.UUID),
+				"message_id": ev.Fields["message_id"].(uuid.UUID),
+			},
+		}
+		cid = ev.Fields["channel_id"].(uuid.UUID)
 	}
-	if status, ok := s.repo.GetHeartbeatStatus(cid); ok {
-		for _, u := range status.UserStatuses {
-			go s.multicast(u.UserID, ed)
+
+	// 複数のチャンネルを閲覧しているユーザーには一度だけ送信する
+	sent := make(map[uuid.UUID]bool)
+	for _, cid := range append([]uuid.UUID{cid}, cids...) {
+		if status, ok := s.repo.GetHeartbeatStatus(cid); ok {
+			for _, u := range status.UserStatuses {
+				if !sent[u.UserID] {
+					sent[u.UserID] = true
+					go s.multicast(u.UserID, ed)
+				}
+			}
+		}
+	}
+}
+
+func (s *SSEStreamer) processModeratorMulticastEvent(ev hub.Message) {
+	var ed *eventData
+	switch ev.Topic() {
+	case event.ModerationCaseUpdated:
+		ed = &eventData{
+			EventType: "MODERATION_CASE_UPDATED",
+			Payload: Payload{
+				"id":         ev.Fields["case_id"].(uuid.UUID),
+				"message_id": ev.Fields["message_id"].(uuid.UUID),
+			},
+		}
+	}
+
+	// 権限の確認のためのユーザーの取得はイベント毎に一度だけ行う
+	users, err := s.repo.GetUsers()
+	if err != nil {
+		return
+	}
+	for _, user := range users {
+		if _, ok := s.loadClients(user.ID); !ok {
+			continue
+		}
+		if s.rbac.IsGranted(user.ID, user.Role, permission.ManageModeration) {
+			go s.multicast(user.ID, ed)
 		}
 	}
 }
@@ -551,6 +723,22 @@ func (s *SSEStreamer) processBroadcastEvent(ev hub.Message) {
 				"id": ev.Fields["channel_id"].(uuid.UUID),
 			},
 		}
+	case event.ChannelMemberJoined:
+		ed = &eventData{
+			EventType: "CHANNEL_MEMBER_JOINED",
+			Payload: Payload{
+				"id":      ev.Fields["channel_id"].(uuid.UUID),
+				"user_id": ev.Fields["user_id"].(uuid.UUID),
+			},
+		}
+	case event.ChannelMemberLeft:
+		ed = &eventData{
+			EventType: "CHANNEL_MEMBER_LEFT",
+			Payload: Payload{
+				"id":      ev.Fields["channel_id"].(uuid.UUID),
+				"user_id": ev.Fields["user_id"].(uuid.UUID),
+			},
+		}
 	case event.UserCreated:
 		ed = &eventData{
 			EventType: "USER_JOINED",
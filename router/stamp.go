@@ -135,7 +135,12 @@ func (h *Handlers) PostMessageStamp(c echo.Context) error {
 
 	// スタンプをメッセージに押す
 	if _, err := h.Repo.AddStampToMessage(messageID, stampID, userID); err != nil {
-		return internalServerError(err, h.requestContextLogger(c))
+		switch err {
+		case repository.ErrChannelArchived:
+			return forbidden("the channel is archived")
+		default:
+			return internalServerError(err, h.requestContextLogger(c))
+		}
 	}
 
 	return c.NoContent(http.StatusNoContent)
@@ -149,7 +154,12 @@ func (h *Handlers) DeleteMessageStamp(c echo.Context) error {
 
 	// スタンプをメッセージから削除
 	if err := h.Repo.RemoveStampFromMessage(messageID, stampID, userID); err != nil {
-		return internalServerError(err, h.requestContextLogger(c))
+		switch err {
+		case repository.ErrChannelArchived:
+			return forbidden("the channel is archived")
+		default:
+			return internalServerError(err, h.requestContextLogger(c))
+		}
 	}
 
 	return c.NoContent(http.StatusNoContent)
@@ -15,11 +15,13 @@ import (
 	"github.com/traPtitech/traQ/rbac"
 	"github.com/traPtitech/traQ/repository"
 	"github.com/traPtitech/traQ/utils/imagemagick"
+	"github.com/traPtitech/traQ/utils/ratelimit"
 	"go.uber.org/zap"
 	"golang.org/x/sync/singleflight"
 	_ "image/jpeg" // image.Decode用
 	_ "image/png"  // image.Decode用
 	"io"
+	"math"
 	"mime/multipart"
 	"net/http"
 	"runtime"
@@ -41,21 +43,27 @@ const (
 
 	errMySQLDuplicatedRecord uint16 = 1062
 
-	paramChannelID   = "channelID"
-	paramPinID       = "pinID"
-	paramUserID      = "userID"
-	paramGroupID     = "groupID"
-	paramTagID       = "tagID"
-	paramStampID     = "stampID"
-	paramMessageID   = "messageID"
-	paramReferenceID = "referenceID"
-	paramFileID      = "fileID"
-	paramWebhookID   = "webhookID"
-	paramClipID      = "clipID"
-	paramFolderID    = "folderID"
-	paramTokenID     = "tokenID"
-	paramBotID       = "botID"
-	paramClientID    = "clientID"
+	paramChannelID          = "channelID"
+	paramPinID              = "pinID"
+	paramUserID             = "userID"
+	paramGroupID            = "groupID"
+	paramTagID              = "tagID"
+	paramStampID            = "stampID"
+	paramMessageID          = "messageID"
+	paramReferenceID        = "referenceID"
+	paramFileID             = "fileID"
+	paramWebhookID          = "webhookID"
+	paramClipID             = "clipID"
+	paramFolderID           = "folderID"
+	paramTokenID            = "tokenID"
+	paramBotID              = "botID"
+	paramClientID           = "clientID"
+	paramScheduledMessageID = "scheduledMessageID"
+	paramPollID             = "pollID"
+	paramRuleID             = "ruleID"
+	paramCaseID             = "caseID"
+	paramReminderID         = "reminderID"
+	paramExportID           = "exportID"
 
 	loggerKey  = "logger"
 	traceIDKey = "traceId"
@@ -74,7 +82,9 @@ const (
 	headerFileMetaType      = "X-TRAQ-FILE-TYPE"
 	headerCacheFile         = "X-TRAQ-FILE-CACHE"
 	headerSignature         = "X-TRAQ-Signature"
+	headerGitHubSignature   = "X-Hub-Signature"
 	headerChannelID         = "X-TRAQ-Channel-Id"
+	headerMore              = "X-TRAQ-MORE"
 
 	unexpectedError = "unexpected error"
 )
@@ -100,6 +110,11 @@ type Handlers struct {
 	emojiCSSCacheLock  sync.RWMutex
 
 	messagesResponseCacheGroup singleflight.Group
+
+	userRateLimiter    *ratelimit.Limiter
+	botRateLimiter     *ratelimit.Limiter
+	webhookRateLimiter *ratelimit.Limiter
+	slowMode           ratelimit.Cooldown
 }
 
 // HandlerConfig ハンドラ設定
@@ -112,6 +127,26 @@ type HandlerConfig struct {
 	IsRefreshEnabled bool
 	// SkyWaySecretKey SkyWayクレデンシャル用シークレットキー
 	SkyWaySecretKey string
+	// RateLimit メッセージ投稿のレート制限
+	RateLimit RateLimitConfig
+}
+
+// RateLimitConfig メッセージ投稿のレート制限設定
+type RateLimitConfig struct {
+	// User 一般ユーザー毎の制限
+	User RateLimit
+	// Bot BOTユーザー毎の制限
+	Bot RateLimit
+	// Webhook Webhook毎の制限
+	Webhook RateLimit
+}
+
+// RateLimit トークンバケットによるレート制限
+type RateLimit struct {
+	// PerMinute 1分あたりに補充される投稿数。0以下の場合は制限しない
+	PerMinute int
+	// Burst 連続で投稿できる最大数
+	Burst int
 }
 
 // NewHandlers ハンドラを生成します
@@ -119,10 +154,14 @@ func NewHandlers(rbac *rbac.RBAC, repo repository.Repository, hub *hub.Hub, logg
 	h := &Handlers{
 		RBAC:          rbac,
 		Repo:          repo,
-		SSE:           NewSSEStreamer(hub, repo),
+		SSE:           NewSSEStreamer(hub, repo, rbac),
 		Hub:           hub,
 		Logger:        logger,
 		HandlerConfig: config,
+
+		userRateLimiter:    ratelimit.NewLimiter(config.RateLimit.User.PerMinute, config.RateLimit.User.Burst),
+		botRateLimiter:     ratelimit.NewLimiter(config.RateLimit.Bot.PerMinute, config.RateLimit.Bot.Burst),
+		webhookRateLimiter: ratelimit.NewLimiter(config.RateLimit.Webhook.PerMinute, config.RateLimit.Webhook.Burst),
 	}
 	go h.stampEventSubscriber(hub.Subscribe(10, event.StampCreated, event.StampUpdated, event.StampDeleted))
 	return h
@@ -353,6 +392,11 @@ func conflict(err ...interface{}) error {
 	return httpError(http.StatusConflict, err)
 }
 
+func tooManyRequests(c echo.Context, retryAfter time.Duration) error {
+	c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	return httpError(http.StatusTooManyRequests, "too many requests")
+}
+
 func internalServerError(err error, logger *zap.Logger) error {
 	if logger != nil {
 		logger.Error(unexpectedError, logging.ErrorReport(runtime.Caller(1)), zap.Error(err))
@@ -371,6 +415,8 @@ func httpError(code int, err interface{}) error {
 		return echo.NewHTTPError(code, v)
 	case *repository.ArgumentError:
 		return echo.NewHTTPError(code, v.Error())
+	case *repository.ContentFilterError:
+		return echo.NewHTTPError(code, &contentFilterErrorResponse{Message: v.Error(), RuleID: v.RuleID})
 	case nil:
 		return echo.NewHTTPError(code)
 	default:
@@ -1,8 +1,6 @@
 package router
 
 import (
-	"crypto/subtle"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"github.com/gofrs/uuid"
@@ -10,7 +8,6 @@ import (
 	"github.com/traPtitech/traQ/model"
 	"github.com/traPtitech/traQ/rbac/permission"
 	"github.com/traPtitech/traQ/repository"
-	"github.com/traPtitech/traQ/utils"
 	"gopkg.in/go-playground/webhooks.v5/github"
 	"gopkg.in/guregu/null.v3"
 	"io/ioutil"
@@ -145,16 +142,6 @@ func (h *Handlers) PostWebhook(c echo.Context) error {
 		return badRequest("empty body")
 	}
 
-	if len(w.GetSecret()) > 0 {
-		sig, _ := hex.DecodeString(c.Request().Header.Get(headerSignature))
-		if len(sig) == 0 {
-			return badRequest("missing X-TRAQ-Signature header")
-		}
-		if subtle.ConstantTimeCompare(utils.CalcHMACSHA1(body, w.GetSecret()), sig) != 1 {
-			return echo.NewHTTPError(http.StatusUnauthorized)
-		}
-	}
-
 	if cid := c.Request().Header.Get(headerChannelID); len(cid) > 0 {
 		id := uuid.FromStringOrNil(cid)
 		ch, err := h.Repo.GetChannel(id)
@@ -172,10 +159,22 @@ func (h *Handlers) PostWebhook(c echo.Context) error {
 		channelID = id
 	}
 
-	if _, err := h.Repo.CreateMessage(w.GetBotUserID(), channelID, string(body)); err != nil {
+	text, _, err := h.Repo.CanonicalizeMessageEmbeds(w.GetBotUserID(), string(body))
+	if err != nil {
 		return internalServerError(err, h.requestContextLogger(c))
 	}
 
+	if _, err := h.Repo.CreateMessage(w.GetBotUserID(), channelID, text); err != nil {
+		switch {
+		case repository.IsArgError(err), repository.IsContentFilterError(err):
+			return badRequest(err)
+		case err == repository.ErrChannelArchived:
+			return forbidden("the channel is archived")
+		default:
+			return internalServerError(err, h.requestContextLogger(c))
+		}
+	}
+
 	return c.NoContent(http.StatusNoContent)
 }
 
@@ -218,16 +217,6 @@ func (h *Handlers) PostWebhookByGithub(c echo.Context) error {
 		return internalServerError(err, h.requestContextLogger(c))
 	}
 
-	if len(w.GetSecret()) > 0 {
-		sig, _ := hex.DecodeString(strings.TrimPrefix(c.Request().Header.Get("X-Hub-Signature"), "sha1="))
-		if len(sig) == 0 {
-			return badRequest("missing X-TRAQ-Signature header")
-		}
-		if subtle.ConstantTimeCompare(utils.CalcHMACSHA1(body, w.GetSecret()), sig) != 1 {
-			return echo.NewHTTPError(http.StatusUnauthorized)
-		}
-	}
-
 	tmpl := webhookDefTmpls.Lookup(fmt.Sprintf("github_%s.tmpl", github.Event(ev)))
 	if tmpl == nil {
 		return c.NoContent(http.StatusNoContent)
@@ -313,10 +302,20 @@ func (h *Handlers) PostWebhookByGithub(c echo.Context) error {
 		messageBuf.WriteString(err.Error())
 	}
 	if messageBuf.Len() > 0 {
-		_, err := h.Repo.CreateMessage(w.GetBotUserID(), w.GetChannelID(), messageBuf.String())
+		text, _, err := h.Repo.CanonicalizeMessageEmbeds(w.GetBotUserID(), messageBuf.String())
 		if err != nil {
 			return internalServerError(err, h.requestContextLogger(c))
 		}
+		if _, err := h.Repo.CreateMessage(w.GetBotUserID(), w.GetChannelID(), text); err != nil {
+			switch {
+			case repository.IsContentFilterError(err):
+				return badRequest(err)
+			case err == repository.ErrChannelArchived:
+				return forbidden("the channel is archived")
+			default:
+				return internalServerError(err, h.requestContextLogger(c))
+			}
+		}
 	}
 
 	return c.NoContent(http.StatusNoContent)
@@ -326,21 +325,25 @@ func (h *Handlers) PostWebhookByGithub(c echo.Context) error {
 func (h *Handlers) GetWebhookMessages(c echo.Context) error {
 	w := getWebhookFromContext(c)
 
-	var req struct {
-		Limit  int `query:"limit"`
-		Offset int `query:"offset"`
-	}
+	var req messagesQuery
 	if err := bindAndValidate(c, &req); err != nil {
 		return badRequest(err)
 	}
-	if req.Limit > 50 || req.Limit <= 0 {
-		req.Limit = 50
+
+	q, err := req.convert(50)
+	if err != nil {
+		return badRequest(err)
 	}
+	q.User = w.GetBotUserID()
 
-	messages, err := h.Repo.GetMessagesByUserID(w.GetBotUserID(), req.Limit, req.Offset)
+	messages, more, err := h.Repo.GetMessages(q)
 	if err != nil {
+		if repository.IsArgError(err) {
+			return badRequest(err)
+		}
 		return internalServerError(err, h.requestContextLogger(c))
 	}
 
+	setMoreHeader(c, more)
 	return c.JSON(http.StatusOK, formatMessages(messages))
 }
@@ -399,8 +399,11 @@ func TestHandlers_PostWebhook(t *testing.T) {
 	t.Run("UnsupportedMediaType", func(t *testing.T) {
 		t.Parallel()
 		e := makeExp(t, server)
+		body := `{"test":"test"}`
 		e.POST("/api/1.0/webhooks/{webhookId}", wb.GetID()).
-			WithJSON(map[string]string{"test": "test"}).
+			WithHeader("Content-Type", "application/json").
+			WithHeader(headerSignature, hex.EncodeToString(utils.CalcHMACSHA1([]byte(body), wb.GetSecret()))).
+			WithBytes([]byte(body)).
 			Expect().
 			Status(http.StatusUnsupportedMediaType)
 	})
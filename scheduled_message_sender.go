@@ -0,0 +1,135 @@
+package main
+
+import (
+	"time"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	scheduledMessageDefaultInterval = 10 * time.Second
+	scheduledMessageFetchLimit      = 100
+	// scheduledMessageSendingTimeout 投稿処理中のままこの時間が経過した予約投稿メッセージは投稿失敗とみなす
+	scheduledMessageSendingTimeout = 5 * time.Minute
+)
+
+// ScheduledMessageSender 予約投稿メッセージ送信者構造体
+//
+// 予約投稿メッセージはDBで管理されるため、プロセスの再起動後も投稿されます。
+// 投稿前にDB上で状態をアトミックに遷移させるため、複数のプロセスで動作させても二重投稿は起こりません。
+type ScheduledMessageSender struct {
+	repo     repository.Repository
+	logger   *zap.Logger
+	interval time.Duration
+	done     chan struct{}
+}
+
+// NewScheduledMessageSender ScheduledMessageSenderを生成し、起動します
+func NewScheduledMessageSender(repo repository.Repository, logger *zap.Logger, interval time.Duration) *ScheduledMessageSender {
+	if interval <= 0 {
+		interval = scheduledMessageDefaultInterval
+	}
+	s := &ScheduledMessageSender{
+		repo:     repo,
+		logger:   logger,
+		interval: interval,
+		done:     make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// Stop ScheduledMessageSenderを停止します
+func (s *ScheduledMessageSender) Stop() {
+	close(s.done)
+}
+
+func (s *ScheduledMessageSender) run() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.process()
+	for {
+		select {
+		case <-ticker.C:
+			s.process()
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *ScheduledMessageSender) process() {
+	// 投稿処理中にプロセスが落ちたものを投稿失敗にする
+	if n, err := s.repo.FailStaleScheduledMessages(time.Now().Add(-scheduledMessageSendingTimeout)); err != nil {
+		s.logger.Error("failed to FailStaleScheduledMessages", zap.Error(err))
+	} else if n > 0 {
+		s.logger.Warn("scheduled messages interrupted while sending were marked as failed", zap.Int("count", n))
+	}
+
+	messages, err := s.repo.GetDueScheduledMessages(time.Now(), scheduledMessageFetchLimit)
+	if err != nil {
+		s.logger.Error("failed to GetDueScheduledMessages", zap.Error(err))
+		return
+	}
+	for _, m := range messages {
+		s.send(m)
+	}
+}
+
+func (s *ScheduledMessageSender) send(sm *model.ScheduledMessage) {
+	logger := s.logger.With(zap.Stringer("scheduledMessageId", sm.ID))
+
+	// 他のプロセスが既に処理している場合は何もしない
+	ok, err := s.repo.ClaimScheduledMessage(sm.ID)
+	if err != nil {
+		logger.Error("failed to ClaimScheduledMessage", zap.Error(err))
+		return
+	}
+	if !ok {
+		return
+	}
+
+	// 投稿時点でチャンネルにアクセスできるかを再確認
+	accessible, err := s.repo.IsChannelAccessibleToUser(sm.UserID, sm.ChannelID)
+	if err != nil {
+		logger.Error("failed to IsChannelAccessibleToUser", zap.Error(err))
+		s.markFailed(logger, sm, "internal error")
+		return
+	}
+	if !accessible {
+		s.markFailed(logger, sm, "the channel is not accessible")
+		return
+	}
+
+	// 予約後に埋め込み先へアクセスできなくなっている場合があるため、投稿時点の権限で検証し直す
+	text, _, err := s.repo.CanonicalizeMessageEmbeds(sm.UserID, sm.Text)
+	if err != nil {
+		logger.Error("failed to CanonicalizeMessageEmbeds", zap.Error(err))
+		s.markFailed(logger, sm, "internal error")
+		return
+	}
+
+	m, err := s.repo.CreateMessage(sm.UserID, sm.ChannelID, text)
+	if err != nil {
+		if repository.IsContentFilterError(err) || err == repository.ErrChannelArchived {
+			s.markFailed(logger, sm, err.Error())
+			return
+		}
+		logger.Error("failed to CreateMessage", zap.Error(err))
+		s.markFailed(logger, sm, "failed to post the message")
+		return
+	}
+
+	if err := s.repo.MarkScheduledMessageSent(sm.ID, m.ID); err != nil {
+		logger.Error("failed to MarkScheduledMessageSent", zap.Error(err), zap.Stringer("messageId", m.ID))
+	}
+}
+
+func (s *ScheduledMessageSender) markFailed(logger *zap.Logger, sm *model.ScheduledMessage, reason string) {
+	if err := s.repo.MarkScheduledMessageFailed(sm.ID, reason); err != nil {
+		logger.Error("failed to MarkScheduledMessageFailed", zap.Error(err))
+	}
+}
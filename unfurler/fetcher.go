@@ -0,0 +1,349 @@
+package unfurler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/utils"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+const (
+	userAgent         = "Mozilla/5.0 (compatible; traQ-unfurler/1.0; +https://github.com/traPtitech/traQ)"
+	maxRedirects      = 3
+	maxTitleLength    = 256
+	maxDescLength     = 1024
+	maxSiteNameLength = 256
+)
+
+var (
+	errPrivateAddress     = errors.New("private address is not allowed")
+	errTooManyRedirects   = errors.New("too many redirects")
+	errUnsupportedScheme  = errors.New("unsupported scheme")
+	errUnsupportedContent = errors.New("unsupported content type")
+	errNoMetadata         = errors.New("no metadata found")
+)
+
+// fetcher URLからプレビュー用のメタデータを取得します
+type fetcher struct {
+	client      *http.Client
+	maxBodySize int64
+	// allowPrivate プライベートアドレスへのアクセスを許可するかどうか (テスト用)
+	allowPrivate bool
+}
+
+func newFetcher(timeout time.Duration, maxBodySize int64) *fetcher {
+	f := &fetcher{maxBodySize: maxBodySize}
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		// 名前解決後の実際の接続先を検査する (DNS rebinding対策)
+		Control: func(network, address string, _ syscall.RawConn) error {
+			if f.allowPrivate {
+				return nil
+			}
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || ip.IsUnspecified() || utils.IsPrivateIP(ip) {
+				return errPrivateAddress
+			}
+			return nil
+		},
+	}
+	f.client = &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   timeout,
+			ResponseHeaderTimeout: timeout,
+			MaxIdleConns:          10,
+			IdleConnTimeout:       30 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return errTooManyRedirects
+			}
+			return f.checkURL(req.URL)
+		},
+	}
+	return f
+}
+
+// checkURL アクセスしてよいURLかどうかを検査します
+func (f *fetcher) checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errUnsupportedScheme
+	}
+	if !f.allowPrivate && utils.IsPrivateHost(u.Hostname()) {
+		return errPrivateAddress
+	}
+	return nil
+}
+
+// get 指定したURLにGETリクエストを送ります
+func (f *fetcher) get(rawURL string, accept string) (*http.Response, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.checkURL(u); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", accept)
+	res, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		return nil, fmt.Errorf("unexpected status code: %d", res.StatusCode)
+	}
+	return res, nil
+}
+
+// fetch 指定したURLのプレビューを取得します
+func (f *fetcher) fetch(rawURL string) (*model.LinkPreview, error) {
+	res, err := f.get(rawURL, "text/html,application/xhtml+xml;q=0.9,image/*;q=0.8")
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	contentType := res.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case strings.HasPrefix(mediaType, "image/"):
+		return &model.LinkPreview{
+			URL:      rawURL,
+			Type:     "image",
+			Title:    truncate(path.Base(res.Request.URL.Path), maxTitleLength),
+			ImageURL: res.Request.URL.String(),
+		}, nil
+	case mediaType == "text/html" || mediaType == "application/xhtml+xml":
+		break
+	default:
+		return nil, errUnsupportedContent
+	}
+
+	body, err := charset.NewReader(io.LimitReader(res.Body, f.maxBodySize), contentType)
+	if err != nil {
+		return nil, err
+	}
+	meta := parseHTML(body)
+	base := res.Request.URL
+
+	if len(meta.oEmbedURL) > 0 {
+		if u, err := base.Parse(meta.oEmbedURL); err == nil {
+			if o, err := f.fetchOEmbed(u.String()); err == nil {
+				meta.mergeOEmbed(o)
+			}
+		}
+	}
+
+	p := meta.toPreview(base)
+	if p == nil {
+		return nil, errNoMetadata
+	}
+	p.URL = rawURL
+	return p, nil
+}
+
+// oEmbedレスポンス
+type oEmbed struct {
+	Type         string `json:"type"`
+	Title        string `json:"title"`
+	AuthorName   string `json:"author_name"`
+	ProviderName string `json:"provider_name"`
+	ThumbnailURL string `json:"thumbnail_url"`
+	URL          string `json:"url"`
+}
+
+// fetchOEmbed oEmbedのJSONを取得します
+func (f *fetcher) fetchOEmbed(rawURL string) (*oEmbed, error) {
+	res, err := f.get(rawURL, "application/json")
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	var o oEmbed
+	if err := json.NewDecoder(io.LimitReader(res.Body, f.maxBodySize)).Decode(&o); err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// htmlから抽出したメタデータ
+type metadata struct {
+	title       string
+	ogTitle     string
+	ogDesc      string
+	ogImage     string
+	ogSiteName  string
+	ogType      string
+	twTitle     string
+	twDesc      string
+	twImage     string
+	twCard      string
+	description string
+	oEmbedURL   string
+	oEmbedType  string
+}
+
+// parseHTML HTMLのhead要素からメタデータを抽出します
+func parseHTML(r io.Reader) *metadata {
+	meta := &metadata{}
+	z := html.NewTokenizer(r)
+	inTitle := false
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			return meta
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			switch string(name) {
+			case "head":
+				return meta
+			case "title":
+				inTitle = false
+			}
+		case html.TextToken:
+			if inTitle && len(meta.title) == 0 {
+				meta.title = strings.TrimSpace(string(z.Text()))
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			attrs := map[string]string{}
+			for hasAttr {
+				var k, v []byte
+				k, v, hasAttr = z.TagAttr()
+				attrs[string(k)] = string(v)
+			}
+			switch string(name) {
+			case "body":
+				return meta
+			case "title":
+				inTitle = tt == html.StartTagToken
+			case "meta":
+				meta.setMeta(attrs)
+			case "link":
+				if strings.EqualFold(attrs["rel"], "alternate") && strings.EqualFold(attrs["type"], "application/json+oembed") && len(meta.oEmbedURL) == 0 {
+					meta.oEmbedURL = attrs["href"]
+				}
+			}
+		}
+	}
+}
+
+func (m *metadata) setMeta(attrs map[string]string) {
+	key := attrs["property"]
+	if len(key) == 0 {
+		key = attrs["name"]
+	}
+	content := strings.TrimSpace(attrs["content"])
+	if len(content) == 0 {
+		return
+	}
+	set := func(dst *string) {
+		if len(*dst) == 0 {
+			*dst = content
+		}
+	}
+	switch strings.ToLower(key) {
+	case "og:title":
+		set(&m.ogTitle)
+	case "og:description":
+		set(&m.ogDesc)
+	case "og:image", "og:image:url", "og:image:secure_url":
+		set(&m.ogImage)
+	case "og:site_name":
+		set(&m.ogSiteName)
+	case "og:type":
+		set(&m.ogType)
+	case "twitter:title":
+		set(&m.twTitle)
+	case "twitter:description":
+		set(&m.twDesc)
+	case "twitter:image", "twitter:image:src":
+		set(&m.twImage)
+	case "twitter:card":
+		set(&m.twCard)
+	case "description":
+		set(&m.description)
+	}
+}
+
+// mergeOEmbed oEmbedの情報でHTMLから得られなかった項目を補います
+func (m *metadata) mergeOEmbed(o *oEmbed) {
+	if len(m.ogTitle) == 0 {
+		m.ogTitle = o.Title
+	}
+	if len(m.ogSiteName) == 0 {
+		m.ogSiteName = o.ProviderName
+	}
+	if len(m.ogImage) == 0 {
+		if len(o.ThumbnailURL) > 0 {
+			m.ogImage = o.ThumbnailURL
+		} else if o.Type == "photo" {
+			m.ogImage = o.URL
+		}
+	}
+	m.oEmbedType = o.Type
+}
+
+// toPreview メタデータをLinkPreviewに変換します。タイトルも画像も無い場合はnilを返します
+func (m *metadata) toPreview(base *url.URL) *model.LinkPreview {
+	p := &model.LinkPreview{
+		Type:        firstNonEmpty(m.twCard, m.oEmbedType, m.ogType, "website"),
+		Title:       truncate(firstNonEmpty(m.ogTitle, m.twTitle, m.title), maxTitleLength),
+		Description: truncate(firstNonEmpty(m.ogDesc, m.twDesc, m.description), maxDescLength),
+		SiteName:    truncate(m.ogSiteName, maxSiteNameLength),
+	}
+	if img := firstNonEmpty(m.ogImage, m.twImage); len(img) > 0 {
+		if u, err := base.Parse(img); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+			p.ImageURL = u.String()
+		}
+	}
+	if len(p.Title) == 0 && len(p.ImageURL) == 0 {
+		return nil
+	}
+	return p
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if len(v) > 0 {
+			return v
+		}
+	}
+	return ""
+}
+
+// truncate 文字列をmaxRunes文字以内に切り詰めます
+func truncate(s string, maxRunes int) string {
+	if utf8.RuneCountInString(s) <= maxRunes {
+		return s
+	}
+	return string([]rune(s)[:maxRunes])
+}
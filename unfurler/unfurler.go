@@ -0,0 +1,160 @@
+package unfurler
+
+import (
+	"time"
+
+	"github.com/leandro-lugaresi/hub"
+	"github.com/traPtitech/traQ/event"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/utils"
+	"github.com/traPtitech/traQ/utils/message"
+	"go.uber.org/zap"
+)
+
+const (
+	// MaxURLsPerMessage 1メッセージあたりのプレビュー取得対象URLの最大数
+	MaxURLsPerMessage = 3
+	// 取得に失敗したURLを再取得するまでの時間
+	failedCacheTTL = time.Hour
+)
+
+// Config Unfurler設定
+type Config struct {
+	// Timeout 1URLあたりの取得タイムアウト
+	Timeout time.Duration
+	// MaxBodySize 読み込むレスポンスボディの最大バイト数
+	MaxBodySize int64
+	// CacheTTL 取得したプレビューのキャッシュ有効期間
+	CacheTTL time.Duration
+}
+
+// Unfurler メッセージ中のURLのプレビューを取得するサービス
+type Unfurler struct {
+	repo     repository.Repository
+	hub      *hub.Hub
+	logger   *zap.Logger
+	fetcher  *fetcher
+	cacheTTL time.Duration
+	locks    *utils.KeyMutex
+}
+
+// New Unfurlerを生成し、起動します
+func New(repo repository.Repository, hub *hub.Hub, logger *zap.Logger, config Config) *Unfurler {
+	if config.Timeout <= 0 {
+		config.Timeout = 5 * time.Second
+	}
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = 1 << 20
+	}
+	if config.CacheTTL <= 0 {
+		config.CacheTTL = 24 * time.Hour
+	}
+	u := &Unfurler{
+		repo:     repo,
+		hub:      hub,
+		logger:   logger,
+		fetcher:  newFetcher(config.Timeout, config.MaxBodySize),
+		cacheTTL: config.CacheTTL,
+		locks:    utils.NewKeyMutex(32),
+	}
+	go func() {
+		sub := hub.Subscribe(100, event.MessageCreated, event.ThreadReplyCreated)
+		for ev := range sub.Receiver {
+			m := ev.Fields["message"].(*model.Message)
+			doc := ev.Fields["document"].(*message.Document)
+			go u.processMessage(m, doc)
+		}
+	}()
+	return u
+}
+
+func (u *Unfurler) processMessage(m *model.Message, doc *message.Document) {
+	urls := extractURLs(doc)
+	if len(urls) == 0 {
+		return
+	}
+
+	ready := false
+	for _, url := range urls {
+		p, err := u.Unfurl(url)
+		if err != nil {
+			u.logger.Error("failed to unfurl url", zap.Error(err), zap.String("url", url), zap.Stringer("messageId", m.ID))
+			continue
+		}
+		if !p.Failed {
+			ready = true
+		}
+	}
+	if !ready {
+		return
+	}
+
+	u.hub.Publish(hub.Message{
+		Name: event.MessagePreviewsReady,
+		Fields: hub.Fields{
+			"message_id": m.ID,
+			"message":    m,
+		},
+	})
+}
+
+// Unfurl 指定したURLのプレビューを取得します
+//
+// 有効なキャッシュが存在する場合はキャッシュを返します。
+// 取得に失敗した場合もFailedなプレビューをキャッシュし、それを返します。
+// DBによるエラーを返すことがあります。
+func (u *Unfurler) Unfurl(url string) (*model.LinkPreview, error) {
+	u.locks.Lock(url)
+	defer u.locks.Unlock(url)
+
+	if cached, err := u.repo.GetLinkPreview(url); err == nil {
+		if !u.isExpired(cached) {
+			return cached, nil
+		}
+	} else if err != repository.ErrNotFound {
+		return nil, err
+	}
+
+	p, err := u.fetcher.fetch(url)
+	if err != nil {
+		u.logger.Debug("failed to fetch link preview", zap.Error(err), zap.String("url", url))
+		p = &model.LinkPreview{URL: url, Failed: true}
+	}
+	p.FetchedAt = time.Now()
+	if err := u.repo.SaveLinkPreview(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (u *Unfurler) isExpired(p *model.LinkPreview) bool {
+	ttl := u.cacheTTL
+	if p.Failed {
+		ttl = failedCacheTTL
+	}
+	return time.Since(p.FetchedAt) > ttl
+}
+
+// ExtractURLs メッセージ本文からプレビュー対象のURLを抽出します
+//
+// コード中のURLは対象外です。重複は除かれ、最大MaxURLsPerMessage個まで返します。
+func ExtractURLs(text string) []string {
+	return extractURLs(message.ParseAST(text))
+}
+
+func extractURLs(doc *message.Document) []string {
+	result := make([]string, 0)
+	seen := map[string]bool{}
+	for _, url := range doc.Links() {
+		if seen[url] {
+			continue
+		}
+		seen[url] = true
+		result = append(result, url)
+		if len(result) >= MaxURLsPerMessage {
+			break
+		}
+	}
+	return result
+}
@@ -0,0 +1,281 @@
+package unfurler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/leandro-lugaresi/hub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traPtitech/traQ/event"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/utils/message"
+	"go.uber.org/zap"
+)
+
+type fakeRepository struct {
+	repository.Repository
+	lock     sync.Mutex
+	previews map[string]model.LinkPreview
+}
+
+func (r *fakeRepository) SaveLinkPreview(preview *model.LinkPreview) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.previews[preview.URL] = *preview
+	return nil
+}
+
+func (r *fakeRepository) GetLinkPreview(url string) (*model.LinkPreview, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	p, ok := r.previews[url]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &p, nil
+}
+
+func newTestUnfurler(t *testing.T) (*Unfurler, *fakeRepository, *hub.Hub) {
+	t.Helper()
+	repo := &fakeRepository{previews: map[string]model.LinkPreview{}}
+	h := hub.New()
+	u := New(repo, h, zap.NewNop(), Config{Timeout: 3 * time.Second, MaxBodySize: 1 << 16})
+	u.fetcher.allowPrivate = true
+	return u, repo, h
+}
+
+func newTestServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ogp", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = fmt.Fprint(w, `<!DOCTYPE html><html><head>
+<title>fallback title</title>
+<meta property="og:title" content="OGP Title">
+<meta property="og:description" content="OGP Description">
+<meta property="og:image" content="/image.png">
+<meta property="og:site_name" content="Example">
+<meta name="twitter:card" content="summary_large_image">
+</head><body><meta property="og:title" content="ignored"></body></html>`)
+	})
+	mux.HandleFunc("/title", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = fmt.Fprint(w, `<html><head><title> Only Title </title><meta name="description" content="desc"></head></html>`)
+	})
+	mux.HandleFunc("/oembed-page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = fmt.Fprint(w, `<html><head><link rel="alternate" type="application/json+oembed" href="/oembed.json"></head></html>`)
+	})
+	mux.HandleFunc("/oembed.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"type":"video","title":"oEmbed Title","provider_name":"Provider","thumbnail_url":"https://example.com/thumb.jpg"}`)
+	})
+	mux.HandleFunc("/image.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write([]byte{0x89, 'P', 'N', 'G'})
+	})
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, r.URL.Path, http.StatusFound)
+	})
+	mux.HandleFunc("/large", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = fmt.Fprint(w, "<html><head><!--"+strings.Repeat("a", 1<<17)+`--><title>too late</title></head></html>`)
+	})
+	mux.HandleFunc("/empty", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = fmt.Fprint(w, `<html><head></head><body>no meta</body></html>`)
+	})
+	mux.HandleFunc("/text", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = fmt.Fprint(w, "plain text")
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestExtractURLs(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	assert.Empty(ExtractURLs("no urls here"))
+	assert.Equal([]string{"https://example.com/a"}, ExtractURLs("see https://example.com/a."))
+	assert.Equal([]string{"https://example.com/b"}, ExtractURLs("(https://example.com/b)"))
+	assert.Equal([]string{"http://example.com/c"}, ExtractURLs("リンク http://example.com/c 。"))
+	assert.Equal([]string{"https://example.com/d"}, ExtractURLs("https://example.com/d https://example.com/d"))
+	assert.Equal([]string{"https://a.example", "https://b.example", "https://c.example"}, ExtractURLs("https://a.example https://b.example https://c.example https://d.example"))
+	assert.Empty(ExtractURLs("https:// broken"))
+	assert.Equal([]string{"https://example.com/e"}, ExtractURLs("[label](https://example.com/e) `https://example.com/f`"))
+}
+
+func TestFetcher_Fetch(t *testing.T) {
+	t.Parallel()
+	ts := newTestServer()
+	defer ts.Close()
+
+	f := newFetcher(3*time.Second, 1<<16)
+	f.allowPrivate = true
+
+	t.Run("ogp", func(t *testing.T) {
+		p, err := f.fetch(ts.URL + "/ogp")
+		if assert.NoError(t, err) {
+			assert.Equal(t, ts.URL+"/ogp", p.URL)
+			assert.Equal(t, "summary_large_image", p.Type)
+			assert.Equal(t, "OGP Title", p.Title)
+			assert.Equal(t, "OGP Description", p.Description)
+			assert.Equal(t, ts.URL+"/image.png", p.ImageURL)
+			assert.Equal(t, "Example", p.SiteName)
+		}
+	})
+
+	t.Run("title only", func(t *testing.T) {
+		p, err := f.fetch(ts.URL + "/title")
+		if assert.NoError(t, err) {
+			assert.Equal(t, "website", p.Type)
+			assert.Equal(t, "Only Title", p.Title)
+			assert.Equal(t, "desc", p.Description)
+			assert.Empty(t, p.ImageURL)
+		}
+	})
+
+	t.Run("oembed", func(t *testing.T) {
+		p, err := f.fetch(ts.URL + "/oembed-page")
+		if assert.NoError(t, err) {
+			assert.Equal(t, "video", p.Type)
+			assert.Equal(t, "oEmbed Title", p.Title)
+			assert.Equal(t, "Provider", p.SiteName)
+			assert.Equal(t, "https://example.com/thumb.jpg", p.ImageURL)
+		}
+	})
+
+	t.Run("image", func(t *testing.T) {
+		p, err := f.fetch(ts.URL + "/image.png")
+		if assert.NoError(t, err) {
+			assert.Equal(t, "image", p.Type)
+			assert.Equal(t, "image.png", p.Title)
+			assert.Equal(t, ts.URL+"/image.png", p.ImageURL)
+		}
+	})
+
+	t.Run("too many redirects", func(t *testing.T) {
+		_, err := f.fetch(ts.URL + "/redirect")
+		assert.Error(t, err)
+	})
+
+	t.Run("body size limit", func(t *testing.T) {
+		_, err := f.fetch(ts.URL + "/large")
+		assert.Equal(t, errNoMetadata, err)
+	})
+
+	t.Run("no metadata", func(t *testing.T) {
+		_, err := f.fetch(ts.URL + "/empty")
+		assert.Equal(t, errNoMetadata, err)
+	})
+
+	t.Run("unsupported content", func(t *testing.T) {
+		_, err := f.fetch(ts.URL + "/text")
+		assert.Equal(t, errUnsupportedContent, err)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := f.fetch(ts.URL + "/not-found")
+		assert.Error(t, err)
+	})
+
+	t.Run("unsupported scheme", func(t *testing.T) {
+		_, err := f.fetch("ftp://example.com/")
+		assert.Equal(t, errUnsupportedScheme, err)
+	})
+}
+
+func TestFetcher_PrivateAddress(t *testing.T) {
+	t.Parallel()
+	ts := newTestServer()
+	defer ts.Close()
+
+	f := newFetcher(3*time.Second, 1<<16)
+	_, err := f.fetch(ts.URL + "/ogp")
+	assert.Equal(t, errPrivateAddress, err)
+}
+
+func TestUnfurler_Unfurl(t *testing.T) {
+	t.Parallel()
+	ts := newTestServer()
+	defer ts.Close()
+	u, repo, _ := newTestUnfurler(t)
+
+	t.Run("success", func(t *testing.T) {
+		p, err := u.Unfurl(ts.URL + "/ogp")
+		if assert.NoError(t, err) {
+			assert.False(t, p.Failed)
+			assert.Equal(t, "OGP Title", p.Title)
+			cached, err := repo.GetLinkPreview(ts.URL + "/ogp")
+			require.NoError(t, err)
+			assert.Equal(t, p.Title, cached.Title)
+		}
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		p, err := u.Unfurl(ts.URL + "/not-found")
+		if assert.NoError(t, err) {
+			assert.True(t, p.Failed)
+			_, err := repo.GetLinkPreview(ts.URL + "/not-found")
+			assert.NoError(t, err)
+		}
+	})
+
+	t.Run("cached", func(t *testing.T) {
+		url := ts.URL + "/cached"
+		require.NoError(t, repo.SaveLinkPreview(&model.LinkPreview{URL: url, Title: "cached", FetchedAt: time.Now()}))
+		p, err := u.Unfurl(url)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "cached", p.Title)
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		url := ts.URL + "/title"
+		require.NoError(t, repo.SaveLinkPreview(&model.LinkPreview{URL: url, Title: "old", FetchedAt: time.Now().Add(-48 * time.Hour)}))
+		p, err := u.Unfurl(url)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "Only Title", p.Title)
+		}
+	})
+}
+
+func TestUnfurler_MessageCreated(t *testing.T) {
+	t.Parallel()
+	ts := newTestServer()
+	defer ts.Close()
+	_, repo, h := newTestUnfurler(t)
+
+	sub := h.Subscribe(1, event.MessagePreviewsReady)
+	defer h.Unsubscribe(sub)
+	time.Sleep(100 * time.Millisecond) // Unfurlerの購読開始を待つ
+
+	m := &model.Message{ID: uuid.Must(uuid.NewV4()), ChannelID: uuid.Must(uuid.NewV4())}
+	h.Publish(hub.Message{
+		Name: event.MessageCreated,
+		Fields: hub.Fields{
+			"message_id": m.ID,
+			"message":    m,
+			"document":   message.ParseAST("look " + ts.URL + "/ogp and " + ts.URL + "/not-found"),
+		},
+	})
+
+	select {
+	case ev := <-sub.Receiver:
+		assert.Equal(t, m.ID, ev.Fields["message_id"])
+		_, err := repo.GetLinkPreview(ts.URL + "/ogp")
+		assert.NoError(t, err)
+		_, err = repo.GetLinkPreview(ts.URL + "/not-found")
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("MessagePreviewsReady event was not published")
+	}
+}
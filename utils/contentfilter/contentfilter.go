@@ -0,0 +1,115 @@
+package contentfilter
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gofrs/uuid"
+	"github.com/traPtitech/traQ/model"
+)
+
+// 伏せ字に使う文字
+const maskChar = "*"
+
+// Filter コンパイル済みのコンテンツフィルタ
+//
+// nilのFilterはどのルールにも一致しません。
+type Filter struct {
+	rules []*rule
+}
+
+type rule struct {
+	*model.ContentFilterRule
+	re *regexp.Regexp
+}
+
+// Result フィルタの適用結果
+type Result struct {
+	// Text 伏せ字を適用した後のテキスト
+	Text string
+	// RejectedBy 拒否の原因となったルール。拒否されなかった場合はnil
+	RejectedBy *model.ContentFilterRule
+	// FlaggedBy 一致した通報ルール
+	FlaggedBy []*model.ContentFilterRule
+}
+
+// Compile ルールのパターンをコンパイルします
+//
+// キーワードの場合は大文字小文字を区別せずに一致する正規表現を返します。
+func Compile(r *model.ContentFilterRule) (*regexp.Regexp, error) {
+	if r.IsRegex {
+		return regexp.Compile(r.Pattern)
+	}
+	return regexp.Compile("(?i)" + regexp.QuoteMeta(r.Pattern))
+}
+
+// New ルールからFilterを生成します
+//
+// コンパイルできないルールは無視されます。
+func New(rules []*model.ContentFilterRule) *Filter {
+	f := &Filter{rules: make([]*rule, 0, len(rules))}
+	for _, r := range rules {
+		re, err := Compile(r)
+		if err != nil {
+			continue
+		}
+		f.rules = append(f.rules, &rule{ContentFilterRule: r, re: re})
+	}
+	return f
+}
+
+// Scoped チャンネルを限定したルールが含まれるかどうかを返します
+func (f *Filter) Scoped() bool {
+	if f == nil {
+		return false
+	}
+	for _, r := range f.rules {
+		if !r.IsGlobal() {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply channelIDsのいずれかのチャンネルが対象のルールと全体ルールをtextに適用します
+//
+// channelIDsには投稿先のチャンネルとその祖先チャンネルを指定します。
+// 拒否ルールが一致した場合は、その時点で適用を中断します。
+func (f *Filter) Apply(text string, channelIDs []uuid.UUID) *Result {
+	res := &Result{Text: text, FlaggedBy: make([]*model.ContentFilterRule, 0)}
+	if f == nil {
+		return res
+	}
+
+	targets := make(map[uuid.UUID]bool, len(channelIDs))
+	for _, id := range channelIDs {
+		targets[id] = true
+	}
+
+	var masks []*regexp.Regexp
+	for _, r := range f.rules {
+		if !r.IsGlobal() && !targets[r.ChannelID] {
+			continue
+		}
+		if !r.re.MatchString(text) {
+			continue
+		}
+		switch r.Action {
+		case model.ContentFilterActionReject:
+			res.RejectedBy = r.ContentFilterRule
+			return res
+		case model.ContentFilterActionFlag:
+			res.FlaggedBy = append(res.FlaggedBy, r.ContentFilterRule)
+		case model.ContentFilterActionMask:
+			masks = append(masks, r.re)
+		}
+	}
+
+	for _, re := range masks {
+		res.Text = re.ReplaceAllStringFunc(res.Text, func(s string) string {
+			return strings.Repeat(maskChar, utf8.RuneCountInString(s))
+		})
+	}
+	return res
+}
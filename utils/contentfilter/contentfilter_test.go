@@ -0,0 +1,86 @@
+package contentfilter
+
+import (
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/traPtitech/traQ/model"
+)
+
+func TestCompile(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	if re, err := Compile(&model.ContentFilterRule{Pattern: "a.b"}); assert.NoError(err) {
+		assert.True(re.MatchString("xA.By"))
+		assert.False(re.MatchString("axb"))
+	}
+	if re, err := Compile(&model.ContentFilterRule{Pattern: "a.b", IsRegex: true}); assert.NoError(err) {
+		assert.True(re.MatchString("axb"))
+	}
+	_, err := Compile(&model.ContentFilterRule{Pattern: "(", IsRegex: true})
+	assert.Error(err)
+}
+
+func TestFilter_Scoped(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	var f *Filter
+	assert.False(f.Scoped())
+	assert.False(New([]*model.ContentFilterRule{{Pattern: "a"}}).Scoped())
+	assert.True(New([]*model.ContentFilterRule{{Pattern: "a"}, {Pattern: "b", ChannelID: uuid.Must(uuid.NewV4())}}).Scoped())
+}
+
+func TestFilter_Apply(t *testing.T) {
+	t.Parallel()
+
+	parent := uuid.Must(uuid.NewV4())
+	other := uuid.Must(uuid.NewV4())
+	reject := &model.ContentFilterRule{ID: uuid.Must(uuid.NewV4()), Pattern: `xoxb-[0-9a-z-]+`, IsRegex: true, Action: model.ContentFilterActionReject}
+	flag := &model.ContentFilterRule{ID: uuid.Must(uuid.NewV4()), Pattern: "password", Action: model.ContentFilterActionFlag}
+	mask := &model.ContentFilterRule{ID: uuid.Must(uuid.NewV4()), Pattern: "ばか", ChannelID: parent, Action: model.ContentFilterActionMask}
+	otherReject := &model.ContentFilterRule{ID: uuid.Must(uuid.NewV4()), Pattern: "hello", ChannelID: other, Action: model.ContentFilterActionReject}
+	invalid := &model.ContentFilterRule{ID: uuid.Must(uuid.NewV4()), Pattern: "(", IsRegex: true, Action: model.ContentFilterActionReject}
+	f := New([]*model.ContentFilterRule{reject, flag, mask, otherReject, invalid})
+	channels := []uuid.UUID{uuid.Must(uuid.NewV4()), parent}
+
+	t.Run("reject", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		res := f.Apply("token: xoxb-1234-abcd", channels)
+		assert.Equal(reject, res.RejectedBy)
+	})
+
+	t.Run("flag and mask", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		res := f.Apply("hello ばか PASSWORD ばか", channels)
+		assert.Nil(res.RejectedBy)
+		assert.Equal([]*model.ContentFilterRule{flag}, res.FlaggedBy)
+		assert.Equal("hello ** PASSWORD **", res.Text)
+	})
+
+	t.Run("out of scope", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		res := f.Apply("hello ばか", nil)
+		assert.Nil(res.RejectedBy)
+		assert.Len(res.FlaggedBy, 0)
+		assert.Equal("hello ばか", res.Text)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		var f *Filter
+		res := f.Apply("xoxb-1", channels)
+		assert.Nil(res.RejectedBy)
+		assert.Equal("xoxb-1", res.Text)
+	})
+}
@@ -0,0 +1,198 @@
+package message
+
+// Node メッセージASTのノード
+type Node interface {
+	// Children 子ノードを返します
+	Children() []Node
+}
+
+// Document メッセージ全体を表すASTのルートノード
+type Document struct {
+	Blocks []Node
+}
+
+// Paragraph 段落
+type Paragraph struct {
+	Inlines []Node
+}
+
+// Quote 引用 (行頭が">")
+type Quote struct {
+	Blocks []Node
+}
+
+// CodeBlock コードブロック (```で囲まれた部分)
+type CodeBlock struct {
+	Lang string
+	Code string
+}
+
+// Text 平文
+type Text struct {
+	Value string
+}
+
+// LineBreak 段落内の改行
+type LineBreak struct{}
+
+// Code インラインコード (`で囲まれた部分)
+type Code struct {
+	Value string
+}
+
+// Link リンク (URLの自動リンク及び[ラベル](URL)形式)
+type Link struct {
+	URL   string
+	Label string
+}
+
+// Mention ユーザー・グループへのメンション埋め込み
+type Mention struct {
+	EmbeddedInfo
+}
+
+// ChannelLink チャンネルリンク埋め込み
+type ChannelLink struct {
+	EmbeddedInfo
+}
+
+// FileEmbed ファイル埋め込み
+type FileEmbed struct {
+	EmbeddedInfo
+}
+
+// PollEmbed 投票埋め込み
+type PollEmbed struct {
+	EmbeddedInfo
+}
+
+// MessageCitation メッセージ引用埋め込み
+type MessageCitation struct {
+	EmbeddedInfo
+}
+
+// Embed その他の種類の埋め込み
+type Embed struct {
+	EmbeddedInfo
+}
+
+// Stamp スタンプ (:name:)
+type Stamp struct {
+	Name string
+}
+
+// Children implements Node interface.
+func (n *Document) Children() []Node { return n.Blocks }
+
+// Children implements Node interface.
+func (n *Paragraph) Children() []Node { return n.Inlines }
+
+// Children implements Node interface.
+func (n *Quote) Children() []Node { return n.Blocks }
+
+// Children implements Node interface.
+func (*CodeBlock) Children() []Node { return nil }
+
+// Children implements Node interface.
+func (*Text) Children() []Node { return nil }
+
+// Children implements Node interface.
+func (*LineBreak) Children() []Node { return nil }
+
+// Children implements Node interface.
+func (*Code) Children() []Node { return nil }
+
+// Children implements Node interface.
+func (*Link) Children() []Node { return nil }
+
+// Children implements Node interface.
+func (*Mention) Children() []Node { return nil }
+
+// Children implements Node interface.
+func (*ChannelLink) Children() []Node { return nil }
+
+// Children implements Node interface.
+func (*FileEmbed) Children() []Node { return nil }
+
+// Children implements Node interface.
+func (*PollEmbed) Children() []Node { return nil }
+
+// Children implements Node interface.
+func (*MessageCitation) Children() []Node { return nil }
+
+// Children implements Node interface.
+func (*Embed) Children() []Node { return nil }
+
+// Children implements Node interface.
+func (*Stamp) Children() []Node { return nil }
+
+// Walk nを根とする木を深さ優先で走査します。fnがfalseを返した場合、そのノードの子は走査しません
+func Walk(n Node, fn func(Node) bool) {
+	if !fn(n) {
+		return
+	}
+	for _, c := range n.Children() {
+		Walk(c, fn)
+	}
+}
+
+// Embedded メッセージ中の埋め込み情報を出現順に返します
+func (n *Document) Embedded() []*EmbeddedInfo {
+	res := make([]*EmbeddedInfo, 0)
+	Walk(n, func(node Node) bool {
+		switch v := node.(type) {
+		case *Mention:
+			res = append(res, &v.EmbeddedInfo)
+		case *ChannelLink:
+			res = append(res, &v.EmbeddedInfo)
+		case *FileEmbed:
+			res = append(res, &v.EmbeddedInfo)
+		case *PollEmbed:
+			res = append(res, &v.EmbeddedInfo)
+		case *MessageCitation:
+			res = append(res, &v.EmbeddedInfo)
+		case *Embed:
+			res = append(res, &v.EmbeddedInfo)
+		}
+		return true
+	})
+	return res
+}
+
+// CitedMessageIDs メッセージ中で引用されているメッセージのIDを重複を除いて出現順に返します
+func (n *Document) CitedMessageIDs() []string {
+	res := make([]string, 0)
+	added := make(map[string]bool)
+	Walk(n, func(node Node) bool {
+		if v, ok := node.(*MessageCitation); ok && !added[v.ID] {
+			added[v.ID] = true
+			res = append(res, v.ID)
+		}
+		return true
+	})
+	return res
+}
+
+// Links メッセージ中のリンクのURLを出現順に返します
+func (n *Document) Links() []string {
+	res := make([]string, 0)
+	Walk(n, func(node Node) bool {
+		if v, ok := node.(*Link); ok {
+			res = append(res, v.URL)
+		}
+		return true
+	})
+	return res
+}
+
+// Stamps メッセージ中のスタンプ名を出現順に返します
+func (n *Document) Stamps() []string {
+	res := make([]string, 0)
+	Walk(n, func(node Node) bool {
+		if v, ok := node.(*Stamp); ok {
+			res = append(res, v.Name)
+		}
+		return true
+	})
+	return res
+}
@@ -2,12 +2,9 @@ package message
 
 import (
 	"encoding/json"
-	"regexp"
 	"strings"
 )
 
-var embRegex = regexp.MustCompile(`(?m)!({(?:[ \t\n]*"(?:[^"]|\\.)*"[ \t\n]*:[ \t\n]*"(?:[^"]|\\.)*",)*(?:[ \t\n]*"(?:[^"]|\\.)*"[ \t\n]*:[ \t\n]*"(?:[^"]|\\.)*")})`)
-
 // EmbeddedInfo メッセージの埋め込み情報
 type EmbeddedInfo struct {
 	Raw  string `json:"raw"`
@@ -15,18 +12,91 @@ type EmbeddedInfo struct {
 	ID   string `json:"id"`
 }
 
+// String メッセージに埋め込むための文字列を返します
+func (e *EmbeddedInfo) String() string {
+	b, _ := json.Marshal(e)
+	return "!" + string(b)
+}
+
 // Parse メッセージの埋め込み情報を抽出したものと、平文化したメッセージを返します
 func Parse(m string) (res []*EmbeddedInfo, plain string) {
-	tmp := embRegex.ReplaceAllStringFunc(m, func(s string) string {
-		info := &EmbeddedInfo{}
-		if err := json.Unmarshal([]byte(s[1:]), info); err != nil || len(info.Type) == 0 || len(info.ID) == 0 {
-			return s
+	doc := ParseAST(m)
+	return doc.Embedded(), doc.SingleLineText()
+}
+
+// ReplaceEmbeds メッセージ中の埋め込みをfnの返す文字列に置き換えたメッセージを返します
+//
+// fnには埋め込みの元の文字列と埋め込み情報が渡されます。
+// コードブロック・インラインコード中の埋め込みのような文字列は置き換えません。
+func ReplaceEmbeds(m string, fn func(token string, info *EmbeddedInfo) string) string {
+	m = strings.Replace(m, "\r\n", "\n", -1)
+	return strings.Join(replaceEmbedsInBlocks(strings.Split(m, "\n"), fn), "\n")
+}
+
+// replaceEmbedsInBlocks parseBlocksと同じ規則で行を解釈し、段落中の埋め込みを置き換えます
+func replaceEmbedsInBlocks(lines []string, fn func(token string, info *EmbeddedInfo) string) []string {
+	res := make([]string, 0, len(lines))
+	var paragraph []string
+	flush := func() {
+		if len(paragraph) > 0 {
+			res = append(res, strings.Split(replaceEmbedsInInlines(strings.Join(paragraph, "\n"), fn), "\n")...)
+			paragraph = nil
 		}
-		res = append(res, info)
-		if info.Type == "file" {
-			return "[添付ファイル]"
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case isFence(line):
+			flush()
+			fence, _ := splitFence(line)
+			res = append(res, line)
+			for i++; i < len(lines); i++ {
+				res = append(res, lines[i])
+				if isClosingFence(lines[i], fence) {
+					break
+				}
+			}
+		case strings.HasPrefix(line, ">"):
+			flush()
+			var prefixes, quoted []string
+			for ; i < len(lines) && strings.HasPrefix(lines[i], ">"); i++ {
+				prefix := ">"
+				if strings.HasPrefix(lines[i], "> ") {
+					prefix = "> "
+				}
+				prefixes = append(prefixes, prefix)
+				quoted = append(quoted, strings.TrimPrefix(lines[i], prefix))
+			}
+			i--
+			for j, l := range replaceEmbedsInBlocks(quoted, fn) {
+				res = append(res, prefixes[j]+l)
+			}
+		case len(strings.TrimSpace(line)) == 0:
+			flush()
+			res = append(res, line)
+		default:
+			paragraph = append(paragraph, line)
 		}
-		return info.Raw
-	})
-	return res, strings.Replace(tmp, "\n", " ", -1)
+	}
+	flush()
+	return res
+}
+
+// replaceEmbedsInInlines parseInlinesと同じ規則で段落を解釈し、埋め込みを置き換えます
+func replaceEmbedsInInlines(src string, fn func(token string, info *EmbeddedInfo) string) string {
+	var (
+		sb   strings.Builder
+		last int
+	)
+	p := &inlineParser{src: src, nodes: make([]Node, 0)}
+	p.onEmbed = func(start, size int, info *EmbeddedInfo) {
+		sb.WriteString(src[last:start])
+		// 改行を含むと行の対応が崩れるため取り除く
+		sb.WriteString(strings.Replace(fn(src[start:start+size], info), "\n", " ", -1))
+		last = start + size
+	}
+	p.parse()
+	sb.WriteString(src[last:])
+	return sb.String()
 }
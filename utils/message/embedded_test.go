@@ -99,3 +99,33 @@ func TestParse(t *testing.T) {
 		})
 	}
 }
+
+func TestEmbeddedInfo_String(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	info := &EmbeddedInfo{Raw: `"a" <b>`, Type: "poll", ID: "1"}
+	s := info.String()
+	assert.Equal(`!{"raw":"\"a\" \u003cb\u003e","type":"poll","id":"1"}`, s)
+	assert.Equal([]*EmbeddedInfo{info}, ParseAST(s).Embedded())
+}
+
+func TestReplaceEmbeds(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	emb := `!{"raw":"@a","type":"user","id":"1"}`
+	text := "x " + emb + " `" + emb + "`\r\n> " + emb + "\n```\n" + emb + "\n```\n\n" + emb
+	res := ReplaceEmbeds(text, func(token string, info *EmbeddedInfo) string {
+		assert.Equal(emb, token)
+		info.Raw = "@b\n"
+		return info.String()
+	})
+	replaced := `!{"raw":"@b\n","type":"user","id":"1"}`
+	assert.Equal("x "+replaced+" `"+emb+"`\n> "+replaced+"\n```\n"+emb+"\n```\n\n"+replaced, res)
+
+	res = ReplaceEmbeds("a"+emb+"b\nc", func(string, *EmbeddedInfo) string {
+		return "line\nbreak"
+	})
+	assert.Equal("aline breakb\nc", res)
+}
@@ -0,0 +1,270 @@
+package message
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	embTokenRegex   = regexp.MustCompile(`\A!({(?:[ \t\n]*"(?:[^"]|\\.)*"[ \t\n]*:[ \t\n]*"(?:[^"]|\\.)*",)*(?:[ \t\n]*"(?:[^"]|\\.)*"[ \t\n]*:[ \t\n]*"(?:[^"]|\\.)*")})`)
+	autoLinkRegex   = regexp.MustCompile(`\Ahttps?://[^\s<>"'\x60]+`)
+	labeledURLRegex = regexp.MustCompile(`\Ahttps?://[^\s<>"'\x60()]+\z`)
+	stampRegex      = regexp.MustCompile(`\A:([a-zA-Z0-9_-]{1,32}):`)
+)
+
+// 自動リンクの末尾から取り除く文字
+const autoLinkTrailingChars = ".,;:!?'\"]}。、」』"
+
+// ParseAST メッセージ本文をASTに変換します
+func ParseAST(text string) *Document {
+	text = strings.Replace(text, "\r\n", "\n", -1)
+	return &Document{Blocks: parseBlocks(strings.Split(text, "\n"))}
+}
+
+// parseBlocks 行の配列をブロック要素に変換します
+func parseBlocks(lines []string) []Node {
+	blocks := make([]Node, 0)
+	var paragraph []string
+	flush := func() {
+		if len(paragraph) > 0 {
+			blocks = append(blocks, &Paragraph{Inlines: parseInlines(strings.Join(paragraph, "\n"))})
+			paragraph = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case isFence(line):
+			flush()
+			fence, lang := splitFence(line)
+			code := make([]string, 0)
+			for i++; i < len(lines); i++ {
+				if isClosingFence(lines[i], fence) {
+					break
+				}
+				code = append(code, lines[i])
+			}
+			blocks = append(blocks, &CodeBlock{Lang: lang, Code: strings.Join(code, "\n")})
+		case strings.HasPrefix(line, ">"):
+			flush()
+			quoted := make([]string, 0)
+			for ; i < len(lines) && strings.HasPrefix(lines[i], ">"); i++ {
+				l := strings.TrimPrefix(lines[i], ">")
+				quoted = append(quoted, strings.TrimPrefix(l, " "))
+			}
+			i--
+			blocks = append(blocks, &Quote{Blocks: parseBlocks(quoted)})
+		case len(strings.TrimSpace(line)) == 0:
+			flush()
+		default:
+			paragraph = append(paragraph, line)
+		}
+	}
+	flush()
+	return blocks
+}
+
+func isFence(line string) bool {
+	return strings.HasPrefix(strings.TrimLeft(line, " "), "```")
+}
+
+// splitFence コードブロックの開始行をフェンスと言語名に分割します
+func splitFence(line string) (fence string, lang string) {
+	line = strings.TrimLeft(line, " ")
+	n := 0
+	for n < len(line) && line[n] == '`' {
+		n++
+	}
+	lang = strings.TrimSpace(line[n:])
+	if i := strings.IndexFunc(lang, unicode.IsSpace); i >= 0 {
+		lang = lang[:i]
+	}
+	return line[:n], lang
+}
+
+func isClosingFence(line string, fence string) bool {
+	line = strings.TrimSpace(line)
+	return strings.HasPrefix(line, fence) && len(strings.Trim(line, "`")) == 0
+}
+
+// inlineParser 段落内のインライン要素のパーサー
+type inlineParser struct {
+	src   string
+	pos   int
+	nodes []Node
+	text  strings.Builder
+	// onEmbed 埋め込みを読んだ時に、src中の開始位置と長さと共に呼ばれます
+	onEmbed func(start, size int, info *EmbeddedInfo)
+}
+
+// parseInlines 段落のテキストをインライン要素に変換します
+func parseInlines(src string) []Node {
+	p := &inlineParser{src: src, nodes: make([]Node, 0)}
+	p.parse()
+	return p.nodes
+}
+
+func (p *inlineParser) parse() {
+	for p.pos < len(p.src) {
+		rest := p.src[p.pos:]
+		switch c := rest[0]; {
+		case c == '\n':
+			p.push(&LineBreak{}, 1)
+		case c == '`' && p.parseCode(rest):
+		case c == '!' && p.parseEmbed(rest):
+		case c == '[' && p.parseLabeledLink(rest):
+		case c == 'h' && p.parseAutoLink(rest):
+		case c == ':' && p.parseStamp(rest):
+		default:
+			_, size := utf8.DecodeRuneInString(rest)
+			p.text.WriteString(rest[:size])
+			p.pos += size
+		}
+	}
+	p.flushText()
+}
+
+func (p *inlineParser) flushText() {
+	if p.text.Len() > 0 {
+		p.nodes = append(p.nodes, &Text{Value: p.text.String()})
+		p.text.Reset()
+	}
+}
+
+// push ノードを追加し、size分読み進めます
+func (p *inlineParser) push(n Node, size int) {
+	p.flushText()
+	p.nodes = append(p.nodes, n)
+	p.pos += size
+}
+
+// prevIsWordChar 現在位置の直前の文字が英数字かどうか
+func (p *inlineParser) prevIsWordChar() bool {
+	if p.pos == 0 {
+		return false
+	}
+	r, _ := utf8.DecodeLastRuneInString(p.src[:p.pos])
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func (p *inlineParser) parseCode(rest string) bool {
+	n := 0
+	for n < len(rest) && rest[n] == '`' {
+		n++
+	}
+	fence := rest[:n]
+	for i := n; i < len(rest); {
+		j := strings.Index(rest[i:], fence)
+		if j < 0 {
+			break
+		}
+		j += i
+		// 閉じ側のバッククォートの数が一致する場合のみ
+		k := j + n
+		if k < len(rest) && rest[k] == '`' {
+			for k < len(rest) && rest[k] == '`' {
+				k++
+			}
+			i = k
+			continue
+		}
+		p.push(&Code{Value: rest[n:j]}, k)
+		return true
+	}
+	// 対応する閉じが無い場合はバッククォートの並びをそのまま平文とする
+	p.text.WriteString(fence)
+	p.pos += n
+	return true
+}
+
+func (p *inlineParser) parseEmbed(rest string) bool {
+	s := embTokenRegex.FindString(rest)
+	if len(s) == 0 {
+		return false
+	}
+	info := EmbeddedInfo{}
+	if err := json.Unmarshal([]byte(s[1:]), &info); err != nil || len(info.Type) == 0 || len(info.ID) == 0 {
+		return false
+	}
+	if p.onEmbed != nil {
+		p.onEmbed(p.pos, len(s), &info)
+	}
+	var n Node
+	switch info.Type {
+	case "user", "group":
+		n = &Mention{EmbeddedInfo: info}
+	case "channel":
+		n = &ChannelLink{EmbeddedInfo: info}
+	case "file":
+		n = &FileEmbed{EmbeddedInfo: info}
+	case "poll":
+		n = &PollEmbed{EmbeddedInfo: info}
+	case "message":
+		n = &MessageCitation{EmbeddedInfo: info}
+	default:
+		n = &Embed{EmbeddedInfo: info}
+	}
+	p.push(n, len(s))
+	return true
+}
+
+func (p *inlineParser) parseLabeledLink(rest string) bool {
+	end := strings.IndexAny(rest, "]\n")
+	if end < 1 || rest[end] != ']' || !strings.HasPrefix(rest[end+1:], "(") {
+		return false
+	}
+	closing := strings.IndexAny(rest[end+2:], ")\n")
+	if closing < 0 || rest[end+2+closing] != ')' {
+		return false
+	}
+	url := rest[end+2 : end+2+closing]
+	if !labeledURLRegex.MatchString(url) {
+		return false
+	}
+	p.push(&Link{URL: url, Label: rest[1:end]}, end+2+closing+1)
+	return true
+}
+
+func (p *inlineParser) parseAutoLink(rest string) bool {
+	if p.prevIsWordChar() {
+		return false
+	}
+	url := trimAutoLink(autoLinkRegex.FindString(rest))
+	if i := strings.Index(url, "://"); i < 0 || len(url) == i+3 {
+		return false
+	}
+	p.push(&Link{URL: url, Label: url}, len(url))
+	return true
+}
+
+// trimAutoLink 自動リンクの末尾の句読点や対応の取れていない閉じ括弧を取り除きます
+func trimAutoLink(url string) string {
+	for len(url) > 0 {
+		r, size := utf8.DecodeLastRuneInString(url)
+		switch {
+		case strings.ContainsRune(autoLinkTrailingChars, r):
+		case r == ')' && strings.Count(url, "(") < strings.Count(url, ")"):
+		case r == '）':
+		default:
+			return url
+		}
+		url = url[:len(url)-size]
+	}
+	return url
+}
+
+func (p *inlineParser) parseStamp(rest string) bool {
+	if p.prevIsWordChar() {
+		return false
+	}
+	m := stampRegex.FindStringSubmatch(rest)
+	if m == nil {
+		return false
+	}
+	p.push(&Stamp{Name: m[1]}, len(m[0]))
+	return true
+}
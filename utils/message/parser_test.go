@@ -0,0 +1,150 @@
+package message
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseAST(t *testing.T) {
+	t.Parallel()
+
+	type Case struct {
+		Name    string
+		Message string
+		Blocks  []Node
+	}
+
+	cases := []Case{
+		{
+			"text",
+			"hello\nworld",
+			[]Node{
+				&Paragraph{Inlines: []Node{&Text{Value: "hello"}, &LineBreak{}, &Text{Value: "world"}}},
+			},
+		},
+		{
+			"paragraphs",
+			"a\n\n\nb",
+			[]Node{
+				&Paragraph{Inlines: []Node{&Text{Value: "a"}}},
+				&Paragraph{Inlines: []Node{&Text{Value: "b"}}},
+			},
+		},
+		{
+			"embeds",
+			`!{"raw":"@a","type":"user","id":"1"} !{"raw":"#b","type":"channel","id":"2"}!{"raw":"","type":"file","id":"3"}!{"raw":"x","type":"message","id":"4"}!{"raw":"q","type":"poll","id":"5"}`,
+			[]Node{
+				&Paragraph{Inlines: []Node{
+					&Mention{EmbeddedInfo{Raw: "@a", Type: "user", ID: "1"}},
+					&Text{Value: " "},
+					&ChannelLink{EmbeddedInfo{Raw: "#b", Type: "channel", ID: "2"}},
+					&FileEmbed{EmbeddedInfo{Raw: "", Type: "file", ID: "3"}},
+					&MessageCitation{EmbeddedInfo{Raw: "x", Type: "message", ID: "4"}},
+					&PollEmbed{EmbeddedInfo{Raw: "q", Type: "poll", ID: "5"}},
+				}},
+			},
+		},
+		{
+			"invalid embed",
+			`!{"raw":"@a","type":"","id":"1"}`,
+			[]Node{
+				&Paragraph{Inlines: []Node{&Text{Value: `!{"raw":"@a","type":"","id":"1"}`}}},
+			},
+		},
+		{
+			"stamps",
+			":blobcat: 12:30:45 a:b:",
+			[]Node{
+				&Paragraph{Inlines: []Node{&Stamp{Name: "blobcat"}, &Text{Value: " 12:30:45 a:b:"}}},
+			},
+		},
+		{
+			"links",
+			"see https://example.com/a. [label](https://example.com/b) (https://en.wikipedia.org/wiki/Go_(programming_language)) [x](javascript:alert(1))",
+			[]Node{
+				&Paragraph{Inlines: []Node{
+					&Text{Value: "see "},
+					&Link{URL: "https://example.com/a", Label: "https://example.com/a"},
+					&Text{Value: ". "},
+					&Link{URL: "https://example.com/b", Label: "label"},
+					&Text{Value: " ("},
+					&Link{URL: "https://en.wikipedia.org/wiki/Go_(programming_language)", Label: "https://en.wikipedia.org/wiki/Go_(programming_language)"},
+					&Text{Value: ") [x](javascript:alert(1))"},
+				}},
+			},
+		},
+		{
+			"inline code",
+			"a `!{\"raw\":\"@a\",\"type\":\"user\",\"id\":\"1\"}` ``b`c`` `d",
+			[]Node{
+				&Paragraph{Inlines: []Node{
+					&Text{Value: "a "},
+					&Code{Value: `!{"raw":"@a","type":"user","id":"1"}`},
+					&Text{Value: " "},
+					&Code{Value: "b`c"},
+					&Text{Value: " `d"},
+				}},
+			},
+		},
+		{
+			"code block",
+			"before\n```go foo\nfunc main() {\n\n}\n```\nafter\n```\nunclosed",
+			[]Node{
+				&Paragraph{Inlines: []Node{&Text{Value: "before"}}},
+				&CodeBlock{Lang: "go", Code: "func main() {\n\n}"},
+				&Paragraph{Inlines: []Node{&Text{Value: "after"}}},
+				&CodeBlock{Lang: "", Code: "unclosed"},
+			},
+		},
+		{
+			"quote",
+			"> quoted :a:\n>\n>second\nnot quoted",
+			[]Node{
+				&Quote{Blocks: []Node{
+					&Paragraph{Inlines: []Node{&Text{Value: "quoted "}, &Stamp{Name: "a"}}},
+					&Paragraph{Inlines: []Node{&Text{Value: "second"}}},
+				}},
+				&Paragraph{Inlines: []Node{&Text{Value: "not quoted"}}},
+			},
+		},
+	}
+
+	for _, v := range cases {
+		v := v
+		t.Run(v.Name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, v.Blocks, ParseAST(v.Message).Blocks)
+		})
+	}
+}
+
+func TestDocument_Embedded(t *testing.T) {
+	t.Parallel()
+
+	doc := ParseAST("!{\"raw\":\"@a\",\"type\":\"user\",\"id\":\"1\"}\n```\n!{\"raw\":\"@b\",\"type\":\"user\",\"id\":\"2\"}\n```\n> !{\"raw\":\"\",\"type\":\"file\",\"id\":\"3\"}")
+	assert.Equal(t, []*EmbeddedInfo{
+		{Raw: "@a", Type: "user", ID: "1"},
+		{Raw: "", Type: "file", ID: "3"},
+	}, doc.Embedded())
+}
+
+func TestDocument_CitedMessageIDs(t *testing.T) {
+	t.Parallel()
+
+	doc := ParseAST("!{\"raw\":\"q\",\"type\":\"message\",\"id\":\"1\"}\n`!{\"raw\":\"q\",\"type\":\"message\",\"id\":\"2\"}`\n> !{\"raw\":\"q\",\"type\":\"message\",\"id\":\"3\"} !{\"raw\":\"q\",\"type\":\"message\",\"id\":\"1\"}")
+	assert.Equal(t, []string{"1", "3"}, doc.CitedMessageIDs())
+}
+
+func TestDocument_Links(t *testing.T) {
+	t.Parallel()
+
+	doc := ParseAST("https://a.example [b](https://b.example)\n`https://c.example`")
+	assert.Equal(t, []string{"https://a.example", "https://b.example"}, doc.Links())
+}
+
+func TestDocument_Stamps(t *testing.T) {
+	t.Parallel()
+
+	doc := ParseAST(":a::b: `:c:`")
+	assert.Equal(t, []string{"a", "b"}, doc.Stamps())
+}
@@ -0,0 +1,139 @@
+package message
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var langRegex = regexp.MustCompile(`^[a-zA-Z0-9_+-]{1,32}$`)
+
+// PlainText メッセージを平文化したものを返します。改行は保たれます
+func (n *Document) PlainText() string {
+	var sb strings.Builder
+	writePlainBlocks(&sb, n.Blocks)
+	return sb.String()
+}
+
+// SingleLineText メッセージを平文化し、改行を空白に置き換えたものを返します
+func (n *Document) SingleLineText() string {
+	return strings.Replace(n.PlainText(), "\n", " ", -1)
+}
+
+func writePlainBlocks(sb *strings.Builder, blocks []Node) {
+	for i, b := range blocks {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		switch v := b.(type) {
+		case *Paragraph:
+			writePlainInlines(sb, v.Inlines)
+		case *Quote:
+			writePlainBlocks(sb, v.Blocks)
+		case *CodeBlock:
+			sb.WriteString(v.Code)
+		}
+	}
+}
+
+func writePlainInlines(sb *strings.Builder, inlines []Node) {
+	for _, n := range inlines {
+		switch v := n.(type) {
+		case *Text:
+			sb.WriteString(v.Value)
+		case *LineBreak:
+			sb.WriteByte('\n')
+		case *Code:
+			sb.WriteString(v.Value)
+		case *Link:
+			sb.WriteString(v.Label)
+		case *Mention:
+			sb.WriteString(v.Raw)
+		case *ChannelLink:
+			sb.WriteString(v.Raw)
+		case *FileEmbed:
+			sb.WriteString("[添付ファイル]")
+		case *PollEmbed:
+			sb.WriteString("[投票]")
+		case *MessageCitation:
+			sb.WriteString("[引用]")
+		case *Embed:
+			sb.WriteString(v.Raw)
+		case *Stamp:
+			sb.WriteString(":" + v.Name + ":")
+		}
+	}
+}
+
+// HTML メッセージをHTMLに変換したものを返します
+//
+// 全てのテキスト及び属性値はエスケープされ、リンクはhttp(s)のURLのみ出力されます。
+func (n *Document) HTML() string {
+	var sb strings.Builder
+	writeHTMLBlocks(&sb, n.Blocks)
+	return sb.String()
+}
+
+func writeHTMLBlocks(sb *strings.Builder, blocks []Node) {
+	for _, b := range blocks {
+		switch v := b.(type) {
+		case *Paragraph:
+			sb.WriteString("<p>")
+			writeHTMLInlines(sb, v.Inlines)
+			sb.WriteString("</p>")
+		case *Quote:
+			sb.WriteString("<blockquote>")
+			writeHTMLBlocks(sb, v.Blocks)
+			sb.WriteString("</blockquote>")
+		case *CodeBlock:
+			sb.WriteString("<pre><code")
+			if langRegex.MatchString(v.Lang) {
+				sb.WriteString(` class="language-` + v.Lang + `"`)
+			}
+			sb.WriteString(">")
+			sb.WriteString(html.EscapeString(v.Code))
+			sb.WriteString("</code></pre>")
+		}
+	}
+}
+
+func writeHTMLInlines(sb *strings.Builder, inlines []Node) {
+	for _, n := range inlines {
+		switch v := n.(type) {
+		case *Text:
+			sb.WriteString(html.EscapeString(v.Value))
+		case *LineBreak:
+			sb.WriteString("<br>")
+		case *Code:
+			sb.WriteString("<code>" + html.EscapeString(v.Value) + "</code>")
+		case *Link:
+			if !strings.HasPrefix(v.URL, "http://") && !strings.HasPrefix(v.URL, "https://") {
+				sb.WriteString(html.EscapeString(v.Label))
+				continue
+			}
+			sb.WriteString(`<a href="` + html.EscapeString(v.URL) + `" target="_blank" rel="nofollow noopener noreferrer">`)
+			sb.WriteString(html.EscapeString(v.Label))
+			sb.WriteString("</a>")
+		case *Mention:
+			writeHTMLEmbed(sb, "mention", &v.EmbeddedInfo, v.Raw)
+		case *ChannelLink:
+			writeHTMLEmbed(sb, "channel-link", &v.EmbeddedInfo, v.Raw)
+		case *FileEmbed:
+			writeHTMLEmbed(sb, "file", &v.EmbeddedInfo, "[添付ファイル]")
+		case *PollEmbed:
+			writeHTMLEmbed(sb, "poll", &v.EmbeddedInfo, "[投票]")
+		case *MessageCitation:
+			writeHTMLEmbed(sb, "message-citation", &v.EmbeddedInfo, "[引用]")
+		case *Embed:
+			writeHTMLEmbed(sb, "embed", &v.EmbeddedInfo, v.Raw)
+		case *Stamp:
+			sb.WriteString(`<span class="stamp" data-name="` + v.Name + `">:` + v.Name + `:</span>`)
+		}
+	}
+}
+
+func writeHTMLEmbed(sb *strings.Builder, class string, info *EmbeddedInfo, content string) {
+	sb.WriteString(`<span class="` + class + `" data-type="` + html.EscapeString(info.Type) + `" data-id="` + html.EscapeString(info.ID) + `">`)
+	sb.WriteString(html.EscapeString(content))
+	sb.WriteString("</span>")
+}
@@ -0,0 +1,71 @@
+package message
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDocument_PlainText(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	doc := ParseAST("hello !{\"raw\":\"@a\",\"type\":\"user\",\"id\":\"1\"} :blobcat:\n[label](https://example.com) `code`\n\n```\nx\ny\n```\n> !{\"raw\":\"\",\"type\":\"file\",\"id\":\"3\"}")
+	assert.Equal("hello @a :blobcat:\nlabel code\nx\ny\n[添付ファイル]", doc.PlainText())
+	assert.Equal("hello @a :blobcat: label code x y [添付ファイル]", doc.SingleLineText())
+}
+
+func TestDocument_HTML(t *testing.T) {
+	t.Parallel()
+
+	type Case struct {
+		Message string
+		HTML    string
+	}
+
+	cases := []Case{
+		{
+			"<script>alert(1)</script>",
+			"<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>",
+		},
+		{
+			"a\nb\n\nc",
+			"<p>a<br>b</p><p>c</p>",
+		},
+		{
+			`!{"raw":"@a\"<b>","type":"user","id":"1\"><x"}`,
+			`<p><span class="mention" data-type="user" data-id="1&#34;&gt;&lt;x">@a&#34;&lt;b&gt;</span></p>`,
+		},
+		{
+			`#!{"raw":"#c","type":"channel","id":"2"} !{"raw":"","type":"file","id":"3"} !{"raw":"q","type":"poll","id":"4"}`,
+			`<p>#<span class="channel-link" data-type="channel" data-id="2">#c</span> <span class="file" data-type="file" data-id="3">[添付ファイル]</span> <span class="poll" data-type="poll" data-id="4">[投票]</span></p>`,
+		},
+		{
+			`!{"raw":"https://q.example/messages/5","type":"message","id":"5"}`,
+			`<p><span class="message-citation" data-type="message" data-id="5">[引用]</span></p>`,
+		},
+		{
+			`:blobcat: [<i>](https://example.com/?a=1&b=2) [x](javascript:alert(1))`,
+			`<p><span class="stamp" data-name="blobcat">:blobcat:</span> <a href="https://example.com/?a=1&amp;b=2" target="_blank" rel="nofollow noopener noreferrer">&lt;i&gt;</a> [x](javascript:alert(1))</p>`,
+		},
+		{
+			`https://example.com/?a=1&b=2`,
+			`<p><a href="https://example.com/?a=1&amp;b=2" target="_blank" rel="nofollow noopener noreferrer">https://example.com/?a=1&amp;b=2</a></p>`,
+		},
+		{
+			"```go\"><script>\n<b>\n```\n```js\nx\n```",
+			`<pre><code>&lt;b&gt;</code></pre><pre><code class="language-js">x</code></pre>`,
+		},
+		{
+			"> `<a>`",
+			"<blockquote><p><code>&lt;a&gt;</code></p></blockquote>",
+		},
+	}
+
+	for _, v := range cases {
+		v := v
+		t.Run(v.Message, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, v.HTML, ParseAST(v.Message).HTML())
+		})
+	}
+}
@@ -11,6 +11,7 @@ func TestIsPrivateIP(t *testing.T) {
 	assert := assert.New(t)
 
 	assert.True(IsPrivateIP(net.ParseIP("127.0.0.1")))
+	assert.True(IsPrivateIP(net.ParseIP("169.254.169.254")))
 	assert.False(IsPrivateIP(net.ParseIP("8.8.8.8")))
 }
 
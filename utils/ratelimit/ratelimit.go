@@ -0,0 +1,148 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// 使われなくなったバケットを削除する間隔
+const gcInterval = time.Minute
+
+// Limiter キー毎のトークンバケットによるレートリミッター
+//
+// nilのLimiterは全てのリクエストを許可します。
+type Limiter struct {
+	interval time.Duration
+	burst    float64
+	now      func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	lastGC  time.Time
+}
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// NewLimiter 1分あたりperMinute個のトークンが補充され、最大burst個のトークンを貯められるLimiterを生成します
+//
+// perMinuteが0以下の場合はnilを返します。burstが1未満の場合は1になります。
+func NewLimiter(perMinute, burst int) *Limiter {
+	if perMinute <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &Limiter{
+		interval: time.Minute / time.Duration(perMinute),
+		burst:    float64(burst),
+		now:      time.Now,
+		buckets:  map[string]*bucket{},
+	}
+}
+
+// Allow キーのバケットからトークンを1つ消費します
+//
+// 消費できた場合はtrueを、できなかった場合はfalseと次のトークンが補充されるまでの時間を返します。
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	if l == nil {
+		return true, 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.gc(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, updatedAt: now}
+		l.buckets[key] = b
+	} else {
+		b.tokens += float64(now.Sub(b.updatedAt)) / float64(l.interval)
+		if b.tokens > l.burst {
+			b.tokens = l.burst
+		}
+		b.updatedAt = now
+	}
+
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) * float64(l.interval))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// gc トークンが満タンまで補充されたバケットを削除します
+func (l *Limiter) gc(now time.Time) {
+	if now.Sub(l.lastGC) < gcInterval {
+		return
+	}
+	l.lastGC = now
+	for k, b := range l.buckets {
+		if b.tokens+float64(now.Sub(b.updatedAt))/float64(l.interval) >= l.burst {
+			delete(l.buckets, k)
+		}
+	}
+}
+
+// Cooldown キー毎に、前回の実行から一定時間が経つまで次の実行を許可しないリミッター
+//
+// ゼロ値で使用できます。
+type Cooldown struct {
+	mu     sync.Mutex
+	last   map[string]time.Time
+	lastGC time.Time
+	// 削除判定に使う最大のinterval
+	maxInterval time.Duration
+}
+
+// Wait キーの前回の実行からinterval以上経っているかを確認します
+//
+// 経っていない場合は実行可能になるまでの時間を返し、経っている場合やintervalが0以下の場合は0を返します。
+// 実行は記録しないため、実行に成功した後にRecordを呼ぶ必要があります。
+func (c *Cooldown) Wait(key string, interval time.Duration) time.Duration {
+	if interval <= 0 {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if t, ok := c.last[key]; ok {
+		if wait := interval - time.Since(t); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
+
+// Record キーの実行を現在時刻で記録します
+//
+// intervalには記録を保持する期間として、Waitに指定するものと同じ値を指定します。intervalが0以下の場合は何もしません。
+func (c *Cooldown) Record(key string, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if c.last == nil {
+		c.last = map[string]time.Time{}
+	}
+	if interval > c.maxInterval {
+		c.maxInterval = interval
+	}
+	if now.Sub(c.lastGC) >= gcInterval {
+		c.lastGC = now
+		for k, t := range c.last {
+			if now.Sub(t) >= c.maxInterval {
+				delete(c.last, k)
+			}
+		}
+	}
+	c.last[key] = now
+}
@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestNewLimiter(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	assert.Nil(NewLimiter(0, 10))
+	if l := NewLimiter(60, 0); assert.NotNil(l) {
+		assert.Equal(time.Second, l.interval)
+		assert.EqualValues(1, l.burst)
+	}
+
+	var l *Limiter
+	ok, _ := l.Allow("a")
+	assert.True(ok)
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	now := time.Now()
+	l := NewLimiter(60, 3)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		ok, _ := l.Allow("a")
+		assert.True(ok)
+	}
+	ok, wait := l.Allow("a")
+	assert.False(ok)
+	assert.Equal(time.Second, wait)
+
+	// 他のキーには影響しない
+	ok, _ = l.Allow("b")
+	assert.True(ok)
+
+	now = now.Add(500 * time.Millisecond)
+	ok, wait = l.Allow("a")
+	assert.False(ok)
+	assert.Equal(500*time.Millisecond, wait)
+
+	now = now.Add(500 * time.Millisecond)
+	ok, _ = l.Allow("a")
+	assert.True(ok)
+
+	// 満タンになったバケットは削除される
+	now = now.Add(time.Hour)
+	ok, _ = l.Allow("c")
+	assert.True(ok)
+	assert.Len(l.buckets, 1)
+}
+
+func TestCooldown(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	var c Cooldown
+	assert.Zero(c.Wait("a", time.Hour))
+	// 記録するまでは何度でも実行できる
+	assert.Zero(c.Wait("a", time.Hour))
+	c.Record("a", time.Hour)
+	assert.True(c.Wait("a", time.Hour) > 59*time.Minute)
+
+	assert.Zero(c.Wait("b", time.Hour))
+	assert.Zero(c.Wait("a", 0))
+	assert.Zero(c.Wait("a", time.Nanosecond))
+
+	// intervalが0以下の場合は記録しない
+	c.Record("c", 0)
+	assert.Zero(c.Wait("c", time.Hour))
+}